@@ -9,6 +9,7 @@ import { SQLConnectionLimits, SQLOptions } from '../../types';
 import { MaxLifetimeField } from './MaxLifetimeField';
 import { MaxOpenConnectionsField } from './MaxOpenConnectionsField';
 import { NumberInput } from './NumberInput';
+import { QueryTimeoutField } from './QueryTimeoutField';
 
 interface Props<T> {
   onOptionsChange: Function;
@@ -169,6 +170,12 @@ export const ConnectionLimits = <T extends SQLConnectionLimits>(props: Props<T>)
         onMaxLifetimeChanged={onJSONDataNumberChanged('connMaxLifetime')}
         jsonData={jsonData}
       />
+
+      <QueryTimeoutField
+        labelWidth={labelWidth}
+        onQueryTimeoutChanged={onJSONDataNumberChanged('queryTimeout')}
+        jsonData={jsonData}
+      />
     </ConfigSubSection>
   );
 };
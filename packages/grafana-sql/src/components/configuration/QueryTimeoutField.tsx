@@ -0,0 +1,46 @@
+import { Trans } from '@grafana/i18n';
+import { Field, Icon, Label, Stack, Tooltip } from '@grafana/ui';
+
+import { SQLOptions } from '../../types';
+
+import { NumberInput } from './NumberInput';
+
+interface Props {
+  labelWidth: number;
+  onQueryTimeoutChanged: (number?: number) => void;
+  jsonData: SQLOptions;
+}
+export function QueryTimeoutField({ labelWidth, onQueryTimeoutChanged, jsonData }: Props) {
+  return (
+    <Field
+      label={
+        <Label>
+          <Stack gap={0.5}>
+            <span>
+              <Trans i18nKey="grafana-sql.components.connection-limits.query-timeout">Query timeout</Trans>
+            </span>
+            <Tooltip
+              content={
+                <span>
+                  <Trans i18nKey="grafana-sql.components.connection-limits.content-query-timeout">
+                    The maximum amount of time in seconds a query may run. Queries running longer are cancelled on the
+                    database server. If set to 0, queries have no timeout.
+                  </Trans>
+                </span>
+              }
+            >
+              <Icon name="info-circle" size="sm" />
+            </Tooltip>
+          </Stack>
+        </Label>
+      }
+    >
+      <NumberInput
+        value={jsonData.queryTimeout ?? 0}
+        defaultValue={0}
+        onChange={onQueryTimeoutChanged}
+        width={labelWidth}
+      />
+    </Field>
+  );
+}
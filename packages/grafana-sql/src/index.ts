@@ -16,6 +16,7 @@ export { formatSQL } from './utils/formatSQL';
 export { ConnectionLimits } from './components/configuration/ConnectionLimits';
 export { MaxLifetimeField } from './components/configuration/MaxLifetimeField';
 export { MaxOpenConnectionsField } from './components/configuration/MaxOpenConnectionsField';
+export { QueryTimeoutField } from './components/configuration/QueryTimeoutField';
 export { Divider } from './components/configuration/Divider';
 export { TLSSecretsConfig } from './components/configuration/TLSSecretsConfig';
 export { useMigrateDatabaseFields } from './components/configuration/useMigrateDatabaseFields';
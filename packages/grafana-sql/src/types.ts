@@ -32,6 +32,7 @@ export interface SQLConnectionLimits {
   maxIdleConns: number;
   maxIdleConnsAuto: boolean;
   connMaxLifetime: number;
+  queryTimeout?: number;
 }
 
 export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
@@ -163,6 +163,63 @@ var adminCommands = []*cli.Command{
 					},
 				},
 			},
+			{
+				Name:   "export-namespace",
+				Usage:  "Exports the unified storage resources of a namespace into a parquet archive",
+				Action: runDbCommand(datamigrations.ExportNamespace),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "The namespace to export.",
+						Value: "default",
+					},
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "The archive file to write. Defaults to a new file in the current directory.",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resource",
+						Usage: "Only export this group/resource, for example dashboard.grafana.app/dashboards. Can be repeated.",
+					},
+					&cli.BoolFlag{
+						Name:  "history",
+						Usage: "Include the previous versions of each resource.",
+					},
+					&cli.BoolFlag{
+						Name:  "blobs",
+						Usage: "Include the large objects referenced by the resources.",
+					},
+				},
+			},
+			{
+				Name:   "import-namespace",
+				Usage:  "Imports a parquet archive written by export-namespace into unified storage",
+				Action: runDbCommand(datamigrations.ImportNamespace),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Usage:    "The archive file to import.",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "The target namespace. Defaults to the namespace of the archive.",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resource",
+						Usage: "Only import this group/resource, for example dashboard.grafana.app/dashboards. Can be repeated.",
+					},
+					&cli.StringFlag{
+						Name:  "conflict",
+						Usage: "What to do with resources that already exist: skip, overwrite, rename or replace (rebuilds the resource types from the archive).",
+						Value: "skip",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only report what would be imported.",
+					},
+				},
+			},
 		},
 	},
 	{
@@ -0,0 +1,198 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/metrics"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	defaultAnomalyBandWidth = 3.0
+	defaultAnomalySeasons   = 3
+	defaultAnomalyAlpha     = 0.3
+	defaultAnomalyBeta      = 0.1
+	defaultAnomalyGamma     = 0.3
+)
+
+// What the anomaly expression returns
+// +enum
+type AnomalyOutput string
+
+const (
+	// One anomaly score series per input series
+	AnomalyOutputScore AnomalyOutput = "score"
+
+	// The expected value, upper and lower band series for each input series
+	AnomalyOutputBands AnomalyOutput = "bands"
+)
+
+// AnomalyCommand is an expression command that computes the expected values of time series
+// and scores how far each point is from them. Unlike the ML outlier command it runs locally.
+type AnomalyCommand struct {
+	VarToScore string
+	Output     AnomalyOutput
+	Options    mathexp.AnomalyOptions
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, varToScore string, output AnomalyOutput, opts mathexp.AnomalyOptions) (*AnomalyCommand, error) {
+	switch output {
+	case AnomalyOutputScore, AnomalyOutputBands:
+	default:
+		return nil, fmt.Errorf("unsupported anomaly output '%s', expected one of [%s, %s]", output, AnomalyOutputScore, AnomalyOutputBands)
+	}
+	switch opts.Model {
+	case mathexp.AnomalyModelHoltWinters:
+		// the factors are checked in a fixed order, so the same query always returns the same error
+		for _, factor := range []struct {
+			name  string
+			value float64
+		}{{"alpha", opts.Alpha}, {"beta", opts.Beta}, {"gamma", opts.Gamma}} {
+			if factor.value < 0 || factor.value > 1 {
+				return nil, fmt.Errorf("smoothing factor %s must be between 0 and 1, got %v", factor.name, factor.value)
+			}
+		}
+	case mathexp.AnomalyModelSeasonalBaseline:
+		if opts.Season <= 0 {
+			return nil, fmt.Errorf("model '%s' requires a season", opts.Model)
+		}
+		if opts.Seasons <= 0 {
+			return nil, fmt.Errorf("model '%s' requires at least one season, got %d", opts.Model, opts.Seasons)
+		}
+		if opts.Horizon > 0 {
+			return nil, fmt.Errorf("a forecast horizon is only supported by the '%s' model", mathexp.AnomalyModelHoltWinters)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported anomaly model '%s', expected one of [%s, %s]", opts.Model, mathexp.AnomalyModelHoltWinters, mathexp.AnomalyModelSeasonalBaseline)
+	}
+	switch opts.Scoring {
+	case mathexp.AnomalyScoringZScore, mathexp.AnomalyScoringMAD:
+	default:
+		return nil, fmt.Errorf("unsupported anomaly scoring '%s', expected one of [%s, %s]", opts.Scoring, mathexp.AnomalyScoringZScore, mathexp.AnomalyScoringMAD)
+	}
+	if opts.BandWidth <= 0 {
+		return nil, fmt.Errorf("band width must be greater than 0, got %v", opts.BandWidth)
+	}
+	return &AnomalyCommand{
+		VarToScore: varToScore,
+		Output:     output,
+		Options:    opts,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	varToScore := strings.TrimPrefix(q.Expression, "$")
+	if varToScore == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+
+	opts := mathexp.AnomalyOptions{
+		Model:     q.Model,
+		Scoring:   q.Scoring,
+		Seasons:   defaultAnomalySeasons,
+		Alpha:     defaultAnomalyAlpha,
+		Beta:      defaultAnomalyBeta,
+		Gamma:     defaultAnomalyGamma,
+		BandWidth: defaultAnomalyBandWidth,
+	}
+	if opts.Scoring == "" {
+		opts.Scoring = mathexp.AnomalyScoringZScore
+	}
+	var err error
+	if q.Season != "" {
+		if opts.Season, err = gtime.ParseDuration(q.Season); err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, q.Season, err)
+		}
+	}
+	if q.Horizon != "" {
+		if opts.Horizon, err = gtime.ParseDuration(q.Horizon); err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "horizon" duration field %q: %w`, q.Horizon, err)
+		}
+	}
+	if q.Seasons != nil {
+		opts.Seasons = *q.Seasons
+	}
+	if q.Alpha != nil {
+		opts.Alpha = *q.Alpha
+	}
+	if q.Beta != nil {
+		opts.Beta = *q.Beta
+	}
+	if q.Gamma != nil {
+		opts.Gamma = *q.Gamma
+	}
+	if q.BandWidth != nil {
+		opts.BandWidth = *q.BandWidth
+	}
+
+	output := q.Output
+	if output == "" {
+		output = AnomalyOutputScore
+	}
+	return NewAnomalyCommand(rn.RefID, varToScore, output, opts)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToScore}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer, _ *metrics.ExprMetrics) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("model", string(ac.Options.Model)),
+		attribute.String("scoring", string(ac.Options.Scoring)),
+		attribute.String("output", string(ac.Output)),
+	)
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToScore].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			if ac.Output == AnomalyOutputBands {
+				bands, err := v.AnomalyBands(ac.refID, ac.Options)
+				if err != nil {
+					return newRes, err
+				}
+				for _, b := range bands {
+					newRes.Values = append(newRes.Values, b)
+				}
+				continue
+			}
+			scores, err := v.AnomalyScore(ac.refID, ac.Options)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, scores)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
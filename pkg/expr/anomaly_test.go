@@ -0,0 +1,171 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *AnomalyCommand
+		expectedError string
+	}{
+		{
+			name:  "defaults",
+			query: `{"type": "anomaly", "expression": "$A", "model": "holt_winters"}`,
+			expected: &AnomalyCommand{
+				VarToScore: "A",
+				Output:     AnomalyOutputScore,
+				Options: mathexp.AnomalyOptions{
+					Model:     mathexp.AnomalyModelHoltWinters,
+					Scoring:   mathexp.AnomalyScoringZScore,
+					Seasons:   defaultAnomalySeasons,
+					Alpha:     defaultAnomalyAlpha,
+					Beta:      defaultAnomalyBeta,
+					Gamma:     defaultAnomalyGamma,
+					BandWidth: defaultAnomalyBandWidth,
+				},
+				refID: "B",
+			},
+		},
+		{
+			name:  "all fields",
+			query: `{"type": "anomaly", "expression": "A", "model": "seasonal_baseline", "scoring": "mad", "season": "1d", "seasons": 7, "bandWidth": 2, "output": "bands"}`,
+			expected: &AnomalyCommand{
+				VarToScore: "A",
+				Output:     AnomalyOutputBands,
+				Options: mathexp.AnomalyOptions{
+					Model:     mathexp.AnomalyModelSeasonalBaseline,
+					Scoring:   mathexp.AnomalyScoringMAD,
+					Season:    24 * time.Hour,
+					Seasons:   7,
+					Alpha:     defaultAnomalyAlpha,
+					Beta:      defaultAnomalyBeta,
+					Gamma:     defaultAnomalyGamma,
+					BandWidth: 2,
+				},
+				refID: "B",
+			},
+		},
+		{
+			name:          "unknown model",
+			query:         `{"type": "anomaly", "expression": "$A", "model": "prophet"}`,
+			expectedError: "unsupported anomaly model",
+		},
+		{
+			name:          "seasonal baseline without season",
+			query:         `{"type": "anomaly", "expression": "$A", "model": "seasonal_baseline"}`,
+			expectedError: "requires a season",
+		},
+		{
+			name:          "horizon with seasonal baseline",
+			query:         `{"type": "anomaly", "expression": "$A", "model": "seasonal_baseline", "season": "1d", "horizon": "1h"}`,
+			expectedError: "horizon is only supported",
+		},
+		{
+			name:          "smoothing factor out of range",
+			query:         `{"type": "anomaly", "expression": "$A", "model": "holt_winters", "alpha": 1.5}`,
+			expectedError: "smoothing factor alpha",
+		},
+		{
+			name:          "seasonal baseline without previous seasons",
+			query:         `{"type": "anomaly", "expression": "$A", "model": "seasonal_baseline", "season": "1d", "seasons": 0}`,
+			expectedError: "requires at least one season, got 0",
+		},
+		{
+			name:          "smoothing factors out of range",
+			query:         `{"type": "anomaly", "expression": "$A", "model": "holt_winters", "alpha": 1.5, "beta": -1, "gamma": 2}`,
+			expectedError: "smoothing factor alpha",
+		},
+		{
+			name:          "no expression",
+			query:         `{"type": "anomaly", "model": "holt_winters"}`,
+			expectedError: "no variable specified",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID:    "B",
+				QueryRaw: []byte(tc.query),
+			})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyExecute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 0)
+	for i := 0; i < 10; i++ {
+		v := float64(i % 2)
+		series.AppendPoint(time.Unix(int64(i*60), 0), &v)
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series, mathexp.NewNoData()}},
+	}
+
+	t.Run("score", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyOutputScore, mathexp.AnomalyOptions{
+			Model:     mathexp.AnomalyModelSeasonalBaseline,
+			Scoring:   mathexp.AnomalyScoringZScore,
+			Season:    2 * time.Minute,
+			Seasons:   1,
+			BandWidth: 3,
+		})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+		require.Equal(t, "B", res.Values[0].(mathexp.Series).Frame.RefID)
+		require.IsType(t, mathexp.NoData{}, res.Values[1])
+	})
+
+	t.Run("bands", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyOutputBands, mathexp.AnomalyOptions{
+			Model:     mathexp.AnomalyModelHoltWinters,
+			Scoring:   mathexp.AnomalyScoringZScore,
+			Season:    2 * time.Minute,
+			Alpha:     0.3,
+			BandWidth: 3,
+		})
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 4)
+		require.Equal(t, "expected", res.Values[0].GetLabels()[mathexp.AnomalyBandLabel])
+		require.Equal(t, "upper", res.Values[1].GetLabels()[mathexp.AnomalyBandLabel])
+		require.Equal(t, "lower", res.Values[2].GetLabels()[mathexp.AnomalyBandLabel])
+	})
+
+	t.Run("numbers are not supported", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyOutputScore, mathexp.AnomalyOptions{
+			Model:     mathexp.AnomalyModelHoltWinters,
+			Scoring:   mathexp.AnomalyScoringZScore,
+			BandWidth: 3,
+		})
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest(), nil)
+		require.Error(t, err)
+	})
+}
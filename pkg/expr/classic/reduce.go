@@ -15,8 +15,11 @@ func (cr reducer) ValidReduceFunc() bool {
 		return true
 	case "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null":
 		return true
+	case "first", "range", "stddev":
+		return true
 	}
-	return false
+	_, ok := mathexp.ParsePercentile(mathexp.ReducerID(cr))
+	return ok
 }
 
 //nolint:gocyclo
@@ -144,6 +147,46 @@ func (cr reducer) Reduce(series mathexp.Series) mathexp.Number {
 		if value > 0 {
 			allNull = false
 		}
+	case "first":
+		for i := 0; i < ff.Len(); i++ {
+			f := ff.GetValue(i)
+			if !nilOrNaN(f) {
+				value = *f
+				allNull = false
+				break
+			}
+		}
+	case "range":
+		values := validValues(ff)
+		if len(values) > 0 {
+			allNull = false
+			sort.Float64s(values)
+			value = values[len(values)-1] - values[0]
+		}
+	case "stddev":
+		values := validValues(ff)
+		if len(values) > 0 {
+			allNull = false
+			var mean float64
+			for _, v := range values {
+				mean += v
+			}
+			mean /= float64(len(values))
+			for _, v := range values {
+				value += (v - mean) * (v - mean)
+			}
+			value = math.Sqrt(value / float64(len(values)))
+		}
+	default:
+		if p, ok := mathexp.ParsePercentile(mathexp.ReducerID(cr)); ok {
+			values := validValues(ff)
+			if len(values) > 0 {
+				allNull = false
+				sort.Float64s(values)
+				// nearest rank, the same method as mathexp.Percentile
+				value = values[int(math.Round(float64(len(values)-1)*p/100))]
+			}
+		}
 	}
 
 	if allNull {
@@ -182,6 +225,19 @@ func calculateDiff(ff mathexp.Float64Field, allNull bool, value float64, fn func
 	return allNull, value
 }
 
+// validValues returns the values of ff that are neither null nor NaN.
+func validValues(ff mathexp.Float64Field) []float64 {
+	values := make([]float64, 0, ff.Len())
+	for i := 0; i < ff.Len(); i++ {
+		f := ff.GetValue(i)
+		if nilOrNaN(f) {
+			continue
+		}
+		values = append(values, *f)
+	}
+	return values
+}
+
 func nilOrNaN(f *float64) bool {
 	return f == nil || math.IsNaN(*f)
 }
@@ -115,6 +115,48 @@ func TestReducer(t *testing.T) {
 			inputSeries:    newSeries(nil, nil),
 			expectedNumber: newNumber(nil),
 		},
+		{
+			name:           "first should ignore null values",
+			reducer:        reducer("first"),
+			inputSeries:    newSeries(nil, util.Pointer(math.NaN()), util.Pointer(3.0), util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(3.0)),
+		},
+		{
+			name:           "range",
+			reducer:        reducer("range"),
+			inputSeries:    newSeries(util.Pointer(3.0), nil, util.Pointer(-1.0), util.Pointer(4.0)),
+			expectedNumber: newNumber(util.Pointer(5.0)),
+		},
+		{
+			name:           "range with only nulls",
+			reducer:        reducer("range"),
+			inputSeries:    newSeries(nil, nil),
+			expectedNumber: newNumber(nil),
+		},
+		{
+			name:           "stddev should ignore null values",
+			reducer:        reducer("stddev"),
+			inputSeries:    newSeries(util.Pointer(2.0), nil, util.Pointer(4.0), util.Pointer(4.0), util.Pointer(4.0), util.Pointer(5.0), util.Pointer(5.0), util.Pointer(7.0), util.Pointer(9.0)),
+			expectedNumber: newNumber(util.Pointer(2.0)),
+		},
+		{
+			name:           "p95 should ignore null values",
+			reducer:        reducer("p95"),
+			inputSeries:    newSeries(util.Pointer(1.0), nil, util.Pointer(3.0), util.Pointer(2.0)),
+			expectedNumber: newNumber(util.Pointer(3.0)),
+		},
+		{
+			name:           "p50",
+			reducer:        reducer("p50"),
+			inputSeries:    newSeries(util.Pointer(1.0), util.Pointer(3.0), util.Pointer(2.0)),
+			expectedNumber: newNumber(util.Pointer(2.0)),
+		},
+		{
+			name:           "p99 with only nulls",
+			reducer:        reducer("p99"),
+			inputSeries:    newSeries(nil, nil),
+			expectedNumber: newNumber(nil),
+		},
 	}
 
 	for _, tt := range tests {
@@ -126,6 +168,12 @@ func TestReducer(t *testing.T) {
 	}
 }
 
+func TestInvalidReducer(t *testing.T) {
+	for _, r := range []reducer{"foo", "p", "p0", "p05", "p+5", "p-5", "p100", "p9x", "stdDev"} {
+		require.False(t, r.ValidReduceFunc(), r)
+	}
+}
+
 func TestDiffReducer(t *testing.T) {
 	var tests = []struct {
 		name           string
@@ -218,6 +218,7 @@ type ResampleCommand struct {
 	VarToResample string
 	Downsampler   mathexp.ReducerID
 	Upsampler     mathexp.Upsampler
+	Options       mathexp.ResampleOptions
 	TimeRange     TimeRange
 	refID         string
 }
@@ -282,11 +283,32 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
-	return NewResampleCommand(rn.RefID, window,
+	opts := mathexp.ResampleOptions{}
+	if rawFillValue, ok := rn.Query["fillValue"]; ok && rawFillValue != nil {
+		fillValue, ok := rawFillValue.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected resample fill value to be a number, got type %T", rawFillValue)
+		}
+		opts.FillValue = fillValue
+	}
+	if rawAlign, ok := rn.Query["align"]; ok && rawAlign != nil {
+		align, ok := rawAlign.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected resample align to be a boolean, got type %T", rawAlign)
+		}
+		opts.Align = align
+	}
+
+	cmd, err := NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
 		mathexp.Upsampler(upsampler),
 		rn.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Options = opts
+	return cmd, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -308,7 +330,7 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.ResampleWithOptions(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To, gr.Options)
 			if err != nil {
 				return newRes, err
 			}
@@ -345,6 +367,8 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for local forecasting and anomaly detection
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -361,6 +385,8 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -381,6 +407,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -215,6 +215,58 @@ func randomReduceFunc() mathexp.ReducerID {
 	return res[rand.Intn(len(res))]
 }
 
+func Test_UnmarshalResampleCommand_Options(t *testing.T) {
+	var tests = []struct {
+		name            string
+		queryOptions    string
+		isError         bool
+		expectedOptions mathexp.ResampleOptions
+	}{
+		{
+			name:            "default options when not specified",
+			queryOptions:    ``,
+			expectedOptions: mathexp.ResampleOptions{},
+		},
+		{
+			name:            "fill value and alignment",
+			queryOptions:    `, "fillValue": -1.5, "align": true`,
+			expectedOptions: mathexp.ResampleOptions{FillValue: -1.5, Align: true},
+		},
+		{
+			name:         "error if fill value is not a number",
+			queryOptions: `, "fillValue": "0"`,
+			isError:      true,
+		},
+		{
+			name:         "error if align is not a boolean",
+			queryOptions: `, "align": "true"`,
+			isError:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := fmt.Sprintf(`{ "expression" : "$A", "window": "1m", "downsampler": "mean", "upsampler": "fillvalue"%s }`, test.queryOptions)
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+
+			cmd, err := UnmarshalResampleCommand(&rawNode{
+				RefID:     "A",
+				Query:     qmap,
+				TimeRange: RelativeTimeRange{},
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedOptions, cmd.Options)
+		})
+	}
+}
+
 func TestResampleCommand_Execute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 	tr := RelativeTimeRange{
@@ -0,0 +1,366 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The model used to compute the expected value of each point
+// +enum
+type AnomalyModel string
+
+const (
+	// Additive Holt-Winters (triple exponential smoothing), or Holt's linear trend method if no season is set
+	AnomalyModelHoltWinters AnomalyModel = "holt_winters"
+
+	// Median of the values at the same point in previous seasons
+	AnomalyModelSeasonalBaseline AnomalyModel = "seasonal_baseline"
+)
+
+// The method used to score the distance of each point from its expected value
+// +enum
+type AnomalyScoring string
+
+const (
+	// Residual divided by the standard deviation of all residuals
+	AnomalyScoringZScore AnomalyScoring = "zscore"
+
+	// Residual divided by the scaled median absolute deviation of all residuals
+	AnomalyScoringMAD AnomalyScoring = "mad"
+)
+
+// madScale makes the median absolute deviation a consistent estimator of the standard deviation for normally distributed data.
+const madScale = 1.4826
+
+// meanADScale does the same for the mean absolute deviation, which is used when the median absolute deviation is 0.
+const meanADScale = 1.2533
+
+// AnomalyBandLabel is the label added to the series returned by AnomalyBands to identify the band.
+const AnomalyBandLabel = "band"
+
+// AnomalyOptions configures how the expected values and anomaly scores of a series are computed.
+type AnomalyOptions struct {
+	Model   AnomalyModel
+	Scoring AnomalyScoring
+	// Season is the length of one season. It is required by the seasonal baseline model.
+	Season time.Duration
+	// Seasons is how many previous seasons the seasonal baseline model uses.
+	Seasons int
+	// Alpha, Beta and Gamma are the Holt-Winters smoothing factors for level, trend and season.
+	Alpha, Beta, Gamma float64
+	// BandWidth is the distance of the bands from the expected value, in multiples of the scoring deviation.
+	BandWidth float64
+	// Horizon is how far past the last point the bands are forecast. Only supported by the Holt-Winters model.
+	// The forecast is limited to as many points as the series has.
+	Horizon time.Duration
+}
+
+// anomalyFit holds the expected value of each point of a series and the deviation used to score residuals.
+type anomalyFit struct {
+	expected []*float64
+	forecast []*float64 // expected values past the last point, spaced by interval
+	interval time.Duration
+	scale    float64
+}
+
+// AnomalyScore returns a series where each point is the signed distance of the point from its expected value,
+// in units of the deviation selected by the scoring method. A score of 3 with z-score scoring means that the point
+// is three standard deviations above the expected value. Points without an expected value, for example during the
+// first season, or without a value are null.
+func (s Series) AnomalyScore(refID string, opts AnomalyOptions) (Series, error) {
+	sorted := sortedSeriesCopy(refID, s)
+	fit, err := fitAnomalyModel(sorted, opts)
+	if err != nil {
+		return Series{}, err
+	}
+	scores := NewSeries(refID, s.GetLabels(), sorted.Len())
+	for i := 0; i < sorted.Len(); i++ {
+		t, v := sorted.GetPoint(i)
+		if v == nil || fit.expected[i] == nil {
+			scores.SetPoint(i, t, nil)
+			continue
+		}
+		score := anomalyScore(*v-*fit.expected[i], fit.scale)
+		scores.SetPoint(i, t, &score)
+	}
+	return scores, nil
+}
+
+// AnomalyBands returns three series: the expected values and the upper and lower bands around them.
+// The series keep the labels of s, with the AnomalyBandLabel label set to "expected", "upper" or "lower".
+// If a horizon is set, the series are extended past the last point of s with forecasted values.
+func (s Series) AnomalyBands(refID string, opts AnomalyOptions) ([]Series, error) {
+	sorted := sortedSeriesCopy(refID, s)
+	fit, err := fitAnomalyModel(sorted, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{"expected", "upper", "lower"}
+	offsets := []float64{0, opts.BandWidth * fit.scale, -opts.BandWidth * fit.scale}
+	bands := make([]Series, 0, len(names))
+	for b, name := range names {
+		labels := data.Labels{}
+		if s.GetLabels() != nil {
+			labels = s.GetLabels().Copy()
+		}
+		labels[AnomalyBandLabel] = name
+		band := NewSeries(refID, labels, 0)
+		for i := 0; i < sorted.Len(); i++ {
+			band.AppendPoint(sorted.GetTime(i), offsetValue(fit.expected[i], offsets[b]))
+		}
+		if sorted.Len() > 0 {
+			last := sorted.GetTime(sorted.Len() - 1)
+			for i, f := range fit.forecast {
+				band.AppendPoint(last.Add(time.Duration(i+1)*fit.interval), offsetValue(f, offsets[b]))
+			}
+		}
+		bands = append(bands, band)
+	}
+	return bands, nil
+}
+
+func offsetValue(f *float64, offset float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	v := *f + offset
+	return &v
+}
+
+func anomalyScore(residual, scale float64) float64 {
+	if scale == 0 {
+		// All residuals are the same, so no point is more unusual than the others.
+		return 0
+	}
+	return residual / scale
+}
+
+// fitAnomalyModel computes the expected value of every point of s, which must be sorted by time.
+func fitAnomalyModel(s Series, opts AnomalyOptions) (anomalyFit, error) {
+	fit := anomalyFit{interval: medianInterval(s)}
+	var err error
+	switch opts.Model {
+	case AnomalyModelHoltWinters:
+		fit.expected, fit.forecast, err = holtWinters(s, fit.interval, opts)
+	case AnomalyModelSeasonalBaseline:
+		fit.expected, err = seasonalBaseline(s, opts)
+		if err == nil && opts.Horizon > 0 {
+			err = fmt.Errorf("a forecast horizon is only supported by the %s model", AnomalyModelHoltWinters)
+		}
+	default:
+		err = fmt.Errorf("unsupported anomaly model '%s', expected one of [%s, %s]", opts.Model, AnomalyModelHoltWinters, AnomalyModelSeasonalBaseline)
+	}
+	if err != nil {
+		return fit, err
+	}
+
+	residuals := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		v := s.GetValue(i)
+		if v == nil || fit.expected[i] == nil || math.IsNaN(*v) || math.IsInf(*v, 0) {
+			continue
+		}
+		residuals = append(residuals, *v-*fit.expected[i])
+	}
+	switch opts.Scoring {
+	case AnomalyScoringZScore:
+		if len(residuals) > 0 {
+			vals := make([]*float64, len(residuals))
+			for i := range residuals {
+				vals[i] = &residuals[i]
+			}
+			ff := Float64Field(*data.NewField("", nil, vals))
+			fit.scale = *StdDev(&ff)
+		}
+	case AnomalyScoringMAD:
+		fit.scale = madScale * medianAbsoluteDeviation(residuals)
+		if fit.scale == 0 {
+			// More than half of the residuals are the same, the few others are scored by the mean absolute deviation.
+			fit.scale = meanADScale * meanAbsoluteDeviation(residuals)
+		}
+	default:
+		return fit, fmt.Errorf("unsupported anomaly scoring '%s', expected one of [%s, %s]", opts.Scoring, AnomalyScoringZScore, AnomalyScoringMAD)
+	}
+	return fit, nil
+}
+
+// holtWinters returns the one step ahead forecast of each point of s using additive Holt-Winters smoothing,
+// and the forecast of the points within the horizon after the last point. The points of s are assumed to be
+// evenly spaced by interval. Null or non-finite points are replaced with their forecast, so they do not
+// influence the model. The points of the first season (or the first point if there is no season) are used
+// to initialize the model and have no expected value.
+func holtWinters(s Series, interval time.Duration, opts AnomalyOptions) ([]*float64, []*float64, error) {
+	expected := make([]*float64, s.Len())
+	if interval <= 0 {
+		return expected, nil, nil
+	}
+	period := 0
+	if opts.Season > 0 {
+		period = int(math.Round(float64(opts.Season) / float64(interval)))
+		if period < 2 {
+			return nil, nil, fmt.Errorf("season %s must be at least two times the interval between points (%s)", opts.Season, interval)
+		}
+	}
+
+	values := make([]float64, s.Len())
+	valid := make([]bool, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if v := s.GetValue(i); v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0) {
+			values[i], valid[i] = *v, true
+		}
+	}
+
+	start := max(period, 1)
+	if s.Len() <= start {
+		return expected, nil, nil
+	}
+
+	// Initialize the level with the mean of the first season and the seasonal components with the
+	// deviation of each point of the first season from it. The trend starts flat.
+	var level, trend float64
+	seasonal := make([]float64, max(period, 1))
+	count := 0
+	for i := 0; i < start; i++ {
+		if valid[i] {
+			level += values[i]
+			count++
+		}
+	}
+	if count == 0 {
+		return expected, nil, nil
+	}
+	level /= float64(count)
+	if period > 0 {
+		for i := 0; i < period; i++ {
+			if valid[i] {
+				seasonal[i] = values[i] - level
+			}
+		}
+	}
+
+	seasonAt := func(i int) float64 {
+		if period == 0 {
+			return 0
+		}
+		return seasonal[i%period]
+	}
+
+	for i := start; i < s.Len(); i++ {
+		f := level + trend + seasonAt(i)
+		expected[i] = &f
+
+		y := f
+		if valid[i] {
+			y = values[i]
+		}
+		prevLevel := level
+		level = opts.Alpha*(y-seasonAt(i)) + (1-opts.Alpha)*(level+trend)
+		trend = opts.Beta*(level-prevLevel) + (1-opts.Beta)*trend
+		if period > 0 {
+			seasonal[i%period] = opts.Gamma*(y-level) + (1-opts.Gamma)*seasonal[i%period]
+		}
+	}
+
+	var forecast []*float64
+	if opts.Horizon > 0 {
+		steps := min(int(opts.Horizon/interval), s.Len())
+		forecast = make([]*float64, 0, steps)
+		for h := 1; h <= steps; h++ {
+			f := level + float64(h)*trend + seasonAt(s.Len()-1+h)
+			forecast = append(forecast, &f)
+		}
+	}
+	return expected, forecast, nil
+}
+
+// seasonalBaseline returns, for each point of s, the median of the values at exactly one, two, and up to
+// opts.Seasons seasons before it. Points with no such values have no expected value.
+func seasonalBaseline(s Series, opts AnomalyOptions) ([]*float64, error) {
+	if opts.Season <= 0 {
+		return nil, fmt.Errorf("the %s model requires a season", AnomalyModelSeasonalBaseline)
+	}
+	if opts.Seasons <= 0 {
+		return nil, fmt.Errorf("the %s model requires at least one season, got %d", AnomalyModelSeasonalBaseline, opts.Seasons)
+	}
+
+	byTime := make(map[int64]float64, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0) {
+			byTime[t.UnixNano()] = *v
+		}
+	}
+
+	expected := make([]*float64, s.Len())
+	previous := make([]float64, 0, opts.Seasons)
+	for i := 0; i < s.Len(); i++ {
+		t := s.GetTime(i)
+		previous = previous[:0]
+		for k := 1; k <= opts.Seasons; k++ {
+			if v, ok := byTime[t.Add(-time.Duration(k)*opts.Season).UnixNano()]; ok {
+				previous = append(previous, v)
+			}
+		}
+		if len(previous) == 0 {
+			continue
+		}
+		m := median(previous)
+		expected[i] = &m
+	}
+	return expected, nil
+}
+
+// medianInterval returns the median of the durations between consecutive points of s, which must be sorted by time.
+func medianInterval(s Series) time.Duration {
+	if s.Len() < 2 {
+		return 0
+	}
+	intervals := make([]float64, 0, s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		intervals = append(intervals, float64(s.GetTime(i).Sub(s.GetTime(i-1))))
+	}
+	return time.Duration(median(intervals))
+}
+
+// median returns the median of values. It sorts values in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func medianAbsoluteDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	m := median(append([]float64(nil), values...))
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+	}
+	return median(deviations)
+}
+
+// meanAbsoluteDeviation returns the mean of the absolute deviations of values from their median.
+func meanAbsoluteDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	m := median(append([]float64(nil), values...))
+	var sum float64
+	for _, v := range values {
+		sum += math.Abs(v - m)
+	}
+	return sum / float64(len(values))
+}
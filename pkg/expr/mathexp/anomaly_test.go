@@ -0,0 +1,162 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+// seasonalSeries returns a series with one point per minute that repeats pattern.
+func seasonalSeries(labels data.Labels, seasons int, pattern ...float64) Series {
+	s := NewSeries("", labels, 0)
+	for i := 0; i < seasons*len(pattern); i++ {
+		v := pattern[i%len(pattern)]
+		s.AppendPoint(time.Unix(int64(i*60), 0), &v)
+	}
+	return s
+}
+
+func TestAnomalyScore(t *testing.T) {
+	t.Run("seasonal baseline scores a spike", func(t *testing.T) {
+		s := seasonalSeries(data.Labels{"host": "a"}, 4, 1, 2, 3, 4)
+		spike := 40.0
+		s.SetPoint(14, s.GetTime(14), &spike) // the third point of the fourth season
+
+		scores, err := s.AnomalyScore("B", AnomalyOptions{
+			Model:   AnomalyModelSeasonalBaseline,
+			Scoring: AnomalyScoringMAD,
+			Season:  4 * time.Minute,
+			Seasons: 3,
+		})
+		require.NoError(t, err)
+		require.Equal(t, s.Len(), scores.Len())
+		require.Equal(t, data.Labels{"host": "a"}, scores.GetLabels())
+
+		// The first season has no previous season to compare with.
+		for i := 0; i < 4; i++ {
+			require.Nil(t, scores.GetValue(i))
+		}
+		// All other residuals are 0, so the MAD is 0 and the spike is scored by the mean absolute deviation.
+		for i := 4; i < s.Len(); i++ {
+			if i == 14 {
+				require.InDelta(t, 37/(meanADScale*37/12), *scores.GetValue(i), 1e-9)
+				continue
+			}
+			require.Equal(t, float64(0), *scores.GetValue(i))
+		}
+	})
+
+	t.Run("scores are finite if all residuals are the same", func(t *testing.T) {
+		// the second season is one above the first, so every residual is 1
+		s := seasonalSeries(nil, 2, 1, 2)
+		for i := 2; i < s.Len(); i++ {
+			v := *s.GetValue(i) + 1
+			s.SetPoint(i, s.GetTime(i), &v)
+		}
+		for _, scoring := range []AnomalyScoring{AnomalyScoringZScore, AnomalyScoringMAD} {
+			scores, err := s.AnomalyScore("B", AnomalyOptions{
+				Model:   AnomalyModelSeasonalBaseline,
+				Scoring: scoring,
+				Season:  2 * time.Minute,
+				Seasons: 1,
+			})
+			require.NoError(t, err)
+			for i := 2; i < scores.Len(); i++ {
+				require.Equal(t, float64(0), *scores.GetValue(i))
+			}
+		}
+	})
+
+	t.Run("holt-winters learns a seasonal pattern", func(t *testing.T) {
+		s := seasonalSeries(nil, 10, 10, 20, 30, 20)
+		spike := 100.0
+		s.SetPoint(38, s.GetTime(38), &spike)
+
+		scores, err := s.AnomalyScore("B", AnomalyOptions{
+			Model:     AnomalyModelHoltWinters,
+			Scoring:   AnomalyScoringZScore,
+			Season:    4 * time.Minute,
+			Alpha:     0.3,
+			Beta:      0.1,
+			Gamma:     0.3,
+			BandWidth: 3,
+		})
+		require.NoError(t, err)
+		require.Nil(t, scores.GetValue(0))
+
+		maxIdx, maxScore := -1, 0.0
+		for i := 0; i < scores.Len(); i++ {
+			if v := scores.GetValue(i); v != nil && *v > maxScore {
+				maxIdx, maxScore = i, *v
+			}
+		}
+		require.Equal(t, 38, maxIdx)
+		require.Greater(t, maxScore, 3.0)
+	})
+
+	t.Run("null points have no score", func(t *testing.T) {
+		s := seasonalSeries(nil, 3, 1, 2)
+		s.SetPoint(4, s.GetTime(4), nil)
+
+		scores, err := s.AnomalyScore("B", AnomalyOptions{
+			Model:   AnomalyModelSeasonalBaseline,
+			Scoring: AnomalyScoringZScore,
+			Season:  2 * time.Minute,
+			Seasons: 1,
+		})
+		require.NoError(t, err)
+		require.Nil(t, scores.GetValue(4))
+		require.NotNil(t, scores.GetValue(5))
+	})
+
+	t.Run("seasonal baseline requires a season", func(t *testing.T) {
+		_, err := seasonalSeries(nil, 2, 1, 2).AnomalyScore("B", AnomalyOptions{
+			Model:   AnomalyModelSeasonalBaseline,
+			Scoring: AnomalyScoringZScore,
+			Seasons: 1,
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestAnomalyBands(t *testing.T) {
+	s := seasonalSeries(data.Labels{"host": "a"}, 3, 5, 5)
+	bands, err := s.AnomalyBands("B", AnomalyOptions{
+		Model:     AnomalyModelHoltWinters,
+		Scoring:   AnomalyScoringZScore,
+		Alpha:     0.5,
+		Beta:      0.1,
+		BandWidth: 2,
+		Horizon:   3 * time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, bands, 3)
+
+	for i, name := range []string{"expected", "upper", "lower"} {
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: name}, bands[i].GetLabels())
+		// the input points plus three forecasted points
+		require.Equal(t, s.Len()+3, bands[i].Len())
+		require.Equal(t, s.GetTime(s.Len()-1).Add(3*time.Minute), bands[i].GetTime(bands[i].Len()-1))
+	}
+
+	t.Run("the forecast is limited to the length of the series", func(t *testing.T) {
+		bands, err := s.AnomalyBands("B", AnomalyOptions{
+			Model:   AnomalyModelHoltWinters,
+			Scoring: AnomalyScoringZScore,
+			Alpha:   0.5,
+			Horizon: 24 * 365 * time.Hour,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2*s.Len(), bands[0].Len())
+	})
+
+	// A constant series has no residuals, so the bands collapse onto the expected value.
+	require.Nil(t, bands[0].GetValue(0))
+	for i := 1; i < bands[0].Len(); i++ {
+		require.InDelta(t, 5, *bands[0].GetValue(i), 1e-9)
+		require.InDelta(t, 5, *bands[1].GetValue(i), 1e-9)
+		require.InDelta(t, 5, *bands[2].GetValue(i), 1e-9)
+	}
+}
@@ -68,6 +68,49 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"rate": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             rate,
+	},
+	"delta": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             delta,
+	},
+	"cumsum": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             cumsum,
+	},
+	"time_shift": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             timeShift,
+		Check:         checkDurationArg(1, false),
+	},
+	"moving_avg": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             movingAvg,
+		Check:         checkDurationArg(1, true),
+	},
+	"sum_by":   aggregateBy(aggSum),
+	"avg_by":   aggregateBy(aggAvg),
+	"min_by":   aggregateBy(aggMin),
+	"max_by":   aggregateBy(aggMax),
+	"count_by": aggregateBy(aggCount),
+	"label_replace": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString, parse.TypeString, parse.TypeString, parse.TypeString},
+		VariantReturn: true,
+		F:             labelReplace,
+		Check:         checkLabelReplace,
+	},
+	"label_drop": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             labelDrop,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -0,0 +1,316 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregateFunc combines the non-null values of a group into a single value.
+// vals may be empty, in which case the function decides what an empty group means.
+type aggregateFunc func(vals []float64) *float64
+
+func aggSum(vals []float64) *float64 {
+	if len(vals) == 0 {
+		return nil
+	}
+	sum := float64(0)
+	for _, v := range vals {
+		sum += v
+	}
+	return &sum
+}
+
+func aggAvg(vals []float64) *float64 {
+	if len(vals) == 0 {
+		return nil
+	}
+	avg := *aggSum(vals) / float64(len(vals))
+	return &avg
+}
+
+func aggMin(vals []float64) *float64 {
+	if len(vals) == 0 {
+		return nil
+	}
+	m := vals[0]
+	for _, v := range vals[1:] {
+		m = math.Min(m, v)
+	}
+	return &m
+}
+
+func aggMax(vals []float64) *float64 {
+	if len(vals) == 0 {
+		return nil
+	}
+	m := vals[0]
+	for _, v := range vals[1:] {
+		m = math.Max(m, v)
+	}
+	return &m
+}
+
+func aggCount(vals []float64) *float64 {
+	c := float64(len(vals))
+	return &c
+}
+
+// aggregateBy returns a parse.Func that groups the items of its first argument by the comma
+// separated label names in its second argument and combines each group with aggF.
+func aggregateBy(aggF aggregateFunc) parse.Func {
+	return parse.Func{
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F: func(e *State, varSet Results, by string) (Results, error) {
+			return aggregate(e, varSet, splitLabelNames(by), aggF)
+		},
+	}
+}
+
+// aggregate groups the values in varSet by the given label names, and combines the values in each
+// group into one value with aggF. The resulting items only keep the labels they were grouped by.
+// If by is empty, all items are combined into one. Series are combined point by point, for each
+// timestamp that exists in any series of the group. Null values are not passed to aggF.
+func aggregate(e *State, varSet Results, by []string, aggF aggregateFunc) (Results, error) {
+	newRes := Results{}
+	if len(varSet.Values) == 0 {
+		return newRes, nil
+	}
+
+	type group struct {
+		labels data.Labels
+		items  []Value
+	}
+	groups := map[string]*group{}
+	var order []string
+	var valType parse.ReturnType
+	for _, val := range varSet.Values {
+		if val.Type() == parse.TypeNoData {
+			continue
+		}
+		if len(order) > 0 && valType != val.Type() {
+			return newRes, fmt.Errorf("can not aggregate mixed types %v and %v", valType, val.Type())
+		}
+		valType = val.Type()
+
+		labels := data.Labels{}
+		for _, name := range by {
+			if v, ok := val.GetLabels()[name]; ok {
+				labels[name] = v
+			}
+		}
+		key := labels.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.items = append(g.items, val)
+	}
+
+	if len(order) == 0 {
+		newRes.Values = append(newRes.Values, NewNoData())
+		return newRes, nil
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		labels := nilIfEmpty(g.labels)
+		switch valType {
+		case parse.TypeSeriesSet:
+			newRes.Values = append(newRes.Values, aggregateSeries(e.RefID, labels, g.items, aggF))
+		case parse.TypeNumberSet:
+			n := NewNumber(e.RefID, labels)
+			n.SetValue(aggF(nonNullValues(g.items)))
+			newRes.Values = append(newRes.Values, n)
+		case parse.TypeScalar:
+			newRes.Values = append(newRes.Values, NewScalar(e.RefID, aggF(nonNullValues(g.items))))
+		default:
+			return newRes, fmt.Errorf("can not aggregate type %v", valType)
+		}
+	}
+	return newRes, nil
+}
+
+// nonNullValues returns the non-null values of a group of Numbers or Scalars.
+func nonNullValues(items []Value) []float64 {
+	vals := make([]float64, 0, len(items))
+	for _, item := range items {
+		var f *float64
+		switch v := item.(type) {
+		case Number:
+			f = v.GetFloat64Value()
+		case Scalar:
+			f = v.GetFloat64Value()
+		}
+		if f != nil {
+			vals = append(vals, *f)
+		}
+	}
+	return vals
+}
+
+// aggregateSeries combines a group of series point by point into one series sorted by time.
+func aggregateSeries(refID string, labels data.Labels, items []Value, aggF aggregateFunc) Series {
+	// Times are keyed by their UnixNano value since time.Time values for the same instant may not be ==.
+	byTime := map[int64][]float64{}
+	var times []time.Time
+	for _, item := range items {
+		s := item.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			vals, ok := byTime[t.UnixNano()]
+			if !ok {
+				times = append(times, t)
+			}
+			if f != nil {
+				vals = append(vals, *f)
+			}
+			byTime[t.UnixNano()] = vals
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	newSeries := NewSeries(refID, labels, len(times))
+	for i, t := range times {
+		newSeries.SetPoint(i, t, aggF(byTime[t.UnixNano()]))
+	}
+	return newSeries
+}
+
+// labelReplace matches the regular expression against the value of the src label of each item. If it
+// matches, the dst label is set to the replacement, where $1, $2 and so on refer to the capture groups
+// of the match. If the replacement is empty, the dst label is removed. Items that do not match are
+// returned unchanged. The regular expression is anchored at both ends, like in PromQL's label_replace.
+func labelReplace(e *State, varSet Results, dst, replacement, src, rawRegex string) (Results, error) {
+	re, err := regexp.Compile("^(?:" + rawRegex + ")$")
+	if err != nil {
+		return Results{}, fmt.Errorf("label_replace: invalid regular expression %q: %w", rawRegex, err)
+	}
+	return perLabels(e, varSet, func(labels data.Labels) data.Labels {
+		srcVal := labels[src]
+		idx := re.FindStringSubmatchIndex(srcVal)
+		if idx == nil {
+			return labels
+		}
+		res := string(re.ExpandString([]byte{}, replacement, srcVal, idx))
+		if res == "" {
+			delete(labels, dst)
+		} else {
+			labels[dst] = res
+		}
+		return labels
+	})
+}
+
+// labelDrop removes the comma separated label names in its second argument from each item.
+func labelDrop(e *State, varSet Results, names string) (Results, error) {
+	toDrop := splitLabelNames(names)
+	return perLabels(e, varSet, func(labels data.Labels) data.Labels {
+		for _, name := range toDrop {
+			delete(labels, name)
+		}
+		return labels
+	})
+}
+
+// checkLabelReplace validates the label name and regular expression arguments of label_replace at parse time.
+func checkLabelReplace(_ *parse.Tree, f *parse.FuncNode) error {
+	dst, ok := f.Args[1].(*parse.StringNode)
+	if !ok || dst.Text == "" {
+		return fmt.Errorf("parse: label_replace requires a destination label name")
+	}
+	re, ok := f.Args[4].(*parse.StringNode)
+	if !ok {
+		return fmt.Errorf("parse: label_replace requires a regular expression")
+	}
+	if _, err := regexp.Compile("^(?:" + re.Text + ")$"); err != nil {
+		return fmt.Errorf("parse: invalid regular expression %q for label_replace: %w", re.Text, err)
+	}
+	return nil
+}
+
+// perLabels returns a copy of each item in varSet with the labels returned by labelsF.
+// labelsF is passed a copy of the item's labels, which it may modify. Like in PromQL, it is
+// an error if two items end up with the same labels, since they could no longer be told apart
+// when they are matched in a binary operation.
+func perLabels(e *State, varSet Results, labelsF func(data.Labels) data.Labels) (Results, error) {
+	newRes := Results{}
+	seen := map[string]struct{}{}
+	checkUnique := func(labels data.Labels) error {
+		key := labels.String()
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("can not have multiple items with the same labels {%s} after changing labels", key)
+		}
+		seen[key] = struct{}{}
+		return nil
+	}
+	for _, res := range varSet.Values {
+		var newVal Value
+		switch v := res.(type) {
+		case Series:
+			labels := nilIfEmpty(labelsF(copyLabels(v.GetLabels())))
+			if err := checkUnique(labels); err != nil {
+				return Results{}, err
+			}
+			newSeries := NewSeries(e.RefID, labels, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				newSeries.SetPoint(i, t, copyFloat(f))
+			}
+			newVal = newSeries
+		case Number:
+			labels := nilIfEmpty(labelsF(copyLabels(v.GetLabels())))
+			if err := checkUnique(labels); err != nil {
+				return Results{}, err
+			}
+			n := NewNumber(e.RefID, labels)
+			n.SetValue(copyFloat(v.GetFloat64Value()))
+			newVal = n
+		case Scalar:
+			// Scalars have no labels.
+			newVal = NewScalar(e.RefID, copyFloat(v.GetFloat64Value()))
+		case NoData:
+			newVal = NewNoData()
+		default:
+			return newRes, fmt.Errorf("can not change labels of type %v", res.Type())
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+func copyLabels(l data.Labels) data.Labels {
+	if l == nil {
+		return data.Labels{}
+	}
+	return l.Copy()
+}
+
+// nilIfEmpty returns nil for empty labels, so items without labels compare equal regardless of how they were built.
+func nilIfEmpty(l data.Labels) data.Labels {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// splitLabelNames splits a comma separated list of label names, ignoring whitespace and empty names.
+func splitLabelNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
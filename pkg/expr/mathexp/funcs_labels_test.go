@@ -0,0 +1,221 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestAggregateByFuncs(t *testing.T) {
+	numbers := resultValuesNoErr(
+		makeNumber("", data.Labels{"cluster": "a", "host": "1"}, float64Pointer(1)),
+		makeNumber("", data.Labels{"cluster": "a", "host": "2"}, float64Pointer(3)),
+		makeNumber("", data.Labels{"cluster": "b", "host": "3"}, float64Pointer(5)),
+		makeNumber("", data.Labels{"cluster": "b", "host": "4"}, nil),
+	)
+
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name:     "sum_by on numbers",
+			expr:     `sum_by($A, "cluster")`,
+			vars:     Vars{"A": numbers},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(5)),
+			),
+		},
+		{
+			name:     "count_by on numbers does not count nulls",
+			expr:     `count_by($A, "cluster")`,
+			vars:     Vars{"A": numbers},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(1)),
+			),
+		},
+		{
+			name:     "max_by with no labels aggregates everything",
+			expr:     `max_by($A, "")`,
+			vars:     Vars{"A": numbers},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+		},
+		{
+			name: "avg_by on series aggregates each timestamp",
+			expr: `avg_by($A, "cluster")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", data.Labels{"cluster": "a", "host": "1"},
+						tp{time.Unix(5, 0), float64Pointer(2)},
+						tp{time.Unix(10, 0), nil}),
+					makeSeries("", data.Labels{"cluster": "a", "host": "2"},
+						tp{time.Unix(10, 0), nil},
+						tp{time.Unix(5, 0), float64Pointer(4)},
+						tp{time.Unix(15, 0), float64Pointer(6)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"cluster": "a"},
+					tp{time.Unix(5, 0), float64Pointer(3)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(15, 0), float64Pointer(6)}),
+			),
+		},
+		{
+			name: "min_by on series",
+			expr: `min_by($A, "cluster, dc")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", data.Labels{"cluster": "a", "dc": "x"}, tp{time.Unix(5, 0), float64Pointer(2)}),
+					makeSeries("", data.Labels{"cluster": "a", "dc": "x"}, tp{time.Unix(5, 0), float64Pointer(-1)}),
+					makeSeries("", data.Labels{"cluster": "a"}, tp{time.Unix(5, 0), float64Pointer(7)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"cluster": "a", "dc": "x"}, tp{time.Unix(5, 0), float64Pointer(-1)}),
+				makeSeries("", data.Labels{"cluster": "a"}, tp{time.Unix(5, 0), float64Pointer(7)}),
+			),
+		},
+		{
+			name:     "sum_by without label argument should error",
+			expr:     `sum_by($A)`,
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestLabelFuncs(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		execErr  string
+		results  Results
+	}{
+		{
+			name: "label_replace with capture group",
+			expr: `label_replace($A, "cluster", "$1", "instance", "(.*)-[0-9]+")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"instance": "prod-1"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"instance": "staging"}, float64Pointer(2)),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"instance": "prod-1", "cluster": "prod"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"instance": "staging"}, float64Pointer(2)),
+			),
+		},
+		{
+			name: "label_replace with empty replacement removes the label",
+			expr: `label_replace($A, "instance", "", "instance", ".*")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", data.Labels{"instance": "prod-1"}, tp{time.Unix(5, 0), float64Pointer(1)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil, tp{time.Unix(5, 0), float64Pointer(1)}),
+			),
+		},
+		{
+			name:     "label_replace with invalid regex should error",
+			expr:     `label_replace($A, "cluster", "$1", "instance", "(")`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "label_drop",
+			expr: `label_drop($A, "host,pod")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"cluster": "a", "host": "1", "pod": "x"}, float64Pointer(1)),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(1)),
+			),
+		},
+		{
+			name: "label functions can be chained with aggregations",
+			expr: `sum_by(label_replace($A, "cluster", "$1", "instance", "(.*)-[0-9]+"), "cluster")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"instance": "prod-1"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"instance": "prod-2"}, float64Pointer(2)),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"cluster": "prod"}, float64Pointer(3)),
+			),
+		},
+		{
+			name: "label_replace that makes labels identical should error",
+			expr: `label_replace($A, "instance", "$1", "instance", "(.*)-[0-9]+")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeNumber("", data.Labels{"instance": "prod-1"}, float64Pointer(1)),
+					makeNumber("", data.Labels{"instance": "prod-2"}, float64Pointer(2)),
+				),
+			},
+			newErrIs: require.NoError,
+			execErr:  "same labels",
+		},
+		{
+			name: "label_drop that makes labels identical should error",
+			expr: `label_drop($A, "host")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", data.Labels{"cluster": "a", "host": "1"}, tp{time.Unix(5, 0), float64Pointer(1)}),
+					makeSeries("", data.Labels{"cluster": "a", "host": "2"}, tp{time.Unix(5, 0), float64Pointer(2)}),
+				),
+			},
+			newErrIs: require.NoError,
+			execErr:  "same labels",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				if tt.execErr != "" {
+					require.ErrorContains(t, err, tt.execErr)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// checkDurationArg returns a parse time check that the string argument at argIdx is a valid duration.
+func checkDurationArg(argIdx int, positive bool) func(*parse.Tree, *parse.FuncNode) error {
+	return func(_ *parse.Tree, f *parse.FuncNode) error {
+		s, ok := f.Args[argIdx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: expected a duration string for argument %v of %s", argIdx, f.Name)
+		}
+		d, err := gtime.ParseDuration(s.Text)
+		if err != nil {
+			return fmt.Errorf("parse: invalid duration %q for argument %v of %s: %w", s.Text, argIdx, f.Name, err)
+		}
+		if positive && d <= 0 {
+			return fmt.Errorf("parse: duration for argument %v of %s must be greater than zero, got %q", argIdx, f.Name, s.Text)
+		}
+		return nil
+	}
+}
+
+// rate returns the per-second rate of change between consecutive points of each series.
+// The first point of each series is dropped since it has no previous point. A point is null if
+// either of the two values it is computed from is null. Counter resets are not accounted for.
+// Numbers and Scalars have no previous value, so NaN is returned for them.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, func(s Series) (Series, error) {
+		return seriesDiff(e.RefID, s, true), nil
+	}, nanFloat)
+}
+
+// delta returns the difference between consecutive points of each series.
+// Nulls and Numbers/Scalars are handled the same way as in rate.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, func(s Series) (Series, error) {
+		return seriesDiff(e.RefID, s, false), nil
+	}, nanFloat)
+}
+
+// cumsum returns the running sum of each series. Null points stay null in the
+// output and do not change the sum. Numbers and Scalars are returned unchanged.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, func(s Series) (Series, error) {
+		sorted := sortedSeriesCopy(e.RefID, s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), sorted.Len())
+		sum := float64(0)
+		for i := 0; i < sorted.Len(); i++ {
+			t, f := sorted.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries, nil
+	}, copyFloat)
+}
+
+// timeShift moves the timestamps of each series forward by the duration argument, so for
+// example time_shift($A, "1d") lines up yesterday's values with today's. A negative duration
+// moves timestamps backward. Numbers and Scalars are returned unchanged.
+func timeShift(e *State, varSet Results, rawDur string) (Results, error) {
+	dur, err := gtime.ParseDuration(rawDur)
+	if err != nil {
+		return Results{}, fmt.Errorf("time_shift: invalid duration %q: %w", rawDur, err)
+	}
+	return perSeries(e, varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(dur), copyFloat(f))
+		}
+		return newSeries, nil
+	}, copyFloat)
+}
+
+// movingAvg returns, for each point of each series, the average of the non-null values
+// within the window ending at (and including) that point. If the window has no non-null
+// values the point is null. NaN values propagate to every window that contains them.
+// Numbers and Scalars are returned unchanged.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return Results{}, fmt.Errorf("moving_avg: invalid window %q: %w", rawWindow, err)
+	}
+	return perSeries(e, varSet, func(s Series) (Series, error) {
+		sorted := sortedSeriesCopy(e.RefID, s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), sorted.Len())
+		start := 0
+		sum, count := float64(0), 0
+		for i := 0; i < sorted.Len(); i++ {
+			t, f := sorted.GetPoint(i)
+			if f != nil {
+				sum += *f
+				count++
+			}
+			for ; start < i && !sorted.GetTime(start).After(t.Add(-window)); start++ {
+				if old := sorted.GetValue(start); old != nil {
+					sum -= *old
+					count--
+				}
+			}
+			if count == 0 {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			avg := sum / float64(count)
+			if math.IsNaN(sum) {
+				// Once NaN has been added, subtracting it again does not restore the sum.
+				avg = windowAvg(sorted, start, i)
+			}
+			newSeries.SetPoint(i, t, &avg)
+		}
+		return newSeries, nil
+	}, copyFloat)
+}
+
+// windowAvg returns the average of the non-null values of s between the start and end indices (inclusive).
+func windowAvg(s Series, start, end int) float64 {
+	sum, count := float64(0), 0
+	for i := start; i <= end; i++ {
+		if f := s.GetValue(i); f != nil {
+			sum += *f
+			count++
+		}
+	}
+	return sum / float64(count)
+}
+
+// seriesDiff returns a series of the difference between each point and the previous point of s.
+// If perSecond is true, the difference is divided by the number of seconds between the two points.
+func seriesDiff(refID string, s Series, perSecond bool) Series {
+	sorted := sortedSeriesCopy(refID, s)
+	if sorted.Len() < 2 {
+		return NewSeries(refID, s.GetLabels(), 0)
+	}
+	newSeries := NewSeries(refID, s.GetLabels(), sorted.Len()-1)
+	for i := 1; i < sorted.Len(); i++ {
+		prevT, prevF := sorted.GetPoint(i - 1)
+		t, f := sorted.GetPoint(i)
+		if prevF == nil || f == nil {
+			newSeries.SetPoint(i-1, t, nil)
+			continue
+		}
+		d := *f - *prevF
+		if perSecond {
+			secs := t.Sub(prevT).Seconds()
+			if secs == 0 {
+				// Points with duplicate timestamps have no defined rate.
+				newSeries.SetPoint(i-1, t, nil)
+				continue
+			}
+			d /= secs
+		}
+		newSeries.SetPoint(i-1, t, &d)
+	}
+	return newSeries
+}
+
+// sortedSeriesCopy returns a copy of s sorted by time from oldest to newest.
+// The input is not sorted in place because it may be referenced by other nodes.
+func sortedSeriesCopy(refID string, s Series) Series {
+	newSeries := NewSeries(refID, s.GetLabels(), s.Len())
+	sorted := true
+	var prev time.Time
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if i > 0 && t.Before(prev) {
+			sorted = false
+		}
+		prev = t
+		newSeries.SetPoint(i, t, f)
+	}
+	if !sorted {
+		newSeries.SortByTime(false)
+	}
+	return newSeries
+}
+
+// perSeries passes each Series in varSet to seriesF. Numbers and Scalars have no time
+// dimension, so their value is passed to floatF instead. NoData values are kept as NoData.
+func perSeries(e *State, varSet Results, seriesF func(s Series) (Series, error), floatF func(f *float64) *float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		var newVal Value
+		switch v := res.(type) {
+		case Series:
+			s, err := seriesF(v)
+			if err != nil {
+				return newRes, err
+			}
+			newVal = s
+		case Number:
+			n := NewNumber(e.RefID, v.GetLabels())
+			n.SetValue(floatF(v.GetFloat64Value()))
+			newVal = n
+		case Scalar:
+			newVal = NewScalar(e.RefID, floatF(v.GetFloat64Value()))
+		case NoData:
+			newVal = NewNoData()
+		default:
+			return newRes, fmt.Errorf("can not perform a series function on type %v", res.Type())
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+func nanFloat(_ *float64) *float64 {
+	f := math.NaN()
+	return &f
+}
+
+func copyFloat(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	nF := *f
+	return &nF
+}
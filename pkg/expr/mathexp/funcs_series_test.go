@@ -0,0 +1,211 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestSeriesFuncs(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name: "rate on series",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(30)},
+						tp{time.Unix(20, 0), float64Pointer(25)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(-0.5)}),
+			),
+		},
+		{
+			name: "rate sorts unsorted series and returns null next to null points",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(20, 0), float64Pointer(40)},
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), nil}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), nil}),
+			),
+		},
+		{
+			name: "rate on number is NaN",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", data.Labels{"host": "a"}, float64Pointer(3))),
+			},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(makeNumber("", data.Labels{"host": "a"}, NaN)),
+		},
+		{
+			name: "delta on series with a single point is empty",
+			expr: "delta($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(makeSeries("", nil)),
+		},
+		{
+			name: "delta on series",
+			expr: "delta($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(60, 0), float64Pointer(4)},
+						tp{time.Unix(120, 0), float64Pointer(math.NaN())}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(60, 0), float64Pointer(3)},
+					tp{time.Unix(120, 0), NaN}),
+			),
+		},
+		{
+			name: "cumsum skips nulls",
+			expr: "cumsum($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(10, 0), nil},
+						tp{time.Unix(20, 0), float64Pointer(2)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(3)}),
+			),
+		},
+		{
+			name:     "cumsum on scalar is unchanged",
+			expr:     "cumsum(2)",
+			vars:     Vars{},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(NewScalar("", float64Pointer(2))),
+		},
+		{
+			name: "time_shift on series",
+			expr: `time_shift($A, "1d")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(60, 0), nil}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0).Add(24 * time.Hour), float64Pointer(1)},
+					tp{time.Unix(60, 0).Add(24 * time.Hour), nil}),
+			),
+		},
+		{
+			name:     "time_shift with invalid duration should error",
+			expr:     `time_shift($A, "yesterday")`,
+			newErrIs: require.Error,
+		},
+		{
+			name: "moving_avg on series",
+			expr: `moving_avg($A, "20s")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(2)},
+						tp{time.Unix(10, 0), float64Pointer(4)},
+						tp{time.Unix(20, 0), nil},
+						tp{time.Unix(30, 0), float64Pointer(9)},
+						tp{time.Unix(40, 0), nil},
+						tp{time.Unix(50, 0), nil}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(4)},
+					tp{time.Unix(30, 0), float64Pointer(9)},
+					tp{time.Unix(40, 0), float64Pointer(9)},
+					tp{time.Unix(50, 0), nil}),
+			),
+		},
+		{
+			name: "moving_avg recovers after NaN leaves the window",
+			expr: `moving_avg($A, "10s")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(math.NaN())},
+						tp{time.Unix(10, 0), float64Pointer(4)}),
+				),
+			},
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), NaN},
+					tp{time.Unix(10, 0), float64Pointer(4)}),
+			),
+		},
+		{
+			name:     "moving_avg with zero window should error",
+			expr:     `moving_avg($A, "0s")`,
+			newErrIs: require.Error,
+		},
+	}
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				require.NoError(t, err)
+				if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+					require.FailNow(t, tt.name, diff)
+				}
+			}
+		})
+	}
+}
@@ -333,6 +333,15 @@ func (t *Tree) Func() (f *FuncNode) {
 			}
 			f.append(newString(token.pos, token.val, s))
 		case itemRightParen:
+			if len(f.Args) > 0 {
+				// a comma must be followed by another argument
+				t.unexpected(token, "func")
+			}
+			return
+		}
+		if token = t.next(); token.typ != itemComma {
+			t.backup()
+			t.expect(itemRightParen, "func")
 			return
 		}
 	}
@@ -0,0 +1,101 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testFuncs = map[string]Func{
+	"one": {
+		Args:   []ReturnType{TypeSeriesSet},
+		Return: TypeSeriesSet,
+	},
+	"two": {
+		Args:   []ReturnType{TypeSeriesSet, TypeScalar},
+		Return: TypeSeriesSet,
+	},
+	"three": {
+		Args:   []ReturnType{TypeSeriesSet, TypeString, TypeScalar},
+		Return: TypeSeriesSet,
+	},
+}
+
+func TestParseFuncArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		args    int
+		wantErr string
+	}{
+		{
+			name:  "single argument",
+			input: "one($A)",
+			args:  1,
+		},
+		{
+			name:  "two arguments",
+			input: "two($A, 5)",
+			args:  2,
+		},
+		{
+			name:  "three arguments with a string",
+			input: `three($A, "5m", 2 * 3)`,
+			args:  3,
+		},
+		{
+			name:  "nested multi-argument call",
+			input: "two(two($A, 1), 2)",
+			args:  2,
+		},
+		{
+			name:    "trailing comma",
+			input:   "two($A, 5,)",
+			wantErr: "unexpected",
+		},
+		{
+			name:    "trailing comma after the last expected argument",
+			input:   "one($A,)",
+			wantErr: "unexpected",
+		},
+		{
+			name:    "missing argument between commas",
+			input:   "three($A, , 5)",
+			wantErr: "unexpected",
+		},
+		{
+			name:    "missing first argument",
+			input:   "two(, 5)",
+			wantErr: "unexpected",
+		},
+		{
+			name:    "missing comma",
+			input:   "two($A 5)",
+			wantErr: "unexpected",
+		},
+		{
+			name:    "not enough arguments",
+			input:   "two($A)",
+			wantErr: "not enough arguments for two",
+		},
+		{
+			name:    "too many arguments",
+			input:   "one($A, 5)",
+			wantErr: "too many arguments for one",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := Parse(tt.input, testFuncs)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			f, ok := tree.Root.(*FuncNode)
+			require.True(t, ok)
+			require.Len(t, f.Args, tt.args)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -22,11 +24,25 @@ const (
 	ReducerCount  ReducerID = "count"
 	ReducerLast   ReducerID = "last"
 	ReducerMedian ReducerID = "median"
+	ReducerFirst  ReducerID = "first"
+	ReducerDiff   ReducerID = "diff"
+	ReducerRange  ReducerID = "range"
+	ReducerStdDev ReducerID = "stddev"
+
+	ReducerCountNonNull ReducerID = "count_non_null"
+	ReducerP90          ReducerID = "p90"
+	ReducerP95          ReducerID = "p95"
+	ReducerP99          ReducerID = "p99"
 )
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Percentiles other than p90, p95 and p99 are supported too but are not listed.
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian,
+		ReducerFirst, ReducerDiff, ReducerRange, ReducerStdDev, ReducerCountNonNull,
+		ReducerP90, ReducerP95, ReducerP99,
+	}
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -126,6 +142,101 @@ func Median(fv *Float64Field) *float64 {
 	}
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	first, last := First(fv), Last(fv)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// Range returns the difference between the largest and the smallest value.
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	mean := *Avg(fv)
+	if math.IsNaN(mean) {
+		return &mean
+	}
+	var sumSquares float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - mean
+		sumSquares += d * d
+	}
+	f := math.Sqrt(sumSquares / float64(fv.Len()))
+	return &f
+}
+
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Percentile returns a ReducerFunc that returns the value at the given percentile (0-100), using
+// the nearest rank. This is the same method the frontend uses for the p1-p99 reducers.
+func Percentile(percentile float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values := make([]float64, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v := fv.GetValue(i)
+			if v == nil || math.IsNaN(*v) {
+				nan := math.NaN()
+				return &nan
+			}
+			values = append(values, *v)
+		}
+
+		if len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+
+		sort.Float64s(values)
+		idx := int(math.Round(float64(len(values)-1) * percentile / 100))
+		return &values[idx]
+	}
+}
+
+// ParsePercentile returns the percentile of a reducer ID in the form of "p<1-99>".
+func ParsePercentile(rFunc ReducerID) (float64, bool) {
+	s, ok := strings.CutPrefix(string(rFunc), "p")
+	// Only plain digits without a leading zero are accepted, so that each percentile has a single ID.
+	if !ok || s == "" || s[0] == '0' || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+	p, err := strconv.Atoi(s)
+	if err != nil || p < 1 || p > 99 {
+		return 0, false
+	}
+	return float64(p), true
+}
+
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -142,7 +253,20 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Last, nil
 	case ReducerMedian:
 		return Median, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerDiff:
+		return Diff, nil
+	case ReducerRange:
+		return Range, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerCountNonNull:
+		return CountNonNull, nil
 	default:
+		if p, ok := ParsePercentile(rFunc); ok {
+			return Percentile(p), nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
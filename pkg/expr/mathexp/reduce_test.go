@@ -1,6 +1,7 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"sort"
@@ -274,6 +275,138 @@ func TestSeriesReduce(t *testing.T) {
 			resultsIs:   require.Equal,
 			results:     resultValuesNoErr(makeNumber("", nil, nil)),
 		},
+		{
+			name:        "first series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:        "first empty series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "diff series",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(-1))),
+		},
+		{
+			name:        "diff series with a nil value",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "range series",
+			red:         "range",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		},
+		{
+			name:        "range empty series",
+			red:         "range",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0.5))),
+		},
+		{
+			name:        "stddev series with a nil value",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "count_non_null series with a nil value",
+			red:         "count_non_null",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		},
+		{
+			name:        "p90 series",
+			red:         "p90",
+			varToReduce: "A",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(1, 0), float64Pointer(5)},
+						tp{time.Unix(2, 0), float64Pointer(1)},
+						tp{time.Unix(3, 0), float64Pointer(4)},
+						tp{time.Unix(4, 0), float64Pointer(2)},
+						tp{time.Unix(5, 0), float64Pointer(3)}),
+				),
+			},
+			errIs:     require.NoError,
+			resultsIs: require.Equal,
+			results:   resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+		},
+		{
+			name:        "p50 series",
+			red:         "p50",
+			varToReduce: "A",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(1, 0), float64Pointer(5)},
+						tp{time.Unix(2, 0), float64Pointer(1)},
+						tp{time.Unix(3, 0), float64Pointer(4)}),
+				),
+			},
+			errIs:     require.NoError,
+			resultsIs: require.Equal,
+			results:   resultValuesNoErr(makeNumber("", nil, float64Pointer(4))),
+		},
+		{
+			name:        "p99 series with a nil value",
+			red:         "p99",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "p100 reduction will error",
+			red:         "p100",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 	}
 
 	for _, tt := range tests {
@@ -394,6 +527,27 @@ func TestSeriesReduceDropNN(t *testing.T) {
 			vars:        seriesWithNil,
 			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
 		},
+		{
+			name:        "DropNN: diff series with a nil value",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0))),
+		},
+		{
+			name:        "DropNN: p95 series that becomes empty after filtering non-number",
+			red:         "p95",
+			varToReduce: "A",
+			vars:        seriesNonNumbers,
+			results:     resultValuesNoErr(makeNumber("", nil, nil)),
+		},
+		{
+			name:        "DropNN: stddev empty series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			results:     resultValuesNoErr(makeNumber("", nil, nil)),
+		},
 	}
 
 	for _, tt := range tests {
@@ -543,3 +697,15 @@ func sortedFloat64(f []float64) []float64 {
 	sort.Float64s(f)
 	return f
 }
+
+func TestParsePercentile(t *testing.T) {
+	for _, id := range []ReducerID{"p1", "p5", "p50", "p99"} {
+		p, ok := ParsePercentile(id)
+		require.True(t, ok, id)
+		require.Equal(t, id, ReducerID(fmt.Sprintf("p%v", p)))
+	}
+	for _, id := range []ReducerID{"p", "p0", "p05", "p+5", "p-5", "p 5", "p100", "p9x", "P50", "mean"} {
+		_, ok := ParsePercentile(id)
+		require.False(t, ok, id)
+	}
+}
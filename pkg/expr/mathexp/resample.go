@@ -20,10 +20,34 @@ const (
 
 	// Do not fill values (nill)
 	UpsamplerFillNA Upsampler = "fillna"
+
+	// Interpolate linearly between the last seen and the next value
+	UpsamplerLinear Upsampler = "linear"
+
+	// Fill with a constant value
+	UpsamplerFillValue Upsampler = "fillvalue"
 )
 
+// ResampleOptions holds resample settings that only some upsamplers or callers need.
+type ResampleOptions struct {
+	// FillValue is the value used by UpsamplerFillValue.
+	FillValue float64
+	// Align moves the first sample back to a multiple of the interval, so that samples fall on
+	// wall-clock boundaries such as the start of a minute or hour (in UTC). This makes the samples
+	// of series resampled from independent queries line up exactly.
+	Align bool
+}
+
 // Resample turns the Series into a Number based on the given reduction function
 func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+	return s.ResampleWithOptions(refID, interval, downsampler, upsampler, from, to, ResampleOptions{})
+}
+
+// ResampleWithOptions is like Resample, but takes additional options.
+func (s Series) ResampleWithOptions(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time, opts ResampleOptions) (Series, error) {
+	if opts.Align && interval > 0 {
+		from = from.Truncate(interval)
+	}
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
@@ -31,6 +55,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
@@ -44,6 +69,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
@@ -63,6 +89,21 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 				}
 			case UpsamplerFillNA:
 				value = nil
+			case UpsamplerLinear:
+				if lastSeen == nil || sIdx == s.Len() { // nothing to interpolate between
+					value = nil
+					break
+				}
+				nextTime, next := s.GetPoint(sIdx)
+				if next == nil {
+					value = nil
+					break
+				}
+				f := *lastSeen + (*next-*lastSeen)*float64(t.Sub(lastSeenTime))/float64(nextTime.Sub(lastSeenTime))
+				value = &f
+			case UpsamplerFillValue:
+				f := opts.FillValue
+				value = &f
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
@@ -71,22 +112,11 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
+			reduceFunc, err := GetReduceFunc(downsampler)
+			if err != nil {
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
-			value = tmp
+			value = reduceFunc(&ff)
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
@@ -1,6 +1,7 @@
 package mathexp
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ func TestResampleSeries(t *testing.T) {
 		interval         time.Duration
 		downsampler      ReducerID
 		upsampler        Upsampler
+		opts             ResampleOptions
 		timeRange        backend.TimeRange
 		seriesToResample Series
 		series           Series
@@ -277,10 +279,125 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: upsampling (mean / linear)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(14, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(12, 0), float64Pointer(3),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(12, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(14, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: upsampling (mean / linear) with a null neighbour",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(4, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(3, 0), nil,
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(2, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(4, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: upsampling (mean / fillvalue)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "fillvalue",
+			opts:        ResampleOptions{FillValue: -1},
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(4, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(-1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(-1),
+			}),
+		},
+		{
+			name:        "resample series: unknown downsampler",
+			interval:    time.Second * 5,
+			downsampler: "p100",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(5, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}),
+		},
+		{
+			name:        "resample series: aligned to the interval",
+			interval:    time.Minute,
+			downsampler: "last",
+			upsampler:   "fillna",
+			opts:        ResampleOptions{Align: true},
+			timeRange: backend.TimeRange{
+				From: time.Unix(90, 0),
+				To:   time.Unix(200, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(100, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(130, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(60, 0), nil,
+			}, tp{
+				time.Unix(120, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(180, 0), float64Pointer(2),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			series, err := tt.seriesToResample.Resample("", tt.interval, tt.downsampler, tt.upsampler, tt.timeRange.From, tt.timeRange.To)
+			series, err := tt.seriesToResample.ResampleWithOptions("", tt.interval, tt.downsampler, tt.upsampler, tt.timeRange.From, tt.timeRange.To, tt.opts)
 			if tt.series.Frame == nil {
 				require.Error(t, err)
 			} else {
@@ -290,3 +407,37 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleSeriesDownsamplers(t *testing.T) {
+	// 11 points in a single window, with the values 10, 20, ..., 110
+	points := make([]tp, 0, 11)
+	for i := 1; i <= 11; i++ {
+		points = append(points, tp{time.Unix(int64(i), 0), float64Pointer(float64(i * 10))})
+	}
+	seriesToResample := makeSeries("", nil, points...)
+
+	var tests = []struct {
+		downsampler ReducerID
+		expected    float64
+	}{
+		{downsampler: ReducerFirst, expected: 10},
+		{downsampler: ReducerDiff, expected: 100},
+		{downsampler: ReducerRange, expected: 100},
+		{downsampler: ReducerStdDev, expected: math.Sqrt(1000)},
+		{downsampler: ReducerCountNonNull, expected: 11},
+		{downsampler: "p90", expected: 100},
+		{downsampler: "p95", expected: 110},
+		{downsampler: "p99", expected: 110},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.downsampler), func(t *testing.T) {
+			series, err := seriesToResample.Resample("", time.Second*12, tt.downsampler, UpsamplerFillNA, time.Unix(0, 0), time.Unix(12, 0))
+			require.NoError(t, err)
+			assert.Equal(t, makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(12, 0), float64Pointer(tt.expected),
+			}), series)
+		})
+	}
+}
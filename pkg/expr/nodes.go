@@ -150,6 +150,8 @@ func buildCMDNode(ctx context.Context, rn *rawNode, toggles featuremgmt.FeatureT
 		node.Command, err = UnmarshalThresholdCommand(rn)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(ctx, rn, cfg)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -160,6 +162,31 @@ func buildCMDNode(ctx context.Context, rn *rawNode, toggles featuremgmt.FeatureT
 	return node, nil
 }
 
+// UnmarshalCommand creates the command of an expression from its query model, so that it can be executed outside of a
+// data pipeline. Commands that need the time range of the request or the service configuration are not supported.
+func UnmarshalCommand(refID string, model json.RawMessage) (Command, error) {
+	query := map[string]any{}
+	if err := json.Unmarshal(model, &query); err != nil {
+		return nil, fmt.Errorf("failed to parse expression '%v': %w", refID, err)
+	}
+	commandType, err := GetExpressionCommandType(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command type in expression '%v': %w", refID, err)
+	}
+	if commandType == TypeResample || commandType == TypeSQL {
+		return nil, fmt.Errorf("expression command type '%v' in expression '%v' cannot be executed outside of a data pipeline", commandType, refID)
+	}
+	node, err := buildCMDNode(context.Background(), &rawNode{
+		RefID:    refID,
+		Query:    query,
+		QueryRaw: model,
+	}, featuremgmt.WithFeatures(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return node.Command, nil
+}
+
 const (
 	defaultIntervalMS = int64(64)
 	defaultMaxDP      = int64(5000)
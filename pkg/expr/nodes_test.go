@@ -165,3 +165,32 @@ func TestCheckIfSeriesNeedToBeFixed(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalCommand(t *testing.T) {
+	t.Run("creates threshold command", func(t *testing.T) {
+		cmd, err := UnmarshalCommand("C", []byte(`{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [5]}}]}`))
+		require.NoError(t, err)
+		require.IsType(t, &ThresholdCommand{}, cmd)
+		require.Equal(t, []string{"B"}, cmd.NeedsVars())
+	})
+
+	t.Run("creates math command", func(t *testing.T) {
+		cmd, err := UnmarshalCommand("C", []byte(`{"type": "math", "expression": "$A * 2"}`))
+		require.NoError(t, err)
+		require.IsType(t, &MathCommand{}, cmd)
+	})
+
+	t.Run("fails for commands that need a data pipeline", func(t *testing.T) {
+		_, err := UnmarshalCommand("C", []byte(`{"type": "resample", "expression": "A", "window": "1m", "downsampler": "mean", "upsampler": "pad"}`))
+		require.ErrorContains(t, err, "outside of a data pipeline")
+		_, err = UnmarshalCommand("C", []byte(`{"type": "sql", "expression": "SELECT 1"}`))
+		require.ErrorContains(t, err, "outside of a data pipeline")
+	})
+
+	t.Run("fails for invalid models", func(t *testing.T) {
+		_, err := UnmarshalCommand("C", []byte(`{"type": "unknown"}`))
+		require.Error(t, err)
+		_, err = UnmarshalCommand("C", []byte(`not json`))
+		require.Error(t, err)
+	})
+}
@@ -29,6 +29,9 @@ const (
 
 	// SQL query
 	QueryTypeSQL QueryType = "sql"
+
+	// Local forecasting and anomaly detection
+	QueryTypeAnomaly QueryType = "anomaly"
 )
 
 type MathQuery struct {
@@ -60,6 +63,12 @@ type ResampleQuery struct {
 
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
+
+	// The value used by the fillvalue upsampler. Defaults to 0
+	FillValue *float64 `json:"fillValue,omitempty"`
+
+	// Align the resampled points to multiples of the window, such as the start of each minute or hour (UTC)
+	Align bool `json:"align,omitempty"`
 }
 
 type ThresholdQuery struct {
@@ -80,6 +89,42 @@ type SQLExpression struct {
 	Format     string `json:"format"`
 }
 
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The model used to compute the expected value of each point
+	Model mathexp.AnomalyModel `json:"model"`
+
+	// The method used to score the distance of each point from its expected value (default zscore)
+	Scoring mathexp.AnomalyScoring `json:"scoring,omitempty"`
+
+	// The length of one season. Required by seasonal_baseline, optional for holt_winters
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// How many previous seasons seasonal_baseline uses (default 3)
+	Seasons *int `json:"seasons,omitempty"`
+
+	// Holt-Winters level smoothing factor between 0 and 1 (default 0.3)
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// Holt-Winters trend smoothing factor between 0 and 1 (default 0.1)
+	Beta *float64 `json:"beta,omitempty"`
+
+	// Holt-Winters seasonal smoothing factor between 0 and 1 (default 0.3)
+	Gamma *float64 `json:"gamma,omitempty"`
+
+	// Distance of the bands from the expected value, in multiples of the scoring deviation (default 3)
+	BandWidth *float64 `json:"bandWidth,omitempty"`
+
+	// What the expression returns (default score)
+	Output AnomalyOutput `json:"output,omitempty"`
+
+	// How far past the last point the bands are forecast. Only supported by holt_winters
+	Horizon string `json:"horizon,omitempty" jsonschema:"example=1h"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestQueryTypeDefinitions(t *testing.T) {
@@ -27,6 +28,9 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(mathexp.AnomalyModelHoltWinters),
+				reflect.TypeOf(mathexp.AnomalyScoringZScore),
+				reflect.TypeOf(AnomalyOutputScore),
 			},
 		})
 	require.NoError(t, err)
@@ -78,6 +82,17 @@ func TestQueryTypeDefinitions(t *testing.T) {
 						Upsampler:   mathexp.UpsamplerPad,
 					}),
 				},
+				{
+					Name: "resample every hour on the hour, filling gaps with zero",
+					SaveModel: data.AsUnstructured(ResampleQuery{
+						Expression:  "$A",
+						Window:      "1h",
+						Downsampler: mathexp.ReducerSum,
+						Upsampler:   mathexp.UpsamplerFillValue,
+						FillValue:   util.Pointer(0.0),
+						Align:       true,
+					}),
+				},
 			},
 		},
 		schemabuilder.QueryTypeInfo{
@@ -92,6 +107,30 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "daily seasonal anomaly score",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Model:      mathexp.AnomalyModelHoltWinters,
+						Scoring:    mathexp.AnomalyScoringMAD,
+						Season:     "1d",
+					}),
+				},
+				{
+					Name: "bands forecast one hour ahead",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Model:      mathexp.AnomalyModelHoltWinters,
+						Output:     AnomalyOutputBands,
+						Horizon:    "1h",
+					}),
+				},
+			},
+		},
 		schemabuilder.QueryTypeInfo{
 			Discriminators: data.NewDiscriminators("type", QueryTypeClassic),
 			GoType:         reflect.TypeOf(&ClassicQuery{}),
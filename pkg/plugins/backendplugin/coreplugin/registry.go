@@ -223,7 +223,7 @@ func NewPlugin(pluginID string, cfg *setting.Cfg, httpClientProvider *httpclient
 	case AzureMonitor:
 		svc = azuremonitor.ProvideService(httpClientProvider)
 	case Elasticsearch:
-		svc = elasticsearch.ProvideService(httpClientProvider)
+		svc = elasticsearch.ProvideService(cfg, httpClientProvider)
 	case Graphite:
 		svc = graphite.ProvideService(httpClientProvider, tracer)
 	case InfluxDB:
@@ -231,7 +231,7 @@ func NewPlugin(pluginID string, cfg *setting.Cfg, httpClientProvider *httpclient
 	case Loki:
 		svc = loki.ProvideService(httpClientProvider, tracer)
 	case OpenTSDB:
-		svc = opentsdb.ProvideService(httpClientProvider)
+		svc = opentsdb.ProvideService(httpClientProvider, tracer)
 	case Prometheus:
 		svc = prometheus.ProvideService(httpClientProvider)
 	case Tempo:
@@ -71,6 +71,11 @@ func (d *directResourceClient) Read(ctx context.Context, in *resourcepb.ReadRequ
 	return d.server.Read(ctx, in)
 }
 
+// Restore implements ResourceClient.
+func (d *directResourceClient) Restore(ctx context.Context, in *resourcepb.RestoreRequest, opts ...grpc.CallOption) (*resourcepb.RestoreResponse, error) {
+	return d.server.Restore(ctx, in)
+}
+
 // Search implements ResourceClient.
 func (d *directResourceClient) Search(ctx context.Context, in *resourcepb.ResourceSearchRequest, opts ...grpc.CallOption) (*resourcepb.ResourceSearchResponse, error) {
 	return d.server.Search(ctx, in)
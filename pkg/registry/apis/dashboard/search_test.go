@@ -691,6 +691,9 @@ func (m *MockClient) IsHealthy(ctx context.Context, in *resourcepb.HealthCheckRe
 func (m *MockClient) BulkProcess(ctx context.Context, opts ...grpc.CallOption) (resourcepb.BulkStore_BulkProcessClient, error) {
 	return nil, nil
 }
+func (m *MockClient) Restore(ctx context.Context, in *resourcepb.RestoreRequest, opts ...grpc.CallOption) (*resourcepb.RestoreResponse, error) {
+	return nil, nil
+}
 func (m *MockClient) UpdateIndex(ctx context.Context, reason string) error {
 	return nil
 }
@@ -377,12 +377,12 @@ func Initialize(ctx context.Context, cfg *setting.Cfg, opts Options, apiOpts api
 	azuremonitorService := azuremonitor.ProvideService(httpclientProvider)
 	cloudwatchService := cloudwatch.ProvideService()
 	cloudmonitoringService := cloudmonitoring.ProvideService(httpclientProvider)
-	elasticsearchService := elasticsearch.ProvideService(httpclientProvider)
+	elasticsearchService := elasticsearch.ProvideService(cfg, httpclientProvider)
 	graphiteService := graphite.ProvideService(httpclientProvider, tracingService)
 	influxdbService := influxdb.ProvideService(httpclientProvider, featureToggles)
 	tracer := otelTracer()
 	lokiService := loki.ProvideService(httpclientProvider, tracer)
-	opentsdbService := opentsdb.ProvideService(httpclientProvider)
+	opentsdbService := opentsdb.ProvideService(httpclientProvider, tracingService)
 	prometheusService := prometheus.ProvideService(httpclientProvider)
 	tempoService := tempo.ProvideService(httpclientProvider)
 	testdatasourceService := testdatasource.ProvideService()
@@ -957,12 +957,12 @@ func InitializeForTest(ctx context.Context, t sqlutil.ITestDB, testingT interfac
 	azuremonitorService := azuremonitor.ProvideService(httpclientProvider)
 	cloudwatchService := cloudwatch.ProvideService()
 	cloudmonitoringService := cloudmonitoring.ProvideService(httpclientProvider)
-	elasticsearchService := elasticsearch.ProvideService(httpclientProvider)
+	elasticsearchService := elasticsearch.ProvideService(cfg, httpclientProvider)
 	graphiteService := graphite.ProvideService(httpclientProvider, tracingService)
 	influxdbService := influxdb.ProvideService(httpclientProvider, featureToggles)
 	tracer := otelTracer()
 	lokiService := loki.ProvideService(httpclientProvider, tracer)
-	opentsdbService := opentsdb.ProvideService(httpclientProvider)
+	opentsdbService := opentsdb.ProvideService(httpclientProvider, tracingService)
 	prometheusService := prometheus.ProvideService(httpclientProvider)
 	tempoService := tempo.ProvideService(httpclientProvider)
 	testdatasourceService := testdatasource.ProvideService()
@@ -39,6 +39,7 @@ import (
 
 type AlertRuleService interface {
 	CleanUpDeletedAlertRules(ctx context.Context) (int64, error)
+	CleanUpStateHistory(ctx context.Context) (int64, error)
 }
 
 type CleanUpService struct {
@@ -135,6 +136,10 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		cleanupJobs = append(cleanupJobs, cleanUpJob{"cleanup trash alert rules", srv.cleanUpTrashAlertRules})
 	}
 
+	if srv.Cfg.UnifiedAlerting.StateHistory.UsesBackend("sql") {
+		cleanupJobs = append(cleanupJobs, cleanUpJob{"cleanup alert state history", srv.cleanUpAlertStateHistory})
+	}
+
 	logger := srv.log.FromContext(ctx)
 	logger.Debug("Starting cleanup jobs", "jobs", fmt.Sprintf("%v", cleanupJobs))
 
@@ -418,3 +423,13 @@ func (srv *CleanUpService) cleanUpTrashAlertRules(ctx context.Context) {
 		logger.Debug("Cleaned up deleted alert rules", "rows affected", affected)
 	}
 }
+
+func (srv *CleanUpService) cleanUpAlertStateHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	affected, err := srv.alertRuleService.CleanUpStateHistory(ctx)
+	if err != nil {
+		logger.Error("Problem cleaning up alert state history", "error", err)
+	} else {
+		logger.Debug("Cleaned up alert state history", "rows affected", affected)
+	}
+}
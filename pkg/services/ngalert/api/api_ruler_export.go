@@ -13,6 +13,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	apivalidation "github.com/grafana/grafana/pkg/services/ngalert/api/validation"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
 )
 
 // ExportFromPayload converts the rule groups from the argument `ruleGroupConfig` to export format. All rules are expected to be fully specified. The access to data sources mentioned in the rules is not enforced.
@@ -97,6 +98,10 @@ func (srv RulerSrv) ExportRules(c *contextmodel.ReqContext) response.Response {
 	// sort result so the response is always stable
 	ngmodels.SortAlertRuleGroupWithFolderTitle(groups)
 
+	if c.Query("format") == "prometheus" {
+		return exportPrometheus(c.QueryBoolWithDefault("download", false), groups)
+	}
+
 	e, err := AlertingFileExportFromAlertRuleGroupWithFolderFullpath(groups)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
@@ -104,6 +109,22 @@ func (srv RulerSrv) ExportRules(c *contextmodel.ReqContext) response.Response {
 	return exportResponse(c, e)
 }
 
+// exportPrometheus returns the rule groups as Prometheus rules in YAML, in the format of the Prometheus-compatible ruler API.
+func exportPrometheus(download bool, groups []ngmodels.AlertRuleGroupWithFolderFullpath) response.Response {
+	body, err := prom.GrafanaRuleGroupsToPrometheusYAML(groups)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create Prometheus rules export")
+	}
+	// As with other YAML exports, text/yaml is used so that browsers display the file instead of downloading it.
+	resp := response.Respond(http.StatusOK, body)
+	if download {
+		return resp.
+			SetHeader("Content-Type", "application/yaml").
+			SetHeader("Content-Disposition", `attachment;filename="export.yaml"`)
+	}
+	return resp.SetHeader("Content-Type", "text/yaml")
+}
+
 // getRuleWithFolderFullpathByRuleUid calls getAuthorizedRuleByUid and combines its result with folder (aka namespace) title.
 func (srv RulerSrv) getRuleWithFolderFullpathByRuleUid(c *contextmodel.ReqContext, ruleUID string) (ngmodels.AlertRuleGroupWithFolderFullpath, error) {
 	rule, err := srv.getAuthorizedRuleByUid(c.Req.Context(), c, ruleUID)
@@ -23,6 +23,7 @@ import (
 	. "github.com/grafana/grafana/pkg/services/ngalert/api/compat"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 )
 
@@ -438,4 +439,35 @@ func TestExportRules(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("return Prometheus rules if format is prometheus", func(t *testing.T) {
+		rc := createRequestContextWithPerms(orgID, map[int64]map[string][]string{
+			orgID: {
+				dashboards.ActionFoldersRead:         []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(f1.UID)},
+				accesscontrol.ActionAlertingRuleRead: []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(f1.UID)},
+				datasources.ActionQuery:              []string{datasources.ScopeProvider.GetResourceScopeUID(accessQuery.DatasourceUID)},
+			},
+		}, nil)
+		rc.Req.Form = url.Values{
+			"folderUid": []string{hasAccessKey1.NamespaceUID},
+			"group":     []string{hasAccessKey1.RuleGroup},
+			"format":    []string{"prometheus"},
+			"download":  []string{"true"},
+		}
+
+		resp := srv.ExportRules(rc)
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		folder, err := ruleStore.GetNamespaceByUID(context.Background(), f1.UID, orgID, nil)
+		require.NoError(t, err)
+		expected, err := prom.GrafanaRuleGroupsToPrometheusYAML([]ngmodels.AlertRuleGroupWithFolderFullpath{
+			ngmodels.NewAlertRuleGroupWithFolderFullpathFromRulesGroup(hasAccessKey1, hasAccess1, folder.Fullpath),
+		})
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(resp.Body()))
+
+		resp.WriteTo(rc)
+		require.Equal(t, "application/yaml", rc.Resp.Header().Get("Content-Type"))
+		require.Equal(t, `attachment;filename="export.yaml"`, rc.Resp.Header().Get("Content-Disposition"))
+	})
 }
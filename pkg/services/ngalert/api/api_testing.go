@@ -20,11 +20,13 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	. "github.com/grafana/grafana/pkg/services/ngalert/api/compat"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	apivalidation "github.com/grafana/grafana/pkg/services/ngalert/api/validation"
@@ -41,6 +43,10 @@ type folderService interface {
 	GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user identity.Requester) (*folder.Folder, error)
 }
 
+type notificationPolicyService interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (apimodels.Route, string, error)
+}
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	DatasourceCache datasources.CacheService
@@ -53,6 +59,8 @@ type TestingApiSrv struct {
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	policies        notificationPolicyService
+	accessControl   ac.AccessControl
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -288,3 +296,98 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// BacktestRuleGroup evaluates all rules of a rule group over a time range, and simulates the notifications that the
+// resulting alerts would have caused with the given or the current notification policy tree of the organization.
+func (srv TestingApiSrv) BacktestRuleGroup(c *contextmodel.ReqContext, cmd apimodels.BacktestRuleGroupConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(400, nil, "From cannot be greater than To")
+	}
+
+	namespace, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, c.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, folder.ErrFolderNotFound) || errors.Is(err, dashboards.ErrFolderNotFound) || errors.Is(err, dashboards.ErrFolderAccessDenied) {
+			return toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the folder")
+	}
+
+	validated, err := apivalidation.ValidateRuleGroup(&cmd.RuleGroup, c.GetOrgID(), namespace.UID, apivalidation.RuleLimitsFromConfig(srv.cfg, srv.featureManager))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	rules := make([]*ngmodels.AlertRule, 0, len(validated))
+	for _, r := range validated {
+		rule := r.AlertRule
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			return ErrResp(http.StatusBadRequest, nil, "Recording rules cannot be backtested")
+		}
+		if err := srv.authz.AuthorizeDatasourceAccessForRule(c.Req.Context(), c.SignedInUser, &rule); err != nil {
+			return errorToResponse(err)
+		}
+		if rule.UID == "" {
+			// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs (like expression engine, evaluator, state manager etc)
+			rule.UID = "backtesting-" + util.GenerateShortUID()
+		}
+		rules = append(rules, &rule)
+	}
+
+	route := cmd.Route
+	if route == nil {
+		// The result reveals the receivers of the policy tree, so it can be used only by those who can read it.
+		evaluator := ac.EvalAny(ac.EvalPermission(ac.ActionAlertingNotificationsRead), ac.EvalPermission(ac.ActionAlertingRoutesRead))
+		ok, err := srv.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to check permissions")
+		}
+		if !ok {
+			return errorToResponse(authz.NewAuthorizationErrorWithPermissions("backtest with the notification policy tree of the organization", evaluator))
+		}
+		tree, _, err := srv.policies.GetPolicyTree(c.Req.Context(), c.GetOrgID())
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the notification policy tree", err)
+		}
+		route = &tree
+	}
+
+	result, err := srv.backtesting.TestGroup(c.Req.Context(), c.SignedInUser, rules, cmd.From, cmd.To, backtesting.GroupTestOptions{
+		FolderTitle:   namespace.Fullpath,
+		IncludeFolder: !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
+		Route:         route,
+	})
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	body := apimodels.BacktestRuleGroupResult{
+		Rules:         make([]apimodels.BacktestRuleResult, 0, len(rules)),
+		Notifications: make([]apimodels.BacktestNotification, 0, len(result.Notifications)),
+		Receivers:     make(map[string]int),
+	}
+	for i, rule := range rules {
+		body.Rules = append(body.Rules, apimodels.BacktestRuleResult{
+			Title: rule.Title,
+			Frame: result.Frames[i],
+		})
+	}
+	for _, n := range result.Notifications {
+		body.Notifications = append(body.Notifications, apimodels.BacktestNotification{
+			Time:        n.Time,
+			Receiver:    n.Receiver,
+			GroupKey:    n.GroupKey,
+			GroupLabels: n.GroupLabels,
+			Firing:      n.Firing,
+			Resolved:    n.Resolved,
+		})
+		body.Receivers[n.Receiver]++
+	}
+	return response.JSON(http.StatusOK, body)
+}
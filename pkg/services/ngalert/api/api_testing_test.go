@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -392,6 +394,98 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+type fakePolicyTreeGetter struct {
+	calls int
+	err   error
+}
+
+func (f *fakePolicyTreeGetter) GetPolicyTree(context.Context, int64) (definitions.Route, string, error) {
+	f.calls++
+	return definitions.Route{}, "", f.err
+}
+
+func TestBacktestRuleGroup(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	features := featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting)
+
+	createCmd := func(srv *TestingApiSrv, namespaceUID string) definitions.BacktestRuleGroupConfig {
+		return definitions.BacktestRuleGroupConfig{
+			From:         time.Now().Add(-time.Hour),
+			To:           time.Now(),
+			NamespaceUID: namespaceUID,
+			RuleGroup:    validGroup(srv.cfg, validRule()),
+		}
+	}
+	queryPermissions := []ac.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID("DATASOURCE_TEST")},
+	}
+
+	t.Run("should return Forbidden if folder is not found", func(t *testing.T) {
+		ruleStore := fakes2.NewRuleStore(t)
+		srv := createTestingApiSrv(t, nil, acMock.New().WithPermissions(queryPermissions), nil, features, ruleStore)
+
+		response := srv.BacktestRuleGroup(rc, createCmd(srv, uuid.NewString()))
+
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should return InternalServerError if folder cannot be fetched", func(t *testing.T) {
+		ruleStore := fakes2.NewRuleStore(t)
+		ruleStore.Hook = func(cmd any) error {
+			q, ok := cmd.(fakes2.GenericRecordedQuery)
+			if ok && q.Name == "GetNamespaceByUID" {
+				return errors.New("test error")
+			}
+			return nil
+		}
+		srv := createTestingApiSrv(t, nil, acMock.New().WithPermissions(queryPermissions), nil, features, ruleStore)
+
+		response := srv.BacktestRuleGroup(rc, createCmd(srv, uuid.NewString()))
+
+		require.Equal(t, http.StatusInternalServerError, response.Status())
+	})
+
+	t.Run("should return Forbidden if route is not set and user cannot read notification policies", func(t *testing.T) {
+		f := randFolder()
+		ruleStore := fakes2.NewRuleStore(t)
+		ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+		policies := &fakePolicyTreeGetter{}
+		srv := createTestingApiSrv(t, nil, acMock.New().WithPermissions(queryPermissions), nil, features, ruleStore)
+		srv.policies = policies
+
+		response := srv.BacktestRuleGroup(rc, createCmd(srv, f.UID))
+
+		require.Equal(t, http.StatusForbidden, response.Status())
+		require.Zero(t, policies.calls)
+	})
+
+	t.Run("should use notification policy tree if route is not set and user can read notification policies", func(t *testing.T) {
+		for _, action := range []string{ac.ActionAlertingNotificationsRead, ac.ActionAlertingRoutesRead} {
+			t.Run(action, func(t *testing.T) {
+				f := randFolder()
+				ruleStore := fakes2.NewRuleStore(t)
+				ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+				policies := &fakePolicyTreeGetter{err: errors.New("test error")}
+				permissions := append([]ac.Permission{{Action: action}}, queryPermissions...)
+				srv := createTestingApiSrv(t, nil, acMock.New().WithPermissions(permissions), nil, features, ruleStore)
+				srv.policies = policies
+
+				response := srv.BacktestRuleGroup(rc, createCmd(srv, f.UID))
+
+				require.Equal(t, http.StatusInternalServerError, response.Status())
+				require.Equal(t, 1, policies.calls)
+			})
+		}
+	})
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory, featureManager featuremgmt.FeatureToggles, ruleStore RuleStore) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
@@ -405,5 +499,6 @@ func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mo
 		tracer:          tracing.InitializeTracerForTest(),
 		featureManager:  featureManager,
 		folderService:   ruleStore,
+		accessControl:   ac,
 	}
 }
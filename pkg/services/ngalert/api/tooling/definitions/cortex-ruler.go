@@ -58,7 +58,7 @@ import (
 
 // swagger:route Get /ruler/grafana/api/v1/export/rules ruler RouteGetRulesForExport
 //
-// List rules in provisioning format
+// List rules in provisioning format, or as Prometheus rules with format=prometheus
 //
 //     Produces:
 //     - application/json
@@ -68,6 +68,19 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /v1/rule/backtest/group testing BacktestRuleGroupConfig
+//
+// Test a rule group and simulate the notifications it would have sent
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestRuleGroupResult
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -234,3 +247,47 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestRuleGroupConfig
+type BacktestRuleGroupConfigRequest struct {
+	// in:body
+	Body BacktestRuleGroupConfig
+}
+
+// swagger:model
+type BacktestRuleGroupConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// The UID of the folder the rule group would be created in.
+	NamespaceUID string                  `json:"namespace_uid"`
+	RuleGroup    PostableRuleGroupConfig `json:"rule_group"`
+
+	// The notification policy tree to simulate the notifications with.
+	// If it is not set, the notification policy tree of the organization is used, which requires the permission to read
+	// notification policies.
+	Route *Route `json:"route,omitempty"`
+}
+
+// swagger:model
+type BacktestRuleGroupResult struct {
+	Rules []BacktestRuleResult `json:"rules"`
+	// The notifications that would have been sent, in the order they would have been sent.
+	Notifications []BacktestNotification `json:"notifications"`
+	// The number of notifications per contact point.
+	Receivers map[string]int `json:"receivers"`
+}
+
+type BacktestRuleResult struct {
+	Title string      `json:"title"`
+	Frame *data.Frame `json:"frame"`
+}
+
+type BacktestNotification struct {
+	Time        time.Time         `json:"time"`
+	Receiver    string            `json:"receiver"`
+	GroupKey    string            `json:"group_key"`
+	GroupLabels map[string]string `json:"group_labels"`
+	Firing      int               `json:"firing"`
+	Resolved    int               `json:"resolved"`
+}
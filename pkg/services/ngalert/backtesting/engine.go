@@ -15,10 +15,13 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -40,13 +43,23 @@ type stateManager interface {
 }
 
 type Engine struct {
+	appUrl             *url.URL
 	evalFactory        eval.EvaluatorFactory
+	history            stateHistorian
+	historyCfg         historyConfig
+	tracer             tracing.Tracer
+	featureToggles     featuremgmt.FeatureToggles
 	createStateManager func() stateManager
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, history stateHistorian, cfg *setting.UnifiedAlertingSettings, tracer tracing.Tracer, featureToggles featuremgmt.FeatureToggles) *Engine {
 	return &Engine{
-		evalFactory: evalFactory,
+		appUrl:         appUrl,
+		evalFactory:    evalFactory,
+		history:        history,
+		historyCfg:     newHistoryConfig(cfg),
+		tracer:         tracer,
+		featureToggles: featureToggles,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -64,6 +77,69 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 }
 
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+	return e.test(ctx, user, rule, e.createStateManager(), nil, nil, from, to)
+}
+
+// GroupTestOptions configures the backtesting of a rule group.
+type GroupTestOptions struct {
+	// FolderTitle is the title of the folder of the rule group. It is added to the alerts as the grafana_folder label if IncludeFolder is true.
+	FolderTitle   string
+	IncludeFolder bool
+	// Route is the notification policy tree the alerts of the rule group are routed with.
+	Route *definitions.Route
+}
+
+// GroupTestResult is the result of backtesting a rule group.
+type GroupTestResult struct {
+	// Frames are the results of each rule of the group in the same format as the result of Test, in the order of the rules.
+	Frames []*data.Frame
+	// Notifications are the notifications that would have been sent, in the order they would have been sent.
+	Notifications []Notification
+}
+
+// TestGroup evaluates all rules of a rule group over the time range like Test does, sends the resulting alerts
+// through the notification policy tree, and returns the results of the rules and the notifications that the
+// contact points would have received.
+func (e *Engine) TestGroup(ctx context.Context, user identity.Requester, rules []*models.AlertRule, from, to time.Time, opts GroupTestOptions) (*GroupTestResult, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: rule group must contain at least one rule", ErrInvalidInputData)
+	}
+	if opts.Route == nil {
+		return nil, fmt.Errorf("%w: notification policy tree must not be empty", ErrInvalidInputData)
+	}
+	logger := logger.FromContext(ctx)
+
+	// The rules share the state manager like the rules of a group do in the scheduler.
+	stateManager := e.createStateManager()
+	var alerts []postedAlert
+	send := func(now time.Time, transitions state.StateTransitions) {
+		for _, t := range transitions {
+			alerts = append(alerts, postedAlert{at: now, alert: state.StateToPostableAlert(t, e.appUrl, e.featureToggles)})
+		}
+	}
+
+	result := &GroupTestResult{Frames: make([]*data.Frame, 0, len(rules))}
+	for _, rule := range rules {
+		extraLabels := state.GetRuleExtraLabels(logger, rule, opts.FolderTitle, opts.IncludeFolder)
+		frame, err := e.test(ctx, user, rule, stateManager, extraLabels, send, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to test rule %s: %w", rule.Title, err)
+		}
+		result.Frames = append(result.Frames, frame)
+	}
+
+	// The rules are evaluated one after another, so the alerts of different rules need to be put in order.
+	sortPostedAlerts(alerts)
+	notifications, err := simulateNotifications(opts.Route, alerts, to)
+	if err != nil {
+		return nil, err
+	}
+	result.Notifications = notifications
+	logger.Info("Rule group testing finished successfully", "rules", len(rules), "alerts", len(alerts), "notifications", len(notifications))
+	return result, nil
+}
+
+func (e *Engine) test(ctx context.Context, user identity.Requester, rule *models.AlertRule, manager stateManager, extraLabels data.Labels, send func(time.Time, state.StateTransitions), from, to time.Time) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -75,10 +151,8 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	}
 	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
-	stateManager := e.createStateManager()
-
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition().WithSource("backtesting"), &schedule.AlertingResultsFromRuleState{
-		Manager: stateManager,
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, e.history, e.historyCfg, e.tracer, user, rule.GetEvalCondition().WithSource("backtesting"), &schedule.AlertingResultsFromRuleState{
+		Manager: manager,
 		Rule:    rule,
 	})
 	if err != nil {
@@ -97,7 +171,13 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
 			return nil
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil, nil)
+		var sender state.Sender
+		if send != nil {
+			sender = func(_ context.Context, transitions state.StateTransitions) {
+				send(currentTime, transitions)
+			}
+		}
+		states := manager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels, sender)
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -130,8 +210,11 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	return result, nil
 }
 
-func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
+func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, history stateHistorian, historyCfg historyConfig, tracer tracing.Tracer, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
+		if q.DatasourceUID == historyDatasourceUID || q.QueryType == historyDatasourceUID {
+			return newHistoryEvaluator(history, historyCfg, user, tracer, condition, q)
+		}
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
 			if len(condition.Data) != 1 {
 				return nil, errors.New("data queries are not supported with other expressions or data queries")
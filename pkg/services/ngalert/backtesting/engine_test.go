@@ -14,6 +14,9 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -145,7 +148,7 @@ func TestNewBacktestingEvaluator(t *testing.T) {
 
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
-				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, testCase.condition, nil)
+				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, historyConfig{}, nil, nil, testCase.condition, nil)
 				if testCase.error {
 					require.Error(t, err)
 					return
@@ -155,6 +158,13 @@ func TestNewBacktestingEvaluator(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("creates history evaluator when there is a query with datasource UID __history__", func(t *testing.T) {
+		condition := historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("C", "A", 5))
+		e, err := newBacktestingEvaluator(context.Background(), nil, &fakeStateHistorian{}, testHistoryConfig, tracing.InitializeTracerForTest(), nil, condition, nil)
+		require.NoError(t, err)
+		require.IsType(t, &historyEvaluator{}, e)
+	})
 }
 
 func TestEvaluatorTest(t *testing.T) {
@@ -176,7 +186,7 @@ func TestEvaluatorTest(t *testing.T) {
 	}
 	manager := &fakeStateManager{}
 
-	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, history stateHistorian, historyCfg historyConfig, tracer tracing.Tracer, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
 		return evaluator, nil
 	}
 
@@ -382,12 +392,83 @@ func TestEvaluatorTest(t *testing.T) {
 	})
 }
 
+func TestEngineTestGroup(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.GenerateResults(1, eval.ResultGen()), nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, history stateHistorian, historyCfg historyConfig, tracer tracing.Tracer, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	from := time.Unix(0, 0)
+	labels := data.Labels{"alertname": "test"}
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{{
+				State: &state.State{
+					CacheID:  labels.Fingerprint(),
+					Labels:   labels,
+					State:    eval.Alerting,
+					StartsAt: from,
+					EndsAt:   now.Add(time.Minute),
+				},
+			}}
+		},
+	}
+	engine := &Engine{
+		featureToggles: featuremgmt.WithFeatures(),
+		createStateManager: func() stateManager {
+			return manager
+		},
+	}
+	gen := models.RuleGen
+	rules := gen.With(gen.WithInterval(10 * time.Second)).GenerateManyRef(2)
+	to := from.Add(2 * time.Minute)
+
+	t.Run("should return the results of all rules and the notifications", func(t *testing.T) {
+		result, err := engine.TestGroup(context.Background(), nil, rules, from, to, GroupTestOptions{
+			Route: &definitions.Route{Receiver: "default"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Frames, len(rules))
+		// The alerts of both rules have the same labels, so they are the same alert for the Alertmanager.
+		require.Equal(t, []Notification{{
+			Time:        from.Add(30 * time.Second),
+			Receiver:    "default",
+			GroupKey:    "{}:{}",
+			GroupLabels: data.Labels{},
+			Firing:      1,
+		}}, result.Notifications)
+	})
+
+	t.Run("should fail without notification policy tree", func(t *testing.T) {
+		_, err := engine.TestGroup(context.Background(), nil, rules, from, to, GroupTestOptions{})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should fail without rules", func(t *testing.T) {
+		_, err := engine.TestGroup(context.Background(), nil, nil, from, to, GroupTestOptions{
+			Route: &definitions.Route{Receiver: "default"},
+		})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
 type fakeStateManager struct {
 	stateCallback func(now time.Time) []state.StateTransition
 }
 
-func (f *fakeStateManager) ProcessEvalResults(_ context.Context, evaluatedAt time.Time, _ *models.AlertRule, _ eval.Results, _ data.Labels, _ state.Sender) state.StateTransitions {
-	return f.stateCallback(evaluatedAt)
+func (f *fakeStateManager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, _ *models.AlertRule, _ eval.Results, _ data.Labels, send state.Sender) state.StateTransitions {
+	transitions := f.stateCallback(evaluatedAt)
+	if send != nil {
+		send(ctx, transitions)
+	}
+	return transitions
 }
 
 func (f *fakeStateManager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*state.State {
@@ -0,0 +1,446 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const historyDatasourceUID = "__history__"
+
+type stateHistorian interface {
+	Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+}
+
+// historyConfig configures how the state history is replayed.
+type historyConfig struct {
+	// backend is the state history backend that serves queries. It determines the format of the returned entries.
+	backend historian.BackendType
+	// lookback is how long before the start of the backtesting the state history is queried, so that the
+	// alert instances that did not change their state since then are replayed from the first evaluation.
+	lookback time.Duration
+	// maxEntries is the maximum number of state history entries that are replayed.
+	maxEntries int
+}
+
+// newHistoryConfig returns the configuration of the replay of the state history.
+func newHistoryConfig(cfg *setting.UnifiedAlertingSettings) historyConfig {
+	backend := historian.BackendTypeNoop
+	if cfg.StateHistory.Enabled {
+		name := cfg.StateHistory.Backend
+		if b, err := historian.ParseBackendType(name); err == nil && b == historian.BackendTypeMultiple {
+			name = cfg.StateHistory.MultiPrimary
+		}
+		if b, err := historian.ParseBackendType(name); err == nil {
+			backend = b
+		}
+	}
+	return historyConfig{
+		backend:    backend,
+		lookback:   cfg.Backtesting.HistoryLookback,
+		maxEntries: cfg.Backtesting.HistoryMaxEntries,
+	}
+}
+
+type historyQueryModel struct {
+	// RuleUID is the UID of the rule whose state history is replayed.
+	RuleUID string `json:"ruleUid"`
+	// RecordedRefID is the refID of the recorded value that is replayed. If it is empty, the value of the condition of the rule is replayed.
+	RecordedRefID string `json:"recordedRefId"`
+}
+
+type historyExpression struct {
+	refID   string
+	command expr.Command
+}
+
+// recordedValue is the value of an alert instance that was recorded in the state history when its state changed.
+type recordedValue struct {
+	at    time.Time
+	value *float64
+}
+
+type recordedInstance struct {
+	labels data.Labels
+	values []recordedValue
+}
+
+// valueAt returns the last value that was recorded at or before now, or nil if there is none.
+func (i *recordedInstance) valueAt(now time.Time) *float64 {
+	idx := sort.Search(len(i.values), func(j int) bool {
+		return i.values[j].at.After(now)
+	})
+	if idx == 0 {
+		return nil
+	}
+	return i.values[idx-1].value
+}
+
+// historyEvaluator replays the values that the state historian recorded for the evaluations of an existing rule
+// instead of querying the data sources, and runs the expressions of the tested rule on them. This makes it possible
+// to compare what happened with what would have happened with, for example, a different threshold.
+// The state history only contains the evaluations that changed the state of an alert instance, so the recorded
+// values are assumed to be unchanged until the next recorded transition.
+type historyEvaluator struct {
+	history       stateHistorian
+	cfg           historyConfig
+	user          identity.Requester
+	tracer        tracing.Tracer
+	refID         string
+	ruleUID       string
+	recordedRefID string
+	expressions   []historyExpression
+	condition     string
+}
+
+func newHistoryEvaluator(history stateHistorian, cfg historyConfig, user identity.Requester, tracer tracing.Tracer, condition models.Condition, query models.AlertQuery) (*historyEvaluator, error) {
+	if history == nil {
+		return nil, errors.New("state history is not available")
+	}
+	switch cfg.backend {
+	case historian.BackendTypeLoki, historian.BackendTypeSQL, historian.BackendTypeAnnotations:
+	default:
+		return nil, fmt.Errorf("replaying the state history requires the loki, sql or annotations state history backend, the configured backend is %s", cfg.backend)
+	}
+	if condition.Condition == "" {
+		return nil, errors.New("condition must not be empty")
+	}
+	m := historyQueryModel{}
+	if err := json.Unmarshal(query.Model, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse history query: %w", err)
+	}
+	if m.RuleUID == "" {
+		return nil, errors.New("the ruleUid field of the history query must not be empty")
+	}
+	// Annotations do not record which query or expression was the condition of the rule.
+	if cfg.backend == historian.BackendTypeAnnotations && m.RecordedRefID == "" {
+		return nil, errors.New("the recordedRefId field of the history query must not be empty with the annotations state history backend")
+	}
+
+	expressions := make([]historyExpression, 0, len(condition.Data)-1)
+	for _, q := range condition.Data {
+		if q.RefID == query.RefID {
+			continue
+		}
+		if isExpr, _ := q.IsExpression(); !isExpr {
+			return nil, fmt.Errorf("history queries are not supported with other data queries, query %s is not an expression", q.RefID)
+		}
+		cmd, err := expr.UnmarshalCommand(q.RefID, q.Model)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, historyExpression{refID: q.RefID, command: cmd})
+	}
+	expressions, err := sortHistoryExpressions(query.RefID, expressions)
+	if err != nil {
+		return nil, err
+	}
+	found := condition.Condition == query.RefID
+	for _, e := range expressions {
+		found = found || e.refID == condition.Condition
+	}
+	if !found {
+		return nil, fmt.Errorf("condition %s does not refer to any query or expression", condition.Condition)
+	}
+
+	return &historyEvaluator{
+		history:       history,
+		cfg:           cfg,
+		user:          user,
+		tracer:        tracer,
+		refID:         query.RefID,
+		ruleUID:       m.RuleUID,
+		recordedRefID: m.RecordedRefID,
+		expressions:   expressions,
+		condition:     condition.Condition,
+	}, nil
+}
+
+// sortHistoryExpressions orders the expressions so that each expression is executed after the expressions it depends on.
+func sortHistoryExpressions(refID string, expressions []historyExpression) ([]historyExpression, error) {
+	available := map[string]struct{}{refID: {}}
+	sorted := make([]historyExpression, 0, len(expressions))
+	for len(expressions) > 0 {
+		remaining := expressions[:0:0]
+		for _, e := range expressions {
+			ready := true
+			for _, v := range e.command.NeedsVars() {
+				if _, ok := available[v]; !ok {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				remaining = append(remaining, e)
+				continue
+			}
+			available[e.refID] = struct{}{}
+			sorted = append(sorted, e)
+		}
+		if len(remaining) == len(expressions) {
+			return nil, fmt.Errorf("expression %s depends on a query or expression that does not exist or has a circular dependency", remaining[0].refID)
+		}
+		expressions = remaining
+	}
+	return sorted, nil
+}
+
+func (h *historyEvaluator) Eval(ctx context.Context, from time.Time, interval time.Duration, evaluations int, callback callbackFunc) error {
+	to := from.Add(time.Duration(evaluations) * interval)
+	frame, err := h.history.Query(ctx, models.HistoryQuery{
+		RuleUID:      h.ruleUID,
+		OrgID:        h.user.GetOrgID(),
+		From:         from.Add(-h.cfg.lookback),
+		To:           to,
+		Limit:        h.cfg.maxEntries + 1,
+		SignedInUser: h.user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query the state history of rule %s: %w", h.ruleUID, err)
+	}
+	// The most recent entries are returned first, so the replay of the first evaluations would be incomplete.
+	if frame != nil && frame.Rows() > h.cfg.maxEntries {
+		return fmt.Errorf("%w: the state history of rule %s has more than %d entries in the time range, reduce the time range or increase the setting history_max_entries", ErrInvalidInputData, h.ruleUID, h.cfg.maxEntries)
+	}
+	var instances []*recordedInstance
+	if h.cfg.backend == historian.BackendTypeAnnotations {
+		instances, err = parseAnnotationHistory(frame, h.recordedRefID)
+	} else {
+		instances, err = parseStateHistory(frame, h.recordedRefID)
+	}
+	if err != nil {
+		return errors.Join(ErrInvalidInputData, err)
+	}
+
+	for idx, now := 0, from; idx < evaluations; idx, now = idx+1, now.Add(interval) {
+		err := callback(idx, now, h.evaluate(ctx, now, instances))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *historyEvaluator) evaluate(ctx context.Context, now time.Time, instances []*recordedInstance) eval.Results {
+	input := mathexp.Results{}
+	for _, i := range instances {
+		value := i.valueAt(now)
+		if value == nil {
+			continue
+		}
+		n := mathexp.NewNumber(h.refID, i.labels)
+		n.SetValue(value)
+		input.Values = append(input.Values, n)
+	}
+	if len(input.Values) == 0 {
+		return eval.Results{{State: eval.NoData, EvaluatedAt: now}}
+	}
+
+	vars := mathexp.Vars{h.refID: input}
+	for _, e := range h.expressions {
+		res, err := e.command.Execute(ctx, now, vars, h.tracer, nil)
+		if err != nil {
+			return eval.Results{eval.NewResultFromError(fmt.Errorf("failed to execute expression %s: %w", e.refID, err), now, 0)}
+		}
+		vars[e.refID] = res
+	}
+
+	results := make(eval.Results, 0, len(vars[h.condition].Values))
+	for _, v := range vars[h.condition].Values {
+		switch n := v.(type) {
+		case mathexp.Number:
+			r := eval.Result{
+				Instance:    n.GetLabels(),
+				Values:      captureValues(vars, n.GetLabels()),
+				EvaluatedAt: now,
+			}
+			switch value := n.GetFloat64Value(); {
+			case value == nil:
+				r.State = eval.NoData
+			case *value == 0:
+				r.State = eval.Normal
+			default:
+				r.State = eval.Alerting
+			}
+			results = append(results, r)
+		case mathexp.NoData:
+			results = append(results, eval.Result{State: eval.NoData, EvaluatedAt: now})
+		default:
+			return eval.Results{eval.NewResultFromError(fmt.Errorf("condition %s returned %s, only numbers can be alerted on", h.condition, v.Type()), now, 0)}
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, eval.Result{State: eval.NoData, EvaluatedAt: now})
+	}
+	return results
+}
+
+// captureValues returns the values of all queries and expressions for the alert instance with the given labels.
+func captureValues(vars mathexp.Vars, labels data.Labels) map[string]eval.NumberValueCapture {
+	captures := make(map[string]eval.NumberValueCapture, len(vars))
+	for refID, res := range vars {
+		for _, v := range res.Values {
+			n, ok := v.(mathexp.Number)
+			if !ok || !n.GetLabels().Equals(labels) {
+				continue
+			}
+			captures[refID] = eval.NumberValueCapture{
+				Var:    refID,
+				Labels: n.GetLabels(),
+				Value:  n.GetFloat64Value(),
+			}
+			break
+		}
+	}
+	return captures
+}
+
+// parseStateHistory extracts the recorded values of each alert instance from the state history in the format of the
+// Loki backend.
+func parseStateHistory(frame *data.Frame, refID string) ([]*recordedInstance, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	timeField, _ := frame.FieldByName("time")
+	lineField, _ := frame.FieldByName("line")
+	if timeField == nil || lineField == nil || timeField.Type() != data.FieldTypeTime || lineField.Type() != data.FieldTypeJSON {
+		return nil, errors.New("the state history backend does not provide the recorded values of evaluations")
+	}
+
+	instances := recordedInstances{}
+	for i := 0; i < frame.Rows(); i++ {
+		var entry historian.LokiEntry
+		if err := json.Unmarshal(lineField.At(i).(json.RawMessage), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse state history entry: %w", err)
+		}
+		key := refID
+		if key == "" {
+			key = entry.Condition
+		}
+		instances.add(data.Labels(entry.InstanceLabels), timeField.At(i).(time.Time), recordedValueOf(entry.Values, key))
+	}
+	return instances.sorted(), nil
+}
+
+// parseAnnotationHistory extracts the recorded values of each alert instance from the state history of the annotations
+// backend. Annotations record the labels of the alert instance in their text, in the format "<rule title> {<labels>} -
+// <values>", and the values of the evaluation in their data. The condition of the rule is not recorded, so refID must
+// not be empty.
+func parseAnnotationHistory(frame *data.Frame, refID string) ([]*recordedInstance, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	timeField, _ := frame.FieldByName("time")
+	textField, _ := frame.FieldByName("text")
+	dataField, _ := frame.FieldByName("data")
+	if timeField == nil || textField == nil || dataField == nil ||
+		timeField.Type() != data.FieldTypeTime || textField.Type() != data.FieldTypeString || dataField.Type() != data.FieldTypeString {
+		return nil, errors.New("the state history backend does not provide the recorded values of evaluations")
+	}
+
+	instances := recordedInstances{}
+	for i := 0; i < frame.Rows(); i++ {
+		labels, err := annotationLabels(textField.At(i).(string))
+		if err != nil {
+			return nil, err
+		}
+		var entry struct {
+			Values *simplejson.Json `json:"values"`
+		}
+		if err := json.Unmarshal([]byte(dataField.At(i).(string)), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse state history annotation: %w", err)
+		}
+		instances.add(labels, timeField.At(i).(time.Time), recordedValueOf(entry.Values, refID))
+	}
+	return instances.sorted(), nil
+}
+
+// annotationLabels parses the labels of an alert instance from the text of a state history annotation.
+func annotationLabels(text string) (data.Labels, error) {
+	start := strings.Index(text, " {")
+	end := strings.LastIndex(text, "} - ")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("failed to parse the labels of state history annotation %q", text)
+	}
+	labels, err := data.LabelsFromString(text[start+len(" {") : end])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the labels of state history annotation %q: %w", text, err)
+	}
+	return labels, nil
+}
+
+// recordedInstances groups recorded values by the labels of their alert instance.
+type recordedInstances map[data.Fingerprint]*recordedInstance
+
+// add records the value of the alert instance with the given labels. The labels that are added to every alert of a
+// rule are removed, as the tested rule adds its own.
+func (r recordedInstances) add(labels data.Labels, at time.Time, value *float64) {
+	labels = labels.Copy()
+	delete(labels, model.AlertNameLabel)
+	delete(labels, models.FolderTitleLabel)
+
+	fp := labels.Fingerprint()
+	instance, ok := r[fp]
+	if !ok {
+		instance = &recordedInstance{labels: labels}
+		r[fp] = instance
+	}
+	instance.values = append(instance.values, recordedValue{at: at, value: value})
+}
+
+// sorted returns the instances sorted by their labels, with their values sorted by time.
+func (r recordedInstances) sorted() []*recordedInstance {
+	instances := make([]*recordedInstance, 0, len(r))
+	for _, instance := range r {
+		sort.SliceStable(instance.values, func(i, j int) bool {
+			return instance.values[i].at.Before(instance.values[j].at)
+		})
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].labels.String() < instances[j].labels.String()
+	})
+	return instances
+}
+
+// recordedValueOf returns the recorded value of the query or expression with the given refID. Special values such as
+// NaN or infinity are recorded as strings.
+func recordedValueOf(values *simplejson.Json, refID string) *float64 {
+	if values == nil {
+		return nil
+	}
+	raw, ok := values.CheckGet(refID)
+	if !ok {
+		return nil
+	}
+	if v, err := raw.Float64(); err == nil {
+		return &v
+	}
+	s, err := raw.String()
+	if err != nil {
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,373 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var testHistoryConfig = historyConfig{
+	backend:    historian.BackendTypeLoki,
+	lookback:   24 * time.Hour,
+	maxEntries: 5000,
+}
+
+type fakeStateHistorian struct {
+	frame   *data.Frame
+	queries []models.HistoryQuery
+}
+
+func (f *fakeStateHistorian) Query(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.queries = append(f.queries, query)
+	return f.frame, nil
+}
+
+type historyEntry struct {
+	at        time.Time
+	labels    map[string]string
+	values    map[string]any
+	condition string
+}
+
+// historyFrame returns a frame in the format of the Loki state history backend.
+func historyFrame(t *testing.T, entries ...historyEntry) *data.Frame {
+	t.Helper()
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		line, err := json.Marshal(map[string]any{
+			"schemaVersion": 1,
+			"condition":     e.condition,
+			"labels":        e.labels,
+			"values":        e.values,
+		})
+		require.NoError(t, err)
+		times = append(times, e.at)
+		lines = append(lines, line)
+	}
+	return data.NewFrame("states",
+		data.NewField("time", nil, times),
+		data.NewField("line", nil, lines),
+	)
+}
+
+// annotationHistoryFrame returns a frame in the format of the annotations state history backend.
+func annotationHistoryFrame(t *testing.T, entries ...historyEntry) *data.Frame {
+	t.Helper()
+	times := make([]time.Time, 0, len(entries))
+	texts := make([]string, 0, len(entries))
+	values := make([]string, 0, len(entries))
+	for _, e := range entries {
+		d, err := json.Marshal(map[string]any{"values": e.values})
+		require.NoError(t, err)
+		times = append(times, e.at)
+		texts = append(texts, fmt.Sprintf("original {%s} - values", data.Labels(e.labels)))
+		values = append(values, string(d))
+	}
+	return data.NewFrame("states",
+		data.NewField("time", nil, times),
+		data.NewField("text", nil, texts),
+		data.NewField("data", nil, values),
+	)
+}
+
+func historyCondition(t *testing.T, model string, expressions ...models.AlertQuery) models.Condition {
+	t.Helper()
+	return models.Condition{
+		Condition: "C",
+		Data: append([]models.AlertQuery{
+			{
+				RefID:         "A",
+				DatasourceUID: historyDatasourceUID,
+				Model:         json.RawMessage(model),
+			},
+		}, expressions...),
+	}
+}
+
+func thresholdQuery(refID, expression string, threshold float64) models.AlertQuery {
+	return models.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(fmt.Sprintf(`{"type": "threshold", "expression": %q, "conditions": [{"evaluator": {"type": "gt", "params": [%v]}}]}`, expression, threshold)),
+	}
+}
+
+func TestHistoryEvaluator_New(t *testing.T) {
+	history := &fakeStateHistorian{}
+	tracer := tracing.InitializeTracerForTest()
+
+	t.Run("should sort expressions by their dependencies", func(t *testing.T) {
+		cond := historyCondition(t, `{"ruleUid": "rule"}`,
+			thresholdQuery("C", "B", 5),
+			models.AlertQuery{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type": "math", "expression": "$A * 2"}`)},
+		)
+		e, err := newHistoryEvaluator(history, testHistoryConfig, &user.SignedInUser{OrgID: 1}, tracer, cond, cond.Data[0])
+		require.NoError(t, err)
+		require.Equal(t, "rule", e.ruleUID)
+		require.Len(t, e.expressions, 2)
+		require.Equal(t, "B", e.expressions[0].refID)
+		require.Equal(t, "C", e.expressions[1].refID)
+	})
+
+	testCases := []struct {
+		name      string
+		history   stateHistorian
+		cfg       historyConfig
+		condition models.Condition
+	}{
+		{
+			name:      "history is not available",
+			cfg:       testHistoryConfig,
+			condition: historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("C", "A", 5)),
+		},
+		{
+			name:      "the history backend does not record values",
+			history:   history,
+			cfg:       historyConfig{backend: historian.BackendTypePrometheus, lookback: time.Hour, maxEntries: 10},
+			condition: historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("C", "A", 5)),
+		},
+		{
+			name:      "the recorded refID is empty with the annotations backend",
+			history:   history,
+			cfg:       historyConfig{backend: historian.BackendTypeAnnotations, lookback: time.Hour, maxEntries: 10},
+			condition: historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("C", "A", 5)),
+		},
+		{
+			name:      "rule UID is empty",
+			history:   history,
+			cfg:       testHistoryConfig,
+			condition: historyCondition(t, `{}`, thresholdQuery("C", "A", 5)),
+		},
+		{
+			name:    "there are other data queries",
+			history: history,
+			cfg:     testHistoryConfig,
+			condition: historyCondition(t, `{"ruleUid": "rule"}`,
+				thresholdQuery("C", "A", 5),
+				models.AlertQuery{RefID: "D", DatasourceUID: "some-datasource", Model: json.RawMessage(`{}`)},
+			),
+		},
+		{
+			name:      "an expression refers to an unknown query",
+			history:   history,
+			cfg:       testHistoryConfig,
+			condition: historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("C", "B", 5)),
+		},
+		{
+			name:      "the condition refers to an unknown query",
+			history:   history,
+			cfg:       testHistoryConfig,
+			condition: historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("D", "A", 5)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("should fail if "+tc.name, func(t *testing.T) {
+			_, err := newHistoryEvaluator(tc.history, tc.cfg, &user.SignedInUser{OrgID: 1}, tracer, tc.condition, tc.condition.Data[0])
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestHistoryEvaluator_Eval(t *testing.T) {
+	from := time.Unix(0, 0).Add(24 * time.Hour).UTC()
+	at := func(d time.Duration) time.Time {
+		return from.Add(d)
+	}
+	hostA := map[string]string{"alertname": "original", "grafana_folder": "folder", "host": "a"}
+	hostB := map[string]string{"alertname": "original", "grafana_folder": "folder", "host": "b"}
+
+	history := &fakeStateHistorian{
+		frame: historyFrame(t,
+			historyEntry{at: at(-time.Hour), labels: hostB, values: map[string]any{"B": 20, "C": 1}, condition: "C"},
+			historyEntry{at: at(0), labels: hostA, values: map[string]any{"B": 3, "C": 0}, condition: "C"},
+			historyEntry{at: at(3 * time.Minute), labels: hostA, values: map[string]any{"B": 10, "C": 1}, condition: "C"},
+			historyEntry{at: at(6 * time.Minute), labels: hostA, values: map[string]any{}, condition: "C"},
+		),
+	}
+	cond := historyCondition(t, `{"ruleUid": "rule", "recordedRefId": "B"}`, thresholdQuery("C", "A", 5))
+	e, err := newHistoryEvaluator(history, testHistoryConfig, &user.SignedInUser{OrgID: 1}, tracing.InitializeTracerForTest(), cond, cond.Data[0])
+	require.NoError(t, err)
+
+	type instanceState map[string]eval.State
+	var states []instanceState
+	err = e.Eval(context.Background(), from, time.Minute, 8, func(idx int, now time.Time, results eval.Results) error {
+		require.Equal(t, at(time.Duration(idx)*time.Minute), now)
+		s := instanceState{}
+		for _, r := range results {
+			s[r.Instance["host"]] = r.State
+			require.Equal(t, now, r.EvaluatedAt)
+			require.NotContains(t, r.Instance, "alertname")
+			require.NotContains(t, r.Instance, "grafana_folder")
+		}
+		states = append(states, s)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, history.queries, 1)
+	require.Equal(t, "rule", history.queries[0].RuleUID)
+	require.Equal(t, int64(1), history.queries[0].OrgID)
+	require.Equal(t, from.Add(-testHistoryConfig.lookback), history.queries[0].From)
+	require.Equal(t, at(8*time.Minute), history.queries[0].To)
+
+	require.Equal(t, []instanceState{
+		{"a": eval.Normal, "b": eval.Alerting},
+		{"a": eval.Normal, "b": eval.Alerting},
+		{"a": eval.Normal, "b": eval.Alerting},
+		{"a": eval.Alerting, "b": eval.Alerting},
+		{"a": eval.Alerting, "b": eval.Alerting},
+		{"a": eval.Alerting, "b": eval.Alerting},
+		// The last entry of host a did not record any value, so it is not part of the data anymore.
+		{"b": eval.Alerting},
+		{"b": eval.Alerting},
+	}, states)
+
+	t.Run("should replay the condition if recorded refID is empty", func(t *testing.T) {
+		cond := historyCondition(t, `{"ruleUid": "rule"}`, thresholdQuery("C", "A", 0.5))
+		e, err := newHistoryEvaluator(history, testHistoryConfig, &user.SignedInUser{OrgID: 1}, tracing.InitializeTracerForTest(), cond, cond.Data[0])
+		require.NoError(t, err)
+
+		var alerting []int
+		err = e.Eval(context.Background(), from, time.Minute, 4, func(idx int, now time.Time, results eval.Results) error {
+			for _, r := range results {
+				if r.Instance["host"] == "a" && r.State == eval.Alerting {
+					alerting = append(alerting, idx)
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int{3}, alerting)
+	})
+
+	t.Run("should fail if the history has more entries than replayed", func(t *testing.T) {
+		cfg := testHistoryConfig
+		cfg.maxEntries = 3
+		e, err := newHistoryEvaluator(history, cfg, &user.SignedInUser{OrgID: 1}, tracing.InitializeTracerForTest(), cond, cond.Data[0])
+		require.NoError(t, err)
+		err = e.Eval(context.Background(), from, time.Minute, 1, func(int, time.Time, eval.Results) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+		require.ErrorContains(t, err, "more than 3 entries")
+		require.Equal(t, 4, history.queries[len(history.queries)-1].Limit)
+	})
+
+	t.Run("should fail if the history does not contain the recorded values", func(t *testing.T) {
+		history := &fakeStateHistorian{
+			frame: data.NewFrame("states", data.NewField("time", nil, []time.Time{}), data.NewField("text", nil, []string{})),
+		}
+		e, err := newHistoryEvaluator(history, testHistoryConfig, &user.SignedInUser{OrgID: 1}, tracing.InitializeTracerForTest(), cond, cond.Data[0])
+		require.NoError(t, err)
+		err = e.Eval(context.Background(), from, time.Minute, 1, func(int, time.Time, eval.Results) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
+func TestHistoryEvaluator_EvalAnnotations(t *testing.T) {
+	from := time.Unix(0, 0).Add(24 * time.Hour).UTC()
+	at := func(d time.Duration) time.Time {
+		return from.Add(d)
+	}
+	hostA := map[string]string{"alertname": "original", "grafana_folder": "folder", "host": "a"}
+	hostB := map[string]string{"alertname": "original", "grafana_folder": "folder", "host": "b"}
+
+	history := &fakeStateHistorian{
+		frame: annotationHistoryFrame(t,
+			historyEntry{at: at(-time.Hour), labels: hostB, values: map[string]any{"B": 20, "C": 1}},
+			historyEntry{at: at(0), labels: hostA, values: map[string]any{"B": 3, "C": 0}},
+			historyEntry{at: at(2 * time.Minute), labels: hostA, values: map[string]any{"B": "NaN", "C": 1}},
+			historyEntry{at: at(3 * time.Minute), labels: hostA, values: map[string]any{"B": 10, "C": 1}},
+		),
+	}
+	cfg := testHistoryConfig
+	cfg.backend = historian.BackendTypeAnnotations
+	cond := historyCondition(t, `{"ruleUid": "rule", "recordedRefId": "B"}`, thresholdQuery("C", "A", 5))
+	e, err := newHistoryEvaluator(history, cfg, &user.SignedInUser{OrgID: 1}, tracing.InitializeTracerForTest(), cond, cond.Data[0])
+	require.NoError(t, err)
+
+	type instanceState map[string]eval.State
+	var states []instanceState
+	err = e.Eval(context.Background(), from, time.Minute, 4, func(idx int, now time.Time, results eval.Results) error {
+		s := instanceState{}
+		for _, r := range results {
+			s[r.Instance["host"]] = r.State
+			require.NotContains(t, r.Instance, "alertname")
+			require.NotContains(t, r.Instance, "grafana_folder")
+		}
+		states = append(states, s)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []instanceState{
+		{"a": eval.Normal, "b": eval.Alerting},
+		{"a": eval.Normal, "b": eval.Alerting},
+		{"a": eval.Normal, "b": eval.Alerting},
+		{"a": eval.Alerting, "b": eval.Alerting},
+	}, states)
+
+	t.Run("should fail if the labels of an annotation cannot be parsed", func(t *testing.T) {
+		history := &fakeStateHistorian{
+			frame: data.NewFrame("states",
+				data.NewField("time", nil, []time.Time{from}),
+				data.NewField("text", nil, []string{"no labels"}),
+				data.NewField("data", nil, []string{`{}`}),
+			),
+		}
+		e, err := newHistoryEvaluator(history, cfg, &user.SignedInUser{OrgID: 1}, tracing.InitializeTracerForTest(), cond, cond.Data[0])
+		require.NoError(t, err)
+		err = e.Eval(context.Background(), from, time.Minute, 1, func(int, time.Time, eval.Results) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
+func TestNewHistoryConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		history  setting.UnifiedAlertingStateHistorySettings
+		expected historian.BackendType
+	}{
+		{
+			name:     "disabled",
+			history:  setting.UnifiedAlertingStateHistorySettings{Enabled: false, Backend: "loki"},
+			expected: historian.BackendTypeNoop,
+		},
+		{
+			name:     "single backend",
+			history:  setting.UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "sql"},
+			expected: historian.BackendTypeSQL,
+		},
+		{
+			name:     "primary of multiple backends",
+			history:  setting.UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "multiple", MultiPrimary: "loki", MultiSecondaries: []string{"annotations"}},
+			expected: historian.BackendTypeLoki,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newHistoryConfig(&setting.UnifiedAlertingSettings{
+				StateHistory: tc.history,
+				Backtesting:  setting.UnifiedAlertingBacktestingSettings{HistoryLookback: time.Hour, HistoryMaxEntries: 10},
+			})
+			require.Equal(t, historyConfig{backend: tc.expected, lookback: time.Hour, maxEntries: 10}, cfg)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package backtesting
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// defaultResolveTimeout is the time after which the Alertmanager resolves an alert that was received without an end time.
+const defaultResolveTimeout = 5 * time.Minute
+
+// Notification is a notification that a contact point would have received.
+type Notification struct {
+	Time        time.Time
+	Receiver    string
+	GroupKey    string
+	GroupLabels data.Labels
+	Firing      int
+	Resolved    int
+}
+
+// postedAlert is an alert that was sent to the Alertmanager at a specific time.
+type postedAlert struct {
+	at    time.Time
+	alert *amv2.PostableAlert
+}
+
+type simulatedAlert struct {
+	labels   model.LabelSet
+	startsAt time.Time
+	endsAt   time.Time
+}
+
+func (a *simulatedAlert) resolvedAt(now time.Time) bool {
+	return !a.endsAt.After(now)
+}
+
+// merge works like the Alertmanager when it receives an update of an alert it already knows: the update replaces
+// the alert unless their activity ranges overlap, in which case the earliest start and the latest end are kept.
+func (a *simulatedAlert) merge(now time.Time, o simulatedAlert) {
+	overlaps := (o.endsAt.After(a.startsAt) && o.endsAt.Before(a.endsAt)) ||
+		(o.startsAt.After(a.startsAt) && o.startsAt.Before(a.endsAt))
+	if overlaps {
+		if a.startsAt.Before(o.startsAt) {
+			o.startsAt = a.startsAt
+		}
+		if !o.resolvedAt(now) && a.endsAt.After(o.endsAt) {
+			o.endsAt = a.endsAt
+		}
+	}
+	*a = o
+}
+
+// notificationLogEntry is what the Alertmanager remembers about the last notification sent for an aggregation group.
+type notificationLogEntry struct {
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+	sentAt   time.Time
+}
+
+// aggregationGroup is a group of alerts that are routed to the same route and share the same group labels.
+type aggregationGroup struct {
+	key        string
+	route      *dispatch.Route
+	labels     model.LabelSet
+	alerts     map[model.Fingerprint]*simulatedAlert
+	next       time.Time
+	hasFlushed bool
+}
+
+// notificationSimulator replays alerts through a notification policy tree and records the notifications the
+// Alertmanager would have sent. It follows the grouping and timing rules of the Alertmanager dispatcher: a new
+// group waits for group_wait before the first notification, a group that changed is flushed every group_interval,
+// and a group that did not change is notified again only after repeat_interval. Mute timings, silences and
+// inhibition rules are not simulated, and resolved notifications are assumed to be enabled for every contact point.
+type notificationSimulator struct {
+	root          *dispatch.Route
+	groups        map[string]*aggregationGroup
+	log           map[string]*notificationLogEntry
+	notifications []Notification
+}
+
+func newNotificationSimulator(route *definitions.Route) (*notificationSimulator, error) {
+	if route == nil {
+		return nil, fmt.Errorf("%w: notification policy tree must not be empty", ErrInvalidInputData)
+	}
+	// Validate also parses the group_by fields of the policies.
+	if err := route.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: invalid notification policy tree: %w", ErrInvalidInputData, err)
+	}
+	return &notificationSimulator{
+		root:   dispatch.NewRoute(route.AsAMRoute(), nil),
+		groups: make(map[string]*aggregationGroup),
+		log:    make(map[string]*notificationLogEntry),
+	}, nil
+}
+
+// simulateNotifications returns the notifications that the notification policy tree would have sent for the alerts
+// until the given time. The alerts must be sorted by the time they were sent.
+func simulateNotifications(route *definitions.Route, alerts []postedAlert, until time.Time) ([]Notification, error) {
+	s, err := newNotificationSimulator(route)
+	if err != nil {
+		return nil, err
+	}
+	idx := 0
+	for {
+		group := s.nextGroup()
+		// Alerts that are received at the same time as a group is flushed are included in the flush.
+		if idx < len(alerts) && (group == nil || !group.next.Before(alerts[idx].at)) {
+			s.insert(alerts[idx].at, alerts[idx].alert)
+			idx++
+			continue
+		}
+		if group == nil || group.next.After(until) {
+			return s.notifications, nil
+		}
+		s.flush(group)
+	}
+}
+
+func (s *notificationSimulator) insert(now time.Time, postable *amv2.PostableAlert) {
+	alert := simulatedAlert{
+		labels:   make(model.LabelSet, len(postable.Labels)),
+		startsAt: time.Time(postable.StartsAt),
+		endsAt:   time.Time(postable.EndsAt),
+	}
+	for k, v := range postable.Labels {
+		alert.labels[model.LabelName(k)] = model.LabelValue(v)
+	}
+	if alert.startsAt.IsZero() {
+		alert.startsAt = now
+	}
+	if alert.endsAt.IsZero() {
+		alert.endsAt = now.Add(defaultResolveTimeout)
+	}
+	fp := alert.labels.Fingerprint()
+
+	for _, route := range s.root.Match(alert.labels) {
+		groupLabels := model.LabelSet{}
+		for name, value := range alert.labels {
+			if _, ok := route.RouteOpts.GroupBy[name]; ok || route.RouteOpts.GroupByAll {
+				groupLabels[name] = value
+			}
+		}
+		key := fmt.Sprintf("%s:%s", route.Key(), groupLabels)
+		group, ok := s.groups[key]
+		if !ok {
+			group = &aggregationGroup{
+				key:    key,
+				route:  route,
+				labels: groupLabels,
+				alerts: make(map[model.Fingerprint]*simulatedAlert),
+				next:   now.Add(route.RouteOpts.GroupWait),
+			}
+			s.groups[key] = group
+		}
+
+		if existing, ok := group.alerts[fp]; ok {
+			existing.merge(now, alert)
+		} else {
+			a := alert
+			group.alerts[fp] = &a
+		}
+		// The Alertmanager flushes a group immediately if the group wait of the alert is already over.
+		if !group.hasFlushed && alert.startsAt.Add(route.RouteOpts.GroupWait).Before(now) {
+			group.next = now
+		}
+	}
+}
+
+// nextGroup returns the group that is flushed next, or nil if there are no groups.
+func (s *notificationSimulator) nextGroup() *aggregationGroup {
+	var next *aggregationGroup
+	for _, g := range s.groups {
+		if next == nil || g.next.Before(next.next) || (g.next.Equal(next.next) && g.key < next.key) {
+			next = g
+		}
+	}
+	return next
+}
+
+func (s *notificationSimulator) flush(group *aggregationGroup) {
+	now := group.next
+	firing := make(map[model.Fingerprint]struct{})
+	resolved := make(map[model.Fingerprint]struct{})
+	for fp, a := range group.alerts {
+		if a.resolvedAt(now) {
+			resolved[fp] = struct{}{}
+		} else {
+			firing[fp] = struct{}{}
+		}
+	}
+
+	receiver := group.route.RouteOpts.Receiver
+	logKey := receiver + "/" + group.key
+	if entry := s.log[logKey]; needsUpdate(entry, firing, resolved, now, group.route.RouteOpts.RepeatInterval) {
+		labels := make(data.Labels, len(group.labels))
+		for k, v := range group.labels {
+			labels[string(k)] = string(v)
+		}
+		s.notifications = append(s.notifications, Notification{
+			Time:        now,
+			Receiver:    receiver,
+			GroupKey:    group.key,
+			GroupLabels: labels,
+			Firing:      len(firing),
+			Resolved:    len(resolved),
+		})
+		s.log[logKey] = &notificationLogEntry{firing: firing, resolved: resolved, sentAt: now}
+	}
+
+	// Resolved alerts are removed from the group once they have been flushed.
+	for fp := range resolved {
+		delete(group.alerts, fp)
+	}
+	group.hasFlushed = true
+	group.next = now.Add(group.route.RouteOpts.GroupInterval)
+	if len(group.alerts) == 0 {
+		delete(s.groups, group.key)
+	}
+}
+
+// needsUpdate decides whether a group is notified in the same way as the deduplication stage of the Alertmanager.
+func needsUpdate(entry *notificationLogEntry, firing, resolved map[model.Fingerprint]struct{}, now time.Time, repeat time.Duration) bool {
+	if entry == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, entry.firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	if !isSubset(resolved, entry.resolved) {
+		return true
+	}
+	return entry.sentAt.Before(now.Add(-repeat))
+}
+
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sortPostedAlerts sorts the alerts by the time they were sent, keeping the order of alerts sent at the same time.
+func sortPostedAlerts(alerts []postedAlert) {
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].at.Before(alerts[j].at)
+	})
+}
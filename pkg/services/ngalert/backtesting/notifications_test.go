@@ -0,0 +1,121 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestSimulateNotifications(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+	// firing returns the alerts sent every minute while the alert is firing, and the alert that resolves it.
+	firing := func(labels map[string]string, from, to time.Duration) []postedAlert {
+		var result []postedAlert
+		for d := from; d < to; d += time.Minute {
+			result = append(result, postable(at(d), labels, at(from), at(d+4*time.Minute)))
+		}
+		return append(result, postable(at(to), labels, at(from), at(to)))
+	}
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	route := func() *definitions.Route {
+		return &definitions.Route{
+			Receiver:       "default",
+			GroupByStr:     []string{model.AlertNameLabel},
+			GroupWait:      duration(30 * time.Second),
+			GroupInterval:  duration(5 * time.Minute),
+			RepeatInterval: duration(time.Hour),
+			Routes: []*definitions.Route{
+				{
+					Receiver:       "team-a",
+					GroupByStr:     []string{"team"},
+					ObjectMatchers: definitions.ObjectMatchers{mustMatcher(t, "team", "a")},
+				},
+			},
+		}
+	}
+
+	t.Run("should wait for group_wait and repeat after repeat_interval", func(t *testing.T) {
+		alerts := firing(map[string]string{model.AlertNameLabel: "test"}, 0, 90*time.Minute)
+
+		notifications, err := simulateNotifications(route(), alerts, at(2*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []Notification{
+			{Time: at(30 * time.Second), Receiver: "default", GroupKey: `{}:{alertname="test"}`, GroupLabels: data.Labels{model.AlertNameLabel: "test"}, Firing: 1},
+			{Time: at(65*time.Minute + 30*time.Second), Receiver: "default", GroupKey: `{}:{alertname="test"}`, GroupLabels: data.Labels{model.AlertNameLabel: "test"}, Firing: 1},
+			{Time: at(90*time.Minute + 30*time.Second), Receiver: "default", GroupKey: `{}:{alertname="test"}`, GroupLabels: data.Labels{model.AlertNameLabel: "test"}, Resolved: 1},
+		}, notifications)
+	})
+
+	t.Run("should group alerts and notify about new alerts after group_interval", func(t *testing.T) {
+		alerts := append(
+			firing(map[string]string{model.AlertNameLabel: "test", "team": "a", "host": "1"}, 0, 20*time.Minute),
+			firing(map[string]string{model.AlertNameLabel: "test", "team": "a", "host": "2"}, 2*time.Minute, 20*time.Minute)...,
+		)
+		sortPostedAlerts(alerts)
+
+		notifications, err := simulateNotifications(route(), alerts, at(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, notifications, 3)
+		for _, n := range notifications {
+			require.Equal(t, "team-a", n.Receiver)
+			require.Equal(t, data.Labels{"team": "a"}, n.GroupLabels)
+		}
+		require.Equal(t, at(30*time.Second), notifications[0].Time)
+		require.Equal(t, 1, notifications[0].Firing)
+		// The second alert is only sent with the next flush of the group.
+		require.Equal(t, at(5*time.Minute+30*time.Second), notifications[1].Time)
+		require.Equal(t, 2, notifications[1].Firing)
+		require.Equal(t, at(20*time.Minute+30*time.Second), notifications[2].Time)
+		require.Equal(t, 2, notifications[2].Resolved)
+	})
+
+	t.Run("should not notify about alerts resolved before group_wait", func(t *testing.T) {
+		alerts := []postedAlert{
+			postable(at(0), map[string]string{model.AlertNameLabel: "test"}, at(0), at(4*time.Minute)),
+			postable(at(10*time.Second), map[string]string{model.AlertNameLabel: "test"}, at(0), at(10*time.Second)),
+		}
+
+		notifications, err := simulateNotifications(route(), alerts, at(time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, notifications)
+	})
+
+	t.Run("should fail if the notification policy tree is invalid", func(t *testing.T) {
+		_, err := simulateNotifications(&definitions.Route{}, nil, at(time.Hour))
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
+func postable(at time.Time, labels map[string]string, startsAt, endsAt time.Time) postedAlert {
+	return postedAlert{
+		at: at,
+		alert: &amv2.PostableAlert{
+			StartsAt: strfmt.DateTime(startsAt),
+			EndsAt:   strfmt.DateTime(endsAt),
+			Alert: amv2.Alert{
+				Labels: labels,
+			},
+		},
+	}
+}
+
+func mustMatcher(t *testing.T, name, value string) *labels.Matcher {
+	t.Helper()
+	m, err := labels.NewMatcher(labels.MatchEqual, name, value)
+	require.NoError(t, err)
+	return m
+}
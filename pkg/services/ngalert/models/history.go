@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
@@ -18,3 +19,41 @@ type HistoryQuery struct {
 	Limit        int
 	SignedInUser identity.Requester
 }
+
+// StateHistoryEntry is a state transition of an alert instance that is stored in the database.
+type StateHistoryEntry struct {
+	ID           int64
+	OrgID        int64
+	RuleUID      string
+	RuleID       int64
+	RuleTitle    string
+	RuleGroup    string
+	NamespaceUID string
+	DashboardUID string
+	PanelID      int64
+	Condition    string
+	Fingerprint  string
+	Labels       map[string]string
+	Previous     string
+	Current      string
+	Error        string
+	// Values is the JSON encoded map of the values of the queries and expressions of the rule.
+	Values json.RawMessage
+	At     time.Time
+}
+
+// StateHistoryEntryQuery represents a query for state history entries stored in the database.
+type StateHistoryEntryQuery struct {
+	OrgID        int64
+	RuleUID      string
+	DashboardUID string
+	PanelID      int64
+	// NamespaceUIDs limits the entries to the rules in these folders. All folders are queried if it is empty.
+	NamespaceUIDs []string
+	// Labels are matched for equality against the labels of the alert instances.
+	Labels map[string]string
+	From   time.Time
+	To     time.Time
+	// Limit is the maximum number of entries to return, the most recent entries are returned first.
+	Limit int
+}
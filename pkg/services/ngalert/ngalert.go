@@ -3,6 +3,7 @@ package ngalert
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
@@ -345,6 +346,7 @@ func (ng *AlertNG) init() error {
 		ng.annotationsRepo,
 		ng.dashboardService,
 		ng.store,
+		ng.store,
 		ng.Metrics.GetHistorianMetrics(),
 		ng.Log,
 		ng.tracer,
@@ -575,7 +577,15 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 			return ng.stateManager.Run(subCtx)
 		})
 	}
-	return children.Wait()
+	err := children.Wait()
+
+	// The rules are no longer evaluated, so the connections of the recording writer can be released.
+	if closer, ok := ng.RecordingWriter.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			ng.Log.Warn("Failed to close the recording rules writer", "error", err)
+		}
+	}
+	return err
 }
 
 // IsDisabled returns true if the alerting service is disabled for this instance.
@@ -604,6 +614,7 @@ func configureHistorianBackend(
 	ar annotations.Repository,
 	ds dashboards.DashboardService,
 	rs historian.RuleStore,
+	hs historian.StateHistoryStore,
 	met *metrics.Historian,
 	l log.Logger,
 	tracer tracing.Tracer,
@@ -628,7 +639,7 @@ func configureHistorianBackend(
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer, ac, datasourceService, httpClientProvider, pluginContextProvider, clock, mw)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, hs, met, l, tracer, ac, datasourceService, httpClientProvider, pluginContextProvider, clock, mw)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -637,7 +648,7 @@ func configureHistorianBackend(
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer, ac, datasourceService, httpClientProvider, pluginContextProvider, clock, mw)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, hs, met, l, tracer, ac, datasourceService, httpClientProvider, pluginContextProvider, clock, mw)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -671,6 +682,12 @@ func configureHistorianBackend(
 		return backend, nil
 	}
 
+	if backend == historian.BackendTypeSQL {
+		logCtx := log.WithContextualAttributes(ctx, []any{"backend", "sql"})
+		sqlBackendLogger := log.New("ngalert.state.historian").FromContext(logCtx)
+		return historian.NewSQLBackend(sqlBackendLogger, hs, rs, met, ac), nil
+	}
+
 	if backend == historian.BackendTypePrometheus {
 		pcfg, err := historian.NewPrometheusConfig(cfg)
 		if err != nil {
@@ -736,6 +753,23 @@ func createRecordingWriter(settings setting.RecordingRuleSettings, httpClientPro
 		logger.Info("Setting up remote write using data sources",
 			"timeout", cfg.Timeout, "default_datasource_uid", cfg.DefaultDatasourceUID)
 
+		if settings.OTLP.Enabled() {
+			cfg.OTLPTargets = map[string]writer.OTLPWriterConfig{
+				settings.OTLP.TargetDatasourceUID: {
+					Endpoint:           settings.OTLP.Endpoint,
+					Protocol:           writer.OTLPProtocol(settings.OTLP.Protocol),
+					Insecure:           settings.OTLP.Insecure,
+					Headers:            settings.OTLP.Headers,
+					Timeout:            settings.Timeout,
+					ResourceLabels:     settings.OTLP.ResourceLabels,
+					ResourceAttributes: settings.OTLP.ResourceAttributes,
+				},
+			}
+
+			logger.Info("Setting up OTLP metrics output for recording rules",
+				"target_datasource_uid", settings.OTLP.TargetDatasourceUID, "endpoint", settings.OTLP.Endpoint, "protocol", settings.OTLP.Protocol)
+		}
+
 		return writer.NewDatasourceWriter(cfg, datasourceService, httpClientProvider, pluginContextProvider, clock, logger, m), nil
 	}
 
@@ -0,0 +1,568 @@
+package prom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	mathparse "github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// UnsupportedRule is a Grafana rule that cannot be expressed as a Prometheus rule.
+type UnsupportedRule struct {
+	UID    string
+	Title  string
+	Reason string
+}
+
+// ExportResult is the result of the conversion of a Grafana rule group to a Prometheus rule group.
+type ExportResult struct {
+	// Group contains the rules that can be expressed as Prometheus rules, in the order of the Grafana rule group.
+	Group PrometheusRuleGroup
+	// Unsupported contains the rules that cannot be expressed as Prometheus rules, and the reason why.
+	Unsupported []UnsupportedRule
+}
+
+// GrafanaRulesToPrometheus converts a Grafana rule group into a Prometheus rule group.
+//
+// Rules that were converted from Prometheus and kept their original definition are exported with it. Other rules
+// can be exported if they query a Prometheus or Loki data source with a single query, and only use reduce, math and
+// threshold expressions on its result. The query offset of the group is taken from the query of the first rule,
+// rules with a different offset cannot be exported.
+//
+// Features of Grafana rules that do not change the result of the query, such as notification settings, are not exported.
+func GrafanaRulesToPrometheus(group models.AlertRuleGroup) ExportResult {
+	result := ExportResult{
+		Group: PrometheusRuleGroup{
+			Name:     group.Title,
+			Interval: prommodel.Duration(time.Duration(group.Interval) * time.Second),
+			Rules:    make([]PrometheusRule, 0, len(group.Rules)),
+		},
+	}
+
+	var queryOffset *time.Duration
+	for _, rule := range group.Rules {
+		promRule, offset, err := grafanaRuleToPrometheus(rule)
+		if err == nil && queryOffset != nil && offset != *queryOffset {
+			err = fmt.Errorf("query offset %s is different from the query offset %s of the group", prommodel.Duration(offset), prommodel.Duration(*queryOffset))
+		}
+		if err != nil {
+			result.Unsupported = append(result.Unsupported, UnsupportedRule{
+				UID:    rule.UID,
+				Title:  rule.Title,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		if queryOffset == nil {
+			queryOffset = &offset
+		}
+		result.Group.Rules = append(result.Group.Rules, promRule)
+	}
+
+	if queryOffset != nil && *queryOffset > 0 {
+		d := prommodel.Duration(*queryOffset)
+		result.Group.QueryOffset = &d
+	}
+	return result
+}
+
+// GrafanaRuleGroupsToPrometheusYAML converts Grafana rule groups into a YAML document in the format of the
+// Prometheus-compatible ruler API, a map of folder paths to rule groups. The rules that cannot be exported are
+// listed in a comment at the top of the document.
+func GrafanaRuleGroupsToPrometheusYAML(groups []models.AlertRuleGroupWithFolderFullpath) ([]byte, error) {
+	namespaces := make(map[string][]PrometheusRuleGroup, len(groups))
+	var comment strings.Builder
+	for _, group := range groups {
+		result := GrafanaRulesToPrometheus(*group.AlertRuleGroup)
+		for _, rule := range result.Unsupported {
+			if comment.Len() == 0 {
+				comment.WriteString("# The following rules cannot be exported in Prometheus format:\n")
+			}
+			reason := strings.ReplaceAll(rule.Reason, "\n", " ")
+			fmt.Fprintf(&comment, "# - %s/%s: %q (%s): %s\n", group.FolderFullpath, group.Title, rule.Title, rule.UID, reason)
+		}
+		if len(result.Group.Rules) == 0 {
+			continue
+		}
+		namespaces[group.FolderFullpath] = append(namespaces[group.FolderFullpath], result.Group)
+	}
+
+	body, err := yaml.Marshal(namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Prometheus rule groups: %w", err)
+	}
+	return append([]byte(comment.String()), body...), nil
+}
+
+func grafanaRuleToPrometheus(rule models.AlertRule) (PrometheusRule, time.Duration, error) {
+	if rule.IsPaused {
+		return PrometheusRule{}, 0, fmt.Errorf("paused rules cannot be exported")
+	}
+
+	q, err := newQueryTranslator(rule.Data)
+	if err != nil {
+		return PrometheusRule{}, 0, err
+	}
+
+	if definition, err := rule.PrometheusRuleDefinition(); err == nil {
+		var r PrometheusRule
+		if err := yaml.Unmarshal([]byte(definition), &r); err != nil {
+			return PrometheusRule{}, 0, fmt.Errorf("failed to unmarshal the original Prometheus rule definition: %w", err)
+		}
+		return r, q.offset, nil
+	}
+
+	if rule.Type() == models.RuleTypeRecording {
+		promExpr, err := q.value(rule.Record.From)
+		if err != nil {
+			return PrometheusRule{}, 0, err
+		}
+		if err := q.validate(promExpr); err != nil {
+			return PrometheusRule{}, 0, err
+		}
+		return PrometheusRule{
+			Record: rule.Record.Metric,
+			Expr:   promExpr,
+			Labels: exportedLabels(rule.Labels),
+		}, q.offset, nil
+	}
+
+	if rule.NoDataState == models.Alerting {
+		return PrometheusRule{}, 0, fmt.Errorf("no data state %s cannot be expressed in Prometheus", rule.NoDataState)
+	}
+	if rule.ExecErrState == models.AlertingErrState {
+		return PrometheusRule{}, 0, fmt.Errorf("error state %s cannot be expressed in Prometheus", rule.ExecErrState)
+	}
+
+	promExpr, err := q.condition(rule.Condition)
+	if err != nil {
+		return PrometheusRule{}, 0, err
+	}
+	if err := q.validate(promExpr); err != nil {
+		return PrometheusRule{}, 0, err
+	}
+
+	result := PrometheusRule{
+		Alert:       rule.Title,
+		Expr:        promExpr,
+		Labels:      exportedLabels(rule.Labels),
+		Annotations: rule.Annotations,
+	}
+	if rule.For > 0 {
+		d := prommodel.Duration(rule.For)
+		result.For = &d
+	}
+	if rule.KeepFiringFor > 0 {
+		d := prommodel.Duration(rule.KeepFiringFor)
+		result.KeepFiringFor = &d
+	}
+	return result, q.offset, nil
+}
+
+// exportedLabels returns the labels of a rule without the private labels that Grafana adds to some rules.
+func exportedLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if strings.HasPrefix(k, "__") {
+			continue
+		}
+		result[k] = v
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// queryTranslator builds a Prometheus expression from the query and the expressions of a Grafana rule.
+type queryTranslator struct {
+	queries        map[string]models.AlertQuery
+	dataRefID      string
+	datasourceType string
+	expr           string
+	instant        bool
+	window         time.Duration
+	offset         time.Duration
+}
+
+func newQueryTranslator(queries []models.AlertQuery) (*queryTranslator, error) {
+	t := &queryTranslator{
+		queries: make(map[string]models.AlertQuery, len(queries)),
+	}
+	for _, q := range queries {
+		t.queries[q.RefID] = q
+		if isExpr, _ := q.IsExpression(); isExpr {
+			continue
+		}
+		if t.dataRefID != "" {
+			return nil, fmt.Errorf("rules with more than one data source query cannot be exported")
+		}
+		t.dataRefID = q.RefID
+
+		var model struct {
+			Datasource *struct {
+				Type string `json:"type"`
+			} `json:"datasource"`
+			Expr      string `json:"expr"`
+			Instant   bool   `json:"instant"`
+			Range     bool   `json:"range"`
+			QueryType string `json:"queryType"`
+		}
+		if err := json.Unmarshal(q.Model, &model); err != nil {
+			return nil, fmt.Errorf("failed to parse query %s: %w", q.RefID, err)
+		}
+		t.datasourceType = q.QueryType
+		if model.Datasource != nil && model.Datasource.Type != "" {
+			t.datasourceType = model.Datasource.Type
+		}
+		switch t.datasourceType {
+		case datasources.DS_PROMETHEUS:
+			t.instant = model.Instant && !model.Range
+		case datasources.DS_LOKI:
+			t.instant = model.QueryType == "instant" || model.Instant
+		default:
+			return nil, fmt.Errorf("query %s does not query a Prometheus or Loki data source", q.RefID)
+		}
+		if model.Expr == "" {
+			return nil, fmt.Errorf("query %s has no expression", q.RefID)
+		}
+		t.expr = model.Expr
+		t.offset = time.Duration(q.RelativeTimeRange.To)
+		t.window = time.Duration(q.RelativeTimeRange.From) - time.Duration(q.RelativeTimeRange.To)
+	}
+	if t.dataRefID == "" {
+		return nil, fmt.Errorf("rules without a data source query cannot be exported")
+	}
+	return t, nil
+}
+
+// validate checks that the expression can be parsed by Prometheus. LogQL expressions are not validated.
+func (t *queryTranslator) validate(promExpr string) error {
+	if t.datasourceType != datasources.DS_PROMETHEUS {
+		return nil
+	}
+	if _, err := parser.ParseExpr(promExpr); err != nil {
+		return fmt.Errorf("the exported expression is not a valid PromQL expression: %s", err)
+	}
+	return nil
+}
+
+// condition returns an expression that returns the series for which the Grafana condition is firing.
+func (t *queryTranslator) condition(refID string) (string, error) {
+	q, ok := t.queries[refID]
+	if !ok {
+		return "", fmt.Errorf("condition %s does not refer to any query or expression", refID)
+	}
+	if q.RefID != t.dataRefID {
+		// A single threshold can be written as a filter, which keeps the value of the series in the alert.
+		model, err := t.expressionModel(q)
+		if err != nil {
+			return "", err
+		}
+		if model.Type == expr.QueryTypeThreshold {
+			return t.threshold(refID, model, true)
+		}
+	}
+	value, err := t.value(refID)
+	if err != nil {
+		return "", err
+	}
+	// Grafana fires for every value that is not zero.
+	return paren(value) + " != 0", nil
+}
+
+type expressionModel struct {
+	Type       expr.QueryType                `json:"type"`
+	Expression string                        `json:"expression"`
+	Reducer    mathexp.ReducerID             `json:"reducer"`
+	Settings   *expr.ReduceSettings          `json:"settings"`
+	Conditions []expr.ThresholdConditionJSON `json:"conditions"`
+}
+
+func (t *queryTranslator) expressionModel(q models.AlertQuery) (expressionModel, error) {
+	var model expressionModel
+	if err := json.Unmarshal(q.Model, &model); err != nil {
+		return model, fmt.Errorf("failed to parse expression %s: %w", q.RefID, err)
+	}
+	return model, nil
+}
+
+// value returns an expression that returns the same values as the query or expression with the given refID.
+// Comparisons return 1 if they are true and 0 otherwise, like in Grafana.
+func (t *queryTranslator) value(refID string) (string, error) {
+	if refID == t.dataRefID {
+		if !t.instant {
+			return "", fmt.Errorf("range query %s must be reduced before it can be used", refID)
+		}
+		return t.expr, nil
+	}
+	q, ok := t.queries[refID]
+	if !ok {
+		return "", fmt.Errorf("expression refers to %s, which does not exist", refID)
+	}
+	model, err := t.expressionModel(q)
+	if err != nil {
+		return "", err
+	}
+	switch model.Type {
+	case expr.QueryTypeReduce:
+		return t.reduce(refID, model)
+	case expr.QueryTypeMath:
+		return t.math(refID, model)
+	case expr.QueryTypeThreshold:
+		return t.threshold(refID, model, false)
+	default:
+		return "", fmt.Errorf("expression %s of type %s cannot be expressed in Prometheus", refID, model.Type)
+	}
+}
+
+// rangeReducers are the reducers that have an equivalent *_over_time function.
+var rangeReducers = map[mathexp.ReducerID]string{
+	mathexp.ReducerLast:   "last_over_time(%s)",
+	mathexp.ReducerMean:   "avg_over_time(%s)",
+	mathexp.ReducerMin:    "min_over_time(%s)",
+	mathexp.ReducerMax:    "max_over_time(%s)",
+	mathexp.ReducerSum:    "sum_over_time(%s)",
+	mathexp.ReducerCount:  "count_over_time(%s)",
+	mathexp.ReducerMedian: "quantile_over_time(0.5, %s)",
+	mathexp.ReducerStdDev: "stddev_over_time(%s)",
+	mathexp.ReducerP90:    "quantile_over_time(0.9, %s)",
+	mathexp.ReducerP95:    "quantile_over_time(0.95, %s)",
+	mathexp.ReducerP99:    "quantile_over_time(0.99, %s)",
+}
+
+// instantReducers are the reducers that return the value of a series that has a single point.
+var instantReducers = map[mathexp.ReducerID]struct{}{
+	mathexp.ReducerLast:   {},
+	mathexp.ReducerFirst:  {},
+	mathexp.ReducerMean:   {},
+	mathexp.ReducerMin:    {},
+	mathexp.ReducerMax:    {},
+	mathexp.ReducerSum:    {},
+	mathexp.ReducerMedian: {},
+	mathexp.ReducerP90:    {},
+	mathexp.ReducerP95:    {},
+	mathexp.ReducerP99:    {},
+}
+
+func (t *queryTranslator) reduce(refID string, model expressionModel) (string, error) {
+	if model.Settings != nil && model.Settings.Mode != expr.ReduceModeStrict {
+		return "", fmt.Errorf("reduce expression %s uses mode %s, which cannot be expressed in Prometheus", refID, model.Settings.Mode)
+	}
+	input := strings.TrimPrefix(model.Expression, "$")
+	if input != t.dataRefID || t.instant {
+		// Instant queries and expressions return a single value per series.
+		if _, ok := instantReducers[model.Reducer]; !ok {
+			return "", fmt.Errorf("reduce expression %s uses reducer %s, which cannot be expressed in Prometheus for a single value", refID, model.Reducer)
+		}
+		return t.value(input)
+	}
+	if t.datasourceType != datasources.DS_PROMETHEUS {
+		return "", fmt.Errorf("reduce expression %s reduces a range query, which is only supported for Prometheus", refID)
+	}
+	format, ok := rangeReducers[model.Reducer]
+	if !ok {
+		return "", fmt.Errorf("reduce expression %s uses reducer %s, which has no equivalent in Prometheus", refID, model.Reducer)
+	}
+	subquery := fmt.Sprintf("%s[%s:]", paren(t.expr), prommodel.Duration(t.window))
+	return fmt.Sprintf(format, subquery), nil
+}
+
+// comparisons maps the threshold types to the comparison operators and the boolean operator used to combine them.
+var comparisons = map[expr.ThresholdType]struct {
+	operators []string
+	and       bool
+}{
+	expr.ThresholdIsAbove:                {operators: []string{">"}},
+	expr.ThresholdIsBelow:                {operators: []string{"<"}},
+	expr.ThresholdIsEqual:                {operators: []string{"=="}},
+	expr.ThresholdIsNotEqual:             {operators: []string{"!="}},
+	expr.ThresholdIsGreaterThanEqual:     {operators: []string{">="}},
+	expr.ThresholdIsLessThanEqual:        {operators: []string{"<="}},
+	expr.ThresholdIsWithinRange:          {operators: []string{">", "<"}, and: true},
+	expr.ThresholdIsWithinRangeIncluded:  {operators: []string{">=", "<="}, and: true},
+	expr.ThresholdIsOutsideRange:         {operators: []string{"<", ">"}},
+	expr.ThresholdIsOutsideRangeIncluded: {operators: []string{"<=", ">="}},
+}
+
+// threshold returns the threshold expression either as a filter that keeps the series for which the threshold
+// is true, or as a value that is 1 if the threshold is true and 0 otherwise.
+func (t *queryTranslator) threshold(refID string, model expressionModel, filter bool) (string, error) {
+	if len(model.Conditions) != 1 {
+		return "", fmt.Errorf("threshold expression %s must have exactly one condition", refID)
+	}
+	if model.Conditions[0].UnloadEvaluator != nil {
+		return "", fmt.Errorf("threshold expression %s uses a recovery threshold, which cannot be expressed in Prometheus", refID)
+	}
+	evaluator := model.Conditions[0].Evaluator
+	c, ok := comparisons[evaluator.Type]
+	if !ok {
+		return "", fmt.Errorf("threshold expression %s uses unknown type %s", refID, evaluator.Type)
+	}
+	if len(evaluator.Params) < len(c.operators) {
+		return "", fmt.Errorf("threshold expression %s of type %s needs %d parameters", refID, evaluator.Type, len(c.operators))
+	}
+	input, err := t.value(strings.TrimPrefix(model.Expression, "$"))
+	if err != nil {
+		return "", err
+	}
+	input = paren(input)
+
+	parts := make([]string, 0, len(c.operators))
+	for i, op := range c.operators {
+		if !filter {
+			op += " bool"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", input, op, formatFloat(evaluator.Params[i])))
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	switch {
+	case filter && c.and:
+		// Comparison operators filter the series, so they can be chained.
+		return fmt.Sprintf("%s %s %s", parts[0], c.operators[1], formatFloat(evaluator.Params[1])), nil
+	case filter:
+		return fmt.Sprintf("%s or %s", parts[0], parts[1]), nil
+	case c.and:
+		return fmt.Sprintf("(%s) * (%s)", parts[0], parts[1]), nil
+	default:
+		// At most one side of a range can be true.
+		return fmt.Sprintf("(%s) + (%s)", parts[0], parts[1]), nil
+	}
+}
+
+// mathFunctions maps the math functions to the Prometheus functions that compute the same.
+var mathFunctions = map[string]string{
+	"abs":   "abs",
+	"ceil":  "ceil",
+	"floor": "floor",
+	"log":   "ln",
+}
+
+// mathOperators maps the operators of math expressions to the Prometheus operators that compute the same.
+var mathOperators = map[string]string{
+	"+":  "+",
+	"-":  "-",
+	"*":  "*",
+	"/":  "/",
+	"%":  "%",
+	"**": "^",
+	">":  "> bool",
+	"<":  "< bool",
+	">=": ">= bool",
+	"<=": "<= bool",
+	"==": "== bool",
+	"!=": "!= bool",
+}
+
+func (t *queryTranslator) math(refID string, model expressionModel) (string, error) {
+	e, err := mathexp.New(model.Expression)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse math expression %s: %w", refID, err)
+	}
+	return t.mathNode(refID, e.Tree.Root)
+}
+
+func (t *queryTranslator) mathNode(refID string, node mathparse.Node) (string, error) {
+	switch n := node.(type) {
+	case *mathparse.ScalarNode:
+		return n.Text, nil
+	case *mathparse.VarNode:
+		v, err := t.value(n.Name)
+		if err != nil {
+			return "", err
+		}
+		return paren(v), nil
+	case *mathparse.UnaryNode:
+		if n.OpStr != "-" {
+			return "", fmt.Errorf("math expression %s uses operator %s, which cannot be expressed in Prometheus", refID, n.OpStr)
+		}
+		arg, err := t.mathNode(refID, n.Arg)
+		if err != nil {
+			return "", err
+		}
+		return "-" + paren(arg), nil
+	case *mathparse.BinaryNode:
+		op, ok := mathOperators[n.OpStr]
+		if !ok {
+			return "", fmt.Errorf("math expression %s uses operator %s, which cannot be expressed in Prometheus", refID, n.OpStr)
+		}
+		left, err := t.mathNode(refID, n.Args[0])
+		if err != nil {
+			return "", err
+		}
+		right, err := t.mathNode(refID, n.Args[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", paren(left), op, paren(right)), nil
+	case *mathparse.FuncNode:
+		name, ok := mathFunctions[n.Name]
+		if !ok || len(n.Args) != 1 {
+			return "", fmt.Errorf("math expression %s uses function %s, which cannot be expressed in Prometheus", refID, n.Name)
+		}
+		arg, err := t.mathNode(refID, n.Args[0])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", name, arg), nil
+	default:
+		return "", fmt.Errorf("math expression %s uses %s, which cannot be expressed in Prometheus", refID, node.String())
+	}
+}
+
+// paren wraps an expression in parentheses unless it is a number or already wrapped.
+func paren(s string) string {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	if strings.HasPrefix(s, "(") && closingParen(s) == len(s)-1 {
+		return s
+	}
+	return "(" + s + ")"
+}
+
+// closingParen returns the index of the parenthesis that closes the first one, ignoring parentheses in strings.
+func closingParen(s string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func formatFloat(f float64) string {
+	return prommodel.SampleValue(f).String()
+}
@@ -0,0 +1,366 @@
+package prom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestGrafanaRulesToPrometheus(t *testing.T) {
+	promQuery := func(refID, query string, instant bool) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: "prometheus-uid",
+			RelativeTimeRange: models.RelativeTimeRange{
+				From: models.Duration(10 * time.Minute),
+			},
+			Model: json.RawMessage(fmt.Sprintf(`{"datasource": {"type": "prometheus", "uid": "prometheus-uid"}, "expr": %q, "instant": %t, "range": %t}`, query, instant, !instant)),
+		}
+	}
+	expression := func(refID, model string) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(model),
+		}
+	}
+	threshold := func(refID, input, evaluator string) models.AlertQuery {
+		return expression(refID, fmt.Sprintf(`{"type": "threshold", "expression": %q, "conditions": [{"evaluator": %s}]}`, input, evaluator))
+	}
+	rule := func(title, condition string, data ...models.AlertQuery) models.AlertRule {
+		return models.AlertRule{
+			UID:          util.GenerateShortUID(),
+			Title:        title,
+			Condition:    condition,
+			Data:         data,
+			NoDataState:  models.NoData,
+			ExecErrState: models.ErrorErrState,
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		rule         models.AlertRule
+		expectedExpr string
+		expectedErr  string
+	}{
+		{
+			name: "instant query with threshold",
+			rule: rule("threshold", "B",
+				promQuery("A", "avg by (instance) (cpu_usage)", true),
+				threshold("B", "A", `{"type": "gt", "params": [80]}`),
+			),
+			expectedExpr: "(avg by (instance) (cpu_usage)) > 80",
+		},
+		{
+			name: "range query reduced by the last value",
+			rule: rule("reduce", "C",
+				promQuery("A", "rate(http_requests_total[5m])", false),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "last"}`),
+				threshold("C", "B", `{"type": "within_range", "params": [1, 5]}`),
+			),
+			expectedExpr: "(last_over_time((rate(http_requests_total[5m]))[10m:])) > 1 < 5",
+		},
+		{
+			name: "instant query with reduce, math and threshold",
+			rule: rule("math", "D",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "mean"}`),
+				expression("C", `{"type": "math", "expression": "abs($B - 1) * 100"}`),
+				threshold("D", "C", `{"type": "outside_range_included", "params": [10, 20]}`),
+			),
+			expectedExpr: "((abs((up) - 1)) * 100) <= 10 or ((abs((up) - 1)) * 100) >= 20",
+		},
+		{
+			name: "math condition with comparison",
+			rule: rule("comparison", "B",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "math", "expression": "$A ** 2 > 0.5"}`),
+			),
+			expectedExpr: "(((up) ^ 2) > bool 0.5) != 0",
+		},
+		{
+			name: "threshold inside of math",
+			rule: rule("comparison", "C",
+				promQuery("A", "up", true),
+				threshold("B", "A", `{"type": "within_range", "params": [1, 5]}`),
+				expression("C", `{"type": "math", "expression": "$B * 2"}`),
+			),
+			expectedExpr: "((((up) > bool 1) * ((up) < bool 5)) * 2) != 0",
+		},
+		{
+			name: "query as condition",
+			rule: rule("query", "A",
+				promQuery("A", "up == 0", true),
+			),
+			expectedExpr: "(up == 0) != 0",
+		},
+		{
+			name: "loki query",
+			rule: rule("loki", "B",
+				models.AlertQuery{
+					RefID:         "A",
+					DatasourceUID: "loki-uid",
+					QueryType:     datasources.DS_LOKI,
+					Model:         json.RawMessage(`{"expr": "count_over_time({app=\"a\"}[5m])", "queryType": "instant"}`),
+				},
+				threshold("B", "A", `{"type": "lt", "params": [1]}`),
+			),
+			expectedExpr: `(count_over_time({app="a"}[5m])) < 1`,
+		},
+		{
+			name: "more than one data query",
+			rule: rule("multiple", "C",
+				promQuery("A", "up", true),
+				promQuery("B", "up", true),
+				expression("C", `{"type": "math", "expression": "$A + $B"}`),
+			),
+			expectedErr: "more than one data source query",
+		},
+		{
+			name: "data query of another data source",
+			rule: rule("other", "A",
+				models.AlertQuery{RefID: "A", DatasourceUID: "graphite-uid", QueryType: "graphite", Model: json.RawMessage(`{"target": "a.b.c"}`)},
+			),
+			expectedErr: "does not query a Prometheus or Loki data source",
+		},
+		{
+			name: "range query without reduce",
+			rule: rule("range", "B",
+				promQuery("A", "up", false),
+				threshold("B", "A", `{"type": "gt", "params": [0]}`),
+			),
+			expectedErr: "range query A must be reduced",
+		},
+		{
+			name: "classic condition",
+			rule: rule("classic", "B",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "classic_conditions", "conditions": []}`),
+			),
+			expectedErr: "expression B of type classic_conditions cannot be expressed",
+		},
+		{
+			name: "reducer without equivalent",
+			rule: rule("diff", "B",
+				promQuery("A", "up", false),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "diff"}`),
+			),
+			expectedErr: "reducer diff, which has no equivalent",
+		},
+		{
+			name: "reduce mode",
+			rule: rule("mode", "B",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "reduce", "expression": "A", "reducer": "last", "settings": {"mode": "dropNN"}}`),
+			),
+			expectedErr: "mode dropNN",
+		},
+		{
+			name: "recovery threshold",
+			rule: rule("hysteresis", "B",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [5]}, "unloadEvaluator": {"type": "lt", "params": [2]}}]}`),
+			),
+			expectedErr: "recovery threshold",
+		},
+		{
+			name: "math function without equivalent",
+			rule: rule("function", "B",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "math", "expression": "is_nan($A)"}`),
+			),
+			expectedErr: "function is_nan",
+		},
+		{
+			name: "logical operator",
+			rule: rule("logical", "B",
+				promQuery("A", "up", true),
+				expression("B", `{"type": "math", "expression": "$A > 1 && $A < 5"}`),
+			),
+			expectedErr: "operator &&",
+		},
+		{
+			name: "no data state alerting",
+			rule: func() models.AlertRule {
+				r := rule("nodata", "A", promQuery("A", "up", true))
+				r.NoDataState = models.Alerting
+				return r
+			}(),
+			expectedErr: "no data state Alerting",
+		},
+		{
+			name: "paused rule",
+			rule: func() models.AlertRule {
+				r := rule("paused", "A", promQuery("A", "up", true))
+				r.IsPaused = true
+				return r
+			}(),
+			expectedErr: "paused rules",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := GrafanaRulesToPrometheus(models.AlertRuleGroup{
+				Title:    "group",
+				Interval: 60,
+				Rules:    []models.AlertRule{tc.rule},
+			})
+			require.Equal(t, "group", result.Group.Name)
+			require.Equal(t, prommodel.Duration(time.Minute), result.Group.Interval)
+			if tc.expectedErr != "" {
+				require.Empty(t, result.Group.Rules)
+				require.Len(t, result.Unsupported, 1)
+				require.Equal(t, tc.rule.UID, result.Unsupported[0].UID)
+				require.Equal(t, tc.rule.Title, result.Unsupported[0].Title)
+				require.Contains(t, result.Unsupported[0].Reason, tc.expectedErr)
+				return
+			}
+			require.Empty(t, result.Unsupported)
+			require.Len(t, result.Group.Rules, 1)
+			require.Equal(t, tc.rule.Title, result.Group.Rules[0].Alert)
+			require.Equal(t, tc.expectedExpr, result.Group.Rules[0].Expr)
+		})
+	}
+
+	t.Run("exports the properties of the rules", func(t *testing.T) {
+		alertRule := rule("alert", "B",
+			promQuery("A", "up", true),
+			threshold("B", "A", `{"type": "lt", "params": [1]}`),
+		)
+		alertRule.For = 5 * time.Minute
+		alertRule.KeepFiringFor = time.Minute
+		alertRule.Labels = map[string]string{"severity": "critical", models.ConvertedPrometheusRuleLabel: "true"}
+		alertRule.Annotations = map[string]string{"summary": "{{ $labels.instance }} is down"}
+
+		recordingRule := rule("recording", "",
+			promQuery("A", "sum(up)", true),
+		)
+		recordingRule.Record = &models.Record{Metric: "up:sum", From: "A"}
+		recordingRule.Labels = map[string]string{"team": "a"}
+
+		result := GrafanaRulesToPrometheus(models.AlertRuleGroup{
+			Title:    "group",
+			Interval: 60,
+			Rules:    []models.AlertRule{alertRule, recordingRule},
+		})
+		require.Empty(t, result.Unsupported)
+		require.Equal(t, []PrometheusRule{
+			{
+				Alert:         "alert",
+				Expr:          "(up) < 1",
+				For:           util.Pointer(prommodel.Duration(5 * time.Minute)),
+				KeepFiringFor: util.Pointer(prommodel.Duration(time.Minute)),
+				Labels:        map[string]string{"severity": "critical"},
+				Annotations:   map[string]string{"summary": "{{ $labels.instance }} is down"},
+			},
+			{
+				Record: "up:sum",
+				Expr:   "sum(up)",
+				Labels: map[string]string{"team": "a"},
+			},
+		}, result.Group.Rules)
+	})
+
+	t.Run("sets the query offset of the group", func(t *testing.T) {
+		withOffset := func(title string, offset time.Duration) models.AlertRule {
+			q := promQuery("A", "up", true)
+			q.RelativeTimeRange = models.RelativeTimeRange{From: models.Duration(offset + time.Minute), To: models.Duration(offset)}
+			return rule(title, "A", q)
+		}
+		result := GrafanaRulesToPrometheus(models.AlertRuleGroup{
+			Title:    "group",
+			Interval: 60,
+			Rules:    []models.AlertRule{withOffset("first", time.Minute), withOffset("second", time.Minute), withOffset("third", 2*time.Minute)},
+		})
+		require.Equal(t, util.Pointer(prommodel.Duration(time.Minute)), result.Group.QueryOffset)
+		require.Len(t, result.Group.Rules, 2)
+		require.Len(t, result.Unsupported, 1)
+		require.Equal(t, "third", result.Unsupported[0].Title)
+		require.Contains(t, result.Unsupported[0].Reason, "query offset 2m is different from the query offset 1m of the group")
+	})
+
+	t.Run("exports converted rules with their original definition", func(t *testing.T) {
+		promGroup := PrometheusRuleGroup{
+			Name:     "group",
+			Interval: prommodel.Duration(time.Minute),
+			Rules: []PrometheusRule{
+				{
+					Alert:  "alert",
+					Expr:   "cpu_usage > 80",
+					For:    util.Pointer(prommodel.Duration(5 * time.Minute)),
+					Labels: map[string]string{"severity": "critical"},
+				},
+				{
+					Record: "cpu_usage:avg",
+					Expr:   "avg(cpu_usage)",
+					Labels: map[string]string{"team": "a"},
+				},
+			},
+		}
+		converter, err := NewConverter(Config{
+			DatasourceUID:   "prometheus-uid",
+			DatasourceType:  datasources.DS_PROMETHEUS,
+			DefaultInterval: time.Minute,
+		})
+		require.NoError(t, err)
+		grafanaGroup, err := converter.PrometheusRulesToGrafana(1, "namespace", promGroup)
+		require.NoError(t, err)
+
+		result := GrafanaRulesToPrometheus(*grafanaGroup)
+		require.Empty(t, result.Unsupported)
+		require.Equal(t, promGroup, result.Group)
+	})
+
+	t.Run("exports rule groups as YAML by folder", func(t *testing.T) {
+		supported := rule("supported", "B",
+			promQuery("A", "up", true),
+			threshold("B", "A", `{"type": "lt", "params": [1]}`),
+		)
+		unsupported := rule("unsupported", "A", models.AlertQuery{
+			RefID:         "A",
+			DatasourceUID: "other-uid",
+			Model:         json.RawMessage(`{"datasource": {"type": "other", "uid": "other-uid"}}`),
+		})
+		body, err := GrafanaRuleGroupsToPrometheusYAML([]models.AlertRuleGroupWithFolderFullpath{
+			{
+				AlertRuleGroup: &models.AlertRuleGroup{Title: "group", Interval: 60, Rules: []models.AlertRule{supported, unsupported}},
+				FolderFullpath: "parent/folder",
+			},
+			{
+				AlertRuleGroup: &models.AlertRuleGroup{Title: "empty", Interval: 60, Rules: []models.AlertRule{unsupported}},
+				FolderFullpath: "other",
+			},
+		})
+		require.NoError(t, err)
+
+		lines := strings.SplitN(string(body), "\n", 4)
+		require.Equal(t, "# The following rules cannot be exported in Prometheus format:", lines[0])
+		require.True(t, strings.HasPrefix(lines[1], fmt.Sprintf(`# - parent/folder/group: "unsupported" (%s): `, unsupported.UID)), lines[1])
+		require.True(t, strings.HasPrefix(lines[2], fmt.Sprintf(`# - other/empty: "unsupported" (%s): `, unsupported.UID)), lines[2])
+
+		var namespaces map[string][]PrometheusRuleGroup
+		require.NoError(t, yaml.Unmarshal(body, &namespaces))
+		require.Equal(t, map[string][]PrometheusRuleGroup{
+			"parent/folder": {
+				{
+					Name:     "group",
+					Interval: prommodel.Duration(time.Minute),
+					Rules:    []PrometheusRule{{Alert: "supported", Expr: "(up) < 1"}},
+				},
+			},
+		}, namespaces)
+	})
+}
@@ -88,6 +88,7 @@ func TestAnnotationHistorian(t *testing.T) {
 			OrgID:   1,
 			From:    now.Add(-10 * time.Second),
 			To:      now,
+			Limit:   20,
 		}
 		_, err := anns.Query(context.Background(), q)
 
@@ -95,6 +96,22 @@ func TestAnnotationHistorian(t *testing.T) {
 		query := store.lastQuery
 		require.Equal(t, now.UnixMilli(), query.To)
 		require.Equal(t, now.Add(-10*time.Second).UnixMilli(), query.From)
+		require.Equal(t, int64(20), query.Limit)
+	})
+
+	t.Run("annotation times are returned from their epoch in milliseconds", func(t *testing.T) {
+		epoch := time.UnixMilli(1700000000123)
+		store := &interceptingAnnotationStore{
+			items: []*annotations.ItemDTO{{ID: 1, Time: epoch.UnixMilli(), Text: "MyAlert {a=b} - No data"}},
+		}
+		anns := createTestAnnotationSutWithStore(t, store)
+
+		frame, err := anns.Query(context.Background(), models.HistoryQuery{RuleUID: "my-rule", OrgID: 1})
+
+		require.NoError(t, err)
+		timeField, _ := frame.FieldByName("time")
+		require.NotNil(t, timeField)
+		require.True(t, epoch.Equal(timeField.At(0).(time.Time)))
 	})
 
 	t.Run("writing state transitions as annotations succeeds", func(t *testing.T) {
@@ -276,11 +293,12 @@ func assertValidJSON(t *testing.T, j *simplejson.Json) string {
 
 type interceptingAnnotationStore struct {
 	lastQuery *annotations.ItemQuery
+	items     []*annotations.ItemDTO
 }
 
 func (i *interceptingAnnotationStore) Find(ctx context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
 	i.lastQuery = query
-	return []*annotations.ItemDTO{}, nil
+	return append([]*annotations.ItemDTO{}, i.items...), nil
 }
 
 func (i *interceptingAnnotationStore) Save(ctx context.Context, panel *PanelKey, annotations []annotations.Item, orgID int64, logger log.Logger) error {
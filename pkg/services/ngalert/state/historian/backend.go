@@ -19,6 +19,7 @@ const (
 	BackendTypeMultiple    BackendType = "multiple"
 	BackendTypePrometheus  BackendType = "prometheus"
 	BackendTypeNoop        BackendType = "noop"
+	BackendTypeSQL         BackendType = "sql"
 )
 
 func ParseBackendType(s string) (BackendType, error) {
@@ -30,6 +31,7 @@ func ParseBackendType(s string) (BackendType, error) {
 		BackendTypeMultiple:    {},
 		BackendTypePrometheus:  {},
 		BackendTypeNoop:        {},
+		BackendTypeSQL:         {},
 	}
 	p := BackendType(norm)
 	if _, ok := types[p]; !ok {
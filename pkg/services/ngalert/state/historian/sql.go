@@ -0,0 +1,223 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+// defaultSQLQueryLimit is the maximum number of entries returned by a query that does not define a limit.
+const defaultSQLQueryLimit = 1000
+
+type StateHistoryStore interface {
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	GetStateHistory(ctx context.Context, query models.StateHistoryEntryQuery) ([]models.StateHistoryEntry, error)
+}
+
+// SQLBackend is a state.Historian that records state history to a table in the Grafana database.
+type SQLBackend struct {
+	store     StateHistoryStore
+	ruleStore RuleStore
+	ac        AccessControl
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+}
+
+func NewSQLBackend(logger log.Logger, store StateHistoryStore, ruleStore RuleStore, metrics *metrics.Historian, ac AccessControl) *SQLBackend {
+	return &SQLBackend{
+		store:     store,
+		ruleStore: ruleStore,
+		ac:        ac,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries := statesToHistoryEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+		logger.Debug("Saving state history batch", "samples", len(entries))
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.store.SaveStateHistory(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch", "samples", len(entries))
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe.
+// The dataframe has the same format as the one produced by the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := getFolderUIDsForFilter(ctx, query, h.ac, h.ruleStore)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultSQLQueryLimit
+	}
+
+	entries, err := h.store.GetStateHistory(ctx, models.StateHistoryEntryQuery{
+		OrgID:         query.OrgID,
+		RuleUID:       query.RuleUID,
+		DashboardUID:  query.DashboardUID,
+		PanelID:       query.PanelID,
+		NamespaceUIDs: uids,
+		Labels:        query.Labels,
+		From:          query.From,
+		To:            query.To,
+		Limit:         query.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return historyEntriesToFrame(entries)
+}
+
+func statesToHistoryEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		var values json.RawMessage
+		if blob := valuesAsDataBlob(state.State); blob != nil {
+			v, err := blob.MarshalJSON()
+			if err != nil {
+				logger.Error("Failed to serialize values of state, skipping", "error", err)
+				continue
+			}
+			values = v
+		}
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		entry := models.StateHistoryEntry{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			RuleID:       rule.ID,
+			RuleTitle:    rule.Title,
+			RuleGroup:    rule.Group,
+			NamespaceUID: rule.NamespaceUID,
+			DashboardUID: rule.DashboardUID,
+			PanelID:      rule.PanelID,
+			Condition:    rule.Condition,
+			Fingerprint:  labelFingerprint(sanitizedLabels),
+			Labels:       sanitizedLabels,
+			Previous:     state.PreviousFormatted(),
+			Current:      state.Formatted(),
+			Values:       values,
+			At:           state.LastEvaluationTime,
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.Error = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func historyEntriesToFrame(entries []models.StateHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		values := simplejson.New()
+		if len(e.Values) > 0 {
+			v, err := simplejson.NewJson(e.Values)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse values of state history entry %d: %w", e.ID, err)
+			}
+			values = v
+		}
+		line, err := json.Marshal(LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.Previous,
+			Current:        e.Current,
+			Error:          e.Error,
+			Values:         values,
+			Condition:      e.Condition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: e.Labels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry: %w", err)
+		}
+		// Use the same labels as the streams of the Loki backend, so that clients can handle both formats.
+		lblsJson, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history labels: %w", err)
+		}
+
+		times = append(times, e.At)
+		lines = append(lines, line)
+		labels = append(labels, lblsJson)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
@@ -0,0 +1,197 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type fakeStateHistoryStore struct {
+	mtx     sync.Mutex
+	entries []models.StateHistoryEntry
+	queries []models.StateHistoryEntryQuery
+	err     error
+}
+
+func (f *fakeStateHistoryStore) SaveStateHistory(_ context.Context, entries []models.StateHistoryEntry) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetStateHistory(_ context.Context, query models.StateHistoryEntryQuery) ([]models.StateHistoryEntry, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.queries = append(f.queries, query)
+	return f.entries, f.err
+}
+
+func TestSQLBackend(t *testing.T) {
+	t.Run("Record", func(t *testing.T) {
+		t.Run("saves state transitions", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			sql := createTestSQLBackend(t, store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+			rule := createTestRule()
+			now := time.Now()
+			states := []state.StateTransition{
+				{
+					PreviousState: eval.Normal,
+					State: &state.State{
+						State:              eval.Alerting,
+						Labels:             data.Labels{"a": "b", "__private__": "c"},
+						Values:             map[string]float64{"A": 1},
+						LastEvaluationTime: now,
+					},
+				},
+				{
+					PreviousState: eval.Alerting,
+					State: &state.State{
+						State:              eval.Error,
+						StateReason:        "error",
+						Error:              errors.New("boom"),
+						Labels:             data.Labels{"a": "b"},
+						LastEvaluationTime: now,
+					},
+				},
+				{
+					PreviousState: eval.Normal,
+					State:         &state.State{State: eval.Normal},
+				},
+			}
+
+			err := <-sql.Record(context.Background(), rule, states)
+
+			require.NoError(t, err)
+			require.Len(t, store.entries, 2)
+			e := store.entries[0]
+			require.Equal(t, rule.UID, e.RuleUID)
+			require.Equal(t, rule.Group, e.RuleGroup)
+			require.Equal(t, rule.NamespaceUID, e.NamespaceUID)
+			require.Equal(t, map[string]string{"a": "b"}, e.Labels)
+			require.Equal(t, "Normal", e.Previous)
+			require.Equal(t, "Alerting", e.Current)
+			require.JSONEq(t, `{"A":1}`, string(e.Values))
+			require.Equal(t, now, e.At)
+			require.Equal(t, "Error (error)", store.entries[1].Current)
+			require.Equal(t, "boom", store.entries[1].Error)
+		})
+
+		t.Run("elides write if nothing to save", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			sql := createTestSQLBackend(t, store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+			err := <-sql.Record(context.Background(), createTestRule(), []state.StateTransition{})
+
+			require.NoError(t, err)
+			require.Empty(t, store.entries)
+		})
+
+		t.Run("emits expected write metrics", func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
+			sql := createTestSQLBackend(t, &fakeStateHistoryStore{}, met)
+			errSQL := createTestSQLBackend(t, &fakeStateHistoryStore{err: errors.New("failed")}, met)
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{
+				State:  eval.Alerting,
+				Labels: data.Labels{"a": "b"},
+			})
+
+			<-sql.Record(context.Background(), rule, states)
+			require.Error(t, <-errSQL.Record(context.Background(), rule, states))
+
+			exp := bytes.NewBufferString(`
+# HELP grafana_alerting_state_history_writes_failed_total The total number of failed writes of state history batches.
+# TYPE grafana_alerting_state_history_writes_failed_total counter
+grafana_alerting_state_history_writes_failed_total{backend="sql",org="1"} 1
+# HELP grafana_alerting_state_history_writes_total The total number of state history batches that were attempted to be written.
+# TYPE grafana_alerting_state_history_writes_total counter
+grafana_alerting_state_history_writes_total{backend="sql",org="1"} 2
+`)
+			err := testutil.GatherAndCompare(reg, exp,
+				"grafana_alerting_state_history_writes_total",
+				"grafana_alerting_state_history_writes_failed_total",
+			)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		now := time.Now().Truncate(time.Millisecond)
+		store := &fakeStateHistoryStore{
+			entries: []models.StateHistoryEntry{
+				{
+					ID:           1,
+					OrgID:        1,
+					RuleUID:      "rule-uid",
+					RuleTitle:    "my-title",
+					RuleGroup:    "my-group",
+					NamespaceUID: "my-folder",
+					Labels:       map[string]string{"a": "b"},
+					Previous:     "Normal",
+					Current:      "Alerting",
+					Values:       json.RawMessage(`{"A":1}`),
+					At:           now,
+				},
+			},
+		}
+		sql := createTestSQLBackend(t, store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+		sql.ac = &acfakes.FakeRuleService{
+			CanReadAllRulesFunc: func(ctx context.Context, user identity.Requester) (bool, error) {
+				return true, nil
+			},
+		}
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:   1,
+			RuleUID: "rule-uid",
+			Labels:  map[string]string{"a": "b"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, store.queries, 1)
+		q := store.queries[0]
+		require.Equal(t, "rule-uid", q.RuleUID)
+		require.Equal(t, map[string]string{"a": "b"}, q.Labels)
+		require.Equal(t, defaultSQLQueryLimit, q.Limit)
+		require.Equal(t, defaultQueryRange, q.To.Sub(q.From))
+		require.Empty(t, q.NamespaceUIDs)
+
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, now, frame.Fields[0].At(0))
+		var entry LokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, "Alerting", entry.Current)
+		require.Equal(t, "rule-uid", entry.RuleUID)
+		require.Equal(t, map[string]string{"a": "b"}, entry.InstanceLabels)
+		require.Equal(t, float64(1), entry.Values.Get("A").MustFloat64())
+		require.JSONEq(t, `{"folderUID":"my-folder","from":"state-history","group":"my-group","orgID":"1"}`, string(frame.Fields[2].At(0).(json.RawMessage)))
+	})
+}
+
+func createTestSQLBackend(t *testing.T, store StateHistoryStore, met *metrics.Historian) *SQLBackend {
+	logger := log.New("ngalert.state.historian", "backend", "sql")
+	return NewSQLBackend(logger, store, fakes.NewRuleStore(t), met, &acfakes.FakeRuleService{})
+}
@@ -0,0 +1,330 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// stateHistoryDeleteBatchSize is the maximum number of organizations whose state history is cleaned up in one transaction.
+const stateHistoryDeleteBatchSize = 100
+
+// stateHistoryInsertBatchSize is the maximum number of state history entries, or of their labels, inserted in one statement.
+const stateHistoryInsertBatchSize = 100
+
+type alertStateHistory struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	UID            string `xorm:"uid"`
+	OrgID          int64  `xorm:"org_id"`
+	RuleUID        string `xorm:"rule_uid"`
+	RuleID         int64  `xorm:"rule_id"`
+	RuleTitle      string `xorm:"rule_title"`
+	RuleGroup      string `xorm:"rule_group"`
+	NamespaceUID   string `xorm:"namespace_uid"`
+	DashboardUID   string `xorm:"dashboard_uid"`
+	PanelID        int64  `xorm:"panel_id"`
+	RuleCondition  string `xorm:"rule_condition"`
+	Fingerprint    string `xorm:"fingerprint"`
+	Labels         string `xorm:"labels"`
+	PreviousState  string `xorm:"previous_state"`
+	CurrentState   string `xorm:"current_state"`
+	ErrorMessage   string `xorm:"error_message"`
+	StateValues    string `xorm:"state_values"`
+	TransitionedAt int64  `xorm:"transitioned_at"`
+}
+
+func (alertStateHistory) TableName() string {
+	return "alert_state_history"
+}
+
+type alertStateHistoryLabel struct {
+	ID        int64 `xorm:"pk autoincr 'id'"`
+	HistoryID int64 `xorm:"history_id"`
+	LabelHash int64 `xorm:"label_hash"`
+}
+
+func (alertStateHistoryLabel) TableName() string {
+	return "alert_state_history_label"
+}
+
+// stateHistoryLabelHash returns the hash of a label that is stored to match the labels of state history entries.
+func stateHistoryLabelHash(name, value string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0xff})
+	_, _ = h.Write([]byte(value))
+	return int64(h.Sum64())
+}
+
+// SaveStateHistory stores state history entries together with the hashes of their labels.
+// The entries and their labels are inserted in batches, in a single transaction.
+func (st DBstore) SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for batch := range slices.Chunk(entries, stateHistoryInsertBatchSize) {
+			if err := insertStateHistoryBatch(sess, batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func insertStateHistoryBatch(sess *db.Session, entries []models.StateHistoryEntry) error {
+	rows := make([]alertStateHistory, 0, len(entries))
+	uids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lbls, err := json.Marshal(e.Labels)
+		if err != nil {
+			return fmt.Errorf("failed to serialize labels of state history entry: %w", err)
+		}
+		row := alertStateHistory{
+			UID:            util.GenerateShortUID(),
+			OrgID:          e.OrgID,
+			RuleUID:        e.RuleUID,
+			RuleID:         e.RuleID,
+			RuleTitle:      e.RuleTitle,
+			RuleGroup:      e.RuleGroup,
+			NamespaceUID:   e.NamespaceUID,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			RuleCondition:  e.Condition,
+			Fingerprint:    e.Fingerprint,
+			Labels:         string(lbls),
+			PreviousState:  e.Previous,
+			CurrentState:   e.Current,
+			ErrorMessage:   e.Error,
+			StateValues:    string(e.Values),
+			TransitionedAt: e.At.UnixMilli(),
+		}
+		rows = append(rows, row)
+		uids = append(uids, row.UID)
+	}
+	if _, err := sess.InsertMulti(&rows); err != nil {
+		return fmt.Errorf("failed to insert state history entries: %w", err)
+	}
+
+	// The IDs of the inserted rows are not returned by multi-row inserts, so they are read by their UIDs.
+	var inserted []alertStateHistory
+	args, in := getINSubQueryArgs(uids)
+	if err := sess.Table(alertStateHistory{}).Cols("id", "uid").Where(fmt.Sprintf("uid IN (%s)", strings.Join(in, ",")), args...).Find(&inserted); err != nil {
+		return fmt.Errorf("failed to read the IDs of state history entries: %w", err)
+	}
+	ids := make(map[string]int64, len(inserted))
+	for _, row := range inserted {
+		ids[row.UID] = row.ID
+	}
+
+	labels := make([]alertStateHistoryLabel, 0, len(entries)*len(entries[0].Labels))
+	for i, e := range entries {
+		id, ok := ids[rows[i].UID]
+		if !ok {
+			return fmt.Errorf("failed to read the ID of state history entry %s", rows[i].UID)
+		}
+		for k, v := range e.Labels {
+			labels = append(labels, alertStateHistoryLabel{HistoryID: id, LabelHash: stateHistoryLabelHash(k, v)})
+		}
+	}
+	for batch := range slices.Chunk(labels, stateHistoryInsertBatchSize) {
+		if _, err := sess.InsertMulti(&batch); err != nil {
+			return fmt.Errorf("failed to insert labels of state history entries: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetStateHistory returns the most recent state history entries that match the query, sorted by time in ascending order.
+// The labels are filtered by hash in SQL and compared after, so the entries are read page by page until the limit is
+// filled, in case of hash collisions.
+func (st DBstore) GetStateHistory(ctx context.Context, query models.StateHistoryEntryQuery) ([]models.StateHistoryEntry, error) {
+	result := make([]models.StateHistoryEntry, 0)
+	var last *alertStateHistory
+	for {
+		var rows []alertStateHistory
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			q := sess.Table(alertStateHistory{}).Where("org_id = ?", query.OrgID)
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.DashboardUID != "" {
+				q = q.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if query.PanelID != 0 {
+				q = q.And("panel_id = ?", query.PanelID)
+			}
+			if len(query.NamespaceUIDs) > 0 {
+				args, in := getINSubQueryArgs(query.NamespaceUIDs)
+				q = q.And(fmt.Sprintf("namespace_uid IN (%s)", strings.Join(in, ",")), args...)
+			}
+			if !query.From.IsZero() {
+				q = q.And("transitioned_at >= ?", query.From.UnixMilli())
+			}
+			if !query.To.IsZero() {
+				q = q.And("transitioned_at <= ?", query.To.UnixMilli())
+			}
+			for k, v := range query.Labels {
+				q = q.And("EXISTS (SELECT 1 FROM alert_state_history_label l WHERE l.history_id = alert_state_history.id AND l.label_hash = ?)", stateHistoryLabelHash(k, v))
+			}
+			// the next page starts after the last entry of the previous one
+			if last != nil {
+				q = q.And("(transitioned_at < ? OR (transitioned_at = ? AND id < ?))", last.TransitionedAt, last.TransitionedAt, last.ID)
+			}
+			q = q.Desc("transitioned_at", "id")
+			if query.Limit > 0 {
+				q = q.Limit(query.Limit)
+			}
+			return q.Find(&rows)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query state history: %w", err)
+		}
+
+		for _, row := range rows {
+			entry, ok, err := stateHistoryEntry(row, query.Labels)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, entry)
+			}
+		}
+		if query.Limit <= 0 || len(rows) < query.Limit || len(result) >= query.Limit {
+			break
+		}
+		last = &rows[len(rows)-1]
+	}
+
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	slices.Reverse(result)
+	return result, nil
+}
+
+// stateHistoryEntry converts a row, it returns false if the labels of the row do not match.
+func stateHistoryEntry(row alertStateHistory, matchers map[string]string) (models.StateHistoryEntry, bool, error) {
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(row.Labels), &labels); err != nil {
+		return models.StateHistoryEntry{}, false, fmt.Errorf("failed to parse labels of state history entry %d: %w", row.ID, err)
+	}
+	// Hashes of different labels can collide, so the labels are compared as well.
+	if !matchesLabels(labels, matchers) {
+		return models.StateHistoryEntry{}, false, nil
+	}
+	var values json.RawMessage
+	if row.StateValues != "" {
+		values = json.RawMessage(row.StateValues)
+	}
+	return models.StateHistoryEntry{
+		ID:           row.ID,
+		OrgID:        row.OrgID,
+		RuleUID:      row.RuleUID,
+		RuleID:       row.RuleID,
+		RuleTitle:    row.RuleTitle,
+		RuleGroup:    row.RuleGroup,
+		NamespaceUID: row.NamespaceUID,
+		DashboardUID: row.DashboardUID,
+		PanelID:      row.PanelID,
+		Condition:    row.RuleCondition,
+		Fingerprint:  row.Fingerprint,
+		Labels:       labels,
+		Previous:     row.PreviousState,
+		Current:      row.CurrentState,
+		Error:        row.ErrorMessage,
+		Values:       values,
+		At:           time.UnixMilli(row.TransitionedAt),
+	}, true, nil
+}
+
+func matchesLabels(labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// CleanUpStateHistory deletes the state history entries that are older than the retention of their organization,
+// and the oldest entries of organizations that have more entries than allowed. It returns the number of deleted entries.
+func (st DBstore) CleanUpStateHistory(ctx context.Context) (int64, error) {
+	cfg := st.Cfg.StateHistory.SQL
+	logger := st.Logger.FromContext(ctx)
+
+	var orgIDs []int64
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table(alertStateHistory{}).Distinct("org_id").Find(&orgIDs)
+	}); err != nil {
+		return -1, fmt.Errorf("failed to list organizations with state history: %w", err)
+	}
+	slices.Sort(orgIDs)
+
+	var deleted int64
+	for batch := range slices.Chunk(orgIDs, stateHistoryDeleteBatchSize) {
+		err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			for _, orgID := range batch {
+				if retention := cfg.RetentionForOrg(orgID); retention > 0 {
+					before := TimeNow().Add(-retention).UnixMilli()
+					n, err := deleteStateHistory(sess, "org_id = ? AND transitioned_at < ?", orgID, before)
+					if err != nil {
+						return err
+					}
+					deleted += n
+				}
+
+				maxEntries := cfg.MaxEntriesForOrg(orgID)
+				if maxEntries <= 0 {
+					continue
+				}
+				// Entries are inserted in the order of their transitions, so the IDs of the oldest entries are the lowest.
+				var ids []int64
+				if err := sess.Table(alertStateHistory{}).Cols("id").Where("org_id = ?", orgID).Desc("id").Limit(1, int(maxEntries)).Find(&ids); err != nil {
+					return fmt.Errorf("failed to find the oldest state history entry to keep: %w", err)
+				}
+				if len(ids) == 0 {
+					continue
+				}
+				n, err := deleteStateHistory(sess, "org_id = ? AND id <= ?", orgID, ids[0])
+				if err != nil {
+					return err
+				}
+				deleted += n
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if deleted > 0 {
+		logger.Debug("Deleted state history entries", "count", deleted)
+	}
+	return deleted, nil
+}
+
+// deleteStateHistory deletes the state history entries matching the condition together with their labels.
+func deleteStateHistory(sess *db.Session, cond string, args ...any) (int64, error) {
+	_, err := sess.Exec(append([]any{fmt.Sprintf("DELETE FROM alert_state_history_label WHERE history_id IN (SELECT id FROM alert_state_history WHERE %s)", cond)}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete labels of state history entries: %w", err)
+	}
+	res, err := sess.Exec(append([]any{fmt.Sprintf("DELETE FROM alert_state_history WHERE %s", cond)}, args...)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete state history entries: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the number of deleted state history entries: %w", err)
+	}
+	return n, nil
+}
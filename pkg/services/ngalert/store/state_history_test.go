@@ -0,0 +1,218 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	oldClk := TimeNow
+	t.Cleanup(func() {
+		TimeNow = oldClk
+	})
+	t0 := time.Now().UTC().Truncate(time.Millisecond)
+	TimeNow = func() time.Time {
+		return t0
+	}
+
+	setupStore := func(t *testing.T, cfg setting.UnifiedAlertingStateHistorySQLSettings) *DBstore {
+		t.Helper()
+		return &DBstore{
+			SQLStore: db.InitTestDB(t),
+			Logger:   log.New("test-dbstore"),
+			Cfg: setting.UnifiedAlertingSettings{
+				StateHistory: setting.UnifiedAlertingStateHistorySettings{SQL: cfg},
+			},
+		}
+	}
+
+	entry := func(orgID int64, ruleUID string, at time.Time, labels map[string]string) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:        orgID,
+			RuleUID:      ruleUID,
+			RuleTitle:    "rule " + ruleUID,
+			RuleGroup:    "group",
+			NamespaceUID: "folder-" + ruleUID,
+			Labels:       labels,
+			Previous:     "Normal",
+			Current:      "Alerting",
+			Values:       json.RawMessage(`{"A":1}`),
+			At:           at,
+		}
+	}
+
+	t.Run("GetStateHistory", func(t *testing.T) {
+		store := setupStore(t, setting.UnifiedAlertingStateHistorySQLSettings{})
+		ctx := context.Background()
+		require.NoError(t, store.SaveStateHistory(ctx, []models.StateHistoryEntry{
+			entry(1, "a", t0.Add(-3*time.Minute), map[string]string{"team": "alpha", "env": "prod"}),
+			entry(1, "a", t0.Add(-2*time.Minute), map[string]string{"team": "alpha", "env": "dev"}),
+			entry(1, "b", t0.Add(-1*time.Minute), map[string]string{"team": "beta", "env": "prod"}),
+			entry(2, "a", t0, map[string]string{"team": "alpha", "env": "prod"}),
+		}))
+
+		ruleUIDs := func(entries []models.StateHistoryEntry) []string {
+			res := make([]string, 0, len(entries))
+			for _, e := range entries {
+				res = append(res, e.RuleUID+":"+e.Labels["env"])
+			}
+			return res
+		}
+
+		testCases := []struct {
+			name     string
+			query    models.StateHistoryEntryQuery
+			expected []string
+		}{
+			{
+				name:     "should return entries of the organization in ascending order",
+				query:    models.StateHistoryEntryQuery{OrgID: 1},
+				expected: []string{"a:prod", "a:dev", "b:prod"},
+			},
+			{
+				name:     "should filter by rule UID",
+				query:    models.StateHistoryEntryQuery{OrgID: 1, RuleUID: "b"},
+				expected: []string{"b:prod"},
+			},
+			{
+				name:     "should filter by labels",
+				query:    models.StateHistoryEntryQuery{OrgID: 1, Labels: map[string]string{"team": "alpha", "env": "prod"}},
+				expected: []string{"a:prod"},
+			},
+			{
+				name:     "should filter by namespaces",
+				query:    models.StateHistoryEntryQuery{OrgID: 1, NamespaceUIDs: []string{"folder-b", "folder-c"}},
+				expected: []string{"b:prod"},
+			},
+			{
+				name:     "should filter by time range",
+				query:    models.StateHistoryEntryQuery{OrgID: 1, From: t0.Add(-2 * time.Minute), To: t0.Add(-2 * time.Minute)},
+				expected: []string{"a:dev"},
+			},
+			{
+				name:     "should return the most recent entries if limited",
+				query:    models.StateHistoryEntryQuery{OrgID: 1, Limit: 2},
+				expected: []string{"a:dev", "b:prod"},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := store.GetStateHistory(ctx, tc.query)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, ruleUIDs(result))
+			})
+		}
+
+		t.Run("should return all fields", func(t *testing.T) {
+			result, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 2})
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+			expected := entry(2, "a", t0, map[string]string{"team": "alpha", "env": "prod"})
+			expected.ID = result[0].ID
+			require.Equal(t, expected.At.UnixMilli(), result[0].At.UnixMilli())
+			expected.At = result[0].At
+			require.Equal(t, expected, result[0])
+		})
+
+		t.Run("should fill the limit when label hashes collide", func(t *testing.T) {
+			entries, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, RuleUID: "b"})
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			// simulate a collision of the hash of team=alpha with the labels of the most recent entry
+			require.NoError(t, store.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.Insert(&alertStateHistoryLabel{HistoryID: entries[0].ID, LabelHash: stateHistoryLabelHash("team", "alpha")})
+				return err
+			}))
+
+			result, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, Labels: map[string]string{"team": "alpha", "env": "prod"}, Limit: 1})
+			require.NoError(t, err)
+			require.Equal(t, []string{"a:prod"}, ruleUIDs(result))
+		})
+	})
+
+	t.Run("SaveStateHistory inserts the entries and their labels in batches", func(t *testing.T) {
+		store := setupStore(t, setting.UnifiedAlertingStateHistorySQLSettings{})
+		ctx := context.Background()
+		count := 2*stateHistoryInsertBatchSize + 1
+		entries := make([]models.StateHistoryEntry, 0, count)
+		for i := range count {
+			entries = append(entries, entry(1, "a", t0.Add(time.Duration(i)*time.Second), map[string]string{"team": "alpha", "index": fmt.Sprint(i)}))
+		}
+		require.NoError(t, store.SaveStateHistory(ctx, entries))
+
+		result, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, count)
+
+		for _, i := range []int{0, stateHistoryInsertBatchSize, count - 1} {
+			result, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, Labels: map[string]string{"index": fmt.Sprint(i)}})
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+			require.Equal(t, entries[i].At.UnixMilli(), result[0].At.UnixMilli())
+		}
+
+		var labels int64
+		err = store.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			labels, err = sess.Table(alertStateHistoryLabel{}).Count()
+			return err
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 2*count, labels)
+	})
+
+	t.Run("CleanUpStateHistory", func(t *testing.T) {
+		store := setupStore(t, setting.UnifiedAlertingStateHistorySQLSettings{
+			Retention:        time.Hour,
+			MaxEntriesPerOrg: 2,
+			OrgRetention:     map[int64]time.Duration{2: 10 * time.Minute},
+			OrgMaxEntries:    map[int64]int64{2: 10},
+		})
+		ctx := context.Background()
+		lbls := map[string]string{"team": "alpha"}
+		require.NoError(t, store.SaveStateHistory(ctx, []models.StateHistoryEntry{
+			entry(1, "a", t0.Add(-2*time.Hour), lbls),
+			entry(1, "a", t0.Add(-3*time.Minute), lbls),
+			entry(1, "a", t0.Add(-2*time.Minute), lbls),
+			entry(1, "a", t0.Add(-1*time.Minute), lbls),
+			entry(2, "a", t0.Add(-30*time.Minute), lbls),
+			entry(2, "a", t0.Add(-1*time.Minute), lbls),
+		}))
+
+		deleted, err := store.CleanUpStateHistory(ctx)
+		require.NoError(t, err)
+		// Org 1 loses one entry to retention and one to the row cap, org 2 loses one entry to its retention override.
+		require.EqualValues(t, 3, deleted)
+
+		org1, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, org1, 2)
+		require.Equal(t, t0.Add(-2*time.Minute).UnixMilli(), org1[0].At.UnixMilli())
+
+		org2, err := store.GetStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Len(t, org2, 1)
+		require.Equal(t, t0.Add(-1*time.Minute).UnixMilli(), org2[0].At.UnixMilli())
+
+		var labels int64
+		err = store.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			labels, err = sess.Table(alertStateHistoryLabel{}).Count()
+			return err
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, labels)
+	})
+}
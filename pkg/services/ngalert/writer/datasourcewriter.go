@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
@@ -51,6 +52,14 @@ type DatasourceWriterConfig struct {
 	// CustomHeaders is a map of optional custom HTTP headers
 	// to include in recording rule write requests.
 	CustomHeaders map[string]string
+
+	// OTLPTargets maps target data source UIDs to OTLP endpoints. Rules that target
+	// one of these UIDs are written as OTLP metrics instead of using remote write.
+	OTLPTargets map[string]OTLPWriterConfig
+}
+
+type remoteWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
 }
 
 type PluginContextProvider interface {
@@ -250,6 +259,67 @@ func (w *DatasourceWriter) makeWriter(ctx context.Context, orgID int64, dsUID st
 		w.metrics)
 }
 
+// getOTLPWriter returns the writer of an OTLP target. OTLP targets are shared by all organizations, which are
+// told apart by the grafana.org_id resource attribute, and their configuration does not change at runtime,
+// so the writer never expires.
+func (w *DatasourceWriter) getOTLPWriter(dsUID string, cfg OTLPWriterConfig) (remoteWriter, error) {
+	key := otlpKey(dsUID)
+	if val, ok := w.writers.Get(key); ok {
+		writer, ok := val.(remoteWriter)
+		if !ok {
+			return nil, errors.New("type in cache not a Writer")
+		}
+		return writer, nil
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = w.cfg.Timeout
+	}
+	writer, err := NewOTLPWriter(cfg, w.httpClientProvider, w.clock, w.l, w.metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	w.l.Debug("Created OTLP writer",
+		"datasource_uid", dsUID,
+		"endpoint", cfg.Endpoint,
+		"protocol", cfg.Protocol,
+		"timeout", cfg.Timeout)
+
+	// Another goroutine may have created the writer in the meantime, in which case it is used instead.
+	if err := w.writers.Add(key, writer, gocache.NoExpiration); err != nil {
+		if err := writer.Close(); err != nil {
+			w.l.Warn("Failed to close OTLP writer", "datasource_uid", dsUID, "error", err)
+		}
+		if val, ok := w.writers.Get(key); ok {
+			if existing, ok := val.(remoteWriter); ok {
+				return existing, nil
+			}
+		}
+	}
+	return writer, nil
+}
+
+// Close closes the cached writers that hold connections, such as the writers of OTLP targets.
+func (w *DatasourceWriter) Close() error {
+	var errs []error
+	for key, item := range w.writers.Items() {
+		closer, ok := item.Object.(io.Closer)
+		if !ok {
+			continue
+		}
+		w.writers.Delete(key)
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func otlpKey(uid string) string {
+	return "otlp-" + uid
+}
+
 func uidKey(orgID int64, uid string) string {
 	return fmt.Sprintf("%d-%s", orgID, uid)
 }
@@ -264,14 +334,23 @@ func (w *DatasourceWriter) WriteDatasource(ctx context.Context, dsUID string, na
 			"org_id", orgID, "datasource_uid", dsUID)
 	}
 
+	if otlpCfg, ok := w.cfg.OTLPTargets[dsUID]; ok {
+		writer, err := w.getOTLPWriter(dsUID, otlpCfg)
+		if err != nil {
+			w.l.Error("Failed to create OTLP writer", "datasource_uid", dsUID, "error", err)
+			return err
+		}
+		return writer.Write(ctx, name, t, frames, orgID, extraLabels)
+	}
+
 	key := uidKey(orgID, dsUID)
 
-	var writer *PrometheusWriter
+	var writer remoteWriter
 
 	val, ok := w.writers.Get(key)
 	if ok {
 		var ok bool
-		writer, ok = val.(*PrometheusWriter)
+		writer, ok = val.(remoteWriter)
 		if !ok {
 			return errors.New("type in cache not a Writer")
 		}
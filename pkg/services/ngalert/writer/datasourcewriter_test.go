@@ -180,6 +180,42 @@ func TestDatasourceWriter(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("when writing an OTLP target then the metrics are exported to the OTLP endpoint", func(t *testing.T) {
+		testDS.Reset()
+		otlp := newTestOTLPTarget(t)
+
+		cfg := DatasourceWriterConfig{
+			Timeout:              time.Second * 5,
+			DefaultDatasourceUID: "prom-2",
+			OTLPTargets: map[string]OTLPWriterConfig{
+				"otlp": {Endpoint: otlp.srv.URL},
+			},
+		}
+		writer := NewDatasourceWriter(cfg, testDS, httpclient.NewProvider(), pluginContextProvider, clock.New(), log.New("test"), met)
+
+		err := writer.WriteDatasource(context.Background(), "otlp", "metric", time.Now(), frames, 1, map[string]string{})
+		require.NoError(t, err)
+		err = writer.WriteDatasource(context.Background(), "otlp", "metric", time.Now(), frames, 2, map[string]string{})
+		require.NoError(t, err)
+
+		assert.Len(t, otlp.requests, 2)
+		assert.Equal(t, 0, testDS.prom1.RequestsCount)
+		assert.Equal(t, 0, testDS.prom2.RequestsCount)
+		for i, req := range otlp.requests {
+			resource := attributesOf(req.GetResourceMetrics()[0].GetResource().GetAttributes())
+			assert.Equal(t, fmt.Sprint(i+1), resource["grafana.org_id"])
+		}
+
+		err = writer.WriteDatasource(context.Background(), "prom-1", "metric", time.Now(), frames, 1, map[string]string{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, testDS.prom1.RequestsCount)
+		assert.Len(t, otlp.requests, 2)
+
+		require.NoError(t, writer.Close())
+		_, ok := writer.writers.Get(otlpKey("otlp"))
+		assert.False(t, ok, "the OTLP writer is closed and removed from the cache")
+	})
+
 	t.Run("when custom headers are configured, they are passed to the request", func(t *testing.T) {
 		testDS.Reset()
 
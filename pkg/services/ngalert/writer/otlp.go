@@ -0,0 +1,403 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+const (
+	otlpType backendType = "otlp"
+
+	// otlpMetricsPath is the default path of the OTLP/HTTP metrics endpoint.
+	otlpMetricsPath = "/v1/metrics"
+	// otlpScopeName is the name of the instrumentation scope of the exported metrics.
+	otlpScopeName = "grafana-recording-rule"
+	// otlpServiceNameAttribute is the resource attribute that identifies the service that produced the metrics.
+	otlpServiceNameAttribute = "service.name"
+	// otlpOrgIDAttribute is the resource attribute that identifies the organization of the recording rule.
+	// The endpoint is shared by all organizations, so it is always set and cannot be overridden by the labels of a series.
+	otlpOrgIDAttribute = "grafana.org_id"
+
+	// maxOTLPErrorBodySize is the maximum number of bytes that are read from an error response.
+	maxOTLPErrorBodySize = 64 * 1024
+)
+
+type OTLPProtocol string
+
+const (
+	OTLPProtocolHTTP OTLPProtocol = "http/protobuf"
+	OTLPProtocolGRPC OTLPProtocol = "grpc"
+)
+
+type OTLPWriterConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP endpoint, or the host:port of the OTLP/gRPC endpoint.
+	// If the URL of an OTLP/HTTP endpoint has no path, /v1/metrics is used.
+	Endpoint string
+
+	// Protocol is the protocol used to export the metrics, either http/protobuf or grpc. Defaults to http/protobuf.
+	Protocol OTLPProtocol
+
+	// Insecure disables TLS for OTLP/gRPC endpoints. OTLP/HTTP endpoints use TLS depending on the scheme of the URL.
+	Insecure bool
+
+	// Headers are added to every export request, for example for authentication.
+	Headers map[string]string
+
+	// Timeout is the maximum time to wait for an export to succeed.
+	Timeout time.Duration
+
+	// ResourceLabels are the labels of the recorded series that are mapped to resource attributes.
+	// All other labels are mapped to attributes of the data points.
+	ResourceLabels []string
+
+	// ResourceAttributes are added to the resource of every exported metric. Resource labels
+	// of a series take precedence over these attributes.
+	ResourceAttributes map[string]string
+}
+
+// otlpExporter sends an export request to an OTLP endpoint. It returns the status of the response,
+// which is the HTTP status code for OTLP/HTTP and the gRPC status code for OTLP/gRPC.
+type otlpExporter interface {
+	Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (string, error)
+	Close() error
+}
+
+// OTLPWriter writes the results of recording rules as OTLP gauge metrics.
+type OTLPWriter struct {
+	exporter           otlpExporter
+	timeout            time.Duration
+	resourceLabels     map[string]struct{}
+	resourceAttributes map[string]string
+	clock              clock.Clock
+	logger             log.Logger
+	metrics            *metrics.RemoteWriter
+}
+
+func NewOTLPWriter(
+	cfg OTLPWriterConfig,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*OTLPWriter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("OTLP endpoint must not be empty")
+	}
+
+	var exporter otlpExporter
+	var err error
+	switch cfg.Protocol {
+	case "", OTLPProtocolHTTP:
+		exporter, err = newOTLPHTTPExporter(cfg, httpClientProvider)
+	case OTLPProtocolGRPC:
+		exporter, err = newOTLPGRPCExporter(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, must be one of %s or %s", cfg.Protocol, OTLPProtocolHTTP, OTLPProtocolGRPC)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resourceLabels := make(map[string]struct{}, len(cfg.ResourceLabels))
+	for _, l := range cfg.ResourceLabels {
+		resourceLabels[l] = struct{}{}
+	}
+	resourceAttributes := make(map[string]string, len(cfg.ResourceAttributes)+1)
+	resourceAttributes[otlpServiceNameAttribute] = "grafana"
+	for k, v := range cfg.ResourceAttributes {
+		resourceAttributes[k] = v
+	}
+
+	return &OTLPWriter{
+		exporter:           exporter,
+		timeout:            cfg.Timeout,
+		resourceLabels:     resourceLabels,
+		resourceAttributes: resourceAttributes,
+		clock:              clock,
+		logger:             l,
+		metrics:            metrics,
+	}, nil
+}
+
+// Write writes the given frames to the OTLP endpoint.
+func (w *OTLPWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), string(otlpType)}
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	l.Debug("Writing metric", "name", name)
+	writeStart := w.clock.Now()
+	statusCode, err := w.exporter.Export(ctx, w.exportRequest(points, orgID))
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+
+	lvs = append(lvs, statusCode)
+	w.metrics.WritesTotal.WithLabelValues(lvs...).Inc()
+
+	return err
+}
+
+// Close releases the connections to the OTLP endpoint.
+func (w *OTLPWriter) Close() error {
+	return w.exporter.Close()
+}
+
+// exportRequest groups the points by their resource attributes and converts them to gauge data points.
+func (w *OTLPWriter) exportRequest(points []Point, orgID int64) *colmetricpb.ExportMetricsServiceRequest {
+	req := &colmetricpb.ExportMetricsServiceRequest{}
+	byResource := make(map[data.Fingerprint]*metricpb.Metric)
+	for _, p := range points {
+		resource := make(data.Labels, len(w.resourceAttributes)+1)
+		for k, v := range w.resourceAttributes {
+			resource[k] = v
+		}
+		attributes := make(data.Labels, len(p.Labels))
+		for k, v := range p.Labels {
+			if _, ok := w.resourceLabels[k]; ok {
+				resource[k] = v
+				continue
+			}
+			attributes[k] = v
+		}
+		resource[otlpOrgIDAttribute] = strconv.FormatInt(orgID, 10)
+
+		fp := resource.Fingerprint()
+		metric, ok := byResource[fp]
+		if !ok {
+			metric = &metricpb.Metric{
+				Name: p.Name,
+				Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{}},
+			}
+			byResource[fp] = metric
+			req.ResourceMetrics = append(req.ResourceMetrics, &metricpb.ResourceMetrics{
+				Resource: &resourcepb.Resource{Attributes: otlpAttributes(resource)},
+				ScopeMetrics: []*metricpb.ScopeMetrics{{
+					Scope:   &commonpb.InstrumentationScope{Name: otlpScopeName},
+					Metrics: []*metricpb.Metric{metric},
+				}},
+			})
+		}
+		gauge := metric.GetGauge()
+		gauge.DataPoints = append(gauge.DataPoints, &metricpb.NumberDataPoint{
+			Attributes:   otlpAttributes(attributes),
+			TimeUnixNano: uint64(p.Metric.T.UnixNano()),
+			Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: p.Metric.V},
+		})
+	}
+	return req
+}
+
+// otlpAttributes converts labels to string attributes sorted by their keys.
+func otlpAttributes(labels data.Labels) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attributes := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attributes = append(attributes, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: labels[k]}},
+		})
+	}
+	return attributes
+}
+
+// checkPartialSuccess returns an error if the endpoint accepted the request but rejected some of the data points.
+func checkPartialSuccess(res *colmetricpb.ExportMetricsServiceResponse) error {
+	partial := res.GetPartialSuccess()
+	if partial == nil || partial.GetRejectedDataPoints() == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d data points were rejected: %s", ErrRejectedWrite, partial.GetRejectedDataPoints(), partial.GetErrorMessage())
+}
+
+type otlpHTTPExporter struct {
+	client  *http.Client
+	url     string
+	headers http.Header
+}
+
+func newOTLPHTTPExporter(cfg OTLPWriterConfig, httpClientProvider HttpClientProvider) (*otlpHTTPExporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s: the scheme must be http or https", cfg.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpMetricsPath
+	}
+
+	cl, err := httpClientProvider.New(httpclient.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(http.Header, len(cfg.Headers))
+	for k, v := range cfg.Headers {
+		headers.Add(k, v)
+	}
+
+	return &otlpHTTPExporter{
+		client:  cl,
+		url:     u.String(),
+		headers: headers,
+	}, nil
+}
+
+func (e *otlpHTTPExporter) Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (string, error) {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return "", errors.Join(ErrBadFrame, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+	httpReq.Header = e.headers.Clone()
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("User-Agent", "grafana-recording-rule")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConnectionFailure, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	statusCode := strconv.Itoa(resp.StatusCode)
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxOTLPErrorBodySize))
+	if err != nil {
+		return statusCode, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		res := &colmetricpb.ExportMetricsServiceResponse{}
+		if err := proto.Unmarshal(respBody, res); err != nil {
+			// The data was accepted, even though the response cannot be read.
+			return statusCode, nil
+		}
+		return statusCode, checkPartialSuccess(res)
+	case resp.StatusCode == http.StatusBadRequest:
+		return statusCode, fmt.Errorf("%w: %s", ErrRejectedWrite, otlpErrorMessage(respBody))
+	case resp.StatusCode == http.StatusUnauthorized:
+		return statusCode, fmt.Errorf("%w: %s", ErrDatasourceUnauthorized, otlpErrorMessage(respBody))
+	case resp.StatusCode == http.StatusForbidden:
+		return statusCode, fmt.Errorf("%w: %s", ErrDatasourceForbidden, otlpErrorMessage(respBody))
+	default:
+		return statusCode, fmt.Errorf("%w: status %d: %s", ErrUnexpectedWriteFailure, resp.StatusCode, otlpErrorMessage(respBody))
+	}
+}
+
+func (e *otlpHTTPExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// otlpErrorMessage extracts the message of an OTLP/HTTP error response, which is a protobuf encoded google.rpc.Status.
+// Endpoints that do not follow the specification may return plain text, which is returned as is.
+func otlpErrorMessage(body []byte) string {
+	st := &rpcstatus.Status{}
+	if err := proto.Unmarshal(body, st); err == nil && st.GetMessage() != "" {
+		return st.GetMessage()
+	}
+	return string(body)
+}
+
+type otlpGRPCExporter struct {
+	conn    *grpc.ClientConn
+	client  colmetricpb.MetricsServiceClient
+	headers metadata.MD
+}
+
+func newOTLPGRPCExporter(cfg OTLPWriterConfig) (*otlpGRPCExporter, error) {
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if cfg.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(cfg.Endpoint,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent("grafana-recording-rule"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+
+	return &otlpGRPCExporter{
+		conn:    conn,
+		client:  colmetricpb.NewMetricsServiceClient(conn),
+		headers: metadata.New(cfg.Headers),
+	}, nil
+}
+
+func (e *otlpGRPCExporter) Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (string, error) {
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	}
+	res, err := e.client.Export(ctx, req)
+	st := status.Convert(err)
+	statusCode := st.Code().String()
+
+	switch st.Code() {
+	case codes.OK:
+		return statusCode, checkPartialSuccess(res)
+	case codes.InvalidArgument:
+		return statusCode, fmt.Errorf("%w: %s", ErrRejectedWrite, st.Message())
+	case codes.Unauthenticated:
+		return statusCode, fmt.Errorf("%w: %s", ErrDatasourceUnauthorized, st.Message())
+	case codes.PermissionDenied:
+		return statusCode, fmt.Errorf("%w: %s", ErrDatasourceForbidden, st.Message())
+	case codes.Unavailable:
+		return statusCode, fmt.Errorf("%w: %s", ErrConnectionFailure, st.Message())
+	default:
+		return statusCode, errors.Join(ErrUnexpectedWriteFailure, err)
+	}
+}
+
+func (e *otlpGRPCExporter) Close() error {
+	return e.conn.Close()
+}
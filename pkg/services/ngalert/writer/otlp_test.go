@@ -0,0 +1,279 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type testOTLPTarget struct {
+	srv *httptest.Server
+
+	mtx          sync.Mutex
+	requests     []*colmetricpb.ExportMetricsServiceRequest
+	lastPath     string
+	lastHeaders  http.Header
+	statusCode   int
+	responseBody []byte
+}
+
+func newTestOTLPTarget(t *testing.T) *testOTLPTarget {
+	t.Helper()
+
+	target := &testOTLPTarget{statusCode: http.StatusOK}
+	target.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target.mtx.Lock()
+		defer target.mtx.Unlock()
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := &colmetricpb.ExportMetricsServiceRequest{}
+		require.NoError(t, proto.Unmarshal(body, req))
+		target.requests = append(target.requests, req)
+		target.lastPath = r.URL.Path
+		target.lastHeaders = r.Header.Clone()
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(target.statusCode)
+		_, err = w.Write(target.responseBody)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(target.srv.Close)
+	return target
+}
+
+func (s *testOTLPTarget) respond(statusCode int, msg proto.Message) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.statusCode = statusCode
+	s.responseBody = nil
+	if msg != nil {
+		s.responseBody, _ = proto.Marshal(msg)
+	}
+}
+
+type testMetricsService struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+
+	requests []*colmetricpb.ExportMetricsServiceRequest
+	metadata metadata.MD
+	err      error
+}
+
+func (s *testMetricsService) Export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	s.requests = append(s.requests, req)
+	s.metadata, _ = metadata.FromIncomingContext(ctx)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+func attributesOf(kvs []*commonpb.KeyValue) map[string]string {
+	res := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		res[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return res
+}
+
+func TestOTLPWriter_exportRequest(t *testing.T) {
+	now := time.Now()
+	writer, err := NewOTLPWriter(OTLPWriterConfig{
+		Endpoint:           "http://localhost:4318",
+		ResourceLabels:     []string{"job"},
+		ResourceAttributes: map[string]string{"deployment.environment": "test", "job": "default"},
+	}, httpclient.NewProvider(), clock.New(), log.New("test"), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	req := writer.exportRequest([]Point{
+		{Name: "metric", Labels: map[string]string{"job": "api", "instance": "a"}, Metric: Metric{T: now, V: 1}},
+		{Name: "metric", Labels: map[string]string{"job": "db", "instance": "b"}, Metric: Metric{T: now, V: 2}},
+		{Name: "metric", Labels: map[string]string{"job": "api", "instance": "c"}, Metric: Metric{T: now, V: 3}},
+		{Name: "metric", Labels: map[string]string{"instance": "d"}, Metric: Metric{T: now, V: 4}},
+		{Name: "metric", Labels: map[string]string{"job": "api", "instance": "e", "grafana.org_id": "2"}, Metric: Metric{T: now, V: 5}},
+	}, 1)
+
+	type dataPoint struct {
+		attributes map[string]string
+		value      float64
+	}
+	type resource struct {
+		attributes map[string]string
+		points     []dataPoint
+	}
+	var resources []resource
+	for _, rm := range req.GetResourceMetrics() {
+		require.Len(t, rm.GetScopeMetrics(), 1)
+		require.Equal(t, otlpScopeName, rm.GetScopeMetrics()[0].GetScope().GetName())
+		require.Len(t, rm.GetScopeMetrics()[0].GetMetrics(), 1)
+		m := rm.GetScopeMetrics()[0].GetMetrics()[0]
+		require.Equal(t, "metric", m.GetName())
+		require.NotNil(t, m.GetGauge())
+
+		r := resource{attributes: attributesOf(rm.GetResource().GetAttributes())}
+		for _, dp := range m.GetGauge().GetDataPoints() {
+			require.Equal(t, uint64(now.UnixNano()), dp.GetTimeUnixNano())
+			r.points = append(r.points, dataPoint{attributes: attributesOf(dp.GetAttributes()), value: dp.GetAsDouble()})
+		}
+		resources = append(resources, r)
+	}
+
+	require.Equal(t, []resource{
+		{
+			attributes: map[string]string{"service.name": "grafana", "deployment.environment": "test", "job": "api", "grafana.org_id": "1"},
+			points: []dataPoint{
+				{attributes: map[string]string{"instance": "a"}, value: 1},
+				{attributes: map[string]string{"instance": "c"}, value: 3},
+				// the organization of a series can not be overridden by its labels
+				{attributes: map[string]string{"instance": "e", "grafana.org_id": "2"}, value: 5},
+			},
+		},
+		{
+			attributes: map[string]string{"service.name": "grafana", "deployment.environment": "test", "job": "db", "grafana.org_id": "1"},
+			points:     []dataPoint{{attributes: map[string]string{"instance": "b"}, value: 2}},
+		},
+		{
+			attributes: map[string]string{"service.name": "grafana", "deployment.environment": "test", "job": "default", "grafana.org_id": "1"},
+			points:     []dataPoint{{attributes: map[string]string{"instance": "d"}, value: 4}},
+		},
+	}, resources)
+}
+
+func TestOTLPWriter_Write(t *testing.T) {
+	series := []map[string]string{{"foo": "1"}, {"foo": "2"}}
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, series)
+
+	t.Run("OTLP/HTTP", func(t *testing.T) {
+		target := newTestOTLPTarget(t)
+		met := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+		writer, err := NewOTLPWriter(OTLPWriterConfig{
+			Endpoint: target.srv.URL,
+			Headers:  map[string]string{"X-Scope-OrgID": "tenant"},
+			Timeout:  time.Second,
+		}, httpclient.NewProvider(), clock.New(), log.New("test"), met)
+		require.NoError(t, err)
+
+		t.Run("should export the metrics", func(t *testing.T) {
+			err := writer.Write(context.Background(), "metric", time.Now(), frames, 1, map[string]string{"extra": "label"})
+			require.NoError(t, err)
+
+			require.Len(t, target.requests, 1)
+			require.Equal(t, otlpMetricsPath, target.lastPath)
+			require.Equal(t, "tenant", target.lastHeaders.Get("X-Scope-OrgID"))
+			require.Equal(t, "application/x-protobuf", target.lastHeaders.Get("Content-Type"))
+			dps := target.requests[0].GetResourceMetrics()[0].GetScopeMetrics()[0].GetMetrics()[0].GetGauge().GetDataPoints()
+			require.Len(t, dps, 2)
+			require.Equal(t, map[string]string{"foo": "1", "extra": "label"}, attributesOf(dps[0].GetAttributes()))
+
+			require.NoError(t, testutil.CollectAndCompare(met.WritesTotal, strings.NewReader(`
+				# HELP grafana_alerting_remote_writer_writes_total The total number of remote writes attempted.
+				# TYPE grafana_alerting_remote_writer_writes_total counter
+				grafana_alerting_remote_writer_writes_total{backend="otlp",org="1",status_code="200"} 1
+			`), "grafana_alerting_remote_writer_writes_total"))
+		})
+
+		t.Run("should fail if data points are rejected", func(t *testing.T) {
+			target.respond(http.StatusOK, &colmetricpb.ExportMetricsServiceResponse{
+				PartialSuccess: &colmetricpb.ExportMetricsPartialSuccess{RejectedDataPoints: 1, ErrorMessage: "invalid metric name"},
+			})
+			err := writer.Write(context.Background(), "metric", time.Now(), frames, 1, nil)
+			require.ErrorIs(t, err, ErrRejectedWrite)
+			require.ErrorContains(t, err, "invalid metric name")
+		})
+
+		testCases := []struct {
+			statusCode  int
+			expectedErr error
+		}{
+			{statusCode: http.StatusBadRequest, expectedErr: ErrRejectedWrite},
+			{statusCode: http.StatusUnauthorized, expectedErr: ErrDatasourceUnauthorized},
+			{statusCode: http.StatusForbidden, expectedErr: ErrDatasourceForbidden},
+			{statusCode: http.StatusServiceUnavailable, expectedErr: ErrUnexpectedWriteFailure},
+		}
+		for _, tc := range testCases {
+			t.Run("should map status "+http.StatusText(tc.statusCode), func(t *testing.T) {
+				target.respond(tc.statusCode, &rpcstatus.Status{Message: "something went wrong"})
+				err := writer.Write(context.Background(), "metric", time.Now(), frames, 1, nil)
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.ErrorContains(t, err, "something went wrong")
+			})
+		}
+
+		t.Run("should fail if frames are empty", func(t *testing.T) {
+			err := writer.Write(context.Background(), "metric", time.Now(), data.Frames{data.NewFrame("test")}, 1, nil)
+			require.ErrorIs(t, err, ErrBadFrame)
+		})
+	})
+
+	t.Run("OTLP/gRPC", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer()
+		service := &testMetricsService{}
+		colmetricpb.RegisterMetricsServiceServer(srv, service)
+		go func() {
+			_ = srv.Serve(lis)
+		}()
+		t.Cleanup(srv.Stop)
+
+		writer, err := NewOTLPWriter(OTLPWriterConfig{
+			Endpoint: lis.Addr().String(),
+			Protocol: OTLPProtocolGRPC,
+			Insecure: true,
+			Headers:  map[string]string{"X-Scope-OrgID": "tenant"},
+			Timeout:  5 * time.Second,
+		}, httpclient.NewProvider(), clock.New(), log.New("test"), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+		require.NoError(t, err)
+
+		err = writer.Write(context.Background(), "metric", time.Now(), frames, 1, nil)
+		require.NoError(t, err)
+		require.Len(t, service.requests, 1)
+		require.Equal(t, []string{"tenant"}, service.metadata.Get("X-Scope-OrgID"))
+
+		service.err = status.Error(codes.InvalidArgument, "invalid metric name")
+		err = writer.Write(context.Background(), "metric", time.Now(), frames, 1, nil)
+		require.ErrorIs(t, err, ErrRejectedWrite)
+		require.ErrorContains(t, err, "invalid metric name")
+
+		require.NoError(t, writer.Close())
+		err = writer.Write(context.Background(), "metric", time.Now(), frames, 1, nil)
+		require.Error(t, err, "the connection is closed")
+	})
+
+	t.Run("should fail with an invalid configuration", func(t *testing.T) {
+		testCases := []OTLPWriterConfig{
+			{},
+			{Endpoint: "localhost:4318"},
+			{Endpoint: "http://localhost:4318", Protocol: "http/json"},
+		}
+		for _, cfg := range testCases {
+			_, err := NewOTLPWriter(cfg, httpclient.NewProvider(), clock.New(), log.New("test"), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+			require.Error(t, err)
+		}
+	})
+}
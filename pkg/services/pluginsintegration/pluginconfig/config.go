@@ -72,7 +72,8 @@ type PluginInstanceCfg struct {
 
 	UserFacingDefaultError string
 
-	DataProxyRowLimit int64
+	DataProxyRowLimit     int64
+	DataProxySQLByteLimit int64
 
 	SQLDatasourceMaxOpenConnsDefault    int
 	SQLDatasourceMaxIdleConnsDefault    int
@@ -121,6 +122,7 @@ func ProvidePluginInstanceConfig(cfg *setting.Cfg, settingProvider setting.Provi
 		ConcurrentQueryCount:                cfg.ConcurrentQueryCount,
 		UserFacingDefaultError:              cfg.UserFacingDefaultError,
 		DataProxyRowLimit:                   cfg.DataProxyRowLimit,
+		DataProxySQLByteLimit:               cfg.DataProxySQLByteLimit,
 		SQLDatasourceMaxOpenConnsDefault:    cfg.SqlDatasourceMaxOpenConnsDefault,
 		SQLDatasourceMaxIdleConnsDefault:    cfg.SqlDatasourceMaxIdleConnsDefault,
 		SQLDatasourceMaxConnLifetimeDefault: cfg.SqlDatasourceMaxConnLifetimeDefault,
@@ -18,6 +18,9 @@ import (
 
 var _ PluginRequestConfigProvider = (*RequestConfigProvider)(nil)
 
+// SQLByteLimit is the key of the maximum number of bytes of the result of a SQL data source query
+const SQLByteLimit = "GF_SQL_BYTE_LIMIT"
+
 type PluginRequestConfigProvider interface {
 	PluginRequestConfig(ctx context.Context, pluginID string, externalService *auth.ExternalService) map[string]string
 }
@@ -163,6 +166,10 @@ func (s *RequestConfigProvider) PluginRequestConfig(ctx context.Context, pluginI
 		m[backend.SQLRowLimit] = strconv.FormatInt(s.cfg.DataProxyRowLimit, 10)
 	}
 
+	if s.cfg.DataProxySQLByteLimit > 0 {
+		m[SQLByteLimit] = strconv.FormatInt(s.cfg.DataProxySQLByteLimit, 10)
+	}
+
 	m[backend.SQLMaxOpenConnsDefault] = strconv.Itoa(s.cfg.SQLDatasourceMaxOpenConnsDefault)
 	m[backend.SQLMaxIdleConnsDefault] = strconv.Itoa(s.cfg.SQLDatasourceMaxIdleConnsDefault)
 	m[backend.SQLMaxConnLifetimeSecondsDefault] = strconv.Itoa(s.cfg.SQLDatasourceMaxConnLifetimeDefault)
@@ -191,6 +191,7 @@ func TestRequestConfigProvider_PluginRequestConfig_SQL(t *testing.T) {
 	t.Run("Uses the configured values", func(t *testing.T) {
 		cfg := setting.NewCfg()
 		cfg.DataProxyRowLimit = 23
+		cfg.DataProxySQLByteLimit = 27
 		cfg.SqlDatasourceMaxOpenConnsDefault = 24
 		cfg.SqlDatasourceMaxIdleConnsDefault = 25
 		cfg.SqlDatasourceMaxConnLifetimeDefault = 26
@@ -201,6 +202,7 @@ func TestRequestConfigProvider_PluginRequestConfig_SQL(t *testing.T) {
 		p := NewRequestConfigProvider(pCfg)
 		require.Subset(t, p.PluginRequestConfig(context.Background(), "", nil), map[string]string{
 			"GF_SQL_ROW_LIMIT":                         "23",
+			"GF_SQL_BYTE_LIMIT":                        "27",
 			"GF_SQL_MAX_OPEN_CONNS_DEFAULT":            "24",
 			"GF_SQL_MAX_IDLE_CONNS_DEFAULT":            "25",
 			"GF_SQL_MAX_CONN_LIFETIME_SECONDS_DEFAULT": "26",
@@ -155,11 +155,11 @@ func TestIntegrationPluginManager(t *testing.T) {
 	am := azuremonitor.ProvideService(hcp)
 	cw := cloudwatch.ProvideService()
 	cm := cloudmonitoring.ProvideService(hcp)
-	es := elasticsearch.ProvideService(hcp)
+	es := elasticsearch.ProvideService(cfg, hcp)
 	grap := graphite.ProvideService(hcp, tracer)
 	idb := influxdb.ProvideService(hcp, features)
 	lk := loki.ProvideService(hcp, tracer)
-	otsdb := opentsdb.ProvideService(hcp)
+	otsdb := opentsdb.ProvideService(hcp, tracer)
 	pr := prometheus.ProvideService(hcp)
 	tmpo := tempo.ProvideService(hcp)
 	td := testdatasource.ProvideService()
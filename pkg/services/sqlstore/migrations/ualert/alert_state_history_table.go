@@ -0,0 +1,58 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddAlertStateHistoryTables adds the tables that store alert state history when the SQL state history backend is used.
+func AddAlertStateHistoryTables(mg *migrator.Migrator) {
+	historyTable := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			// uid identifies the rows inserted together, whose IDs are not returned by multi-row inserts
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "rule_condition", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "transitioned_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "transitioned_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "rule_uid", "transitioned_at"}, Type: migrator.IndexType},
+			{Cols: []string{"uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("add alert_state_history table", migrator.NewAddTableMigration(historyTable))
+	mg.AddMigration("add index to alert_state_history on org_id and transitioned_at columns", migrator.NewAddIndexMigration(historyTable, historyTable.Indices[0]))
+	mg.AddMigration("add index to alert_state_history on org_id, rule_uid and transitioned_at columns", migrator.NewAddIndexMigration(historyTable, historyTable.Indices[1]))
+	mg.AddMigration("add unique index to alert_state_history on uid column", migrator.NewAddIndexMigration(historyTable, historyTable.Indices[2]))
+
+	// Each row represents a label of the alert instance of a state history entry. Only the hash of the label is
+	// stored, as label values can be longer than what can be indexed.
+	labelTable := migrator.Table{
+		Name: "alert_state_history_label",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "history_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "label_hash", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"history_id", "label_hash"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("add alert_state_history_label table", migrator.NewAddTableMigration(labelTable))
+	mg.AddMigration("add unique index to alert_state_history_label on history_id and label_hash columns", migrator.NewAddIndexMigration(labelTable, labelTable.Indices[0]))
+}
@@ -301,6 +301,7 @@ type Cfg struct {
 	DataProxyIdleConnTimeout       int
 	ResponseLimit                  int64
 	DataProxyRowLimit              int64
+	DataProxySQLByteLimit          int64
 	DataProxyUserAgent             string
 
 	// DistributedCache
@@ -591,6 +592,10 @@ type Cfg struct {
 	SprinklesApiServerPageLimit                int
 	CACertPath                                 string
 	HttpsSkipVerify                            bool
+	HistoryRetention                           HistoryRetentionSettings
+	HistoryRetentionEnabled                    bool
+	HistoryRetentionInterval                   time.Duration
+	ArchiveMaxImportSize                       int64
 
 	// Secrets Management
 	SecretsManagement SecretsManagerSettings
@@ -604,6 +609,18 @@ type UnifiedStorageConfig struct {
 	DataSyncerInterval time.Duration
 	// DataSyncerRecordsLimit defines how many records will be processed at max during a sync invocation.
 	DataSyncerRecordsLimit int
+	// HistoryRetention overrides the default history retention for the resource when set.
+	HistoryRetention *HistoryRetentionSettings
+}
+
+// HistoryRetentionSettings defines how many versions of each resource are kept in the unified storage history.
+type HistoryRetentionSettings struct {
+	// MaxVersions is the maximum number of versions kept per resource, 0 means no limit.
+	MaxVersions int64
+	// MaxAge is how long a version is kept, 0 means no limit.
+	MaxAge time.Duration
+	// KeepAtLeast is the number of versions kept per resource whatever their age.
+	KeepAtLeast int64
 }
 
 type InstallPlugin struct {
@@ -70,6 +70,10 @@ const (
 	lokiDefaultMaxQuerySize                = 65536 // 64kb
 	defaultHistorianPrometheusWriteTimeout = 10 * time.Second
 	defaultHistorianPrometheusMetricName   = "GRAFANA_ALERTS"
+	defaultHistorianSQLRetention           = 30 * 24 * time.Hour
+	defaultHistorianSQLMaxEntriesPerOrg    = 100000
+	defaultBacktestingHistoryLookback      = 24 * time.Hour
+	defaultBacktestingHistoryMaxEntries    = 5000
 )
 
 var (
@@ -126,6 +130,7 @@ type UnifiedAlertingSettings struct {
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
 	PrometheusConversion          UnifiedAlertingPrometheusConversionSettings
+	Backtesting                   UnifiedAlertingBacktestingSettings
 
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency    int
@@ -153,6 +158,24 @@ type RecordingRuleSettings struct {
 	CustomHeaders        map[string]string
 	Timeout              time.Duration
 	DefaultDatasourceUID string
+	OTLP                 RecordingRuleOTLPSettings
+}
+
+// RecordingRuleOTLPSettings configures an OpenTelemetry endpoint that recording rules can write to
+// by targeting TargetDatasourceUID instead of a Prometheus data source.
+type RecordingRuleOTLPSettings struct {
+	TargetDatasourceUID string
+	Endpoint            string
+	Protocol            string
+	Insecure            bool
+	Headers             map[string]string
+	ResourceLabels      []string
+	ResourceAttributes  map[string]string
+}
+
+// Enabled returns true if an OTLP endpoint is configured.
+func (s RecordingRuleOTLPSettings) Enabled() bool {
+	return s.Endpoint != ""
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -182,6 +205,14 @@ type UnifiedAlertingPrometheusConversionSettings struct {
 	RuleQueryOffset time.Duration
 }
 
+// UnifiedAlertingBacktestingSettings contains configuration for backtesting rules against the recorded state history
+type UnifiedAlertingBacktestingSettings struct {
+	// HistoryLookback is how long before the start of the backtesting the state history is queried
+	HistoryLookback time.Duration
+	// HistoryMaxEntries is the maximum number of state history entries that are replayed
+	HistoryMaxEntries int
+}
+
 type UnifiedAlertingLokiSettings struct {
 	LokiRemoteURL string
 	LokiReadURL   string
@@ -206,6 +237,52 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary                  string
 	MultiSecondaries              []string
 	ExternalLabels                map[string]string
+	SQL                           UnifiedAlertingStateHistorySQLSettings
+}
+
+// UnifiedAlertingStateHistorySQLSettings contains the retention settings of the SQL state history backend.
+type UnifiedAlertingStateHistorySQLSettings struct {
+	// Retention is how long state history entries are kept. 0 keeps them forever.
+	Retention time.Duration
+	// MaxEntriesPerOrg is the maximum number of state history entries kept for each organization. 0 means no limit.
+	MaxEntriesPerOrg int64
+	// OrgRetention overrides Retention for specific organizations.
+	OrgRetention map[int64]time.Duration
+	// OrgMaxEntries overrides MaxEntriesPerOrg for specific organizations.
+	OrgMaxEntries map[int64]int64
+}
+
+// RetentionForOrg returns how long state history entries of the organization are kept.
+func (s UnifiedAlertingStateHistorySQLSettings) RetentionForOrg(orgID int64) time.Duration {
+	if r, ok := s.OrgRetention[orgID]; ok {
+		return r
+	}
+	return s.Retention
+}
+
+// MaxEntriesForOrg returns the maximum number of state history entries kept for the organization.
+func (s UnifiedAlertingStateHistorySQLSettings) MaxEntriesForOrg(orgID int64) int64 {
+	if m, ok := s.OrgMaxEntries[orgID]; ok {
+		return m
+	}
+	return s.MaxEntriesPerOrg
+}
+
+// UsesBackend returns true if state history is enabled and the backend, or one of the backends
+// of the multiple backend, is of the given type.
+func (s UnifiedAlertingStateHistorySettings) UsesBackend(backend string) bool {
+	if !s.Enabled {
+		return false
+	}
+	if strings.EqualFold(s.Backend, backend) || strings.EqualFold(s.MultiPrimary, backend) {
+		return true
+	}
+	for _, b := range s.MultiSecondaries {
+		if strings.EqualFold(b, backend) {
+			return true
+		}
+	}
+	return false
 }
 
 type UnifiedAlertingNotificationHistorySettings struct {
@@ -482,6 +559,36 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		PrometheusTargetDatasourceUID: stateHistory.Key("prometheus_target_datasource_uid").MustString(""),
 		PrometheusWriteTimeout:        stateHistory.Key("prometheus_write_timeout").MustDuration(defaultHistorianPrometheusWriteTimeout),
 		ExternalLabels:                stateHistoryLabels.KeysHash(),
+		SQL: UnifiedAlertingStateHistorySQLSettings{
+			MaxEntriesPerOrg: stateHistory.Key("sql_max_entries_per_org").MustInt64(defaultHistorianSQLMaxEntriesPerOrg),
+		},
+	}
+	uaCfgStateHistory.SQL.Retention, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_retention", defaultHistorianSQLRetention.String()))
+	if err != nil {
+		return fmt.Errorf("setting 'sql_retention' in section 'unified_alerting.state_history' is invalid: %w", err)
+	}
+	if uaCfgStateHistory.SQL.Retention < 0 || uaCfgStateHistory.SQL.MaxEntriesPerOrg < 0 {
+		return fmt.Errorf("settings 'sql_retention' and 'sql_max_entries_per_org' in section 'unified_alerting.state_history' must not be negative")
+	}
+	uaCfgStateHistory.SQL.OrgRetention, err = parseOrgOverrides(stateHistory.Key("sql_org_retention").MustString(""), func(s string) (time.Duration, error) {
+		d, err := gtime.ParseDuration(s)
+		if err == nil && d < 0 {
+			err = fmt.Errorf("retention must not be negative")
+		}
+		return d, err
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'sql_org_retention' in section 'unified_alerting.state_history' is invalid: %w", err)
+	}
+	uaCfgStateHistory.SQL.OrgMaxEntries, err = parseOrgOverrides(stateHistory.Key("sql_org_max_entries").MustString(""), func(s string) (int64, error) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("maximum number of entries must not be negative")
+		}
+		return n, err
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'sql_org_max_entries' in section 'unified_alerting.state_history' is invalid: %w", err)
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
@@ -508,6 +615,15 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		RuleQueryOffset: prometheusConversion.Key("rule_query_offset").MustDuration(time.Minute),
 	}
 
+	backtesting := iniFile.Section("unified_alerting.backtesting")
+	uaCfg.Backtesting = UnifiedAlertingBacktestingSettings{
+		HistoryLookback:   backtesting.Key("history_lookback").MustDuration(defaultBacktestingHistoryLookback),
+		HistoryMaxEntries: backtesting.Key("history_max_entries").MustInt(defaultBacktestingHistoryMaxEntries),
+	}
+	if uaCfg.Backtesting.HistoryLookback < 0 || uaCfg.Backtesting.HistoryMaxEntries <= 0 {
+		return fmt.Errorf("setting 'history_lookback' in section 'unified_alerting.backtesting' must not be negative and 'history_max_entries' must be greater than 0")
+	}
+
 	rr := iniFile.Section("recording_rules")
 	uaCfgRecordingRules := RecordingRuleSettings{
 		Enabled:              rr.Key("enabled").MustBool(true),
@@ -522,6 +638,23 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfgRecordingRules.CustomHeaders[key.Name()] = key.Value()
 	}
 
+	otlp := iniFile.Section("recording_rules.otlp")
+	uaCfgRecordingRules.OTLP = RecordingRuleOTLPSettings{
+		TargetDatasourceUID: otlp.Key("target_datasource_uid").MustString("otlp"),
+		Endpoint:            otlp.Key("endpoint").MustString(""),
+		Protocol:            otlp.Key("protocol").MustString("http/protobuf"),
+		Insecure:            otlp.Key("insecure").MustBool(false),
+		Headers:             iniFile.Section("recording_rules.otlp.headers").KeysHash(),
+		ResourceLabels:      util.SplitString(otlp.Key("resource_labels").MustString("")),
+	}
+	if p := uaCfgRecordingRules.OTLP.Protocol; p != "http/protobuf" && p != "grpc" {
+		return fmt.Errorf("setting 'protocol' in section 'recording_rules.otlp' is invalid, only http/protobuf or grpc are allowed, got %q", p)
+	}
+	uaCfgRecordingRules.OTLP.ResourceAttributes, err = splitResourceAttributes(otlp.Key("resource_attributes").MustString(""))
+	if err != nil {
+		return fmt.Errorf("setting 'resource_attributes' in section 'recording_rules.otlp' is invalid: %w", err)
+	}
+
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
@@ -568,3 +701,45 @@ func splitTrim(s string, sep string) []string {
 	}
 	return spl
 }
+
+// splitResourceAttributes parses a comma separated list of attributes in 'key:value' form.
+func splitResourceAttributes(s string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("attribute malformed - must be in 'key:value' form: %q", v)
+		}
+		res[parts[0]] = parts[1]
+	}
+	return res, nil
+}
+
+// parseOrgOverrides parses a comma separated list of per-organization values in 'orgID:value' form.
+func parseOrgOverrides[T any](s string, parse func(string) (T, error)) (map[int64]T, error) {
+	res := map[int64]T{}
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("override malformed - must be in 'orgID:value' form: %q", v)
+		}
+		orgID, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid organization ID in %q: %w", v, err)
+		}
+		value, err := parse(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %q: %w", v, err)
+		}
+		res[orgID] = value
+	}
+	return res, nil
+}
@@ -419,3 +419,127 @@ func TestHARedisSentinelModeSettings(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordingRulesOTLPSettings(t *testing.T) {
+	t.Run("should be disabled by default", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(ini.Empty()))
+		require.False(t, cfg.UnifiedAlerting.RecordingRules.OTLP.Enabled())
+		require.Equal(t, "otlp", cfg.UnifiedAlerting.RecordingRules.OTLP.TargetDatasourceUID)
+		require.Equal(t, "http/protobuf", cfg.UnifiedAlerting.RecordingRules.OTLP.Protocol)
+	})
+
+	t.Run("should read the OTLP endpoint", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[recording_rules.otlp]
+endpoint = localhost:4317
+target_datasource_uid = collector
+protocol = grpc
+insecure = true
+resource_labels = job, instance
+resource_attributes = deployment.environment:production,service.namespace:monitoring
+
+[recording_rules.otlp.headers]
+X-Scope-OrgID = tenant
+`))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+		require.Equal(t, RecordingRuleOTLPSettings{
+			TargetDatasourceUID: "collector",
+			Endpoint:            "localhost:4317",
+			Protocol:            "grpc",
+			Insecure:            true,
+			Headers:             map[string]string{"X-Scope-OrgID": "tenant"},
+			ResourceLabels:      []string{"job", "instance"},
+			ResourceAttributes:  map[string]string{"deployment.environment": "production", "service.namespace": "monitoring"},
+		}, cfg.UnifiedAlerting.RecordingRules.OTLP)
+		require.True(t, cfg.UnifiedAlerting.RecordingRules.OTLP.Enabled())
+	})
+
+	testCases := map[string]string{
+		"protocol is unknown":             "protocol = http/json",
+		"resource attributes are invalid": "resource_attributes = production",
+	}
+	for desc, options := range testCases {
+		t.Run("should fail if "+desc, func(t *testing.T) {
+			f, err := ini.Load([]byte("[recording_rules.otlp]\n" + options))
+			require.NoError(t, err)
+			require.Error(t, NewCfg().ReadUnifiedAlertingSettings(f))
+		})
+	}
+}
+
+func TestStateHistorySQLSettings(t *testing.T) {
+	t.Run("should use defaults", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(ini.Empty()))
+		require.Equal(t, defaultHistorianSQLRetention, cfg.UnifiedAlerting.StateHistory.SQL.RetentionForOrg(1))
+		require.Equal(t, int64(defaultHistorianSQLMaxEntriesPerOrg), cfg.UnifiedAlerting.StateHistory.SQL.MaxEntriesForOrg(1))
+		require.False(t, cfg.UnifiedAlerting.StateHistory.UsesBackend("sql"))
+	})
+
+	t.Run("should read per-organization overrides", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[unified_alerting.state_history]
+enabled = true
+backend = multiple
+primary = annotations
+secondaries = SQL
+sql_retention = 7d
+sql_max_entries_per_org = 1000
+sql_org_retention = 2:1h, 3:0
+sql_org_max_entries = 2:10
+`))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+		sql := cfg.UnifiedAlerting.StateHistory.SQL
+		require.True(t, cfg.UnifiedAlerting.StateHistory.UsesBackend("sql"))
+		require.Equal(t, 7*24*time.Hour, sql.RetentionForOrg(1))
+		require.Equal(t, time.Hour, sql.RetentionForOrg(2))
+		require.Equal(t, time.Duration(0), sql.RetentionForOrg(3))
+		require.Equal(t, int64(1000), sql.MaxEntriesForOrg(1))
+		require.Equal(t, int64(10), sql.MaxEntriesForOrg(2))
+	})
+
+	testCases := map[string]string{
+		"retention is negative":         "sql_retention = -1h",
+		"override has no organization":  "sql_org_retention = 1h",
+		"override has an invalid value": "sql_org_max_entries = 2:many",
+		"override is negative":          "sql_org_max_entries = 2:-1",
+	}
+	for desc, options := range testCases {
+		t.Run("should fail if "+desc, func(t *testing.T) {
+			f, err := ini.Load([]byte("[unified_alerting.state_history]\n" + options))
+			require.NoError(t, err)
+			require.Error(t, NewCfg().ReadUnifiedAlertingSettings(f))
+		})
+	}
+}
+
+func TestBacktestingSettings(t *testing.T) {
+	t.Run("should use defaults", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(ini.Empty()))
+		require.Equal(t, defaultBacktestingHistoryLookback, cfg.UnifiedAlerting.Backtesting.HistoryLookback)
+		require.Equal(t, defaultBacktestingHistoryMaxEntries, cfg.UnifiedAlerting.Backtesting.HistoryMaxEntries)
+	})
+
+	t.Run("should read the settings", func(t *testing.T) {
+		f, err := ini.Load([]byte("[unified_alerting.backtesting]\nhistory_lookback = 6h\nhistory_max_entries = 100"))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+		require.Equal(t, 6*time.Hour, cfg.UnifiedAlerting.Backtesting.HistoryLookback)
+		require.Equal(t, 100, cfg.UnifiedAlerting.Backtesting.HistoryMaxEntries)
+	})
+
+	t.Run("should fail if the maximum number of entries is not positive", func(t *testing.T) {
+		f, err := ini.Load([]byte("[unified_alerting.backtesting]\nhistory_max_entries = 0"))
+		require.NoError(t, err)
+		require.Error(t, NewCfg().ReadUnifiedAlertingSettings(f))
+	})
+}
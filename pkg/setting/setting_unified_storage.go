@@ -4,9 +4,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+
 	"github.com/grafana/grafana/pkg/apiserver/rest"
 )
 
+const defaultHistoryMaxVersions = 20
+
+// The largest archive accepted by the archive import API, 1GiB
+const defaultArchiveMaxImportSize = 1 << 30
+
 // read storage configs from ini file. They look like:
 // [unified_storage.<group>.<resource>]
 // <field> = <value>
@@ -14,6 +22,14 @@ import (
 // [unified_storage.playlists.playlist.grafana.app]
 // dualWriterMode = 2
 func (cfg *Cfg) setUnifiedStorageConfig() {
+	// History retention defaults, they can be overridden in the resource sections
+	section := cfg.Raw.Section("unified_storage")
+	cfg.HistoryRetentionEnabled = section.Key("history_retention_enabled").MustBool(false)
+	cfg.HistoryRetentionInterval = section.Key("history_retention_interval").MustDuration(time.Hour)
+	cfg.HistoryRetention = cfg.readHistoryRetention(section,
+		"history_max_versions", "history_max_age", "history_keep_at_least",
+		HistoryRetentionSettings{MaxVersions: defaultHistoryMaxVersions, KeepAtLeast: 1})
+
 	storageConfig := make(map[string]UnifiedStorageConfig)
 	sections := cfg.Raw.Sections()
 	for _, section := range sections {
@@ -39,18 +55,26 @@ func (cfg *Cfg) setUnifiedStorageConfig() {
 		// parse dataSyncerInterval from resource section
 		dataSyncerInterval := section.Key("dataSyncerInterval").MustDuration(time.Hour)
 
+		// parse the history retention of the resource, the missing keys use the defaults
+		var historyRetention *HistoryRetentionSettings
+		if section.HasKey("historyMaxVersions") || section.HasKey("historyMaxAge") || section.HasKey("historyKeepAtLeast") {
+			retention := cfg.readHistoryRetention(section,
+				"historyMaxVersions", "historyMaxAge", "historyKeepAtLeast", cfg.HistoryRetention)
+			historyRetention = &retention
+		}
+
 		storageConfig[resourceName] = UnifiedStorageConfig{
 			DualWriterMode:                       rest.DualWriterMode(dualWriterMode),
 			DualWriterPeriodicDataSyncJobEnabled: dualWriterPeriodicDataSyncJobEnabled,
 			DualWriterMigrationDataSyncDisabled:  dualWriterMigrationDataSyncDisabled,
 			DataSyncerRecordsLimit:               dataSyncerRecordsLimit,
 			DataSyncerInterval:                   dataSyncerInterval,
+			HistoryRetention:                     historyRetention,
 		}
 	}
 	cfg.UnifiedStorage = storageConfig
 
 	// Set indexer config for unified storage
-	section := cfg.Raw.Section("unified_storage")
 	cfg.MaxPageSizeBytes = section.Key("max_page_size_bytes").MustInt(0)
 	cfg.IndexPath = section.Key("index_path").String()
 	cfg.IndexWorkers = section.Key("index_workers").MustInt(10)
@@ -77,4 +101,24 @@ func (cfg *Cfg) setUnifiedStorageConfig() {
 	cfg.SprinklesApiServerPageLimit = section.Key("sprinkles_api_server_page_limit").MustInt(10000)
 	cfg.CACertPath = section.Key("ca_cert_path").String()
 	cfg.HttpsSkipVerify = section.Key("https_skip_verify").MustBool(false)
+	cfg.ArchiveMaxImportSize = section.Key("archive_max_import_size").MustInt64(defaultArchiveMaxImportSize)
+}
+
+// readHistoryRetention reads the history retention keys of a section.
+// The max age accepts the units of gtime, like 90d or 1y.
+func (cfg *Cfg) readHistoryRetention(section *ini.Section, maxVersionsKey, maxAgeKey, keepAtLeastKey string, defaults HistoryRetentionSettings) HistoryRetentionSettings {
+	retention := HistoryRetentionSettings{
+		MaxVersions: section.Key(maxVersionsKey).MustInt64(defaults.MaxVersions),
+		MaxAge:      defaults.MaxAge,
+		KeepAtLeast: section.Key(keepAtLeastKey).MustInt64(defaults.KeepAtLeast),
+	}
+	if value := section.Key(maxAgeKey).MustString(""); value != "" {
+		maxAge, err := gtime.ParseDuration(value)
+		if err != nil {
+			cfg.Logger.Warn("invalid history max age, using the default", "section", section.Name(), "key", maxAgeKey, "value", value, "error", err)
+		} else {
+			retention.MaxAge = maxAge
+		}
+	}
+	return retention
 }
@@ -66,5 +66,60 @@ func TestCfg_setUnifiedStorageConfig(t *testing.T) {
 		// Test that default index settings are applied
 		assert.Equal(t, 1, cfg.IndexMinCount)
 		assert.Equal(t, 0, cfg.IndexMaxCount)
+
+		// Test that the default history retention is applied
+		assert.False(t, cfg.HistoryRetentionEnabled)
+		assert.Equal(t, time.Hour, cfg.HistoryRetentionInterval)
+		assert.Equal(t, HistoryRetentionSettings{MaxVersions: 20, KeepAtLeast: 1}, cfg.HistoryRetention)
+
+		// Test that the archive imports are limited by default
+		assert.Equal(t, int64(1<<30), cfg.ArchiveMaxImportSize)
+	})
+
+	t.Run("read history retention configs", func(t *testing.T) {
+		cfg := NewCfg()
+		err := cfg.Load(CommandLineArgs{HomePath: "../../", Config: "../../conf/defaults.ini"})
+		assert.NoError(t, err)
+
+		unifiedStorageSection, err := cfg.Raw.NewSection("unified_storage")
+		assert.NoError(t, err)
+		_, err = unifiedStorageSection.NewKey("history_retention_enabled", "true")
+		assert.NoError(t, err)
+		_, err = unifiedStorageSection.NewKey("history_max_versions", "50")
+		assert.NoError(t, err)
+		_, err = unifiedStorageSection.NewKey("history_keep_at_least", "3")
+		assert.NoError(t, err)
+
+		dashboards, err := cfg.Raw.NewSection("unified_storage.dashboards.dashboard.grafana.app")
+		assert.NoError(t, err)
+		_, err = dashboards.NewKey("historyMaxAge", "365d")
+		assert.NoError(t, err)
+		_, err = dashboards.NewKey("historyMaxVersions", "0")
+		assert.NoError(t, err)
+
+		receivers, err := cfg.Raw.NewSection("unified_storage.receivers.notifications.alerting.grafana.app")
+		assert.NoError(t, err)
+		_, err = receivers.NewKey("historyMaxAge", "90d")
+		assert.NoError(t, err)
+
+		playlists, err := cfg.Raw.NewSection("unified_storage.playlists.playlist.grafana.app")
+		assert.NoError(t, err)
+		_, err = playlists.NewKey("dualWriterMode", "2")
+		assert.NoError(t, err)
+
+		cfg.setUnifiedStorageConfig()
+
+		assert.True(t, cfg.HistoryRetentionEnabled)
+		assert.Equal(t, HistoryRetentionSettings{MaxVersions: 50, KeepAtLeast: 3}, cfg.HistoryRetention)
+		assert.Equal(t, &HistoryRetentionSettings{
+			MaxAge:      365 * 24 * time.Hour,
+			KeepAtLeast: 3,
+		}, cfg.UnifiedStorage["dashboards.dashboard.grafana.app"].HistoryRetention)
+		assert.Equal(t, &HistoryRetentionSettings{
+			MaxVersions: 50,
+			MaxAge:      90 * 24 * time.Hour,
+			KeepAtLeast: 3,
+		}, cfg.UnifiedStorage["receivers.notifications.alerting.grafana.app"].HistoryRetention)
+		assert.Nil(t, cfg.UnifiedStorage["playlists.playlist.grafana.app"].HistoryRetention)
 	})
 }
@@ -0,0 +1,414 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/metadata"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// ArchiveVersion is the version of the archive format written by the ArchiveWriter
+const ArchiveVersion = 1
+
+// The archive header is saved in the parquet metadata
+const (
+	archiveMetaVersion   = "grafana.archive.version"
+	archiveMetaNamespace = "grafana.archive.namespace"
+	archiveMetaCreated   = "grafana.archive.created"
+	archiveMetaHistory   = "grafana.archive.history"
+	archiveMetaBlobs     = "grafana.archive.blobs"
+	archiveMetaResources = "grafana.archive.resources"
+)
+
+// ArchiveRecordKind is the kind of a row in the archive
+type ArchiveRecordKind string
+
+const (
+	// ArchiveRecordResource is one version of a resource
+	ArchiveRecordResource ArchiveRecordKind = "resource"
+	// ArchiveRecordBlob is a large object, it is written before the resource versions that reference it
+	ArchiveRecordBlob ArchiveRecordKind = "blob"
+)
+
+// ArchiveHeader describes the content of an archive
+type ArchiveHeader struct {
+	Version   int
+	Namespace string
+	Created   time.Time
+	// The archive includes the previous versions of each resource
+	History bool
+	// The archive includes the large objects referenced by the resources
+	Blobs bool
+	// The archived resource types
+	Resources []schema.GroupResource
+}
+
+// ArchiveRecord is a single row of an archive
+type ArchiveRecord struct {
+	Kind            ArchiveRecordKind
+	Key             *resourcepb.ResourceKey
+	ResourceVersion int64
+	Folder          string
+	Action          resourcepb.WatchEvent_Type
+
+	// Blob uid and content type, only set for blobs
+	UID         string
+	ContentType string
+
+	// The resource JSON or the blob content
+	Value []byte
+}
+
+func (h *ArchiveHeader) metadata() arrow.Metadata {
+	resources := make([]string, len(h.Resources))
+	for i, gr := range h.Resources {
+		resources[i] = gr.Group + "/" + gr.Resource
+	}
+	return arrow.NewMetadata([]string{
+		archiveMetaVersion,
+		archiveMetaNamespace,
+		archiveMetaCreated,
+		archiveMetaHistory,
+		archiveMetaBlobs,
+		archiveMetaResources,
+	}, []string{
+		strconv.Itoa(h.Version),
+		h.Namespace,
+		h.Created.UTC().Format(time.RFC3339),
+		strconv.FormatBool(h.History),
+		strconv.FormatBool(h.Blobs),
+		strings.Join(resources, ","),
+	})
+}
+
+func readArchiveHeader(md metadata.KeyValueMetadata) (*ArchiveHeader, error) {
+	get := func(key string) string {
+		if v := md.FindValue(key); v != nil {
+			return *v
+		}
+		return ""
+	}
+
+	version, err := strconv.Atoi(get(archiveMetaVersion))
+	if err != nil {
+		return nil, fmt.Errorf("not a grafana archive, missing version")
+	}
+	if version > ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version: %d", version)
+	}
+	h := &ArchiveHeader{
+		Version:   version,
+		Namespace: get(archiveMetaNamespace),
+		History:   get(archiveMetaHistory) == "true",
+		Blobs:     get(archiveMetaBlobs) == "true",
+	}
+	if h.Namespace == "" {
+		return nil, fmt.Errorf("archive is missing a namespace")
+	}
+	h.Created, err = time.Parse(time.RFC3339, get(archiveMetaCreated))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive creation time: %w", err)
+	}
+	if v := get(archiveMetaResources); v != "" {
+		for _, gr := range strings.Split(v, ",") {
+			group, resource, ok := strings.Cut(gr, "/")
+			if !ok {
+				return nil, fmt.Errorf("invalid archive resource: %s", gr)
+			}
+			h.Resources = append(h.Resources, schema.GroupResource{Group: group, Resource: resource})
+		}
+	}
+	return h, nil
+}
+
+func newArchiveSchema(metadata *arrow.Metadata) *arrow.Schema {
+	return arrow.NewSchema([]arrow.Field{
+		{Name: "kind", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "resource_version", Type: &arrow.Int64Type{}, Nullable: false},
+		{Name: "group", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "resource", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "namespace", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "name", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "folder", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "action", Type: &arrow.Int8Type{}, Nullable: false}, // 1,2,3
+		{Name: "uid", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "content_type", Type: &arrow.StringType{}, Nullable: false},
+		{Name: "value", Type: &arrow.BinaryType{}, Nullable: false},
+	}, metadata)
+}
+
+func newFileWriter(schema *arrow.Schema, f io.Writer) (*pqarrow.FileWriter, error) {
+	props := parquet.NewWriterProperties(
+		parquet.WithCompression(compress.Codecs.Brotli),
+	)
+	return pqarrow.NewFileWriter(schema, f, props, pqarrow.DefaultWriterProps())
+}
+
+// ArchiveWriter writes a self describing archive of resources and blobs
+type ArchiveWriter struct {
+	pool   memory.Allocator
+	buffer int
+	wrote  int
+
+	schema *arrow.Schema
+	writer *pqarrow.FileWriter
+
+	kind        *array.StringBuilder
+	rv          *array.Int64Builder
+	group       *array.StringBuilder
+	resource    *array.StringBuilder
+	namespace   *array.StringBuilder
+	name        *array.StringBuilder
+	folder      *array.StringBuilder
+	action      *array.Int8Builder
+	uid         *array.StringBuilder
+	contentType *array.StringBuilder
+	value       *array.BinaryBuilder
+}
+
+// NewArchiveWriter starts an archive, the header is written with the file metadata
+func NewArchiveWriter(f io.Writer, header ArchiveHeader) (*ArchiveWriter, error) {
+	if header.Namespace == "" {
+		return nil, fmt.Errorf("missing namespace")
+	}
+	header.Version = ArchiveVersion
+	if header.Created.IsZero() {
+		header.Created = time.Now()
+	}
+	md := header.metadata()
+
+	w := &ArchiveWriter{
+		pool:   memory.DefaultAllocator,
+		schema: newArchiveSchema(&md),
+		buffer: 1024 * 10 * 100 * 10, // 10MB
+	}
+	writer, err := newFileWriter(w.schema, f)
+	if err != nil {
+		return nil, err
+	}
+	w.writer = writer
+	w.init()
+	return w, nil
+}
+
+// Write adds a record to the archive
+func (w *ArchiveWriter) Write(rec *ArchiveRecord) error {
+	if rec.Key == nil {
+		return fmt.Errorf("missing key")
+	}
+	switch rec.Kind {
+	case ArchiveRecordResource, ArchiveRecordBlob:
+	default:
+		return fmt.Errorf("unknown record kind: %q", rec.Kind)
+	}
+
+	w.kind.Append(string(rec.Kind))
+	w.rv.Append(rec.ResourceVersion)
+	w.group.Append(rec.Key.Group)
+	w.resource.Append(rec.Key.Resource)
+	w.namespace.Append(rec.Key.Namespace)
+	w.name.Append(rec.Key.Name)
+	w.folder.Append(rec.Folder)
+	w.action.Append(int8(rec.Action))
+	w.uid.Append(rec.UID)
+	w.contentType.Append(rec.ContentType)
+	w.value.Append(rec.Value)
+
+	w.wrote = w.wrote + len(rec.Value)
+	if w.wrote > w.buffer {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close writes the remaining records and the parquet footer
+func (w *ArchiveWriter) Close() error {
+	if w.rv.Len() > 0 {
+		if err := w.flush(); err != nil {
+			_ = w.writer.Close()
+			return err
+		}
+	}
+	return w.writer.Close()
+}
+
+// writes the current buffer to parquet and re-inits the arrow buffer
+func (w *ArchiveWriter) flush() error {
+	rec := array.NewRecord(w.schema, []arrow.Array{
+		w.kind.NewArray(),
+		w.rv.NewArray(),
+		w.group.NewArray(),
+		w.resource.NewArray(),
+		w.namespace.NewArray(),
+		w.name.NewArray(),
+		w.folder.NewArray(),
+		w.action.NewArray(),
+		w.uid.NewArray(),
+		w.contentType.NewArray(),
+		w.value.NewArray(),
+	}, int64(w.rv.Len()))
+	defer rec.Release()
+	err := w.writer.Write(rec)
+	if err != nil {
+		return err
+	}
+	w.init()
+	return nil
+}
+
+func (w *ArchiveWriter) init() {
+	w.kind = array.NewStringBuilder(w.pool)
+	w.rv = array.NewInt64Builder(w.pool)
+	w.group = array.NewStringBuilder(w.pool)
+	w.resource = array.NewStringBuilder(w.pool)
+	w.namespace = array.NewStringBuilder(w.pool)
+	w.name = array.NewStringBuilder(w.pool)
+	w.folder = array.NewStringBuilder(w.pool)
+	w.action = array.NewInt8Builder(w.pool)
+	w.uid = array.NewStringBuilder(w.pool)
+	w.contentType = array.NewStringBuilder(w.pool)
+	w.value = array.NewBinaryBuilder(w.pool, arrow.BinaryTypes.Binary)
+	w.wrote = 0
+}
+
+// ArchiveReader reads the records of an archive in the order they were written
+type ArchiveReader struct {
+	file   *file.Reader
+	reader pqarrow.RecordReader
+	header *ArchiveHeader
+
+	batch arrow.Record
+	index int
+	rec   *ArchiveRecord
+	err   error
+}
+
+// OpenArchive opens an archive and reads its header
+func OpenArchive(ctx context.Context, inputPath string) (*ArchiveReader, error) {
+	pf, err := file.OpenParquetFile(inputPath, false)
+	if err != nil {
+		return nil, err
+	}
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{BatchSize: 100}, memory.DefaultAllocator)
+	if err != nil {
+		_ = pf.Close()
+		return nil, err
+	}
+	s, err := fr.Schema()
+	if err != nil {
+		_ = pf.Close()
+		return nil, err
+	}
+	header, err := readArchiveHeader(pf.MetaData().KeyValueMetadata())
+	if err != nil {
+		_ = pf.Close()
+		return nil, err
+	}
+	if !sameFields(s, newArchiveSchema(nil)) {
+		_ = pf.Close()
+		return nil, fmt.Errorf("unexpected archive schema")
+	}
+	reader, err := fr.GetRecordReader(ctx, nil, nil)
+	if err != nil {
+		_ = pf.Close()
+		return nil, err
+	}
+	return &ArchiveReader{
+		file:   pf,
+		reader: reader,
+		header: header,
+	}, nil
+}
+
+// sameFields compares the field names and types, the parquet reader adds its own field metadata
+func sameFields(a, b *arrow.Schema) bool {
+	if a.NumFields() != b.NumFields() {
+		return false
+	}
+	for i, f := range a.Fields() {
+		if f.Name != b.Field(i).Name || !arrow.TypeEqual(f.Type, b.Field(i).Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// Header describes the archive content
+func (r *ArchiveReader) Header() *ArchiveHeader {
+	return r.header
+}
+
+// Next reads the next record, it returns false at the end of the archive or on error
+func (r *ArchiveReader) Next() bool {
+	r.rec = nil
+	if r.err != nil {
+		return false
+	}
+	for r.batch == nil || r.index >= int(r.batch.NumRows()) {
+		if !r.reader.Next() {
+			r.err = r.reader.Err()
+			if r.err == io.EOF {
+				r.err = nil
+			}
+			return false
+		}
+		r.batch = r.reader.Record()
+		r.index = 0
+	}
+
+	cols := r.batch.Columns()
+	i := r.index
+	r.index++
+
+	// copy the values, the batch buffers are reused
+	str := func(col int) string {
+		return strings.Clone(cols[col].(*array.String).Value(i))
+	}
+	r.rec = &ArchiveRecord{
+		Kind:            ArchiveRecordKind(str(0)),
+		ResourceVersion: cols[1].(*array.Int64).Value(i),
+		Key: &resourcepb.ResourceKey{
+			Group:     str(2),
+			Resource:  str(3),
+			Namespace: str(4),
+			Name:      str(5),
+		},
+		Folder:      str(6),
+		Action:      resourcepb.WatchEvent_Type(cols[7].(*array.Int8).Value(i)),
+		UID:         str(8),
+		ContentType: str(9),
+		Value:       bytes.Clone(cols[10].(*array.Binary).Value(i)),
+	}
+	return true
+}
+
+// Record returns the current record
+func (r *ArchiveReader) Record() *ArchiveRecord {
+	return r.rec
+}
+
+// Err returns the error that stopped Next
+func (r *ArchiveReader) Err() error {
+	return r.err
+}
+
+// Close releases the archive file
+func (r *ArchiveReader) Close() error {
+	r.reader.Release()
+	return r.file.Close()
+}
@@ -0,0 +1,182 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+var (
+	_ resourcepb.ArchiveStoreServer = (*archiveServer)(nil)
+)
+
+// The archive is streamed in parts of this size, below the default gRPC message limit
+const archiveChunkSize = 1024 * 1024
+
+// NewArchiveServer exports and imports namespace archives with the client, so the
+// resources are read and written with the permissions of the caller.
+// The imported archives are saved on disk, they can not be larger than maxImportSize bytes.
+func NewArchiveServer(client resource.ResourceClient, maxImportSize int64) resourcepb.ArchiveStoreServer {
+	return &archiveServer{client: client, maxImportSize: maxImportSize}
+}
+
+type archiveServer struct {
+	client        resource.ResourceClient
+	maxImportSize int64
+}
+
+// Export implements resourcepb.ArchiveStoreServer.
+func (s *archiveServer) Export(req *resourcepb.ExportRequest, stream resourcepb.ArchiveStore_ExportServer) error {
+	resources, err := ParseGroupResources(req.Kinds)
+	if err != nil {
+		return stream.Send(&resourcepb.ExportResponse{Error: resource.AsErrorResult(err)})
+	}
+
+	out := &chunkWriter{send: func(chunk []byte) error {
+		return stream.Send(&resourcepb.ExportResponse{Chunk: chunk})
+	}}
+	rsp, err := ExportNamespace(stream.Context(), s.client, out, ExportOptions{
+		Namespace:   req.Namespace,
+		Resources:   resources,
+		WithHistory: req.WithHistory,
+		WithBlobs:   req.WithBlobs,
+	})
+	if err == nil {
+		err = out.flush()
+	}
+	if err != nil {
+		return stream.Send(&resourcepb.ExportResponse{Error: resource.AsErrorResult(err)})
+	}
+	return stream.Send(&resourcepb.ExportResponse{Summary: rsp.Summary})
+}
+
+// Import implements resourcepb.ArchiveStoreServer.
+func (s *archiveServer) Import(stream resourcepb.ArchiveStore_ImportServer) error {
+	rsp, err := s.importArchive(stream)
+	if status.Code(err) == codes.ResourceExhausted {
+		return err
+	}
+	if err != nil {
+		rsp.Error = resource.AsErrorResult(err)
+	}
+	return stream.SendAndClose(rsp)
+}
+
+func (s *archiveServer) importArchive(stream resourcepb.ArchiveStore_ImportServer) (*resourcepb.ImportResponse, error) {
+	rsp := &resourcepb.ImportResponse{}
+
+	// The archive is read more than once, so it is saved before it is imported
+	file, err := os.CreateTemp("", "grafana-archive-import-*.parquet")
+	if err != nil {
+		return rsp, err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	var options *resourcepb.ImportRequest_Options
+	var size int64
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return rsp, err
+		}
+		if first {
+			options = req.GetOptions()
+		}
+		size += int64(len(req.Chunk))
+		if size > s.maxImportSize {
+			_ = file.Close()
+			return rsp, status.Errorf(codes.ResourceExhausted, "the archive is larger than the maximum import size of %d bytes", s.maxImportSize)
+		}
+		if _, err = file.Write(req.Chunk); err != nil {
+			_ = file.Close()
+			return rsp, err
+		}
+	}
+	if err = file.Close(); err != nil {
+		return rsp, err
+	}
+
+	resources, err := ParseGroupResources(options.GetKinds())
+	if err != nil {
+		return rsp, err
+	}
+	result, err := ImportNamespace(stream.Context(), s.client, file.Name(), ImportOptions{
+		Namespace: options.GetNamespace(),
+		Resources: resources,
+		Conflict:  ImportConflictPolicy(options.GetConflict()),
+		DryRun:    options.GetDryRun(),
+	})
+	if result != nil {
+		rsp.Namespace = result.Namespace
+		rsp.DryRun = result.DryRun
+		rsp.Bulk = result.Response
+		for _, summary := range result.Summary {
+			rsp.Summary = append(rsp.Summary, &resourcepb.ImportResponse_Summary{
+				Group:       summary.Group,
+				Resource:    summary.Resource,
+				Created:     int64(summary.Created),
+				Overwritten: int64(summary.Overwritten),
+				Skipped:     int64(summary.Skipped),
+				Renamed:     int64(summary.Renamed),
+				Kept:        int64(summary.Kept),
+				Removed:     int64(summary.Removed),
+				Versions:    int64(summary.Versions),
+				Blobs:       int64(summary.Blobs),
+			})
+		}
+	}
+	return rsp, err
+}
+
+// ParseGroupResources reads group/resource values, like dashboard.grafana.app/dashboards
+func ParseGroupResources(values []string) ([]schema.GroupResource, error) {
+	var resources []schema.GroupResource
+	for _, v := range values {
+		group, res, ok := strings.Cut(v, "/")
+		if !ok || group == "" || res == "" {
+			return nil, fmt.Errorf("invalid resource %q, expected group/resource", v)
+		}
+		resources = append(resources, schema.GroupResource{Group: group, Resource: res})
+	}
+	return resources, nil
+}
+
+// chunkWriter sends the written bytes in parts of archiveChunkSize
+type chunkWriter struct {
+	buf  []byte
+	send func(chunk []byte) error
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= archiveChunkSize {
+		if err := w.send(w.buf[:archiveChunkSize:archiveChunkSize]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[archiveChunkSize:]
+	}
+	return len(p), nil
+}
+
+// flush sends the remaining bytes
+func (w *chunkWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.send(w.buf)
+	w.buf = nil
+	return err
+}
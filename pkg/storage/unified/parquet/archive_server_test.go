@@ -0,0 +1,155 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func TestArchiveServer(t *testing.T) {
+	source := newFakeResourceClient()
+	source.add(t, "org-1", "aaa", "first", "")
+	source.add(t, "org-1", "aaa", "second", "")
+	source.add(t, "org-1", "bbb", "other", "")
+
+	export := &fakeExportStream{ctx: context.Background()}
+	err := NewArchiveServer(source, archiveChunkSize).Export(&resourcepb.ExportRequest{
+		Namespace:   "org-1",
+		Kinds:       []string{dashboards.Group + "/" + dashboards.Resource},
+		WithHistory: true,
+	}, export)
+	require.NoError(t, err)
+
+	last := export.sent[len(export.sent)-1]
+	require.Nil(t, last.Error)
+	require.Len(t, last.Summary, 1)
+	require.Equal(t, int64(2), last.Summary[0].Count)
+	require.Equal(t, int64(3), last.Summary[0].History)
+
+	var archive bytes.Buffer
+	for _, rsp := range export.sent {
+		archive.Write(rsp.Chunk)
+	}
+	path := filepath.Join(t.TempDir(), "backup.parquet")
+	require.NoError(t, os.WriteFile(path, archive.Bytes(), 0600))
+	reader, err := OpenArchive(context.Background(), path)
+	require.NoError(t, err)
+	require.Equal(t, "org-1", reader.Header().Namespace)
+	require.NoError(t, reader.Close())
+
+	t.Run("export errors", func(t *testing.T) {
+		stream := &fakeExportStream{ctx: context.Background()}
+		err := NewArchiveServer(source, archiveChunkSize).Export(&resourcepb.ExportRequest{Namespace: "org-1", Kinds: []string{"dashboards"}}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.sent, 1)
+		require.NotNil(t, stream.sent[0].Error)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		// the archive is sent in two parts, the options are in the first one
+		half := archive.Len() / 2
+		stream := &fakeImportStream{ctx: context.Background(), requests: []*resourcepb.ImportRequest{{
+			Options: &resourcepb.ImportRequest_Options{Namespace: "org-2", Conflict: "overwrite"},
+			Chunk:   archive.Bytes()[:half],
+		}, {
+			Chunk: archive.Bytes()[half:],
+		}}}
+
+		target := newFakeResourceClient()
+		require.NoError(t, NewArchiveServer(target, archiveChunkSize).Import(stream))
+		require.Nil(t, stream.rsp.Error)
+		require.Equal(t, "org-2", stream.rsp.Namespace)
+		require.Equal(t, []*resourcepb.ImportResponse_Summary{{
+			Group:    dashboards.Group,
+			Resource: dashboards.Resource,
+			Created:  2,
+			Versions: 3,
+		}}, stream.rsp.Summary)
+		require.Nil(t, stream.rsp.Bulk, "only the replace policy uses a bulk request")
+		require.Equal(t, []string{"org-2/aaa/first", "org-2/aaa/second", "org-2/bbb/other"}, target.sent(t))
+	})
+
+	t.Run("import errors", func(t *testing.T) {
+		stream := &fakeImportStream{ctx: context.Background(), requests: []*resourcepb.ImportRequest{{
+			Options: &resourcepb.ImportRequest_Options{Conflict: "merge"},
+			Chunk:   archive.Bytes(),
+		}}}
+		require.NoError(t, NewArchiveServer(newFakeResourceClient(), archiveChunkSize).Import(stream))
+		require.NotNil(t, stream.rsp.Error)
+	})
+
+	t.Run("import size limit", func(t *testing.T) {
+		stream := &fakeImportStream{ctx: context.Background(), requests: []*resourcepb.ImportRequest{{
+			Options: &resourcepb.ImportRequest_Options{Namespace: "org-2"},
+			Chunk:   archive.Bytes(),
+		}}}
+		target := newFakeResourceClient()
+		err := NewArchiveServer(target, int64(archive.Len()-1)).Import(stream)
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.Nil(t, stream.rsp)
+		require.Empty(t, target.requests)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		var chunks []int
+		w := &chunkWriter{send: func(chunk []byte) error {
+			chunks = append(chunks, len(chunk))
+			return nil
+		}}
+		_, err := w.Write(make([]byte, archiveChunkSize/2))
+		require.NoError(t, err)
+		_, err = w.Write(make([]byte, archiveChunkSize*2))
+		require.NoError(t, err)
+		require.NoError(t, w.flush())
+		require.Equal(t, []int{archiveChunkSize, archiveChunkSize, archiveChunkSize / 2}, chunks)
+	})
+}
+
+type fakeExportStream struct {
+	resourcepb.ArchiveStore_ExportServer
+	ctx  context.Context
+	sent []*resourcepb.ExportResponse
+}
+
+func (s *fakeExportStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeExportStream) Send(rsp *resourcepb.ExportResponse) error {
+	s.sent = append(s.sent, rsp)
+	return nil
+}
+
+type fakeImportStream struct {
+	resourcepb.ArchiveStore_ImportServer
+	ctx      context.Context
+	requests []*resourcepb.ImportRequest
+	rsp      *resourcepb.ImportResponse
+}
+
+func (s *fakeImportStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeImportStream) Recv() (*resourcepb.ImportRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeImportStream) SendAndClose(rsp *resourcepb.ImportResponse) error {
+	s.rsp = rsp
+	return nil
+}
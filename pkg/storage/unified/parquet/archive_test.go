@@ -0,0 +1,467 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+var dashboards = schema.GroupResource{Group: "dashboard.grafana.app", Resource: "dashboards"}
+
+func TestArchiveWriteThenRead(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "archive-*.parquet")
+	require.NoError(t, err)
+
+	writer, err := NewArchiveWriter(file, ArchiveHeader{
+		Namespace: "default",
+		History:   true,
+		Resources: []schema.GroupResource{dashboards},
+	})
+	require.NoError(t, err)
+
+	key := &resourcepb.ResourceKey{Namespace: "default", Group: dashboards.Group, Resource: dashboards.Resource, Name: "aaa"}
+	require.NoError(t, writer.Write(&ArchiveRecord{
+		Kind:            ArchiveRecordBlob,
+		Key:             key,
+		ResourceVersion: 10,
+		UID:             "blob-uid",
+		ContentType:     "application/json",
+		Value:           []byte(`{"large":true}`),
+	}))
+	require.NoError(t, writer.Write(&ArchiveRecord{
+		Kind:            ArchiveRecordResource,
+		Key:             key,
+		ResourceVersion: 10,
+		Folder:          "folder",
+		Action:          resourcepb.WatchEvent_MODIFIED,
+		Value:           []byte(`{"metadata":{"name":"aaa"}}`),
+	}))
+	require.NoError(t, writer.Close())
+
+	reader, err := OpenArchive(context.Background(), file.Name())
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	header := reader.Header()
+	require.Equal(t, ArchiveVersion, header.Version)
+	require.Equal(t, "default", header.Namespace)
+	require.True(t, header.History)
+	require.False(t, header.Blobs)
+	require.Equal(t, []schema.GroupResource{dashboards}, header.Resources)
+	require.False(t, header.Created.IsZero())
+
+	var records []*ArchiveRecord
+	for reader.Next() {
+		records = append(records, reader.Record())
+	}
+	require.NoError(t, reader.Err())
+	require.Len(t, records, 2)
+
+	require.Equal(t, ArchiveRecordBlob, records[0].Kind)
+	require.Equal(t, "blob-uid", records[0].UID)
+	require.Equal(t, "application/json", records[0].ContentType)
+	require.Equal(t, `{"large":true}`, string(records[0].Value))
+
+	require.Equal(t, ArchiveRecordResource, records[1].Kind)
+	require.Equal(t, "aaa", records[1].Key.Name)
+	require.Equal(t, int64(10), records[1].ResourceVersion)
+	require.Equal(t, "folder", records[1].Folder)
+	require.Equal(t, resourcepb.WatchEvent_MODIFIED, records[1].Action)
+}
+
+func TestOpenArchiveRejectsBulkFiles(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "temp-*.parquet")
+	require.NoError(t, err)
+
+	writer, err := NewParquetWriter(file)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	_, err = OpenArchive(context.Background(), file.Name())
+	require.Error(t, err)
+}
+
+func TestNamespaceExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	source := newFakeResourceClient()
+	source.add(t, "org-1", "aaa", "first", "")
+	source.add(t, "org-1", "aaa", "second", "")
+	source.add(t, "org-1", "bbb", "large", "blob-1")
+	source.blobs["blob-1"] = []byte("blob value")
+
+	export := func(t *testing.T, opts ExportOptions) string {
+		path := filepath.Join(t.TempDir(), "backup.parquet")
+		out, err := os.Create(path)
+		require.NoError(t, err)
+		defer func() { _ = out.Close() }()
+
+		opts.Namespace = "org-1"
+		rsp, err := ExportNamespace(ctx, source, out, opts)
+		require.NoError(t, err)
+		require.Len(t, rsp.Summary, 1)
+		require.Equal(t, int64(2), rsp.Summary[0].Count)
+		return path
+	}
+
+	t.Run("export current versions", func(t *testing.T) {
+		reader, err := OpenArchive(ctx, export(t, ExportOptions{}))
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }()
+
+		require.Equal(t, []schema.GroupResource{dashboards}, reader.Header().Resources)
+		var names []string
+		for reader.Next() {
+			require.Equal(t, ArchiveRecordResource, reader.Record().Kind)
+			names = append(names, reader.Record().Key.Name)
+		}
+		require.NoError(t, reader.Err())
+		require.Equal(t, []string{"aaa", "bbb"}, names)
+	})
+
+	t.Run("export history and blobs", func(t *testing.T) {
+		reader, err := OpenArchive(ctx, export(t, ExportOptions{WithHistory: true, WithBlobs: true}))
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }()
+
+		var rows []string
+		for reader.Next() {
+			rec := reader.Record()
+			rows = append(rows, fmt.Sprintf("%s/%s", rec.Kind, rec.Key.Name))
+		}
+		require.NoError(t, reader.Err())
+		require.Equal(t, []string{"resource/aaa", "resource/aaa", "blob/bbb", "resource/bbb"}, rows)
+	})
+
+	t.Run("import into a new namespace", func(t *testing.T) {
+		path := export(t, ExportOptions{WithHistory: true, WithBlobs: true})
+
+		target := newFakeResourceClient()
+		res, err := ImportNamespace(ctx, target, path, ImportOptions{Namespace: "org-2"})
+		require.NoError(t, err)
+		require.Equal(t, []*ImportSummary{{
+			Group:    dashboards.Group,
+			Resource: dashboards.Resource,
+			Created:  2,
+			Versions: 3,
+			Blobs:    1,
+		}}, res.Summary)
+
+		require.Equal(t, []string{"org-2/aaa/first", "org-2/aaa/second", "org-2/bbb/large"}, target.sent(t))
+		require.Equal(t, []resourcepb.BulkRequest_Action{
+			resourcepb.BulkRequest_ADDED,
+			resourcepb.BulkRequest_MODIFIED,
+			resourcepb.BulkRequest_ADDED,
+		}, target.actions())
+		require.False(t, target.bulk, "the collection is not rebuilt")
+
+		// the versions are written again, with new resource versions
+		_, updated, err := readObject(target.requests[1].Value)
+		require.NoError(t, err)
+		require.Empty(t, updated.GetResourceVersion())
+		require.Equal(t, int64(2), updated.GetGeneration())
+
+		// the blob annotation points to the uploaded blob
+		obj, meta, err := readObject(target.requests[2].Value)
+		require.NoError(t, err)
+		require.Equal(t, "org-2", obj.GetNamespace())
+		require.Equal(t, "put-1", meta.GetBlob().UID)
+		require.Equal(t, []byte("blob value"), target.blobs["put-1"])
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		target := newFakeResourceClient()
+		res, err := ImportNamespace(ctx, target, export(t, ExportOptions{}), ImportOptions{DryRun: true})
+		require.NoError(t, err)
+		require.True(t, res.DryRun)
+		require.Nil(t, res.Response)
+		require.Equal(t, 2, res.Summary[0].Created)
+		require.Empty(t, target.requests)
+	})
+
+	t.Run("resource filter", func(t *testing.T) {
+		target := newFakeResourceClient()
+		_, err := ImportNamespace(ctx, target, export(t, ExportOptions{}), ImportOptions{
+			Resources: []schema.GroupResource{{Group: "folder.grafana.app", Resource: "folders"}},
+		})
+		require.Error(t, err)
+		require.Empty(t, target.requests)
+	})
+
+	conflicts := func(t *testing.T, conflict ImportConflictPolicy) (*ImportResult, *fakeResourceClient) {
+		target := newFakeResourceClient()
+		target.add(t, "org-1", "aaa", "existing", "")
+		target.add(t, "org-1", "ccc", "other", "")
+
+		res, err := ImportNamespace(ctx, target, export(t, ExportOptions{}), ImportOptions{Conflict: conflict})
+		require.NoError(t, err)
+		return res, target
+	}
+
+	t.Run("conflict skip", func(t *testing.T) {
+		res, target := conflicts(t, ImportConflictSkip)
+		require.Equal(t, 1, res.Summary[0].Created)
+		require.Equal(t, 1, res.Summary[0].Skipped)
+		require.Equal(t, 1, res.Summary[0].Kept)
+		require.Equal(t, []string{"org-1/bbb/large"}, target.sent(t))
+		require.False(t, target.bulk)
+		require.Len(t, target.versions["aaa"], 1, "the existing resource is not written")
+	})
+
+	t.Run("conflict overwrite", func(t *testing.T) {
+		res, target := conflicts(t, ImportConflictOverwrite)
+		require.Equal(t, 1, res.Summary[0].Overwritten)
+		require.Equal(t, 1, res.Summary[0].Kept)
+		require.Equal(t, []string{"org-1/aaa/second", "org-1/bbb/large"}, target.sent(t))
+		require.Equal(t, []resourcepb.BulkRequest_Action{resourcepb.BulkRequest_MODIFIED, resourcepb.BulkRequest_ADDED}, target.actions())
+		require.Len(t, target.versions["aaa"], 2, "the history of the existing resource is kept")
+		require.False(t, target.bulk)
+	})
+
+	t.Run("conflict rename", func(t *testing.T) {
+		res, target := conflicts(t, ImportConflictRename)
+		require.Equal(t, 1, res.Summary[0].Renamed)
+		require.Equal(t, []string{"org-1/aaa-restored/second", "org-1/bbb/large"}, target.sent(t))
+		require.False(t, target.bulk)
+	})
+
+	t.Run("conflict replace", func(t *testing.T) {
+		res, target := conflicts(t, ImportConflictReplace)
+		require.Equal(t, 1, res.Summary[0].Overwritten)
+		require.Equal(t, 1, res.Summary[0].Created)
+		require.Equal(t, 1, res.Summary[0].Removed)
+		require.Equal(t, []string{"org-1/aaa/second", "org-1/bbb/large"}, target.sent(t))
+		require.True(t, target.bulk)
+		require.True(t, target.settings.RebuildCollection)
+		require.Equal(t, "org-1", target.settings.Collection[0].Namespace)
+		require.NotNil(t, res.Response)
+	})
+
+	t.Run("deleted versions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "deleted.parquet")
+		out, err := os.Create(path)
+		require.NoError(t, err)
+		writer, err := NewArchiveWriter(out, ArchiveHeader{
+			Namespace: "org-1",
+			History:   true,
+			Resources: []schema.GroupResource{dashboards},
+		})
+		require.NoError(t, err)
+		for _, action := range []resourcepb.WatchEvent_Type{
+			resourcepb.WatchEvent_ADDED,
+			resourcepb.WatchEvent_DELETED,
+			resourcepb.WatchEvent_ADDED,
+		} {
+			require.NoError(t, writer.Write(&ArchiveRecord{
+				Kind:   ArchiveRecordResource,
+				Key:    &resourcepb.ResourceKey{Namespace: "org-1", Group: dashboards.Group, Resource: dashboards.Resource, Name: "aaa"},
+				Action: action,
+				Value:  []byte(`{"apiVersion":"dashboard.grafana.app/v1","kind":"Dashboard","metadata":{"name":"aaa","namespace":"org-1"}}`),
+			}))
+		}
+		require.NoError(t, writer.Close())
+
+		target := newFakeResourceClient()
+		target.add(t, "org-1", "aaa", "existing", "")
+		_, err = ImportNamespace(ctx, target, path, ImportOptions{Conflict: ImportConflictOverwrite})
+		require.NoError(t, err)
+		require.Equal(t, []resourcepb.BulkRequest_Action{
+			resourcepb.BulkRequest_MODIFIED,
+			resourcepb.BulkRequest_DELETED,
+			resourcepb.BulkRequest_ADDED,
+		}, target.actions())
+		require.False(t, target.bulk)
+
+		_, recreated, err := readObject(target.requests[2].Value)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), recreated.GetGeneration())
+	})
+
+	t.Run("unknown conflict policy", func(t *testing.T) {
+		_, err := ImportNamespace(ctx, newFakeResourceClient(), "unused", ImportOptions{Conflict: "merge"})
+		require.Error(t, err)
+	})
+}
+
+// fakeResourceClient keeps the versions of a single resource type in memory
+type fakeResourceClient struct {
+	resource.ResourceClient
+
+	names    []string
+	versions map[string][][]byte
+	blobs    map[string][]byte
+
+	// the writes, bulk is true when they are sent with BulkProcess
+	bulk     bool
+	settings resource.BulkSettings
+	requests []*resourcepb.BulkRequest
+	rv       int64
+}
+
+func newFakeResourceClient() *fakeResourceClient {
+	return &fakeResourceClient{
+		versions: make(map[string][][]byte),
+		blobs:    make(map[string][]byte),
+	}
+}
+
+func (c *fakeResourceClient) add(t *testing.T, namespace, name, spec, blob string) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": dashboards.Group + "/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"namespace":  namespace,
+			"name":       name,
+			"generation": int64(len(c.versions[name]) + 1),
+		},
+		"spec": map[string]any{"title": spec},
+	}}
+	if blob != "" {
+		meta, err := utils.MetaAccessor(obj)
+		require.NoError(t, err)
+		meta.SetBlob(&utils.BlobInfo{UID: blob})
+	}
+	value, err := obj.MarshalJSON()
+	require.NoError(t, err)
+
+	if c.versions[name] == nil {
+		c.names = append(c.names, name)
+	}
+	c.versions[name] = append(c.versions[name], value)
+}
+
+// sent lists the writes as namespace/name/title
+func (c *fakeResourceClient) sent(t *testing.T) []string {
+	var sent []string
+	for _, req := range c.requests {
+		obj, _, err := readObject(req.Value)
+		require.NoError(t, err)
+		require.Equal(t, obj.GetName(), req.Key.Name)
+		title, _, _ := unstructured.NestedString(obj.Object, "spec", "title")
+		sent = append(sent, fmt.Sprintf("%s/%s/%s", req.Key.Namespace, req.Key.Name, title))
+	}
+	return sent
+}
+
+func (c *fakeResourceClient) actions() []resourcepb.BulkRequest_Action {
+	actions := make([]resourcepb.BulkRequest_Action, 0, len(c.requests))
+	for _, req := range c.requests {
+		actions = append(actions, req.Action)
+	}
+	return actions
+}
+
+func (c *fakeResourceClient) Create(ctx context.Context, in *resourcepb.CreateRequest, opts ...grpc.CallOption) (*resourcepb.CreateResponse, error) {
+	if c.versions[in.Key.Name] != nil {
+		return &resourcepb.CreateResponse{Error: resource.AsErrorResult(fmt.Errorf("already exists"))}, nil
+	}
+	c.names = append(c.names, in.Key.Name)
+	c.versions[in.Key.Name] = [][]byte{in.Value}
+	c.requests = append(c.requests, &resourcepb.BulkRequest{Key: in.Key, Action: resourcepb.BulkRequest_ADDED, Value: in.Value})
+	c.rv++
+	return &resourcepb.CreateResponse{ResourceVersion: c.rv}, nil
+}
+
+func (c *fakeResourceClient) Update(ctx context.Context, in *resourcepb.UpdateRequest, opts ...grpc.CallOption) (*resourcepb.UpdateResponse, error) {
+	if in.ResourceVersion == 0 || c.versions[in.Key.Name] == nil {
+		return &resourcepb.UpdateResponse{Error: resource.AsErrorResult(fmt.Errorf("missing resource version"))}, nil
+	}
+	c.versions[in.Key.Name] = append(c.versions[in.Key.Name], in.Value)
+	c.requests = append(c.requests, &resourcepb.BulkRequest{Key: in.Key, Action: resourcepb.BulkRequest_MODIFIED, Value: in.Value})
+	c.rv++
+	return &resourcepb.UpdateResponse{ResourceVersion: c.rv}, nil
+}
+
+func (c *fakeResourceClient) Delete(ctx context.Context, in *resourcepb.DeleteRequest, opts ...grpc.CallOption) (*resourcepb.DeleteResponse, error) {
+	if in.ResourceVersion == 0 || c.versions[in.Key.Name] == nil {
+		return &resourcepb.DeleteResponse{Error: resource.AsErrorResult(fmt.Errorf("missing resource version"))}, nil
+	}
+	c.names = slices.DeleteFunc(c.names, func(n string) bool { return n == in.Key.Name })
+	delete(c.versions, in.Key.Name)
+	c.requests = append(c.requests, &resourcepb.BulkRequest{Key: in.Key, Action: resourcepb.BulkRequest_DELETED})
+	c.rv++
+	return &resourcepb.DeleteResponse{ResourceVersion: c.rv}, nil
+}
+
+func (c *fakeResourceClient) GetStats(ctx context.Context, in *resourcepb.ResourceStatsRequest, opts ...grpc.CallOption) (*resourcepb.ResourceStatsResponse, error) {
+	return &resourcepb.ResourceStatsResponse{Stats: []*resourcepb.ResourceStatsResponse_Stats{{
+		Group:    dashboards.Group,
+		Resource: dashboards.Resource,
+		Count:    int64(len(c.names)),
+	}}}, nil
+}
+
+func (c *fakeResourceClient) List(ctx context.Context, in *resourcepb.ListRequest, opts ...grpc.CallOption) (*resourcepb.ListResponse, error) {
+	rsp := &resourcepb.ListResponse{}
+	key := in.Options.Key
+	if key.Group != dashboards.Group || key.Resource != dashboards.Resource {
+		return rsp, nil
+	}
+	for _, name := range c.names {
+		versions := c.versions[name]
+		if in.Source == resourcepb.ListRequest_HISTORY {
+			if name != key.Name {
+				continue
+			}
+			for i, v := range versions {
+				rsp.Items = append(rsp.Items, &resourcepb.ResourceWrapper{ResourceVersion: int64(i + 1), Value: v})
+			}
+			continue
+		}
+		rsp.Items = append(rsp.Items, &resourcepb.ResourceWrapper{ResourceVersion: int64(len(versions)), Value: versions[len(versions)-1]})
+	}
+	return rsp, nil
+}
+
+func (c *fakeResourceClient) GetBlob(ctx context.Context, in *resourcepb.GetBlobRequest, opts ...grpc.CallOption) (*resourcepb.GetBlobResponse, error) {
+	value, ok := c.blobs[in.Uid]
+	if !ok {
+		return &resourcepb.GetBlobResponse{Error: resource.NewNotFoundError(in.Resource)}, nil
+	}
+	return &resourcepb.GetBlobResponse{ContentType: "text/plain", Value: value}, nil
+}
+
+func (c *fakeResourceClient) PutBlob(ctx context.Context, in *resourcepb.PutBlobRequest, opts ...grpc.CallOption) (*resourcepb.PutBlobResponse, error) {
+	uid := fmt.Sprintf("put-%d", len(c.blobs)+1)
+	c.blobs[uid] = bytes.Clone(in.Value)
+	return &resourcepb.PutBlobResponse{Uid: uid, Size: int64(len(in.Value)), MimeType: in.ContentType}, nil
+}
+
+func (c *fakeResourceClient) BulkProcess(ctx context.Context, opts ...grpc.CallOption) (resourcepb.BulkStore_BulkProcessClient, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	settings, err := resource.NewBulkSettings(md)
+	if err != nil {
+		return nil, err
+	}
+	c.bulk = true
+	c.settings = settings
+	return &fakeBulkStream{client: c}, nil
+}
+
+type fakeBulkStream struct {
+	resourcepb.BulkStore_BulkProcessClient
+	client *fakeResourceClient
+}
+
+func (s *fakeBulkStream) Send(req *resourcepb.BulkRequest) error {
+	s.client.requests = append(s.client.requests, req)
+	return nil
+}
+
+func (s *fakeBulkStream) CloseAndRecv() (*resourcepb.BulkResponse, error) {
+	return &resourcepb.BulkResponse{Processed: int64(len(s.client.requests))}, nil
+}
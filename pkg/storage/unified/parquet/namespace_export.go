@@ -0,0 +1,276 @@
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const exportPageSize = 100
+
+// ExportOptions configures ExportNamespace
+type ExportOptions struct {
+	Namespace string
+
+	// The resource types to export, all of the namespace when empty
+	Resources []schema.GroupResource
+
+	// Include the previous versions of each resource.
+	// The deleted resources are not exported, with or without history.
+	WithHistory bool
+
+	// Include the large objects referenced by the resources
+	WithBlobs bool
+
+	// Called as resources are exported
+	Progress func(count int, msg string)
+}
+
+// ExportNamespace writes every resource of a namespace into an archive.
+// The returned summary has one entry for each exported resource type.
+func ExportNamespace(ctx context.Context, client resource.ResourceClient, out io.Writer, opts ExportOptions) (*resourcepb.BulkResponse, error) {
+	if opts.Namespace == "" {
+		return nil, fmt.Errorf("missing namespace")
+	}
+	if opts.Progress == nil {
+		opts.Progress = func(int, string) {}
+	}
+
+	resources := opts.Resources
+	if len(resources) == 0 {
+		var err error
+		resources, err = namespaceResources(ctx, client, opts.Namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer, err := NewArchiveWriter(out, ArchiveHeader{
+		Namespace: opts.Namespace,
+		History:   opts.WithHistory,
+		Blobs:     opts.WithBlobs,
+		Resources: resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &exporter{
+		ctx:    ctx,
+		client: client,
+		writer: writer,
+		opts:   opts,
+		rsp:    &resourcepb.BulkResponse{},
+	}
+	for _, gr := range resources {
+		if err = e.exportResource(gr); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		_ = writer.Close()
+		return e.rsp, err
+	}
+	return e.rsp, writer.Close()
+}
+
+// namespaceResources lists the resource types that have values in the namespace
+func namespaceResources(ctx context.Context, client resource.ResourceClient, namespace string) ([]schema.GroupResource, error) {
+	stats, err := client.GetStats(ctx, &resourcepb.ResourceStatsRequest{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	if stats.Error != nil {
+		return nil, resource.GetError(stats.Error)
+	}
+	resources := make([]schema.GroupResource, 0, len(stats.Stats))
+	for _, s := range stats.Stats {
+		if s.Count > 0 {
+			resources = append(resources, schema.GroupResource{Group: s.Group, Resource: s.Resource})
+		}
+	}
+	slices.SortFunc(resources, func(a, b schema.GroupResource) int {
+		if a.Group != b.Group {
+			return compareStrings(a.Group, b.Group)
+		}
+		return compareStrings(a.Resource, b.Resource)
+	})
+	return resources, nil
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+type exporter struct {
+	ctx    context.Context
+	client resource.ResourceClient
+	writer *ArchiveWriter
+	opts   ExportOptions
+	rsp    *resourcepb.BulkResponse
+	count  int
+}
+
+func (e *exporter) exportResource(gr schema.GroupResource) error {
+	summary := &resourcepb.BulkResponse_Summary{
+		Namespace: e.opts.Namespace,
+		Group:     gr.Group,
+		Resource:  gr.Resource,
+	}
+	e.rsp.Summary = append(e.rsp.Summary, summary)
+
+	key := &resourcepb.ResourceKey{
+		Namespace: e.opts.Namespace,
+		Group:     gr.Group,
+		Resource:  gr.Resource,
+	}
+	return listAll(e.ctx, e.client, &resourcepb.ListRequest{
+		Options: &resourcepb.ListOptions{Key: key},
+	}, func(item *resourcepb.ResourceWrapper) error {
+		summary.Count++
+		if summary.ResourceVersion < item.ResourceVersion {
+			summary.ResourceVersion = item.ResourceVersion
+		}
+		if !e.opts.WithHistory {
+			return e.writeResource(key, item)
+		}
+
+		// The history includes the current version
+		obj, _, err := readObject(item.Value)
+		if err != nil {
+			return err
+		}
+		return listAll(e.ctx, e.client, &resourcepb.ListRequest{
+			Source: resourcepb.ListRequest_HISTORY,
+			// oldest first
+			VersionMatchV2: resourcepb.ResourceVersionMatchV2_NotOlderThan,
+			Options: &resourcepb.ListOptions{Key: &resourcepb.ResourceKey{
+				Namespace: key.Namespace,
+				Group:     key.Group,
+				Resource:  key.Resource,
+				Name:      obj.GetName(),
+			}},
+		}, func(version *resourcepb.ResourceWrapper) error {
+			summary.History++
+			return e.writeResource(key, version)
+		})
+	})
+}
+
+func (e *exporter) writeResource(collection *resourcepb.ResourceKey, item *resourcepb.ResourceWrapper) error {
+	obj, meta, err := readObject(item.Value)
+	if err != nil {
+		return err
+	}
+	key := &resourcepb.ResourceKey{
+		Namespace: collection.Namespace,
+		Group:     collection.Group,
+		Resource:  collection.Resource,
+		Name:      obj.GetName(),
+	}
+
+	if blob := meta.GetBlob(); blob != nil && e.opts.WithBlobs {
+		if err := e.writeBlob(key, item.ResourceVersion, blob.UID); err != nil {
+			return err
+		}
+	}
+
+	err = e.writer.Write(&ArchiveRecord{
+		Kind:            ArchiveRecordResource,
+		Key:             key,
+		ResourceVersion: item.ResourceVersion,
+		Folder:          meta.GetFolder(),
+		Action:          writeAction(meta),
+		Value:           item.Value,
+	})
+	if err != nil {
+		return err
+	}
+	e.count++
+	e.opts.Progress(e.count, fmt.Sprintf("%s/%s/%s", key.Group, key.Resource, key.Name))
+	return nil
+}
+
+func (e *exporter) writeBlob(key *resourcepb.ResourceKey, rv int64, uid string) error {
+	rsp, err := e.client.GetBlob(e.ctx, &resourcepb.GetBlobRequest{
+		Resource:        key,
+		ResourceVersion: rv,
+		Uid:             uid,
+		MustProxyBytes:  true,
+	})
+	if err != nil {
+		return err
+	}
+	if rsp.Error != nil {
+		return fmt.Errorf("get blob %s for %s: %w", uid, key.Name, resource.GetError(rsp.Error))
+	}
+	return e.writer.Write(&ArchiveRecord{
+		Kind:            ArchiveRecordBlob,
+		Key:             key,
+		ResourceVersion: rv,
+		UID:             uid,
+		ContentType:     rsp.ContentType,
+		Value:           rsp.Value,
+	})
+}
+
+// listAll calls the callback for every item, following the continue tokens
+func listAll(ctx context.Context, client resource.ResourceClient, req *resourcepb.ListRequest, cb func(*resourcepb.ResourceWrapper) error) error {
+	req.Limit = exportPageSize
+	for {
+		rsp, err := client.List(ctx, req)
+		if err != nil {
+			return err
+		}
+		if rsp.Error != nil {
+			return resource.GetError(rsp.Error)
+		}
+		for _, item := range rsp.Items {
+			if err := cb(item); err != nil {
+				return err
+			}
+		}
+		if rsp.NextPageToken == "" {
+			return nil
+		}
+		req.NextPageToken = rsp.NextPageToken
+	}
+}
+
+func readObject(value []byte) (*unstructured.Unstructured, utils.GrafanaMetaAccessor, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(value); err != nil {
+		return nil, nil, err
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, meta, nil
+}
+
+// writeAction guesses the write action from the generation, like the parquet writer
+func writeAction(meta utils.GrafanaMetaAccessor) resourcepb.WatchEvent_Type {
+	switch meta.GetGeneration() {
+	case 0, 1:
+		return resourcepb.WatchEvent_ADDED
+	case utils.DeletedGeneration:
+		return resourcepb.WatchEvent_DELETED
+	default:
+		return resourcepb.WatchEvent_MODIFIED
+	}
+}
@@ -0,0 +1,512 @@
+package parquet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// ImportConflictPolicy decides what happens when an archived resource already exists
+type ImportConflictPolicy string
+
+const (
+	// ImportConflictSkip keeps the existing resource
+	ImportConflictSkip ImportConflictPolicy = "skip"
+	// ImportConflictOverwrite updates the existing resource with the archived versions
+	ImportConflictOverwrite ImportConflictPolicy = "overwrite"
+	// ImportConflictRename keeps the existing resource and imports the archived one under a new name
+	ImportConflictRename ImportConflictPolicy = "rename"
+	// ImportConflictReplace rebuilds the whole collection from the archive. The existing
+	// resources and their history, including the deleted ones, are removed.
+	ImportConflictReplace ImportConflictPolicy = "replace"
+)
+
+// ParseImportConflictPolicy validates a conflict policy, skip is the default
+func ParseImportConflictPolicy(v string) (ImportConflictPolicy, error) {
+	switch p := ImportConflictPolicy(v); p {
+	case "":
+		return ImportConflictSkip, nil
+	case ImportConflictSkip, ImportConflictOverwrite, ImportConflictRename, ImportConflictReplace:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %q (expected skip, overwrite, rename or replace)", v)
+}
+
+// The suffix added to the names of renamed resources
+const renameSuffix = "-restored"
+
+// ImportOptions configures ImportNamespace
+type ImportOptions struct {
+	// The target namespace, defaults to the namespace of the archive
+	Namespace string
+
+	// Only import these resource types, everything in the archive when empty
+	Resources []schema.GroupResource
+
+	// What to do with resources that already exist
+	Conflict ImportConflictPolicy
+
+	// Only report what would be imported
+	DryRun bool
+
+	// Called as resources are imported
+	Progress func(count int, msg string)
+}
+
+// ImportSummary counts the changes to a single resource type
+type ImportSummary struct {
+	Group    string
+	Resource string
+
+	// Number of resources by outcome
+	Created     int
+	Overwritten int
+	Skipped     int
+	Renamed     int
+	// Existing resources that are not in the archive
+	Kept int
+	// Existing resources removed by the replace policy
+	Removed int
+
+	// Number of imported versions and blobs
+	Versions int
+	Blobs    int
+}
+
+// ImportResult is returned by ImportNamespace
+type ImportResult struct {
+	Namespace string
+	DryRun    bool
+	Summary   []*ImportSummary
+
+	// The bulk response of the replace policy, nil otherwise
+	Response *resourcepb.BulkResponse
+}
+
+// ImportNamespace restores an archive written by ExportNamespace. The archived
+// versions are written with regular create, update and delete requests, so the
+// existing resources are not modified unless they are overwritten. Only the replace
+// policy rebuilds each imported resource type with a single bulk request.
+func ImportNamespace(ctx context.Context, client resource.ResourceClient, inputPath string, opts ImportOptions) (*ImportResult, error) {
+	conflict, err := ParseImportConflictPolicy(string(opts.Conflict))
+	if err != nil {
+		return nil, err
+	}
+	opts.Conflict = conflict
+	if opts.Progress == nil {
+		opts.Progress = func(int, string) {}
+	}
+
+	reader, err := OpenArchive(ctx, inputPath)
+	if err != nil {
+		return nil, err
+	}
+	header := reader.Header()
+	_ = reader.Close()
+
+	if opts.Namespace == "" {
+		opts.Namespace = header.Namespace
+	}
+	imp := &importer{
+		ctx:         ctx,
+		client:      client,
+		opts:        opts,
+		source:      header.Namespace,
+		collections: make(map[schema.GroupResource]*importCollection),
+		result: &ImportResult{
+			Namespace: opts.Namespace,
+			DryRun:    opts.DryRun,
+		},
+	}
+	for _, gr := range header.Resources {
+		if len(opts.Resources) > 0 && !containsResource(opts.Resources, gr) {
+			continue
+		}
+		summary := &ImportSummary{Group: gr.Group, Resource: gr.Resource}
+		imp.result.Summary = append(imp.result.Summary, summary)
+		imp.resources = append(imp.resources, gr)
+		imp.collections[gr] = &importCollection{
+			summary:  summary,
+			existing: make(map[string]*importState),
+			archived: make(map[string]string),
+			uids:     make(map[string]types.UID),
+			blobs:    make(map[string]*utils.BlobInfo),
+		}
+	}
+	if len(imp.collections) == 0 {
+		return imp.result, fmt.Errorf("no matching resources in the archive")
+	}
+
+	if err := imp.plan(inputPath); err != nil {
+		return imp.result, err
+	}
+	if opts.DryRun {
+		return imp.result, nil
+	}
+	if opts.Conflict == ImportConflictReplace {
+		return imp.result, imp.rebuild(inputPath)
+	}
+	return imp.result, imp.run(inputPath)
+}
+
+func containsResource(list []schema.GroupResource, gr schema.GroupResource) bool {
+	for _, v := range list {
+		if v == gr {
+			return true
+		}
+	}
+	return false
+}
+
+// importState is the latest version of a resource in the target namespace
+type importState struct {
+	resourceVersion int64
+	generation      int64
+}
+
+type importCollection struct {
+	summary *ImportSummary
+
+	// The resources that exist in the target namespace, updated as the archive is written
+	existing map[string]*importState
+
+	// Archived name => name to write, empty when skipped
+	archived map[string]string
+
+	// The new uid of renamed resources
+	uids map[string]types.UID
+
+	// Archived blob uid => the uploaded blob
+	blobs map[string]*utils.BlobInfo
+}
+
+type importer struct {
+	ctx    context.Context
+	client resource.ResourceClient
+	opts   ImportOptions
+	source string
+	result *ImportResult
+
+	resources   []schema.GroupResource
+	collections map[schema.GroupResource]*importCollection
+	count       int
+}
+
+func (i *importer) key(gr schema.GroupResource, name string) *resourcepb.ResourceKey {
+	return &resourcepb.ResourceKey{
+		Namespace: i.opts.Namespace,
+		Group:     gr.Group,
+		Resource:  gr.Resource,
+		Name:      name,
+	}
+}
+
+// plan reads the existing resources and the archive, to decide what happens to every name
+func (i *importer) plan(inputPath string) error {
+	for _, gr := range i.resources {
+		c := i.collections[gr]
+		err := listAll(i.ctx, i.client, &resourcepb.ListRequest{
+			Options: &resourcepb.ListOptions{Key: i.key(gr, "")},
+		}, func(item *resourcepb.ResourceWrapper) error {
+			obj, _, err := readObject(item.Value)
+			if err != nil {
+				return err
+			}
+			c.existing[obj.GetName()] = &importState{
+				resourceVersion: item.ResourceVersion,
+				generation:      obj.GetGeneration(),
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("list existing %s: %w", gr.String(), err)
+		}
+	}
+
+	// Names used by the archive, so renamed resources do not replace another archived one
+	used := make(map[schema.GroupResource]map[string]bool, len(i.collections))
+	err := i.readArchive(inputPath, func(gr schema.GroupResource, c *importCollection, rec *ArchiveRecord) error {
+		if used[gr] == nil {
+			used[gr] = make(map[string]bool)
+		}
+		used[gr][rec.Key.Name] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = i.readArchive(inputPath, func(gr schema.GroupResource, c *importCollection, rec *ArchiveRecord) error {
+		name, seen := c.archived[rec.Key.Name]
+		if !seen {
+			name = i.decide(c, rec.Key.Name, used[gr])
+			c.archived[rec.Key.Name] = name
+		}
+		if name == "" {
+			return nil
+		}
+		if rec.Kind == ArchiveRecordBlob {
+			c.summary.Blobs++
+		} else {
+			c.summary.Versions++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range i.collections {
+		for name := range c.existing {
+			if _, ok := c.archived[name]; ok {
+				continue
+			}
+			if i.opts.Conflict == ImportConflictReplace {
+				c.summary.Removed++
+			} else {
+				c.summary.Kept++
+			}
+		}
+	}
+	return nil
+}
+
+// decide returns the name to write an archived resource to, or empty to skip it
+func (i *importer) decide(c *importCollection, name string, used map[string]bool) string {
+	if c.existing[name] == nil {
+		c.summary.Created++
+		return name
+	}
+	switch i.opts.Conflict {
+	case ImportConflictOverwrite, ImportConflictReplace:
+		c.summary.Overwritten++
+		return name
+	case ImportConflictRename:
+		c.summary.Renamed++
+		renamed := name + renameSuffix
+		for n := 2; c.existing[renamed] != nil || used[renamed]; n++ {
+			renamed = fmt.Sprintf("%s%s-%d", name, renameSuffix, n)
+		}
+		used[renamed] = true
+		c.uids[name] = types.UID(uuid.NewString())
+		return renamed
+	default:
+		c.summary.Skipped++
+		return ""
+	}
+}
+
+// readArchive calls the callback for every archive record of the imported resource types
+func (i *importer) readArchive(inputPath string, cb func(gr schema.GroupResource, c *importCollection, rec *ArchiveRecord) error) error {
+	reader, err := OpenArchive(i.ctx, inputPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	for reader.Next() {
+		rec := reader.Record()
+		if rec.Key.Namespace != i.source {
+			return fmt.Errorf("unexpected namespace in archive: %s", rec.Key.Namespace)
+		}
+		gr := schema.GroupResource{Group: rec.Key.Group, Resource: rec.Key.Resource}
+		c, ok := i.collections[gr]
+		if !ok {
+			continue
+		}
+		if err := cb(gr, c, rec); err != nil {
+			return err
+		}
+	}
+	return reader.Err()
+}
+
+// run writes the archived versions of the planned names, oldest first
+func (i *importer) run(inputPath string) error {
+	return i.readArchive(inputPath, func(gr schema.GroupResource, c *importCollection, rec *ArchiveRecord) error {
+		name := c.archived[rec.Key.Name]
+		if name == "" {
+			return nil
+		}
+		if rec.Kind == ArchiveRecordBlob {
+			return i.putBlob(gr, c, name, rec)
+		}
+		return i.write(gr, c, rec, name)
+	})
+}
+
+// write creates, updates or deletes the target resource with an archived version
+func (i *importer) write(gr schema.GroupResource, c *importCollection, rec *ArchiveRecord, name string) error {
+	key := i.key(gr, name)
+	current := c.existing[name]
+	if rec.Action == resourcepb.WatchEvent_DELETED {
+		if current == nil {
+			return nil
+		}
+		rsp, err := i.client.Delete(i.ctx, &resourcepb.DeleteRequest{Key: key, ResourceVersion: current.resourceVersion})
+		if err != nil {
+			return fmt.Errorf("delete %s: %w", name, err)
+		}
+		if rsp.Error != nil {
+			return fmt.Errorf("delete %s: %w", name, resource.GetError(rsp.Error))
+		}
+		delete(c.existing, name)
+		i.progress(key)
+		return nil
+	}
+
+	next := &importState{generation: 1}
+	if current != nil {
+		next.generation = current.generation + 1
+	}
+	obj, meta, err := i.rewrite(c, rec, name)
+	if err != nil {
+		return err
+	}
+	meta.SetResourceVersion("")
+	meta.SetGeneration(next.generation)
+	value, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	if current == nil {
+		rsp, err := i.client.Create(i.ctx, &resourcepb.CreateRequest{Key: key, Value: value})
+		if err != nil {
+			return fmt.Errorf("create %s: %w", name, err)
+		}
+		if rsp.Error != nil {
+			return fmt.Errorf("create %s: %w", name, resource.GetError(rsp.Error))
+		}
+		next.resourceVersion = rsp.ResourceVersion
+	} else {
+		rsp, err := i.client.Update(i.ctx, &resourcepb.UpdateRequest{Key: key, Value: value, ResourceVersion: current.resourceVersion})
+		if err != nil {
+			return fmt.Errorf("update %s: %w", name, err)
+		}
+		if rsp.Error != nil {
+			return fmt.Errorf("update %s: %w", name, resource.GetError(rsp.Error))
+		}
+		next.resourceVersion = rsp.ResourceVersion
+	}
+	c.existing[name] = next
+	i.progress(key)
+	return nil
+}
+
+// rebuild replaces the imported resource types with the archive, using a single bulk request
+func (i *importer) rebuild(inputPath string) error {
+	settings := resource.BulkSettings{RebuildCollection: true}
+	for _, gr := range i.resources {
+		settings.Collection = append(settings.Collection, i.key(gr, ""))
+	}
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(i.ctx, settings.ToMD()))
+	defer cancel()
+
+	stream, err := i.client.BulkProcess(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = i.readArchive(inputPath, func(gr schema.GroupResource, c *importCollection, rec *ArchiveRecord) error {
+		name := c.archived[rec.Key.Name]
+		if rec.Kind == ArchiveRecordBlob {
+			return i.putBlob(gr, c, name, rec)
+		}
+		obj, _, err := i.rewrite(c, rec, name)
+		if err != nil {
+			return err
+		}
+		value, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		key := i.key(gr, name)
+		err = stream.Send(&resourcepb.BulkRequest{
+			Key:    key,
+			Action: resourcepb.BulkRequest_Action(rec.Action),
+			Folder: rec.Folder,
+			Value:  value,
+		})
+		if err != nil {
+			return err
+		}
+		i.progress(key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rsp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	i.result.Response = rsp
+	if rsp.Error != nil {
+		return resource.GetError(rsp.Error)
+	}
+	if len(rsp.Rejected) > 0 {
+		return fmt.Errorf("%d resources were rejected", len(rsp.Rejected))
+	}
+	return nil
+}
+
+func (i *importer) putBlob(gr schema.GroupResource, c *importCollection, name string, rec *ArchiveRecord) error {
+	if c.blobs[rec.UID] != nil {
+		return nil
+	}
+	rsp, err := i.client.PutBlob(i.ctx, &resourcepb.PutBlobRequest{
+		Resource:    i.key(gr, name),
+		Method:      resourcepb.PutBlobRequest_GRPC,
+		ContentType: rec.ContentType,
+		Value:       rec.Value,
+	})
+	if err != nil {
+		return err
+	}
+	if rsp.Error != nil {
+		return fmt.Errorf("put blob %s for %s: %w", rec.UID, name, resource.GetError(rsp.Error))
+	}
+	c.blobs[rec.UID] = &utils.BlobInfo{
+		UID:      rsp.Uid,
+		Size:     rsp.Size,
+		Hash:     rsp.Hash,
+		MimeType: rsp.MimeType,
+		Charset:  rsp.Charset,
+	}
+	return nil
+}
+
+// rewrite moves the archived value to the target namespace and name
+func (i *importer) rewrite(c *importCollection, rec *ArchiveRecord, name string) (*unstructured.Unstructured, utils.GrafanaMetaAccessor, error) {
+	obj, meta, err := readObject(rec.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta.SetNamespace(i.opts.Namespace)
+	if name != rec.Key.Name {
+		meta.SetName(name)
+		meta.SetUID(c.uids[rec.Key.Name])
+	}
+	if blob := meta.GetBlob(); blob != nil {
+		if uploaded := c.blobs[blob.UID]; uploaded != nil {
+			meta.SetBlob(uploaded)
+		}
+	}
+	return obj, meta, nil
+}
+
+func (i *importer) progress(key *resourcepb.ResourceKey) {
+	i.count++
+	i.opts.Progress(i.count, fmt.Sprintf("%s/%s/%s", key.Group, key.Resource, key.Name))
+}
@@ -7,8 +7,6 @@ import (
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
 	"github.com/apache/arrow-go/v18/arrow/memory"
-	"github.com/apache/arrow-go/v18/parquet"
-	"github.com/apache/arrow-go/v18/parquet/compress"
 	"github.com/apache/arrow-go/v18/parquet/pqarrow"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
@@ -34,10 +32,7 @@ func NewParquetWriter(f io.Writer) (*parquetWriter, error) {
 		summary: make(map[string]*resourcepb.BulkResponse_Summary),
 	}
 
-	props := parquet.NewWriterProperties(
-		parquet.WithCompression(compress.Codecs.Brotli),
-	)
-	writer, err := pqarrow.NewFileWriter(w.schema, f, props, pqarrow.DefaultWriterProps())
+	writer, err := newFileWriter(w.schema, f)
 	if err != nil {
 		return nil, err
 	}
@@ -116,9 +111,9 @@ func (w *parquetWriter) flush() error {
 	w.logger.Info("flush", "count", w.rv.Len())
 	rec := array.NewRecord(w.schema, []arrow.Array{
 		w.rv.NewArray(),
-		w.namespace.NewArray(),
 		w.group.NewArray(),
 		w.resource.NewArray(),
+		w.namespace.NewArray(),
 		w.name.NewArray(),
 		w.folder.NewArray(),
 		w.action.NewArray(),
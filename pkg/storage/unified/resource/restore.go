@@ -0,0 +1,516 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const (
+	foldersGroup    = "folder.grafana.app"
+	foldersResource = "folders"
+)
+
+var _ resourcepb.RestoreStoreServer = &server{}
+
+// ResourceVersionClock is implemented by the storage backends whose resource versions follow the clock,
+// so a restore can target a point in time
+type ResourceVersionClock interface {
+	// ResourceVersionAt returns the highest resource version that can be written at the given time
+	ResourceVersionAt(t time.Time) int64
+}
+
+func validateRestoreRequest(r *resourcepb.RestoreRequest) *resourcepb.ErrorResult {
+	if r.Namespace == "" {
+		return NewBadRequestError("missing namespace")
+	}
+	if (r.Group == "") != (r.Resource == "") {
+		return NewBadRequestError("group and resource must be set together")
+	}
+	if r.ResourceVersion < 0 {
+		return NewBadRequestError("invalid resource version")
+	}
+	if r.Timestamp < 0 {
+		return NewBadRequestError("invalid timestamp")
+	}
+	if r.ResourceVersion == 0 && r.Timestamp == 0 {
+		return NewBadRequestError("missing resource version or timestamp")
+	}
+	return nil
+}
+
+// Restore writes the resources that changed since the requested state again, as new versions.
+// The restore is recorded in the history like any other write, so it can be reverted
+// by restoring the previous resource version of the response.
+// Every write is checked against the permissions of the caller before anything is written,
+// so a restore is not applied partially because of a missing permission.
+func (s *server) Restore(ctx context.Context, req *resourcepb.RestoreRequest) (*resourcepb.RestoreResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "storage_server.Restore")
+	defer span.End()
+
+	rsp := &resourcepb.RestoreResponse{DryRun: req.DryRun}
+	if e := validateRestoreRequest(req); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
+	user, ok := claims.AuthInfoFrom(ctx)
+	if !ok || user == nil {
+		rsp.Error = &resourcepb.ErrorResult{
+			Message: "no user found in context",
+			Code:    http.StatusUnauthorized,
+		}
+		return rsp, nil
+	}
+
+	rsp.ResourceVersion = req.ResourceVersion
+	if rsp.ResourceVersion == 0 {
+		clock, ok := s.backend.(ResourceVersionClock)
+		if !ok {
+			rsp.Error = NewBadRequestError("the storage backend does not support restoring to a timestamp, use a resource version")
+			return rsp, nil
+		}
+		rsp.ResourceVersion = clock.ResourceVersionAt(time.UnixMilli(req.Timestamp))
+	}
+
+	resources, err := s.restoreResources(ctx, req)
+	if err != nil {
+		rsp.Error = AsErrorResult(err)
+		return rsp, nil
+	}
+
+	// The folder hierarchy is needed to select a sub tree
+	var subtree map[string]int
+	if req.Folder != "" {
+		subtree, err = s.restoreSubtree(ctx, req.Namespace, req.Folder, rsp)
+		if err != nil {
+			rsp.Error = AsErrorResult(err)
+			return rsp, nil
+		}
+	}
+
+	for _, nr := range resources {
+		changes, err := s.restoreDiff(ctx, nr, subtree, rsp)
+		if err != nil {
+			rsp.Error = AsErrorResult(err)
+			return rsp, nil
+		}
+		rsp.Changes = append(rsp.Changes, changes...)
+	}
+	sortRestoreChanges(rsp.Changes, subtree)
+
+	if e := s.restoreAuthorize(ctx, user, rsp.Changes); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
+	if req.DryRun {
+		return rsp, nil
+	}
+	for _, change := range rsp.Changes {
+		s.restoreWrite(ctx, change, rsp.ResourceVersion)
+		if change.Error != nil && rsp.Error == nil {
+			rsp.Error = &resourcepb.ErrorResult{
+				Code:    change.Error.Code,
+				Message: fmt.Sprintf("failed to restore %s/%s: %s", change.Key.Resource, change.Key.Name, change.Error.Message),
+			}
+		}
+	}
+	return rsp, nil
+}
+
+// restoreResources lists the resource types to restore
+func (s *server) restoreResources(ctx context.Context, req *resourcepb.RestoreRequest) ([]NamespacedResource, error) {
+	if req.Group != "" {
+		return []NamespacedResource{{Namespace: req.Namespace, Group: req.Group, Resource: req.Resource}}, nil
+	}
+	stats, err := s.backend.GetResourceStats(ctx, req.Namespace, 0)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]NamespacedResource, 0, len(stats))
+	for _, v := range stats {
+		resources = append(resources, v.NamespacedResource)
+	}
+	return resources, nil
+}
+
+// restoreSubtree returns the depth of every folder below the root, in the
+// current hierarchy or in the one that is restored
+func (s *server) restoreSubtree(ctx context.Context, namespace string, root string, rsp *resourcepb.RestoreResponse) (map[string]int, error) {
+	key := &resourcepb.ResourceKey{Namespace: namespace, Group: foldersGroup, Resource: foldersResource}
+	parents := map[string][]string{}
+	for _, rv := range []int64{0, rsp.ResourceVersion} {
+		items, _, err := s.restoreList(ctx, key, rv)
+		if err != nil {
+			return nil, err
+		}
+		for name, item := range items {
+			parent := item.meta.GetFolder()
+			if !slices.Contains(parents[parent], name) {
+				parents[parent] = append(parents[parent], name)
+			}
+		}
+	}
+
+	subtree := map[string]int{root: 0}
+	queue := []string{root}
+	for len(queue) > 0 {
+		folder := queue[0]
+		queue = queue[1:]
+		for _, child := range parents[folder] {
+			if _, ok := subtree[child]; !ok {
+				subtree[child] = subtree[folder] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+	return subtree, nil
+}
+
+type restoreItem struct {
+	rv   int64
+	obj  *unstructured.Unstructured
+	meta utils.GrafanaMetaAccessor
+}
+
+// restoreList reads a collection, at the given resource version or the latest one
+func (s *server) restoreList(ctx context.Context, key *resourcepb.ResourceKey, rv int64) (map[string]*restoreItem, int64, error) {
+	items := map[string]*restoreItem{}
+	req := &resourcepb.ListRequest{
+		ResourceVersion: rv,
+		Limit:           500,
+		Options:         &resourcepb.ListOptions{Key: key},
+	}
+	for {
+		rsp, err := s.List(ctx, req)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rsp.Error != nil {
+			return nil, 0, GetError(rsp.Error)
+		}
+		for _, v := range rsp.Items {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(v.Value); err != nil {
+				return nil, 0, err
+			}
+			meta, err := utils.MetaAccessor(obj)
+			if err != nil {
+				return nil, 0, err
+			}
+			items[obj.GetName()] = &restoreItem{rv: v.ResourceVersion, obj: obj, meta: meta}
+		}
+		if rsp.NextPageToken == "" {
+			return items, rsp.ResourceVersion, nil
+		}
+		req.NextPageToken = rsp.NextPageToken
+	}
+}
+
+// restoreDiff compares the current and the restored state of a collection
+func (s *server) restoreDiff(ctx context.Context, nr NamespacedResource, subtree map[string]int, rsp *resourcepb.RestoreResponse) ([]*resourcepb.RestoreResponse_Change, error) {
+	key := &resourcepb.ResourceKey{Namespace: nr.Namespace, Group: nr.Group, Resource: nr.Resource}
+	current, latestRV, err := s.restoreList(ctx, key, 0)
+	if err != nil {
+		return nil, err
+	}
+	if latestRV > rsp.PreviousResourceVersion {
+		rsp.PreviousResourceVersion = latestRV
+	}
+	target, _, err := s.restoreList(ctx, key, rsp.ResourceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	isFolders := nr.Group == foldersGroup && nr.Resource == foldersResource
+	selected := func(name string, item *restoreItem) bool {
+		if item == nil || subtree == nil {
+			return item != nil
+		}
+		if _, ok := subtree[item.meta.GetFolder()]; ok {
+			return true
+		}
+		_, ok := subtree[name]
+		return isFolders && ok
+	}
+
+	var changes []*resourcepb.RestoreResponse_Change
+	for name, item := range target {
+		cur := current[name]
+		if !selected(name, item) && !selected(name, cur) {
+			continue
+		}
+		change := &resourcepb.RestoreResponse_Change{
+			Key:                   &resourcepb.ResourceKey{Namespace: nr.Namespace, Group: nr.Group, Resource: nr.Resource, Name: name},
+			Action:                resourcepb.RestoreResponse_CREATE,
+			Folder:                item.meta.GetFolder(),
+			TargetResourceVersion: item.rv,
+		}
+		if cur != nil {
+			if cur.rv == item.rv {
+				continue
+			}
+			change.Action = resourcepb.RestoreResponse_UPDATE
+			change.CurrentResourceVersion = cur.rv
+			change.Fields = restoreDiffFields(cur.obj, item.obj)
+			if len(change.Fields) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	for name, cur := range current {
+		if target[name] != nil || !selected(name, cur) {
+			continue
+		}
+		// A resource missing at the target may only have lost its older versions
+		key := &resourcepb.ResourceKey{Namespace: nr.Namespace, Group: nr.Group, Resource: nr.Resource, Name: name}
+		pruned, err := s.restoreHistoryPruned(ctx, key, rsp.ResourceVersion)
+		if err != nil {
+			return nil, err
+		}
+		if pruned {
+			return nil, GetError(NewBadRequestError(fmt.Sprintf(
+				"the history of %s/%s before resource version %d has been pruned, it can not be restored",
+				nr.Resource, name, rsp.ResourceVersion)))
+		}
+		changes = append(changes, &resourcepb.RestoreResponse_Change{
+			Key:                    key,
+			Action:                 resourcepb.RestoreResponse_DELETE,
+			Folder:                 cur.meta.GetFolder(),
+			CurrentResourceVersion: cur.rv,
+		})
+	}
+	return changes, nil
+}
+
+// restoreHistoryPruned checks if the versions of a resource written before rv were pruned.
+// The oldest retained version is newer than rv in that case, and it is not the first version.
+func (s *server) restoreHistoryPruned(ctx context.Context, key *resourcepb.ResourceKey, rv int64) (bool, error) {
+	rsp, err := s.List(ctx, &resourcepb.ListRequest{
+		Source:         resourcepb.ListRequest_HISTORY,
+		VersionMatchV2: resourcepb.ResourceVersionMatchV2_NotOlderThan, // oldest first
+		Limit:          1,
+		Options:        &resourcepb.ListOptions{Key: key},
+	})
+	if err != nil {
+		return false, err
+	}
+	if rsp.Error != nil {
+		return false, GetError(rsp.Error)
+	}
+	if len(rsp.Items) == 0 || rsp.Items[0].ResourceVersion <= rv {
+		return false, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(rsp.Items[0].Value); err != nil {
+		return false, err
+	}
+	// The first version of a resource is the only one written with generation 1,
+	// unless only its metadata changed since
+	return obj.GetGeneration() > 1, nil
+}
+
+// The annotations that change on every write
+var restoreIgnoredAnnotations = []string{
+	utils.AnnoKeyUpdatedTimestamp,
+	utils.AnnoKeyUpdatedBy,
+	utils.AnnoKeyMessage,
+	utils.AnnoKeyKubectlLastAppliedConfig,
+}
+
+// restoreDiffFields lists the fields that are different, ignoring the write bookkeeping
+func restoreDiffFields(current, target *unstructured.Unstructured) []string {
+	var fields []string
+	for k := range target.Object {
+		if k != "metadata" && !reflect.DeepEqual(current.Object[k], target.Object[k]) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range current.Object {
+		if _, ok := target.Object[k]; !ok && k != "metadata" {
+			fields = append(fields, k)
+		}
+	}
+	if !reflect.DeepEqual(current.GetLabels(), target.GetLabels()) {
+		fields = append(fields, "metadata.labels")
+	}
+	annotations := func(obj *unstructured.Unstructured) map[string]string {
+		v := obj.GetAnnotations()
+		for _, k := range restoreIgnoredAnnotations {
+			delete(v, k)
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	}
+	if !reflect.DeepEqual(annotations(current), annotations(target)) {
+		fields = append(fields, "metadata.annotations")
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// sortRestoreChanges orders the writes so that folders exist before the resources they contain
+func sortRestoreChanges(changes []*resourcepb.RestoreResponse_Change, subtree map[string]int) {
+	rank := func(c *resourcepb.RestoreResponse_Change) int {
+		folder := c.Key.Group == foldersGroup && c.Key.Resource == foldersResource
+		switch {
+		case folder && c.Action != resourcepb.RestoreResponse_DELETE:
+			return 0
+		case c.Action != resourcepb.RestoreResponse_DELETE:
+			return 1
+		case !folder:
+			return 2
+		default:
+			return 3
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b *resourcepb.RestoreResponse_Change) int {
+		if r := rank(a) - rank(b); r != 0 {
+			return r
+		}
+		// parents are written first, and deleted last
+		switch d := subtree[a.Key.Name] - subtree[b.Key.Name]; {
+		case d != 0 && rank(a) == 0:
+			return d
+		case d != 0 && rank(a) == 3:
+			return -d
+		}
+		if c := strings.Compare(a.Key.Resource, b.Key.Resource); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key.Name, b.Key.Name)
+	})
+}
+
+// restoreAuthorize checks that the caller can make every write of the restore
+func (s *server) restoreAuthorize(ctx context.Context, user claims.AuthInfo, changes []*resourcepb.RestoreResponse_Change) *resourcepb.ErrorResult {
+	var denied int
+	for _, change := range changes {
+		verb := utils.VerbUpdate
+		switch change.Action {
+		case resourcepb.RestoreResponse_CREATE:
+			verb = utils.VerbCreate
+		case resourcepb.RestoreResponse_DELETE:
+			verb = utils.VerbDelete
+		}
+		check, err := s.access.Check(ctx, user, claims.CheckRequest{
+			Verb:      verb,
+			Group:     change.Key.Group,
+			Resource:  change.Key.Resource,
+			Namespace: change.Key.Namespace,
+			Name:      change.Key.Name,
+			Folder:    change.Folder,
+		})
+		if err != nil {
+			return AsErrorResult(err)
+		}
+		if !check.Allowed {
+			change.Error = &resourcepb.ErrorResult{
+				Message: fmt.Sprintf("not allowed to %s this resource", verb),
+				Code:    http.StatusForbidden,
+			}
+			denied++
+		}
+	}
+	if denied > 0 {
+		return &resourcepb.ErrorResult{
+			Message: fmt.Sprintf("not allowed to restore %d resources", denied),
+			Code:    http.StatusForbidden,
+		}
+	}
+	return nil
+}
+
+// restoreWrite writes a single change with the regular write path, so it is checked and saved like any other write
+func (s *server) restoreWrite(ctx context.Context, change *resourcepb.RestoreResponse_Change, rv int64) {
+	if change.Action == resourcepb.RestoreResponse_DELETE {
+		rsp, err := s.Delete(ctx, &resourcepb.DeleteRequest{Key: change.Key, ResourceVersion: change.CurrentResourceVersion})
+		if err != nil {
+			change.Error = AsErrorResult(err)
+		} else if rsp.Error != nil {
+			change.Error = rsp.Error
+		} else {
+			change.ResourceVersion = rsp.ResourceVersion
+		}
+		return
+	}
+
+	read := s.backend.ReadResource(ctx, &resourcepb.ReadRequest{Key: change.Key, ResourceVersion: change.TargetResourceVersion})
+	if read.Error != nil {
+		change.Error = read.Error
+		return
+	}
+	value, err := s.restoreValue(ctx, change, read.Value, rv)
+	if err != nil {
+		change.Error = AsErrorResult(err)
+		return
+	}
+
+	if change.Action == resourcepb.RestoreResponse_CREATE {
+		rsp, err := s.Create(ctx, &resourcepb.CreateRequest{Key: change.Key, Value: value})
+		if err != nil {
+			change.Error = AsErrorResult(err)
+		} else if rsp.Error != nil {
+			change.Error = rsp.Error
+		} else {
+			change.ResourceVersion = rsp.ResourceVersion
+		}
+		return
+	}
+
+	rsp, err := s.Update(ctx, &resourcepb.UpdateRequest{Key: change.Key, Value: value, ResourceVersion: change.CurrentResourceVersion})
+	if err != nil {
+		change.Error = AsErrorResult(err)
+	} else if rsp.Error != nil {
+		change.Error = rsp.Error
+	} else {
+		change.ResourceVersion = rsp.ResourceVersion
+	}
+}
+
+// restoreValue prepares the restored value as a new version
+func (s *server) restoreValue(ctx context.Context, change *resourcepb.RestoreResponse_Change, value []byte, rv int64) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(value); err != nil {
+		return nil, err
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	generation := int64(1)
+	if change.Action == resourcepb.RestoreResponse_UPDATE {
+		current := s.backend.ReadResource(ctx, &resourcepb.ReadRequest{Key: change.Key})
+		if current.Error != nil {
+			return nil, GetError(current.Error)
+		}
+		old := &unstructured.Unstructured{}
+		if err := old.UnmarshalJSON(current.Value); err != nil {
+			return nil, err
+		}
+		generation = old.GetGeneration() + 1
+	}
+
+	user, _ := claims.AuthInfoFrom(ctx)
+	now := time.UnixMilli(s.now())
+	meta.SetResourceVersion("")
+	meta.SetGeneration(generation)
+	meta.SetDeletionTimestamp(nil)
+	meta.SetUpdatedTimestamp(&now)
+	meta.SetUpdatedBy(user.GetUID())
+	meta.SetMessage(fmt.Sprintf("restored to resource version %d", rv))
+	return obj.MarshalJSON()
+}
@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	authlib "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func TestRestoreRequestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *resourcepb.RestoreRequest
+		invalid bool
+	}{
+		{name: "missing namespace", req: &resourcepb.RestoreRequest{ResourceVersion: 1}, invalid: true},
+		{name: "missing version", req: &resourcepb.RestoreRequest{Namespace: "default"}, invalid: true},
+		{name: "group without resource", req: &resourcepb.RestoreRequest{Namespace: "default", Group: "g", ResourceVersion: 1}, invalid: true},
+		{name: "resource version", req: &resourcepb.RestoreRequest{Namespace: "default", ResourceVersion: 1}},
+		{name: "timestamp", req: &resourcepb.RestoreRequest{Namespace: "default", Timestamp: 1_700_000_000_000}},
+		{name: "negative timestamp", req: &resourcepb.RestoreRequest{Namespace: "default", Timestamp: -1}, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRestoreRequest(tt.req)
+			if tt.invalid {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestRestoreDiffFields(t *testing.T) {
+	newObj := func(title string, labels map[string]string, annotations map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"metadata": map[string]any{"name": "a"},
+			"spec":     map[string]any{"title": title},
+		}}
+		obj.SetLabels(labels)
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+
+	current := newObj("a", nil, map[string]string{utils.AnnoKeyUpdatedBy: "user:1"})
+	require.Empty(t, restoreDiffFields(current, newObj("a", nil, nil)), "write bookkeeping is ignored")
+
+	require.Equal(t, []string{"metadata.annotations", "metadata.labels", "spec"}, restoreDiffFields(current,
+		newObj("b", map[string]string{"x": "y"}, map[string]string{utils.AnnoKeyFolder: "f1"})))
+}
+
+func TestSortRestoreChanges(t *testing.T) {
+	change := func(resource, name string, action resourcepb.RestoreResponse_Action) *resourcepb.RestoreResponse_Change {
+		group := "playlist.grafana.app"
+		if resource == foldersResource {
+			group = foldersGroup
+		}
+		return &resourcepb.RestoreResponse_Change{Key: &resourcepb.ResourceKey{Group: group, Resource: resource, Name: name}, Action: action}
+	}
+	changes := []*resourcepb.RestoreResponse_Change{
+		change(foldersResource, "child", resourcepb.RestoreResponse_DELETE),
+		change("playlists", "b", resourcepb.RestoreResponse_DELETE),
+		change(foldersResource, "root", resourcepb.RestoreResponse_DELETE),
+		change("playlists", "a", resourcepb.RestoreResponse_CREATE),
+		change(foldersResource, "child", resourcepb.RestoreResponse_CREATE),
+		change(foldersResource, "root", resourcepb.RestoreResponse_UPDATE),
+	}
+	sortRestoreChanges(changes, map[string]int{"root": 0, "child": 1})
+
+	var order []string
+	for _, c := range changes {
+		order = append(order, c.Action.String()+" "+c.Key.Name)
+	}
+	require.Equal(t, []string{
+		"UPDATE root",
+		"CREATE child",
+		"CREATE a",
+		"DELETE b",
+		"DELETE child",
+		"DELETE root",
+	}, order)
+}
+
+func TestRestoreAuthorize(t *testing.T) {
+	ctx := context.Background()
+	user := &identity.StaticRequester{Namespace: "default"}
+	changes := func() []*resourcepb.RestoreResponse_Change {
+		return []*resourcepb.RestoreResponse_Change{
+			{Key: &resourcepb.ResourceKey{Namespace: "default", Group: "playlist.grafana.app", Resource: "playlists", Name: "a"}, Action: resourcepb.RestoreResponse_CREATE},
+			{Key: &resourcepb.ResourceKey{Namespace: "default", Group: "playlist.grafana.app", Resource: "playlists", Name: "b"}, Action: resourcepb.RestoreResponse_DELETE},
+		}
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		s := &server{access: authlib.FixedAccessClient(true)}
+		c := changes()
+		require.Nil(t, s.restoreAuthorize(ctx, user, c))
+		require.Nil(t, c[0].Error)
+		require.Nil(t, c[1].Error)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		s := &server{access: authlib.FixedAccessClient(false)}
+		c := changes()
+		err := s.restoreAuthorize(ctx, user, c)
+		require.NotNil(t, err)
+		require.Equal(t, int32(http.StatusForbidden), err.Code)
+		require.Equal(t, int32(http.StatusForbidden), c[0].Error.Code)
+		require.Equal(t, int32(http.StatusForbidden), c[1].Error.Code)
+	})
+}
@@ -36,6 +36,7 @@ type ResourceServer interface {
 	resourcepb.ManagedObjectIndexServer
 	resourcepb.BlobStoreServer
 	resourcepb.DiagnosticsServer
+	resourcepb.RestoreStoreServer
 }
 
 type ListIterator interface {
@@ -38,6 +38,7 @@ type kvStorageBackend struct {
 }
 
 var _ StorageBackend = &kvStorageBackend{}
+var _ ResourceVersionClock = &kvStorageBackend{}
 
 func NewKvStorageBackend(kv KV) *kvStorageBackend {
 	s, err := snowflake.NewNode(rand.Int64N(1024))
@@ -57,6 +58,12 @@ func NewKvStorageBackend(kv KV) *kvStorageBackend {
 	}
 }
 
+// ResourceVersionAt returns the highest snowflake ID that can be generated in the millisecond of t.
+func (k *kvStorageBackend) ResourceVersionAt(t time.Time) int64 {
+	shift := snowflake.NodeBits + snowflake.StepBits
+	return (t.UnixMilli()-snowflake.Epoch)<<shift | (1<<shift - 1)
+}
+
 // WriteEvent writes a resource event (create/update/delete) to the storage backend.
 func (k *kvStorageBackend) WriteEvent(ctx context.Context, event WriteEvent) (int64, error) {
 	if err := event.Validate(); err != nil {
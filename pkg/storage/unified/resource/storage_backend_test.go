@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,6 +33,17 @@ func TestNewKvStorageBackend(t *testing.T) {
 	assert.NotNil(t, backend.snowflake)
 }
 
+func TestKvStorageBackend_ResourceVersionAt(t *testing.T) {
+	backend := setupTestStorageBackend(t)
+
+	before := time.Now().Add(-time.Millisecond)
+	rv := backend.snowflake.Generate().Int64()
+	after := time.Now().Add(time.Millisecond)
+
+	require.Less(t, backend.ResourceVersionAt(before), rv)
+	require.GreaterOrEqual(t, backend.ResourceVersionAt(after), rv)
+}
+
 func TestKvStorageBackend_WriteEvent_Success(t *testing.T) {
 	backend := setupTestStorageBackend(t)
 	ctx := context.Background()
@@ -408,6 +408,61 @@ func (ResourceTableColumnDefinition_ColumnType) EnumDescriptor() ([]byte, []int)
 	return file_resource_proto_rawDescGZIP(), []int{28, 0}
 }
 
+type RestoreResponse_Action int32
+
+const (
+	RestoreResponse_UNKNOWN RestoreResponse_Action = 0
+	// Create a resource that was deleted since
+	RestoreResponse_CREATE RestoreResponse_Action = 1
+	// Write the previous value of a resource
+	RestoreResponse_UPDATE RestoreResponse_Action = 2
+	// Delete a resource that was created since
+	RestoreResponse_DELETE RestoreResponse_Action = 3
+)
+
+// Enum value maps for RestoreResponse_Action.
+var (
+	RestoreResponse_Action_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "CREATE",
+		2: "UPDATE",
+		3: "DELETE",
+	}
+	RestoreResponse_Action_value = map[string]int32{
+		"UNKNOWN": 0,
+		"CREATE":  1,
+		"UPDATE":  2,
+		"DELETE":  3,
+	}
+)
+
+func (x RestoreResponse_Action) Enum() *RestoreResponse_Action {
+	p := new(RestoreResponse_Action)
+	*p = x
+	return p
+}
+
+func (x RestoreResponse_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RestoreResponse_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_resource_proto_enumTypes[7].Descriptor()
+}
+
+func (RestoreResponse_Action) Type() protoreflect.EnumType {
+	return &file_resource_proto_enumTypes[7]
+}
+
+func (x RestoreResponse_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RestoreResponse_Action.Descriptor instead.
+func (RestoreResponse_Action) EnumDescriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{31, 0}
+}
+
 type ResourceKey struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Namespace (tenant)
@@ -2484,28 +2539,41 @@ func (x *ResourceTableRow) GetObject() []byte {
 	return nil
 }
 
-type WatchEvent_Resource struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
-	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+// Restore many resources to a previous state
+type RestoreRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The namespace to restore
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Only restore a single resource type, all the types of the namespace when empty
+	Group    string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Resource string `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Only restore the resources in this folder and its sub folders
+	Folder string `protobuf:"bytes,4,opt,name=folder,proto3" json:"folder,omitempty"`
+	// Restore to the state at this resource version
+	ResourceVersion int64 `protobuf:"varint,5,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	// Restore to the state at this time (unix milliseconds), used when the resource version is not set.
+	// This is only supported by the storage backends whose resource versions follow the clock.
+	Timestamp int64 `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Only return the changes, without writing them
+	DryRun        bool `protobuf:"varint,7,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WatchEvent_Resource) Reset() {
-	*x = WatchEvent_Resource{}
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
 	mi := &file_resource_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WatchEvent_Resource) String() string {
+func (x *RestoreRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WatchEvent_Resource) ProtoMessage() {}
+func (*RestoreRequest) ProtoMessage() {}
 
-func (x *WatchEvent_Resource) ProtoReflect() protoreflect.Message {
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_resource_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2517,54 +2585,89 @@ func (x *WatchEvent_Resource) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WatchEvent_Resource.ProtoReflect.Descriptor instead.
-func (*WatchEvent_Resource) Descriptor() ([]byte, []int) {
-	return file_resource_proto_rawDescGZIP(), []int{18, 0}
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *WatchEvent_Resource) GetVersion() int64 {
+func (x *RestoreRequest) GetNamespace() string {
 	if x != nil {
-		return x.Version
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetResourceVersion() int64 {
+	if x != nil {
+		return x.ResourceVersion
 	}
 	return 0
 }
 
-func (x *WatchEvent_Resource) GetValue() []byte {
+func (x *RestoreRequest) GetTimestamp() int64 {
 	if x != nil {
-		return x.Value
+		return x.Timestamp
 	}
-	return nil
+	return 0
 }
 
-type BulkResponse_Summary struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Namespace       string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
-	Group           string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
-	Resource        string                 `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
-	Count           int64                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
-	History         int64                  `protobuf:"varint,5,opt,name=history,proto3" json:"history,omitempty"`
-	ResourceVersion int64                  `protobuf:"varint,6,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"` // The max saved RV
-	// The previous count
-	PreviousCount   int64 `protobuf:"varint,7,opt,name=previous_count,json=previousCount,proto3" json:"previous_count,omitempty"`
-	PreviousHistory int64 `protobuf:"varint,8,opt,name=previous_history,json=previousHistory,proto3" json:"previous_history,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *RestoreRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
 }
 
-func (x *BulkResponse_Summary) Reset() {
-	*x = BulkResponse_Summary{}
+type RestoreResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error details
+	Error *ErrorResult `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	// The restored resource version
+	ResourceVersion int64 `protobuf:"varint,2,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	// The latest resource version before the restore.
+	// Restoring to this version reverts the restore.
+	PreviousResourceVersion int64                     `protobuf:"varint,3,opt,name=previous_resource_version,json=previousResourceVersion,proto3" json:"previous_resource_version,omitempty"`
+	DryRun                  bool                      `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Changes                 []*RestoreResponse_Change `protobuf:"bytes,5,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
 	mi := &file_resource_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BulkResponse_Summary) String() string {
+func (x *RestoreResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BulkResponse_Summary) ProtoMessage() {}
+func (*RestoreResponse) ProtoMessage() {}
 
-func (x *BulkResponse_Summary) ProtoReflect() protoreflect.Message {
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_resource_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2576,91 +2679,76 @@ func (x *BulkResponse_Summary) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BulkResponse_Summary.ProtoReflect.Descriptor instead.
-func (*BulkResponse_Summary) Descriptor() ([]byte, []int) {
-	return file_resource_proto_rawDescGZIP(), []int{20, 0}
-}
-
-func (x *BulkResponse_Summary) GetNamespace() string {
-	if x != nil {
-		return x.Namespace
-	}
-	return ""
-}
-
-func (x *BulkResponse_Summary) GetGroup() string {
-	if x != nil {
-		return x.Group
-	}
-	return ""
-}
-
-func (x *BulkResponse_Summary) GetResource() string {
-	if x != nil {
-		return x.Resource
-	}
-	return ""
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *BulkResponse_Summary) GetCount() int64 {
+func (x *RestoreResponse) GetError() *ErrorResult {
 	if x != nil {
-		return x.Count
+		return x.Error
 	}
-	return 0
+	return nil
 }
 
-func (x *BulkResponse_Summary) GetHistory() int64 {
+func (x *RestoreResponse) GetResourceVersion() int64 {
 	if x != nil {
-		return x.History
+		return x.ResourceVersion
 	}
 	return 0
 }
 
-func (x *BulkResponse_Summary) GetResourceVersion() int64 {
+func (x *RestoreResponse) GetPreviousResourceVersion() int64 {
 	if x != nil {
-		return x.ResourceVersion
+		return x.PreviousResourceVersion
 	}
 	return 0
 }
 
-func (x *BulkResponse_Summary) GetPreviousCount() int64 {
+func (x *RestoreResponse) GetDryRun() bool {
 	if x != nil {
-		return x.PreviousCount
+		return x.DryRun
 	}
-	return 0
+	return false
 }
 
-func (x *BulkResponse_Summary) GetPreviousHistory() int64 {
+func (x *RestoreResponse) GetChanges() []*RestoreResponse_Change {
 	if x != nil {
-		return x.PreviousHistory
+		return x.Changes
 	}
-	return 0
+	return nil
 }
 
-// Collect a few invalid messages
-type BulkResponse_Rejected struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           *ResourceKey           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Action        BulkRequest_Action     `protobuf:"varint,2,opt,name=action,proto3,enum=resource.BulkRequest_Action" json:"action,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+// Export the resources of a namespace into an archive
+type ExportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The namespace to export
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Only export these group/resource types, all the types of the namespace when empty
+	Kinds []string `protobuf:"bytes,2,rep,name=kinds,proto3" json:"kinds,omitempty"`
+	// Include the previous versions of each resource.
+	// The resources that are deleted are not exported.
+	WithHistory bool `protobuf:"varint,3,opt,name=with_history,json=withHistory,proto3" json:"with_history,omitempty"`
+	// Include the large objects referenced by the resources
+	WithBlobs     bool `protobuf:"varint,4,opt,name=with_blobs,json=withBlobs,proto3" json:"with_blobs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BulkResponse_Rejected) Reset() {
-	*x = BulkResponse_Rejected{}
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
 	mi := &file_resource_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BulkResponse_Rejected) String() string {
+func (x *ExportRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BulkResponse_Rejected) ProtoMessage() {}
+func (*ExportRequest) ProtoMessage() {}
 
-func (x *BulkResponse_Rejected) ProtoReflect() protoreflect.Message {
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_resource_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2672,64 +2760,65 @@ func (x *BulkResponse_Rejected) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BulkResponse_Rejected.ProtoReflect.Descriptor instead.
-func (*BulkResponse_Rejected) Descriptor() ([]byte, []int) {
-	return file_resource_proto_rawDescGZIP(), []int{20, 1}
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *BulkResponse_Rejected) GetKey() *ResourceKey {
+func (x *ExportRequest) GetNamespace() string {
 	if x != nil {
-		return x.Key
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
 	}
 	return nil
 }
 
-func (x *BulkResponse_Rejected) GetAction() BulkRequest_Action {
+func (x *ExportRequest) GetWithHistory() bool {
 	if x != nil {
-		return x.Action
+		return x.WithHistory
 	}
-	return BulkRequest_UNKNOWN
+	return false
 }
 
-func (x *BulkResponse_Rejected) GetError() string {
+func (x *ExportRequest) GetWithBlobs() bool {
 	if x != nil {
-		return x.Error
+		return x.WithBlobs
 	}
-	return ""
+	return false
 }
 
-type ListManagedObjectsResponse_Item struct {
+type ExportResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The resource object key
-	Object *ResourceKey `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
-	// Hash for the resource
-	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
-	// Verification hash from the origin
-	Hash string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
-	// Change time from the origin
-	Time int64 `protobuf:"varint,5,opt,name=time,proto3" json:"time,omitempty"`
-	// Title inside the payload
-	Title string `protobuf:"bytes,6,opt,name=title,proto3" json:"title,omitempty"`
-	// The name of the folder in metadata
-	Folder        string `protobuf:"bytes,7,opt,name=folder,proto3" json:"folder,omitempty"`
+	// The next part of the archive
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	// Error details, sent instead of the remaining chunks
+	Error *ErrorResult `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// The exported resource types, sent with the last message
+	Summary       []*BulkResponse_Summary `protobuf:"bytes,3,rep,name=summary,proto3" json:"summary,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListManagedObjectsResponse_Item) Reset() {
-	*x = ListManagedObjectsResponse_Item{}
+func (x *ExportResponse) Reset() {
+	*x = ExportResponse{}
 	mi := &file_resource_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListManagedObjectsResponse_Item) String() string {
+func (x *ExportResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListManagedObjectsResponse_Item) ProtoMessage() {}
+func (*ExportResponse) ProtoMessage() {}
 
-func (x *ListManagedObjectsResponse_Item) ProtoReflect() protoreflect.Message {
+func (x *ExportResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_resource_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2741,78 +2830,57 @@ func (x *ListManagedObjectsResponse_Item) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListManagedObjectsResponse_Item.ProtoReflect.Descriptor instead.
-func (*ListManagedObjectsResponse_Item) Descriptor() ([]byte, []int) {
-	return file_resource_proto_rawDescGZIP(), []int{22, 0}
+// Deprecated: Use ExportResponse.ProtoReflect.Descriptor instead.
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *ListManagedObjectsResponse_Item) GetObject() *ResourceKey {
+func (x *ExportResponse) GetChunk() []byte {
 	if x != nil {
-		return x.Object
+		return x.Chunk
 	}
 	return nil
 }
 
-func (x *ListManagedObjectsResponse_Item) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *ListManagedObjectsResponse_Item) GetHash() string {
-	if x != nil {
-		return x.Hash
-	}
-	return ""
-}
-
-func (x *ListManagedObjectsResponse_Item) GetTime() int64 {
-	if x != nil {
-		return x.Time
-	}
-	return 0
-}
-
-func (x *ListManagedObjectsResponse_Item) GetTitle() string {
+func (x *ExportResponse) GetError() *ErrorResult {
 	if x != nil {
-		return x.Title
+		return x.Error
 	}
-	return ""
+	return nil
 }
 
-func (x *ListManagedObjectsResponse_Item) GetFolder() string {
+func (x *ExportResponse) GetSummary() []*BulkResponse_Summary {
 	if x != nil {
-		return x.Folder
+		return x.Summary
 	}
-	return ""
+	return nil
 }
 
-type CountManagedObjectsResponse_ResourceCount struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
-	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	Group         string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
-	Resource      string                 `protobuf:"bytes,4,opt,name=resource,proto3" json:"resource,omitempty"`
-	Count         int64                  `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+// Import an archive written by Export
+type ImportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The import options, only read from the first message
+	Options *ImportRequest_Options `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	// The next part of the archive
+	Chunk         []byte `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CountManagedObjectsResponse_ResourceCount) Reset() {
-	*x = CountManagedObjectsResponse_ResourceCount{}
+func (x *ImportRequest) Reset() {
+	*x = ImportRequest{}
 	mi := &file_resource_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CountManagedObjectsResponse_ResourceCount) String() string {
+func (x *ImportRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CountManagedObjectsResponse_ResourceCount) ProtoMessage() {}
+func (*ImportRequest) ProtoMessage() {}
 
-func (x *CountManagedObjectsResponse_ResourceCount) ProtoReflect() protoreflect.Message {
+func (x *ImportRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_resource_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2824,28 +2892,465 @@ func (x *CountManagedObjectsResponse_ResourceCount) ProtoReflect() protoreflect.
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CountManagedObjectsResponse_ResourceCount.ProtoReflect.Descriptor instead.
-func (*CountManagedObjectsResponse_ResourceCount) Descriptor() ([]byte, []int) {
-	return file_resource_proto_rawDescGZIP(), []int{24, 0}
+// Deprecated: Use ImportRequest.ProtoReflect.Descriptor instead.
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *CountManagedObjectsResponse_ResourceCount) GetKind() string {
+func (x *ImportRequest) GetOptions() *ImportRequest_Options {
 	if x != nil {
-		return x.Kind
+		return x.Options
 	}
-	return ""
+	return nil
 }
 
-func (x *CountManagedObjectsResponse_ResourceCount) GetId() string {
+func (x *ImportRequest) GetChunk() []byte {
 	if x != nil {
-		return x.Id
+		return x.Chunk
 	}
-	return ""
+	return nil
 }
 
-func (x *CountManagedObjectsResponse_ResourceCount) GetGroup() string {
-	if x != nil {
-		return x.Group
+type ImportResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error details
+	Error     *ErrorResult              `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Namespace string                    `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	DryRun    bool                      `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Summary   []*ImportResponse_Summary `protobuf:"bytes,4,rep,name=summary,proto3" json:"summary,omitempty"`
+	// The response of the bulk request of the replace policy, not set otherwise
+	Bulk          *BulkResponse `protobuf:"bytes,5,opt,name=bulk,proto3" json:"bulk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportResponse) Reset() {
+	*x = ImportResponse{}
+	mi := &file_resource_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportResponse) ProtoMessage() {}
+
+func (x *ImportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportResponse.ProtoReflect.Descriptor instead.
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ImportResponse) GetError() *ErrorResult {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *ImportResponse) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ImportResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportResponse) GetSummary() []*ImportResponse_Summary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *ImportResponse) GetBulk() *BulkResponse {
+	if x != nil {
+		return x.Bulk
+	}
+	return nil
+}
+
+type WatchEvent_Resource struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEvent_Resource) Reset() {
+	*x = WatchEvent_Resource{}
+	mi := &file_resource_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent_Resource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent_Resource) ProtoMessage() {}
+
+func (x *WatchEvent_Resource) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent_Resource.ProtoReflect.Descriptor instead.
+func (*WatchEvent_Resource) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{18, 0}
+}
+
+func (x *WatchEvent_Resource) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WatchEvent_Resource) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type BulkResponse_Summary struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Namespace       string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Group           string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Resource        string                 `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	Count           int64                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	History         int64                  `protobuf:"varint,5,opt,name=history,proto3" json:"history,omitempty"`
+	ResourceVersion int64                  `protobuf:"varint,6,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"` // The max saved RV
+	// The previous count
+	PreviousCount   int64 `protobuf:"varint,7,opt,name=previous_count,json=previousCount,proto3" json:"previous_count,omitempty"`
+	PreviousHistory int64 `protobuf:"varint,8,opt,name=previous_history,json=previousHistory,proto3" json:"previous_history,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BulkResponse_Summary) Reset() {
+	*x = BulkResponse_Summary{}
+	mi := &file_resource_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkResponse_Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkResponse_Summary) ProtoMessage() {}
+
+func (x *BulkResponse_Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkResponse_Summary.ProtoReflect.Descriptor instead.
+func (*BulkResponse_Summary) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{20, 0}
+}
+
+func (x *BulkResponse_Summary) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *BulkResponse_Summary) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *BulkResponse_Summary) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *BulkResponse_Summary) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *BulkResponse_Summary) GetHistory() int64 {
+	if x != nil {
+		return x.History
+	}
+	return 0
+}
+
+func (x *BulkResponse_Summary) GetResourceVersion() int64 {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return 0
+}
+
+func (x *BulkResponse_Summary) GetPreviousCount() int64 {
+	if x != nil {
+		return x.PreviousCount
+	}
+	return 0
+}
+
+func (x *BulkResponse_Summary) GetPreviousHistory() int64 {
+	if x != nil {
+		return x.PreviousHistory
+	}
+	return 0
+}
+
+// Collect a few invalid messages
+type BulkResponse_Rejected struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *ResourceKey           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Action        BulkRequest_Action     `protobuf:"varint,2,opt,name=action,proto3,enum=resource.BulkRequest_Action" json:"action,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkResponse_Rejected) Reset() {
+	*x = BulkResponse_Rejected{}
+	mi := &file_resource_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkResponse_Rejected) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkResponse_Rejected) ProtoMessage() {}
+
+func (x *BulkResponse_Rejected) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkResponse_Rejected.ProtoReflect.Descriptor instead.
+func (*BulkResponse_Rejected) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{20, 1}
+}
+
+func (x *BulkResponse_Rejected) GetKey() *ResourceKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *BulkResponse_Rejected) GetAction() BulkRequest_Action {
+	if x != nil {
+		return x.Action
+	}
+	return BulkRequest_UNKNOWN
+}
+
+func (x *BulkResponse_Rejected) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListManagedObjectsResponse_Item struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The resource object key
+	Object *ResourceKey `protobuf:"bytes,1,opt,name=object,proto3" json:"object,omitempty"`
+	// Hash for the resource
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// Verification hash from the origin
+	Hash string `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Change time from the origin
+	Time int64 `protobuf:"varint,5,opt,name=time,proto3" json:"time,omitempty"`
+	// Title inside the payload
+	Title string `protobuf:"bytes,6,opt,name=title,proto3" json:"title,omitempty"`
+	// The name of the folder in metadata
+	Folder        string `protobuf:"bytes,7,opt,name=folder,proto3" json:"folder,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListManagedObjectsResponse_Item) Reset() {
+	*x = ListManagedObjectsResponse_Item{}
+	mi := &file_resource_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListManagedObjectsResponse_Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListManagedObjectsResponse_Item) ProtoMessage() {}
+
+func (x *ListManagedObjectsResponse_Item) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListManagedObjectsResponse_Item.ProtoReflect.Descriptor instead.
+func (*ListManagedObjectsResponse_Item) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{22, 0}
+}
+
+func (x *ListManagedObjectsResponse_Item) GetObject() *ResourceKey {
+	if x != nil {
+		return x.Object
+	}
+	return nil
+}
+
+func (x *ListManagedObjectsResponse_Item) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ListManagedObjectsResponse_Item) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ListManagedObjectsResponse_Item) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *ListManagedObjectsResponse_Item) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ListManagedObjectsResponse_Item) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
+}
+
+type CountManagedObjectsResponse_ResourceCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Group         string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	Resource      string                 `protobuf:"bytes,4,opt,name=resource,proto3" json:"resource,omitempty"`
+	Count         int64                  `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountManagedObjectsResponse_ResourceCount) Reset() {
+	*x = CountManagedObjectsResponse_ResourceCount{}
+	mi := &file_resource_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountManagedObjectsResponse_ResourceCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountManagedObjectsResponse_ResourceCount) ProtoMessage() {}
+
+func (x *CountManagedObjectsResponse_ResourceCount) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountManagedObjectsResponse_ResourceCount.ProtoReflect.Descriptor instead.
+func (*CountManagedObjectsResponse_ResourceCount) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{24, 0}
+}
+
+func (x *CountManagedObjectsResponse_ResourceCount) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *CountManagedObjectsResponse_ResourceCount) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CountManagedObjectsResponse_ResourceCount) GetGroup() string {
+	if x != nil {
+		return x.Group
 	}
 	return ""
 }
@@ -2886,7 +3391,7 @@ type ResourceTableColumnDefinition_Properties struct {
 
 func (x *ResourceTableColumnDefinition_Properties) Reset() {
 	*x = ResourceTableColumnDefinition_Properties{}
-	mi := &file_resource_proto_msgTypes[35]
+	mi := &file_resource_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2898,7 +3403,7 @@ func (x *ResourceTableColumnDefinition_Properties) String() string {
 func (*ResourceTableColumnDefinition_Properties) ProtoMessage() {}
 
 func (x *ResourceTableColumnDefinition_Properties) ProtoReflect() protoreflect.Message {
-	mi := &file_resource_proto_msgTypes[35]
+	mi := &file_resource_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2949,6 +3454,305 @@ func (x *ResourceTableColumnDefinition_Properties) GetDefaultValue() []byte {
 	return nil
 }
 
+// The restore of a single resource
+type RestoreResponse_Change struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Key    *ResourceKey           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Action RestoreResponse_Action `protobuf:"varint,2,opt,name=action,proto3,enum=resource.RestoreResponse_Action" json:"action,omitempty"`
+	Folder string                 `protobuf:"bytes,3,opt,name=folder,proto3" json:"folder,omitempty"`
+	// The current version, 0 when the resource does not exist
+	CurrentResourceVersion int64 `protobuf:"varint,4,opt,name=current_resource_version,json=currentResourceVersion,proto3" json:"current_resource_version,omitempty"`
+	// The version that is restored, 0 when the resource is deleted
+	TargetResourceVersion int64 `protobuf:"varint,5,opt,name=target_resource_version,json=targetResourceVersion,proto3" json:"target_resource_version,omitempty"`
+	// The fields that are different, like spec or metadata.labels
+	Fields []string `protobuf:"bytes,6,rep,name=fields,proto3" json:"fields,omitempty"`
+	// The version written by the restore
+	ResourceVersion int64 `protobuf:"varint,7,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	// The write error
+	Error         *ErrorResult `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreResponse_Change) Reset() {
+	*x = RestoreResponse_Change{}
+	mi := &file_resource_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreResponse_Change) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResponse_Change) ProtoMessage() {}
+
+func (x *RestoreResponse_Change) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResponse_Change.ProtoReflect.Descriptor instead.
+func (*RestoreResponse_Change) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{31, 0}
+}
+
+func (x *RestoreResponse_Change) GetKey() *ResourceKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RestoreResponse_Change) GetAction() RestoreResponse_Action {
+	if x != nil {
+		return x.Action
+	}
+	return RestoreResponse_UNKNOWN
+}
+
+func (x *RestoreResponse_Change) GetFolder() string {
+	if x != nil {
+		return x.Folder
+	}
+	return ""
+}
+
+func (x *RestoreResponse_Change) GetCurrentResourceVersion() int64 {
+	if x != nil {
+		return x.CurrentResourceVersion
+	}
+	return 0
+}
+
+func (x *RestoreResponse_Change) GetTargetResourceVersion() int64 {
+	if x != nil {
+		return x.TargetResourceVersion
+	}
+	return 0
+}
+
+func (x *RestoreResponse_Change) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *RestoreResponse_Change) GetResourceVersion() int64 {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return 0
+}
+
+func (x *RestoreResponse_Change) GetError() *ErrorResult {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ImportRequest_Options struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The target namespace, defaults to the namespace of the archive
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Only import these group/resource types, everything in the archive when empty
+	Kinds []string `protobuf:"bytes,2,rep,name=kinds,proto3" json:"kinds,omitempty"`
+	// What to do with resources that already exist: skip (default), overwrite, rename or replace
+	Conflict string `protobuf:"bytes,3,opt,name=conflict,proto3" json:"conflict,omitempty"`
+	// Only report what would be imported
+	DryRun        bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRequest_Options) Reset() {
+	*x = ImportRequest_Options{}
+	mi := &file_resource_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRequest_Options) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRequest_Options) ProtoMessage() {}
+
+func (x *ImportRequest_Options) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRequest_Options.ProtoReflect.Descriptor instead.
+func (*ImportRequest_Options) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{34, 0}
+}
+
+func (x *ImportRequest_Options) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ImportRequest_Options) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+func (x *ImportRequest_Options) GetConflict() string {
+	if x != nil {
+		return x.Conflict
+	}
+	return ""
+}
+
+func (x *ImportRequest_Options) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// The changes to a single resource type
+type ImportResponse_Summary struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Group    string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Resource string                 `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Number of resources by outcome
+	Created     int64 `protobuf:"varint,3,opt,name=created,proto3" json:"created,omitempty"`
+	Overwritten int64 `protobuf:"varint,4,opt,name=overwritten,proto3" json:"overwritten,omitempty"`
+	Skipped     int64 `protobuf:"varint,5,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Renamed     int64 `protobuf:"varint,6,opt,name=renamed,proto3" json:"renamed,omitempty"`
+	// Existing resources that are not in the archive
+	Kept int64 `protobuf:"varint,7,opt,name=kept,proto3" json:"kept,omitempty"`
+	// Existing resources removed by the replace policy
+	Removed int64 `protobuf:"varint,8,opt,name=removed,proto3" json:"removed,omitempty"`
+	// Number of imported versions and blobs
+	Versions      int64 `protobuf:"varint,9,opt,name=versions,proto3" json:"versions,omitempty"`
+	Blobs         int64 `protobuf:"varint,10,opt,name=blobs,proto3" json:"blobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportResponse_Summary) Reset() {
+	*x = ImportResponse_Summary{}
+	mi := &file_resource_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportResponse_Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportResponse_Summary) ProtoMessage() {}
+
+func (x *ImportResponse_Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportResponse_Summary.ProtoReflect.Descriptor instead.
+func (*ImportResponse_Summary) Descriptor() ([]byte, []int) {
+	return file_resource_proto_rawDescGZIP(), []int{35, 0}
+}
+
+func (x *ImportResponse_Summary) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ImportResponse_Summary) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ImportResponse_Summary) GetCreated() int64 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetOverwritten() int64 {
+	if x != nil {
+		return x.Overwritten
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetSkipped() int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetRenamed() int64 {
+	if x != nil {
+		return x.Renamed
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetKept() int64 {
+	if x != nil {
+		return x.Kept
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetRemoved() int64 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetVersions() int64 {
+	if x != nil {
+		return x.Versions
+	}
+	return 0
+}
+
+func (x *ImportResponse_Summary) GetBlobs() int64 {
+	if x != nil {
+		return x.Blobs
+	}
+	return 0
+}
+
 var File_resource_proto protoreflect.FileDescriptor
 
 var file_resource_proto_rawDesc = string([]byte{
@@ -3320,68 +4124,200 @@ var file_resource_proto_rawDesc = string([]byte{
 	0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
 	0x12, 0x14, 0x0a, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52,
 	0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x2a, 0x49,
-	0x0a, 0x14, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
-	0x6e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43,
-	0x41, 0x54, 0x45, 0x44, 0x5f, 0x4e, 0x6f, 0x74, 0x4f, 0x6c, 0x64, 0x65, 0x72, 0x54, 0x68, 0x61,
-	0x6e, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45,
-	0x44, 0x5f, 0x45, 0x78, 0x61, 0x63, 0x74, 0x10, 0x01, 0x2a, 0x4d, 0x0a, 0x16, 0x52, 0x65, 0x73,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x74, 0x63,
-	0x68, 0x56, 0x32, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00,
-	0x12, 0x09, 0x0a, 0x05, 0x55, 0x6e, 0x73, 0x65, 0x74, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x45,
-	0x78, 0x61, 0x63, 0x74, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x4f, 0x6c, 0x64,
-	0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x10, 0x03, 0x32, 0xed, 0x02, 0x0a, 0x0d, 0x52, 0x65, 0x73,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x35, 0x0a, 0x04, 0x52, 0x65,
-	0x61, 0x64, 0x12, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65,
-	0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x3b, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x72, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b,
-	0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
-	0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
-	0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
-	0x63, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x37, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x14, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x57, 0x61, 0x74, 0x63,
-	0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x32, 0x4b, 0x0a, 0x09, 0x42, 0x75, 0x6c, 0x6b,
-	0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x3e, 0x0a, 0x0b, 0x42, 0x75, 0x6c, 0x6b, 0x50, 0x72, 0x6f,
-	0x63, 0x65, 0x73, 0x73, 0x12, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e,
-	0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x22, 0xda,
+	0x01, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x66, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22, 0x9d, 0x05, 0x0a, 0x0f,
+	0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x2b, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x29, 0x0a, 0x10,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x19, 0x70, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x6f, 0x75, 0x73, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x3a, 0x0a, 0x07,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52,
+	0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x1a, 0xe5, 0x02, 0x0a, 0x06, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x27, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x38, 0x0a, 0x06,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x72,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x6c, 0x64, 0x65, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x38,
+	0x0a, 0x18, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x16, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x17, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x74, 0x61, 0x72, 0x67, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x22, 0x39, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e,
+	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x52, 0x45, 0x41, 0x54,
+	0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12,
+	0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x03, 0x22, 0x85, 0x01, 0x0a, 0x0d,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6b,
+	0x69, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6b, 0x69, 0x6e, 0x64,
+	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x77, 0x69, 0x74, 0x68, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x77, 0x69, 0x74, 0x68, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x77, 0x69, 0x74, 0x68, 0x5f, 0x62, 0x6c, 0x6f,
+	0x62, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x77, 0x69, 0x74, 0x68, 0x42, 0x6c,
+	0x6f, 0x62, 0x73, 0x22, 0x8d, 0x01, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x2b, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x38, 0x0a, 0x07, 0x73, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d,
+	0x61, 0x72, 0x79, 0x22, 0xd4, 0x01, 0x0a, 0x0d, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x39, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x1a, 0x72, 0x0a, 0x07, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6b, 0x69, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05,
+	0x6b, 0x69, 0x6e, 0x64, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22, 0xea, 0x03, 0x0a, 0x0e, 0x49,
+	0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f,
+	0x72, 0x75, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75,
+	0x6e, 0x12, 0x3a, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x49, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x2a, 0x0a,
+	0x04, 0x62, 0x75, 0x6c, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x72, 0x65,
 	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x28, 0x01, 0x32, 0xd9, 0x01, 0x0a, 0x12, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
-	0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x62, 0x0a, 0x13,
-	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65,
-	0x63, 0x74, 0x73, 0x12, 0x24, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43,
-	0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63,
-	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x72, 0x65, 0x73, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
-	0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x5f, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f,
-	0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x12, 0x23, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a,
-	0x65, 0x63, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x72, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67,
-	0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x32, 0x57, 0x0a, 0x0b, 0x44, 0x69, 0x61, 0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x73,
-	0x12, 0x48, 0x0a, 0x09, 0x49, 0x73, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12, 0x1c, 0x2e,
-	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43,
-	0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x72, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
-	0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69,
-	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61,
-	0x2f, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x74, 0x6f,
-	0x72, 0x61, 0x67, 0x65, 0x2f, 0x75, 0x6e, 0x69, 0x66, 0x69, 0x65, 0x64, 0x2f, 0x72, 0x65, 0x73,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x73, 0x65, 0x52, 0x04, 0x62, 0x75, 0x6c, 0x6b, 0x1a, 0x8b, 0x02, 0x0a, 0x07, 0x53, 0x75,
+	0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x6f, 0x76, 0x65, 0x72, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6f, 0x76, 0x65, 0x72, 0x77, 0x72, 0x69, 0x74,
+	0x74, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x72, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x72, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x70, 0x74, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6b, 0x65, 0x70, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x62, 0x6c, 0x6f, 0x62, 0x73, 0x2a, 0x49, 0x0a, 0x14, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x1b, 0x0a, 0x17, 0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x4e, 0x6f,
+	0x74, 0x4f, 0x6c, 0x64, 0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10,
+	0x44, 0x45, 0x50, 0x52, 0x45, 0x43, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x45, 0x78, 0x61, 0x63, 0x74,
+	0x10, 0x01, 0x2a, 0x4d, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x56, 0x32, 0x12, 0x0b, 0x0a, 0x07,
+	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x6e, 0x73,
+	0x65, 0x74, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x78, 0x61, 0x63, 0x74, 0x10, 0x02, 0x12,
+	0x10, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x4f, 0x6c, 0x64, 0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x10,
+	0x03, 0x32, 0xed, 0x02, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x74,
+	0x6f, 0x72, 0x65, 0x12, 0x35, 0x0a, 0x04, 0x52, 0x65, 0x61, 0x64, 0x12, 0x15, 0x2e, 0x72, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65,
+	0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x12, 0x17, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x17,
+	0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x35, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x72, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x12, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x72, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30,
+	0x01, 0x32, 0x4b, 0x0a, 0x09, 0x42, 0x75, 0x6c, 0x6b, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x3e,
+	0x0a, 0x0b, 0x42, 0x75, 0x6c, 0x6b, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x12, 0x15, 0x2e,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e,
+	0x42, 0x75, 0x6c, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x32, 0xd9,
+	0x01, 0x0a, 0x12, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x62, 0x0a, 0x13, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x61,
+	0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x12, 0x24, 0x2e, 0x72,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x25, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5f, 0x0a, 0x12, 0x4c, 0x69, 0x73,
+	0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x12,
+	0x23, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x64, 0x4f, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x57, 0x0a, 0x0b, 0x44, 0x69,
+	0x61, 0x67, 0x6e, 0x6f, 0x73, 0x74, 0x69, 0x63, 0x73, 0x12, 0x48, 0x0a, 0x09, 0x49, 0x73, 0x48,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12, 0x1c, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e,
+	0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x32, 0x4e, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x74,
+	0x6f, 0x72, 0x65, 0x12, 0x3e, 0x0a, 0x07, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x18,
+	0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x32, 0x8c, 0x01, 0x0a, 0x0c, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x53,
+	0x74, 0x6f, 0x72, 0x65, 0x12, 0x3d, 0x0a, 0x06, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x17,
+	0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x30, 0x01, 0x12, 0x3d, 0x0a, 0x06, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x17, 0x2e,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x28, 0x01, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61, 0x2f, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2f, 0x75, 0x6e, 0x69,
+	0x66, 0x69, 0x65, 0x64, 0x2f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -3396,8 +4332,8 @@ func file_resource_proto_rawDescGZIP() []byte {
 	return file_resource_proto_rawDescData
 }
 
-var file_resource_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
-var file_resource_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_resource_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_resource_proto_msgTypes = make([]protoimpl.MessageInfo, 45)
 var file_resource_proto_goTypes = []any{
 	(ResourceVersionMatch)(0),                         // 0: resource.ResourceVersionMatch
 	(ResourceVersionMatchV2)(0),                       // 1: resource.ResourceVersionMatchV2
@@ -3406,110 +4342,137 @@ var file_resource_proto_goTypes = []any{
 	(BulkRequest_Action)(0),                           // 4: resource.BulkRequest.Action
 	(HealthCheckResponse_ServingStatus)(0),            // 5: resource.HealthCheckResponse.ServingStatus
 	(ResourceTableColumnDefinition_ColumnType)(0),     // 6: resource.ResourceTableColumnDefinition.ColumnType
-	(*ResourceKey)(nil),                               // 7: resource.ResourceKey
-	(*ResourceWrapper)(nil),                           // 8: resource.ResourceWrapper
-	(*ErrorResult)(nil),                               // 9: resource.ErrorResult
-	(*ErrorDetails)(nil),                              // 10: resource.ErrorDetails
-	(*ErrorCause)(nil),                                // 11: resource.ErrorCause
-	(*CreateRequest)(nil),                             // 12: resource.CreateRequest
-	(*CreateResponse)(nil),                            // 13: resource.CreateResponse
-	(*UpdateRequest)(nil),                             // 14: resource.UpdateRequest
-	(*UpdateResponse)(nil),                            // 15: resource.UpdateResponse
-	(*DeleteRequest)(nil),                             // 16: resource.DeleteRequest
-	(*DeleteResponse)(nil),                            // 17: resource.DeleteResponse
-	(*ReadRequest)(nil),                               // 18: resource.ReadRequest
-	(*ReadResponse)(nil),                              // 19: resource.ReadResponse
-	(*Requirement)(nil),                               // 20: resource.Requirement
-	(*ListOptions)(nil),                               // 21: resource.ListOptions
-	(*ListRequest)(nil),                               // 22: resource.ListRequest
-	(*ListResponse)(nil),                              // 23: resource.ListResponse
-	(*WatchRequest)(nil),                              // 24: resource.WatchRequest
-	(*WatchEvent)(nil),                                // 25: resource.WatchEvent
-	(*BulkRequest)(nil),                               // 26: resource.BulkRequest
-	(*BulkResponse)(nil),                              // 27: resource.BulkResponse
-	(*ListManagedObjectsRequest)(nil),                 // 28: resource.ListManagedObjectsRequest
-	(*ListManagedObjectsResponse)(nil),                // 29: resource.ListManagedObjectsResponse
-	(*CountManagedObjectsRequest)(nil),                // 30: resource.CountManagedObjectsRequest
-	(*CountManagedObjectsResponse)(nil),               // 31: resource.CountManagedObjectsResponse
-	(*HealthCheckRequest)(nil),                        // 32: resource.HealthCheckRequest
-	(*HealthCheckResponse)(nil),                       // 33: resource.HealthCheckResponse
-	(*ResourceTable)(nil),                             // 34: resource.ResourceTable
-	(*ResourceTableColumnDefinition)(nil),             // 35: resource.ResourceTableColumnDefinition
-	(*ResourceTableRow)(nil),                          // 36: resource.ResourceTableRow
-	(*WatchEvent_Resource)(nil),                       // 37: resource.WatchEvent.Resource
-	(*BulkResponse_Summary)(nil),                      // 38: resource.BulkResponse.Summary
-	(*BulkResponse_Rejected)(nil),                     // 39: resource.BulkResponse.Rejected
-	(*ListManagedObjectsResponse_Item)(nil),           // 40: resource.ListManagedObjectsResponse.Item
-	(*CountManagedObjectsResponse_ResourceCount)(nil), // 41: resource.CountManagedObjectsResponse.ResourceCount
-	(*ResourceTableColumnDefinition_Properties)(nil),  // 42: resource.ResourceTableColumnDefinition.Properties
+	(RestoreResponse_Action)(0),                       // 7: resource.RestoreResponse.Action
+	(*ResourceKey)(nil),                               // 8: resource.ResourceKey
+	(*ResourceWrapper)(nil),                           // 9: resource.ResourceWrapper
+	(*ErrorResult)(nil),                               // 10: resource.ErrorResult
+	(*ErrorDetails)(nil),                              // 11: resource.ErrorDetails
+	(*ErrorCause)(nil),                                // 12: resource.ErrorCause
+	(*CreateRequest)(nil),                             // 13: resource.CreateRequest
+	(*CreateResponse)(nil),                            // 14: resource.CreateResponse
+	(*UpdateRequest)(nil),                             // 15: resource.UpdateRequest
+	(*UpdateResponse)(nil),                            // 16: resource.UpdateResponse
+	(*DeleteRequest)(nil),                             // 17: resource.DeleteRequest
+	(*DeleteResponse)(nil),                            // 18: resource.DeleteResponse
+	(*ReadRequest)(nil),                               // 19: resource.ReadRequest
+	(*ReadResponse)(nil),                              // 20: resource.ReadResponse
+	(*Requirement)(nil),                               // 21: resource.Requirement
+	(*ListOptions)(nil),                               // 22: resource.ListOptions
+	(*ListRequest)(nil),                               // 23: resource.ListRequest
+	(*ListResponse)(nil),                              // 24: resource.ListResponse
+	(*WatchRequest)(nil),                              // 25: resource.WatchRequest
+	(*WatchEvent)(nil),                                // 26: resource.WatchEvent
+	(*BulkRequest)(nil),                               // 27: resource.BulkRequest
+	(*BulkResponse)(nil),                              // 28: resource.BulkResponse
+	(*ListManagedObjectsRequest)(nil),                 // 29: resource.ListManagedObjectsRequest
+	(*ListManagedObjectsResponse)(nil),                // 30: resource.ListManagedObjectsResponse
+	(*CountManagedObjectsRequest)(nil),                // 31: resource.CountManagedObjectsRequest
+	(*CountManagedObjectsResponse)(nil),               // 32: resource.CountManagedObjectsResponse
+	(*HealthCheckRequest)(nil),                        // 33: resource.HealthCheckRequest
+	(*HealthCheckResponse)(nil),                       // 34: resource.HealthCheckResponse
+	(*ResourceTable)(nil),                             // 35: resource.ResourceTable
+	(*ResourceTableColumnDefinition)(nil),             // 36: resource.ResourceTableColumnDefinition
+	(*ResourceTableRow)(nil),                          // 37: resource.ResourceTableRow
+	(*RestoreRequest)(nil),                            // 38: resource.RestoreRequest
+	(*RestoreResponse)(nil),                           // 39: resource.RestoreResponse
+	(*ExportRequest)(nil),                             // 40: resource.ExportRequest
+	(*ExportResponse)(nil),                            // 41: resource.ExportResponse
+	(*ImportRequest)(nil),                             // 42: resource.ImportRequest
+	(*ImportResponse)(nil),                            // 43: resource.ImportResponse
+	(*WatchEvent_Resource)(nil),                       // 44: resource.WatchEvent.Resource
+	(*BulkResponse_Summary)(nil),                      // 45: resource.BulkResponse.Summary
+	(*BulkResponse_Rejected)(nil),                     // 46: resource.BulkResponse.Rejected
+	(*ListManagedObjectsResponse_Item)(nil),           // 47: resource.ListManagedObjectsResponse.Item
+	(*CountManagedObjectsResponse_ResourceCount)(nil), // 48: resource.CountManagedObjectsResponse.ResourceCount
+	(*ResourceTableColumnDefinition_Properties)(nil),  // 49: resource.ResourceTableColumnDefinition.Properties
+	(*RestoreResponse_Change)(nil),                    // 50: resource.RestoreResponse.Change
+	(*ImportRequest_Options)(nil),                     // 51: resource.ImportRequest.Options
+	(*ImportResponse_Summary)(nil),                    // 52: resource.ImportResponse.Summary
 }
 var file_resource_proto_depIdxs = []int32{
-	10, // 0: resource.ErrorResult.details:type_name -> resource.ErrorDetails
-	11, // 1: resource.ErrorDetails.causes:type_name -> resource.ErrorCause
-	7,  // 2: resource.CreateRequest.key:type_name -> resource.ResourceKey
-	9,  // 3: resource.CreateResponse.error:type_name -> resource.ErrorResult
-	7,  // 4: resource.UpdateRequest.key:type_name -> resource.ResourceKey
-	9,  // 5: resource.UpdateResponse.error:type_name -> resource.ErrorResult
-	7,  // 6: resource.DeleteRequest.key:type_name -> resource.ResourceKey
-	9,  // 7: resource.DeleteResponse.error:type_name -> resource.ErrorResult
-	7,  // 8: resource.ReadRequest.key:type_name -> resource.ResourceKey
-	9,  // 9: resource.ReadResponse.error:type_name -> resource.ErrorResult
-	7,  // 10: resource.ListOptions.key:type_name -> resource.ResourceKey
-	20, // 11: resource.ListOptions.labels:type_name -> resource.Requirement
-	20, // 12: resource.ListOptions.fields:type_name -> resource.Requirement
+	11, // 0: resource.ErrorResult.details:type_name -> resource.ErrorDetails
+	12, // 1: resource.ErrorDetails.causes:type_name -> resource.ErrorCause
+	8,  // 2: resource.CreateRequest.key:type_name -> resource.ResourceKey
+	10, // 3: resource.CreateResponse.error:type_name -> resource.ErrorResult
+	8,  // 4: resource.UpdateRequest.key:type_name -> resource.ResourceKey
+	10, // 5: resource.UpdateResponse.error:type_name -> resource.ErrorResult
+	8,  // 6: resource.DeleteRequest.key:type_name -> resource.ResourceKey
+	10, // 7: resource.DeleteResponse.error:type_name -> resource.ErrorResult
+	8,  // 8: resource.ReadRequest.key:type_name -> resource.ResourceKey
+	10, // 9: resource.ReadResponse.error:type_name -> resource.ErrorResult
+	8,  // 10: resource.ListOptions.key:type_name -> resource.ResourceKey
+	21, // 11: resource.ListOptions.labels:type_name -> resource.Requirement
+	21, // 12: resource.ListOptions.fields:type_name -> resource.Requirement
 	0,  // 13: resource.ListRequest.version_match:type_name -> resource.ResourceVersionMatch
-	21, // 14: resource.ListRequest.options:type_name -> resource.ListOptions
+	22, // 14: resource.ListRequest.options:type_name -> resource.ListOptions
 	2,  // 15: resource.ListRequest.source:type_name -> resource.ListRequest.Source
 	1,  // 16: resource.ListRequest.version_match_v2:type_name -> resource.ResourceVersionMatchV2
-	8,  // 17: resource.ListResponse.items:type_name -> resource.ResourceWrapper
-	9,  // 18: resource.ListResponse.error:type_name -> resource.ErrorResult
-	21, // 19: resource.WatchRequest.options:type_name -> resource.ListOptions
+	9,  // 17: resource.ListResponse.items:type_name -> resource.ResourceWrapper
+	10, // 18: resource.ListResponse.error:type_name -> resource.ErrorResult
+	22, // 19: resource.WatchRequest.options:type_name -> resource.ListOptions
 	3,  // 20: resource.WatchEvent.type:type_name -> resource.WatchEvent.Type
-	37, // 21: resource.WatchEvent.resource:type_name -> resource.WatchEvent.Resource
-	37, // 22: resource.WatchEvent.previous:type_name -> resource.WatchEvent.Resource
-	7,  // 23: resource.BulkRequest.key:type_name -> resource.ResourceKey
+	44, // 21: resource.WatchEvent.resource:type_name -> resource.WatchEvent.Resource
+	44, // 22: resource.WatchEvent.previous:type_name -> resource.WatchEvent.Resource
+	8,  // 23: resource.BulkRequest.key:type_name -> resource.ResourceKey
 	4,  // 24: resource.BulkRequest.action:type_name -> resource.BulkRequest.Action
-	9,  // 25: resource.BulkResponse.error:type_name -> resource.ErrorResult
-	38, // 26: resource.BulkResponse.summary:type_name -> resource.BulkResponse.Summary
-	39, // 27: resource.BulkResponse.rejected:type_name -> resource.BulkResponse.Rejected
-	40, // 28: resource.ListManagedObjectsResponse.items:type_name -> resource.ListManagedObjectsResponse.Item
-	9,  // 29: resource.ListManagedObjectsResponse.error:type_name -> resource.ErrorResult
-	41, // 30: resource.CountManagedObjectsResponse.items:type_name -> resource.CountManagedObjectsResponse.ResourceCount
-	9,  // 31: resource.CountManagedObjectsResponse.error:type_name -> resource.ErrorResult
+	10, // 25: resource.BulkResponse.error:type_name -> resource.ErrorResult
+	45, // 26: resource.BulkResponse.summary:type_name -> resource.BulkResponse.Summary
+	46, // 27: resource.BulkResponse.rejected:type_name -> resource.BulkResponse.Rejected
+	47, // 28: resource.ListManagedObjectsResponse.items:type_name -> resource.ListManagedObjectsResponse.Item
+	10, // 29: resource.ListManagedObjectsResponse.error:type_name -> resource.ErrorResult
+	48, // 30: resource.CountManagedObjectsResponse.items:type_name -> resource.CountManagedObjectsResponse.ResourceCount
+	10, // 31: resource.CountManagedObjectsResponse.error:type_name -> resource.ErrorResult
 	5,  // 32: resource.HealthCheckResponse.status:type_name -> resource.HealthCheckResponse.ServingStatus
-	35, // 33: resource.ResourceTable.columns:type_name -> resource.ResourceTableColumnDefinition
-	36, // 34: resource.ResourceTable.rows:type_name -> resource.ResourceTableRow
+	36, // 33: resource.ResourceTable.columns:type_name -> resource.ResourceTableColumnDefinition
+	37, // 34: resource.ResourceTable.rows:type_name -> resource.ResourceTableRow
 	6,  // 35: resource.ResourceTableColumnDefinition.type:type_name -> resource.ResourceTableColumnDefinition.ColumnType
-	42, // 36: resource.ResourceTableColumnDefinition.properties:type_name -> resource.ResourceTableColumnDefinition.Properties
-	7,  // 37: resource.ResourceTableRow.key:type_name -> resource.ResourceKey
-	7,  // 38: resource.BulkResponse.Rejected.key:type_name -> resource.ResourceKey
-	4,  // 39: resource.BulkResponse.Rejected.action:type_name -> resource.BulkRequest.Action
-	7,  // 40: resource.ListManagedObjectsResponse.Item.object:type_name -> resource.ResourceKey
-	18, // 41: resource.ResourceStore.Read:input_type -> resource.ReadRequest
-	12, // 42: resource.ResourceStore.Create:input_type -> resource.CreateRequest
-	14, // 43: resource.ResourceStore.Update:input_type -> resource.UpdateRequest
-	16, // 44: resource.ResourceStore.Delete:input_type -> resource.DeleteRequest
-	22, // 45: resource.ResourceStore.List:input_type -> resource.ListRequest
-	24, // 46: resource.ResourceStore.Watch:input_type -> resource.WatchRequest
-	26, // 47: resource.BulkStore.BulkProcess:input_type -> resource.BulkRequest
-	30, // 48: resource.ManagedObjectIndex.CountManagedObjects:input_type -> resource.CountManagedObjectsRequest
-	28, // 49: resource.ManagedObjectIndex.ListManagedObjects:input_type -> resource.ListManagedObjectsRequest
-	32, // 50: resource.Diagnostics.IsHealthy:input_type -> resource.HealthCheckRequest
-	19, // 51: resource.ResourceStore.Read:output_type -> resource.ReadResponse
-	13, // 52: resource.ResourceStore.Create:output_type -> resource.CreateResponse
-	15, // 53: resource.ResourceStore.Update:output_type -> resource.UpdateResponse
-	17, // 54: resource.ResourceStore.Delete:output_type -> resource.DeleteResponse
-	23, // 55: resource.ResourceStore.List:output_type -> resource.ListResponse
-	25, // 56: resource.ResourceStore.Watch:output_type -> resource.WatchEvent
-	27, // 57: resource.BulkStore.BulkProcess:output_type -> resource.BulkResponse
-	31, // 58: resource.ManagedObjectIndex.CountManagedObjects:output_type -> resource.CountManagedObjectsResponse
-	29, // 59: resource.ManagedObjectIndex.ListManagedObjects:output_type -> resource.ListManagedObjectsResponse
-	33, // 60: resource.Diagnostics.IsHealthy:output_type -> resource.HealthCheckResponse
-	51, // [51:61] is the sub-list for method output_type
-	41, // [41:51] is the sub-list for method input_type
-	41, // [41:41] is the sub-list for extension type_name
-	41, // [41:41] is the sub-list for extension extendee
-	0,  // [0:41] is the sub-list for field type_name
+	49, // 36: resource.ResourceTableColumnDefinition.properties:type_name -> resource.ResourceTableColumnDefinition.Properties
+	8,  // 37: resource.ResourceTableRow.key:type_name -> resource.ResourceKey
+	10, // 38: resource.RestoreResponse.error:type_name -> resource.ErrorResult
+	50, // 39: resource.RestoreResponse.changes:type_name -> resource.RestoreResponse.Change
+	10, // 40: resource.ExportResponse.error:type_name -> resource.ErrorResult
+	45, // 41: resource.ExportResponse.summary:type_name -> resource.BulkResponse.Summary
+	51, // 42: resource.ImportRequest.options:type_name -> resource.ImportRequest.Options
+	10, // 43: resource.ImportResponse.error:type_name -> resource.ErrorResult
+	52, // 44: resource.ImportResponse.summary:type_name -> resource.ImportResponse.Summary
+	28, // 45: resource.ImportResponse.bulk:type_name -> resource.BulkResponse
+	8,  // 46: resource.BulkResponse.Rejected.key:type_name -> resource.ResourceKey
+	4,  // 47: resource.BulkResponse.Rejected.action:type_name -> resource.BulkRequest.Action
+	8,  // 48: resource.ListManagedObjectsResponse.Item.object:type_name -> resource.ResourceKey
+	8,  // 49: resource.RestoreResponse.Change.key:type_name -> resource.ResourceKey
+	7,  // 50: resource.RestoreResponse.Change.action:type_name -> resource.RestoreResponse.Action
+	10, // 51: resource.RestoreResponse.Change.error:type_name -> resource.ErrorResult
+	19, // 52: resource.ResourceStore.Read:input_type -> resource.ReadRequest
+	13, // 53: resource.ResourceStore.Create:input_type -> resource.CreateRequest
+	15, // 54: resource.ResourceStore.Update:input_type -> resource.UpdateRequest
+	17, // 55: resource.ResourceStore.Delete:input_type -> resource.DeleteRequest
+	23, // 56: resource.ResourceStore.List:input_type -> resource.ListRequest
+	25, // 57: resource.ResourceStore.Watch:input_type -> resource.WatchRequest
+	27, // 58: resource.BulkStore.BulkProcess:input_type -> resource.BulkRequest
+	31, // 59: resource.ManagedObjectIndex.CountManagedObjects:input_type -> resource.CountManagedObjectsRequest
+	29, // 60: resource.ManagedObjectIndex.ListManagedObjects:input_type -> resource.ListManagedObjectsRequest
+	33, // 61: resource.Diagnostics.IsHealthy:input_type -> resource.HealthCheckRequest
+	38, // 62: resource.RestoreStore.Restore:input_type -> resource.RestoreRequest
+	40, // 63: resource.ArchiveStore.Export:input_type -> resource.ExportRequest
+	42, // 64: resource.ArchiveStore.Import:input_type -> resource.ImportRequest
+	20, // 65: resource.ResourceStore.Read:output_type -> resource.ReadResponse
+	14, // 66: resource.ResourceStore.Create:output_type -> resource.CreateResponse
+	16, // 67: resource.ResourceStore.Update:output_type -> resource.UpdateResponse
+	18, // 68: resource.ResourceStore.Delete:output_type -> resource.DeleteResponse
+	24, // 69: resource.ResourceStore.List:output_type -> resource.ListResponse
+	26, // 70: resource.ResourceStore.Watch:output_type -> resource.WatchEvent
+	28, // 71: resource.BulkStore.BulkProcess:output_type -> resource.BulkResponse
+	32, // 72: resource.ManagedObjectIndex.CountManagedObjects:output_type -> resource.CountManagedObjectsResponse
+	30, // 73: resource.ManagedObjectIndex.ListManagedObjects:output_type -> resource.ListManagedObjectsResponse
+	34, // 74: resource.Diagnostics.IsHealthy:output_type -> resource.HealthCheckResponse
+	39, // 75: resource.RestoreStore.Restore:output_type -> resource.RestoreResponse
+	41, // 76: resource.ArchiveStore.Export:output_type -> resource.ExportResponse
+	43, // 77: resource.ArchiveStore.Import:output_type -> resource.ImportResponse
+	65, // [65:78] is the sub-list for method output_type
+	52, // [52:65] is the sub-list for method input_type
+	52, // [52:52] is the sub-list for extension type_name
+	52, // [52:52] is the sub-list for extension extendee
+	0,  // [0:52] is the sub-list for field type_name
 }
 
 func init() { file_resource_proto_init() }
@@ -3523,10 +4486,10 @@ func file_resource_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_resource_proto_rawDesc), len(file_resource_proto_rawDesc)),
-			NumEnums:      7,
-			NumMessages:   36,
+			NumEnums:      8,
+			NumMessages:   45,
 			NumExtensions: 0,
-			NumServices:   4,
+			NumServices:   6,
 		},
 		GoTypes:           file_resource_proto_goTypes,
 		DependencyIndexes: file_resource_proto_depIdxs,
@@ -709,3 +709,302 @@ var Diagnostics_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "resource.proto",
 }
+
+const (
+	RestoreStore_Restore_FullMethodName = "/resource.RestoreStore/Restore"
+)
+
+// RestoreStoreClient is the client API for RestoreStore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Restore the resources of a namespace, folder or resource type to a previous state.
+// The changes are written like any other write, so they are checked against the
+// permissions of the caller and saved in the history.
+type RestoreStoreClient interface {
+	// Write the resources that changed since the requested state again, as new versions
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
+}
+
+type restoreStoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRestoreStoreClient(cc grpc.ClientConnInterface) RestoreStoreClient {
+	return &restoreStoreClient{cc}
+}
+
+func (c *restoreStoreClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, RestoreStore_Restore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RestoreStoreServer is the server API for RestoreStore service.
+// All implementations should embed UnimplementedRestoreStoreServer
+// for forward compatibility
+//
+// Restore the resources of a namespace, folder or resource type to a previous state.
+// The changes are written like any other write, so they are checked against the
+// permissions of the caller and saved in the history.
+type RestoreStoreServer interface {
+	// Write the resources that changed since the requested state again, as new versions
+	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
+}
+
+// UnimplementedRestoreStoreServer should be embedded to have forward compatible implementations.
+type UnimplementedRestoreStoreServer struct {
+}
+
+func (UnimplementedRestoreStoreServer) Restore(context.Context, *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+
+// UnsafeRestoreStoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RestoreStoreServer will
+// result in compilation errors.
+type UnsafeRestoreStoreServer interface {
+	mustEmbedUnimplementedRestoreStoreServer()
+}
+
+func RegisterRestoreStoreServer(s grpc.ServiceRegistrar, srv RestoreStoreServer) {
+	s.RegisterService(&RestoreStore_ServiceDesc, srv)
+}
+
+func _RestoreStore_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RestoreStoreServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RestoreStore_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RestoreStoreServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RestoreStore_ServiceDesc is the grpc.ServiceDesc for RestoreStore service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RestoreStore_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "resource.RestoreStore",
+	HandlerType: (*RestoreStoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Restore",
+			Handler:    _RestoreStore_Restore_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "resource.proto",
+}
+
+const (
+	ArchiveStore_Export_FullMethodName = "/resource.ArchiveStore/Export"
+	ArchiveStore_Import_FullMethodName = "/resource.ArchiveStore/Import"
+)
+
+// ArchiveStoreClient is the client API for ArchiveStore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Export and import the resources of a namespace with a self describing archive.
+// The resources are read and written like any other request, so they are checked
+// against the permissions of the caller.
+type ArchiveStoreClient interface {
+	// Stream an archive of the resources of a namespace
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (ArchiveStore_ExportClient, error)
+	// Import an archive streamed by the client
+	Import(ctx context.Context, opts ...grpc.CallOption) (ArchiveStore_ImportClient, error)
+}
+
+type archiveStoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArchiveStoreClient(cc grpc.ClientConnInterface) ArchiveStoreClient {
+	return &archiveStoreClient{cc}
+}
+
+func (c *archiveStoreClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (ArchiveStore_ExportClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ArchiveStore_ServiceDesc.Streams[0], ArchiveStore_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &archiveStoreExportClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ArchiveStore_ExportClient interface {
+	Recv() (*ExportResponse, error)
+	grpc.ClientStream
+}
+
+type archiveStoreExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *archiveStoreExportClient) Recv() (*ExportResponse, error) {
+	m := new(ExportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *archiveStoreClient) Import(ctx context.Context, opts ...grpc.CallOption) (ArchiveStore_ImportClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ArchiveStore_ServiceDesc.Streams[1], ArchiveStore_Import_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &archiveStoreImportClient{ClientStream: stream}
+	return x, nil
+}
+
+type ArchiveStore_ImportClient interface {
+	Send(*ImportRequest) error
+	CloseAndRecv() (*ImportResponse, error)
+	grpc.ClientStream
+}
+
+type archiveStoreImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *archiveStoreImportClient) Send(m *ImportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *archiveStoreImportClient) CloseAndRecv() (*ImportResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ArchiveStoreServer is the server API for ArchiveStore service.
+// All implementations should embed UnimplementedArchiveStoreServer
+// for forward compatibility
+//
+// Export and import the resources of a namespace with a self describing archive.
+// The resources are read and written like any other request, so they are checked
+// against the permissions of the caller.
+type ArchiveStoreServer interface {
+	// Stream an archive of the resources of a namespace
+	Export(*ExportRequest, ArchiveStore_ExportServer) error
+	// Import an archive streamed by the client
+	Import(ArchiveStore_ImportServer) error
+}
+
+// UnimplementedArchiveStoreServer should be embedded to have forward compatible implementations.
+type UnimplementedArchiveStoreServer struct {
+}
+
+func (UnimplementedArchiveStoreServer) Export(*ExportRequest, ArchiveStore_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedArchiveStoreServer) Import(ArchiveStore_ImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+
+// UnsafeArchiveStoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArchiveStoreServer will
+// result in compilation errors.
+type UnsafeArchiveStoreServer interface {
+	mustEmbedUnimplementedArchiveStoreServer()
+}
+
+func RegisterArchiveStoreServer(s grpc.ServiceRegistrar, srv ArchiveStoreServer) {
+	s.RegisterService(&ArchiveStore_ServiceDesc, srv)
+}
+
+func _ArchiveStore_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArchiveStoreServer).Export(m, &archiveStoreExportServer{ServerStream: stream})
+}
+
+type ArchiveStore_ExportServer interface {
+	Send(*ExportResponse) error
+	grpc.ServerStream
+}
+
+type archiveStoreExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *archiveStoreExportServer) Send(m *ExportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ArchiveStore_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ArchiveStoreServer).Import(&archiveStoreImportServer{ServerStream: stream})
+}
+
+type ArchiveStore_ImportServer interface {
+	SendAndClose(*ImportResponse) error
+	Recv() (*ImportRequest, error)
+	grpc.ServerStream
+}
+
+type archiveStoreImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *archiveStoreImportServer) SendAndClose(m *ImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *archiveStoreImportServer) Recv() (*ImportRequest, error) {
+	m := new(ImportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ArchiveStore_ServiceDesc is the grpc.ServiceDesc for ArchiveStore service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ArchiveStore_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "resource.ArchiveStore",
+	HandlerType: (*ArchiveStoreServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _ArchiveStore_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _ArchiveStore_Import_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "resource.proto",
+}
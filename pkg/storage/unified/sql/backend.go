@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,7 +35,6 @@ import (
 const tracePrefix = "sql.resource."
 const defaultPollingInterval = 100 * time.Millisecond
 const defaultWatchBufferSize = 100 // number of events to buffer in the watch stream
-const defaultPrunerHistoryLimit = 20
 
 type Backend interface {
 	resource.StorageBackend
@@ -51,10 +51,17 @@ type BackendOptions struct {
 	IsHA            bool
 	storageMetrics  *resource.StorageMetrics
 
+	// If true, watchers are notified of new events with LISTEN/NOTIFY when
+	// running in HA with PostgreSQL, instead of polling only.
+	ListenNotify bool
+
 	// If true, the backend will prune history on write events.
 	// Will be removed once fully rolled out.
 	withPruner bool
 
+	// Which versions are kept by the history pruner and the retention job
+	HistoryRetention HistoryRetentionOptions
+
 	// testing
 	SimulatedNetworkLatency time.Duration // slows down the create transactions by a fixed amount
 }
@@ -76,6 +83,7 @@ func NewBackend(opts BackendOptions) (Backend, error) {
 	}
 	return &backend{
 		isHA:                    opts.IsHA,
+		listenNotify:            opts.ListenNotify,
 		done:                    ctx.Done(),
 		cancel:                  cancel,
 		log:                     logging.DefaultLogger.With("logger", "sql-resource-server"),
@@ -88,6 +96,8 @@ func NewBackend(opts BackendOptions) (Backend, error) {
 		bulkLock:                &bulkLock{running: make(map[string]bool)},
 		simulatedNetworkLatency: opts.SimulatedNetworkLatency,
 		withPruner:              opts.withPruner,
+		historyRetention:        opts.HistoryRetention,
+		lockOwner:               uuid.NewString(),
 	}, nil
 }
 
@@ -116,7 +126,8 @@ func (p *noopPruner) Start(ctx context.Context) {}
 
 type backend struct {
 	//general
-	isHA bool
+	isHA         bool
+	listenNotify bool
 
 	// server lifecycle
 	done     <-chan struct{}
@@ -148,8 +159,12 @@ type backend struct {
 	// testing
 	simulatedNetworkLatency time.Duration
 
-	historyPruner pruner
-	withPruner    bool
+	historyPruner    pruner
+	withPruner       bool
+	historyRetention HistoryRetentionOptions
+	retentionMetrics *historyRetentionMetrics
+	// identifies this replica in the locks of the background jobs
+	lockOwner string
 }
 
 func (b *backend) Init(ctx context.Context) error {
@@ -203,6 +218,14 @@ func (b *backend) initLocked(ctx context.Context) error {
 }
 
 func (b *backend) initPruner(ctx context.Context) error {
+	if b.withPruner || b.historyRetention.Interval > 0 {
+		b.retentionMetrics = newHistoryRetentionMetrics(b.reg)
+	}
+	if b.historyRetention.Interval > 0 {
+		b.log.Debug("starting history retention job", "interval", b.historyRetention.Interval)
+		go b.runHistoryRetention(ctx)
+	}
+
 	if !b.withPruner {
 		b.log.Debug("using noop history pruner")
 		b.historyPruner = &noopPruner{}
@@ -216,32 +239,22 @@ func (b *backend) initPruner(ctx context.Context) error {
 		MinWait:    time.Second * 30,
 		MaxWait:    time.Minute * 5,
 		ProcessHandler: func(ctx context.Context, key pruningKey) error {
-			return b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
-				res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryPrune, &sqlPruneHistoryRequest{
-					SQLTemplate:  sqltemplate.New(b.dialect),
-					HistoryLimit: defaultPrunerHistoryLimit,
-					Key: &resourcepb.ResourceKey{
-						Namespace: key.namespace,
-						Group:     key.group,
-						Resource:  key.resource,
-						Name:      key.name,
-					},
-				})
-				if err != nil {
-					return fmt.Errorf("failed to prune history: %w", err)
-				}
-				rows, err := res.RowsAffected()
-				if err != nil {
-					return fmt.Errorf("failed to get rows affected: %w", err)
-				}
-				b.log.Debug("pruned history successfully",
-					"namespace", key.namespace,
-					"group", key.group,
-					"resource", key.resource,
-					"name", key.name,
-					"rows", rows)
-				return nil
-			})
+			rows, err := b.pruneHistory(ctx, &resourcepb.ResourceKey{
+				Namespace: key.namespace,
+				Group:     key.group,
+				Resource:  key.resource,
+				Name:      key.name,
+			}, time.Now())
+			if err != nil {
+				return err
+			}
+			b.log.Debug("pruned history successfully",
+				"namespace", key.namespace,
+				"group", key.group,
+				"resource", key.resource,
+				"name", key.name,
+				"rows", rows)
+			return nil
 		},
 		ErrorHandler: func(key pruningKey, err error) {
 			b.log.Error("failed to prune history",
@@ -272,6 +285,11 @@ func (b *backend) IsHealthy(ctx context.Context, _ *resourcepb.HealthCheckReques
 	return &resourcepb.HealthCheckResponse{Status: resourcepb.HealthCheckResponse_SERVING}, nil
 }
 
+// ResourceVersionAt implements resource.ResourceVersionClock, the resource versions are microsecond timestamps.
+func (b *backend) ResourceVersionAt(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
 func (b *backend) Stop(_ context.Context) error {
 	b.cancel()
 	return nil
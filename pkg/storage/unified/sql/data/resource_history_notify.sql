@@ -0,0 +1,2 @@
+SELECT pg_notify({{ .Arg .Channel }}, {{ .Arg .Payload }})
+;
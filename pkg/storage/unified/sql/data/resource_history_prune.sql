@@ -1,9 +1,14 @@
 DELETE FROM {{ .Ident "resource_history" }}
 WHERE {{ .Ident "guid" }} IN (
+  {{ if .BatchSize }}
+  SELECT {{ .Ident "guid" }}
+  FROM (
+  {{ end }}
   SELECT {{ .Ident "guid" }}
   FROM (
   SELECT
     {{ .Ident "guid" }},
+    {{ .Ident "resource_version" }},
     ROW_NUMBER() OVER (
       PARTITION BY {{ .Ident "namespace" }}
         , {{ .Ident "group" }}
@@ -18,10 +23,26 @@ WHERE {{ .Ident "guid" }} IN (
   WHERE {{ .Ident "namespace" }} = {{ .Arg .Key.Namespace }}
     AND {{ .Ident "group" }} = {{ .Arg .Key.Group }}
     AND {{ .Ident "resource" }} = {{ .Arg .Key.Resource }}
+    {{ if .Key.Name }}
     AND {{ .Ident "name" }} = {{ .Arg .Key.Name }}
+    {{ end }}
     {{ if .PartitionByGeneration }}
     AND {{ .Ident "generation" }} > 0
     {{ end }}
   ) AS {{ .Ident "ranked" }}
+  {{ if .MinResourceVersion }}
+  WHERE {{ .Ident "rn" }} > {{ .Arg .KeepAtLeast }}
+    AND (
+      {{ .Ident "resource_version" }} < {{ .Arg .MinResourceVersion }}
+      {{ if .HistoryLimit }}
+      OR {{ .Ident "rn" }} > {{ .Arg .HistoryLimit }}
+      {{ end }}
+    )
+  {{ else }}
   WHERE {{ .Ident "rn" }} > {{ .Arg .HistoryLimit }}
+  {{ end }}
+  {{ if .BatchSize }}
+  LIMIT {{ .Arg .BatchSize }}
+  ) AS {{ .Ident "batch" }}
+  {{ end }}
 );
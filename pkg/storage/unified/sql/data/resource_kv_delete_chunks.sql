@@ -0,0 +1,6 @@
+DELETE FROM {{ .Ident "resource_kv" }}
+    WHERE 1 = 1
+        AND {{ .Ident "section" }} = {{ .Arg .Section }}
+        AND {{ .Ident "key" }}     = {{ .Arg .Key }}
+        AND {{ .Ident "chunk" }}  >= {{ .Arg .Chunk }}
+;
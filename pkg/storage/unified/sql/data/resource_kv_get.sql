@@ -0,0 +1,16 @@
+SELECT
+    {{ .Ident "guid" }},
+    {{ .Ident "value" }},
+    (
+        SELECT COUNT(*)
+        FROM {{ .Ident "resource_kv" }}
+        WHERE 1 = 1
+            AND {{ .Ident "section" }} = {{ .Arg .Section }}
+            AND {{ .Ident "key" }}     = {{ .Arg .Key }}
+    )
+    FROM {{ .Ident "resource_kv" }}
+    WHERE 1 = 1
+        AND {{ .Ident "section" }} = {{ .Arg .Section }}
+        AND {{ .Ident "key" }}     = {{ .Arg .Key }}
+        AND {{ .Ident "chunk" }}   = 0
+;
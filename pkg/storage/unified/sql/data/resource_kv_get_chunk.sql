@@ -0,0 +1,9 @@
+SELECT
+    {{ .Ident "value" }}
+    FROM {{ .Ident "resource_kv" }}
+    WHERE 1 = 1
+        AND {{ .Ident "section" }} = {{ .Arg .Section }}
+        AND {{ .Ident "key" }}     = {{ .Arg .Key }}
+        AND {{ .Ident "chunk" }}   = {{ .Arg .Chunk }}
+        AND {{ .Ident "guid" }}    = {{ .Arg .GUID }}
+;
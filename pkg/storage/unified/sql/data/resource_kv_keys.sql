@@ -0,0 +1,22 @@
+SELECT
+    {{ .Ident "key" }}
+    FROM {{ .Ident "resource_kv" }}
+    WHERE 1 = 1
+        AND {{ .Ident "section" }} = {{ .Arg .Section }}
+        AND {{ .Ident "chunk" }}   = 0
+        {{ if .StartKey }}
+        AND {{ .Ident "key" }}    >= {{ .Arg .StartKey }}
+        {{ end }}
+        {{ if .AfterKey }}
+        AND {{ .Ident "key" }}     > {{ .Arg .AfterKey }}
+        {{ end }}
+        {{ if .EndKey }}
+        AND {{ .Ident "key" }}     < {{ .Arg .EndKey }}
+        {{ end }}
+    {{ if .SortDesc }}
+    ORDER BY {{ .Ident "key" }} DESC
+    {{ else }}
+    ORDER BY {{ .Ident "key" }} ASC
+    {{ end }}
+    LIMIT {{ .Arg .Limit }}
+;
@@ -0,0 +1,3 @@
+SELECT
+    {{ .CurrentEpoch | .Into .Response.CurrentEpoch }}
+;
@@ -0,0 +1,23 @@
+INSERT INTO {{ .Ident "resource_kv" }}
+    (
+        {{ .Ident "section" }},
+        {{ .Ident "key" }},
+        {{ .Ident "chunk" }},
+        {{ .Ident "guid" }},
+        {{ .Ident "value" }}
+    )
+    VALUES (
+        {{ .Arg .Section }},
+        {{ .Arg .Key }},
+        {{ .Arg .Chunk }},
+        {{ .Arg .GUID }},
+        {{ .Arg .Value }}
+    )
+{{ if eq .DialectName "mysql" }}
+    ON DUPLICATE KEY UPDATE
+{{ else }}
+    ON CONFLICT ({{ .Ident "section" }}, {{ .Ident "key" }}, {{ .Ident "chunk" }}) DO UPDATE SET
+{{ end }}
+        {{ .Ident "guid" }}  = {{ .Arg .GUID }},
+        {{ .Ident "value" }} = {{ .Arg .Value }}
+;
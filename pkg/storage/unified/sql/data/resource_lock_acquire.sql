@@ -0,0 +1,10 @@
+UPDATE {{ .Ident "resource_lock" }}
+    SET
+        {{ .Ident "owner" }}   = {{ .Arg .Owner }},
+        {{ .Ident "expires" }} = {{ .Arg .Expires }}
+    WHERE {{ .Ident "name" }} = {{ .Arg .Name }}
+        AND (
+            {{ .Ident "owner" }} = {{ .Arg .Owner }}
+            OR {{ .Ident "expires" }} < {{ .Arg .Now }}
+        )
+;
@@ -0,0 +1,17 @@
+INSERT INTO {{ .Ident "resource_lock" }}
+    (
+        {{ .Ident "name" }},
+        {{ .Ident "owner" }},
+        {{ .Ident "expires" }}
+    )
+    VALUES (
+        {{ .Arg .Name }},
+        '',
+        0
+    )
+{{ if eq .DialectName "mysql" }}
+    ON DUPLICATE KEY UPDATE {{ .Ident "name" }} = {{ .Ident "name" }}
+{{ else }}
+    ON CONFLICT ({{ .Ident "name" }}) DO NOTHING
+{{ end }}
+;
@@ -103,6 +103,11 @@ func (p *resourceDBProvider) Init(ctx context.Context) (db.DB, error) {
 	return p.resourceDB, p.initErr
 }
 
+// ConnectionString returns the connection string of the resource database.
+func (p *resourceDBProvider) ConnectionString() string {
+	return p.engine.DataSourceName()
+}
+
 func (p *resourceDBProvider) initDB(ctx context.Context) (db.DB, error) {
 	p.log.Info("Initializing Resource DB",
 		"db_type",
@@ -167,5 +167,36 @@ func initResourceTables(mg *migrator.Migrator) string {
 		Name: "IDX_resource_history_namespace_group_resource_name_generation",
 	}))
 
+	// Key/value store used by the KV storage backend. Large values are split
+	// in chunks that share a guid, chunk 0 is always present.
+	resource_kv_table := migrator.Table{
+		Name: "resource_kv",
+		Columns: []*migrator.Column{
+			{Name: "section", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			// binary so that keys are sorted byte by byte in every database
+			{Name: "key", Type: migrator.DB_VarBinary, Length: 1024, Nullable: false},
+			{Name: "chunk", Type: migrator.DB_Int, Nullable: false},
+			{Name: "guid", Type: migrator.DB_NVarchar, Length: 36, Nullable: false},
+			{Name: "value", Type: migrator.DB_LongBlob, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"section", "key", "chunk"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create table resource_kv", migrator.NewAddTableMigration(resource_kv_table))
+	mg.AddMigration("create table resource_kv, index: 0", migrator.NewAddIndexMigration(resource_kv_table, resource_kv_table.Indices[0]))
+
+	// Leases on the background jobs that must only run on one replica at a time.
+	// The lock is held by the owner until it expires (unix microseconds).
+	resource_lock_table := migrator.Table{
+		Name: "resource_lock",
+		Columns: []*migrator.Column{
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, IsPrimaryKey: true},
+			{Name: "owner", Type: migrator.DB_NVarchar, Length: 36, Nullable: false},
+			{Name: "expires", Type: migrator.DB_BigInt, Nullable: false},
+		},
+	}
+	mg.AddMigration("create table resource_lock", migrator.NewAddTableMigration(resource_lock_table))
+
 	return marker
 }
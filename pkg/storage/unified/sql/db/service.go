@@ -26,6 +26,13 @@ type DBProvider interface {
 	Init(context.Context) (DB, error)
 }
 
+// ConnectionStringProvider is optionally implemented by a DBProvider to allow
+// opening dedicated connections outside of the pool, e.g. to LISTEN for
+// PostgreSQL notifications.
+type ConnectionStringProvider interface {
+	ConnectionString() string
+}
+
 // DB is a thin abstraction on *sql.DB to allow mocking to provide better unit
 // testing. We purposefully hide database operation methods that would use
 // context.Background().
@@ -0,0 +1,283 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/dbutil"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+// The policy used when none is configured
+var defaultHistoryRetention = HistoryRetentionPolicy{MaxVersions: 20, KeepAtLeast: 1}
+
+const (
+	// Maximum number of versions deleted in one transaction
+	historyPruneBatchSize = 1000
+	// Name of the lock making sure a single replica runs the retention job
+	historyRetentionLock = "history_retention"
+)
+
+// HistoryRetentionPolicy defines which versions of a resource are kept in the history.
+// The resource versions are microsecond timestamps, so they are used to compute the age of a version.
+type HistoryRetentionPolicy struct {
+	// MaxVersions is the maximum number of versions kept per resource, 0 means no limit.
+	MaxVersions int64
+	// MaxAge is how long a version is kept, 0 means no limit.
+	MaxAge time.Duration
+	// KeepAtLeast is the number of versions kept per resource whatever their age.
+	// The latest version is always kept.
+	KeepAtLeast int64
+}
+
+// HistoryRetentionOptions configures how the resource history is pruned
+type HistoryRetentionOptions struct {
+	// Default is the policy of the resources without their own policy.
+	// The last 20 versions are kept when nil.
+	Default *HistoryRetentionPolicy
+
+	// Resources overrides the default policy for some resource types
+	Resources map[schema.GroupResource]HistoryRetentionPolicy
+
+	// Interval between two runs of the background job enforcing the policies.
+	// The job is disabled when zero. It is also how long a replica holds the
+	// lock of the job without renewing it.
+	Interval time.Duration
+}
+
+// NewHistoryRetentionOptions reads the retention policies from the unified storage settings.
+// The job is only enabled when history_retention_enabled is set.
+func NewHistoryRetentionOptions(cfg *setting.Cfg) HistoryRetentionOptions {
+	opts := HistoryRetentionOptions{
+		Default:   historyRetentionPolicy(cfg.HistoryRetention),
+		Resources: make(map[schema.GroupResource]HistoryRetentionPolicy),
+	}
+	if cfg.HistoryRetentionEnabled {
+		opts.Interval = cfg.HistoryRetentionInterval
+	}
+	for name, c := range cfg.UnifiedStorage {
+		if c.HistoryRetention == nil {
+			continue
+		}
+		// the section names are <resource>.<group>
+		gr := schema.ParseGroupResource(name)
+		opts.Resources[gr] = *historyRetentionPolicy(*c.HistoryRetention)
+	}
+	return opts
+}
+
+func historyRetentionPolicy(s setting.HistoryRetentionSettings) *HistoryRetentionPolicy {
+	return &HistoryRetentionPolicy{
+		MaxVersions: s.MaxVersions,
+		MaxAge:      s.MaxAge,
+		KeepAtLeast: s.KeepAtLeast,
+	}
+}
+
+// policy returns the retention policy of a resource type
+func (o *HistoryRetentionOptions) policy(group, resource string) HistoryRetentionPolicy {
+	if p, ok := o.Resources[schema.GroupResource{Group: group, Resource: resource}]; ok {
+		return p
+	}
+	if o.Default != nil {
+		return *o.Default
+	}
+	return defaultHistoryRetention
+}
+
+// pruneRequest returns the request deleting the versions outside of the policy,
+// or nil when the policy keeps every version.
+// Without a name in the key, the history of every resource in the collection is pruned.
+func (p HistoryRetentionPolicy) pruneRequest(dialect sqltemplate.Dialect, key *resourcepb.ResourceKey, now time.Time) *sqlPruneHistoryRequest {
+	req := &sqlPruneHistoryRequest{
+		SQLTemplate: sqltemplate.New(dialect),
+		Key:         key,
+		KeepAtLeast: max(p.KeepAtLeast, 1),
+	}
+	if p.MaxVersions > 0 {
+		req.HistoryLimit = max(p.MaxVersions, req.KeepAtLeast)
+	}
+	if p.MaxAge > 0 {
+		req.MinResourceVersion = now.Add(-p.MaxAge).UnixMicro()
+	}
+	if req.HistoryLimit == 0 && req.MinResourceVersion == 0 {
+		return nil
+	}
+	return req
+}
+
+type historyRetentionMetrics struct {
+	prunedRows  *prometheus.CounterVec
+	runDuration prometheus.Histogram
+}
+
+func newHistoryRetentionMetrics(reg prometheus.Registerer) *historyRetentionMetrics {
+	return &historyRetentionMetrics{
+		prunedRows: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "storage_server",
+			Name:      "history_pruned_rows_total",
+			Help:      "Number of resource history rows deleted by the retention policies",
+		}, []string{"group", "resource"}),
+		runDuration: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "storage_server",
+			Name:      "history_retention_duration_seconds",
+			Help:      "Duration of the background job enforcing the history retention policies",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}),
+	}
+}
+
+// pruneHistory deletes the versions of the key outside of its retention policy.
+// The versions are deleted in batches, each in its own short transaction.
+func (b *backend) pruneHistory(ctx context.Context, key *resourcepb.ResourceKey, now time.Time) (int64, error) {
+	req := b.historyRetention.policy(key.Group, key.Resource).pruneRequest(b.dialect, key, now)
+	if req == nil {
+		return 0, nil
+	}
+	req.BatchSize = historyPruneBatchSize
+	var total int64
+	for {
+		var rows int64
+		err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+			res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryPrune, req)
+			if err != nil {
+				return fmt.Errorf("failed to prune history: %w", err)
+			}
+			rows, err = res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		if b.retentionMetrics != nil && rows > 0 {
+			b.retentionMetrics.prunedRows.WithLabelValues(key.Group, key.Resource).Add(float64(rows))
+		}
+		total += rows
+		if rows < req.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// acquireHistoryRetentionLock takes or renews the lock of the retention job until the next run.
+// It returns false when the lock is held by another replica.
+func (b *backend) acquireHistoryRetentionLock(ctx context.Context) (bool, error) {
+	now := time.Now()
+	req := &sqlResourceLockRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		Name:        historyRetentionLock,
+		Owner:       b.lockOwner,
+		Now:         now.UnixMicro(),
+		Expires:     now.Add(b.historyRetention.Interval).UnixMicro(),
+	}
+	res, err := dbutil.Exec(ctx, b.db, sqlResourceLockAcquire, req)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// runHistoryRetention enforces the retention policies on an interval, until the backend is stopped
+func (b *backend) runHistoryRetention(ctx context.Context) {
+	ticker := time.NewTicker(b.historyRetention.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.enforceHistoryRetention(ctx, time.Now()); err != nil {
+				b.log.Error("failed to enforce history retention", "error", err)
+			}
+		}
+	}
+}
+
+// enforceHistoryRetention prunes the history of every collection.
+// Only the replica holding the lock of the job prunes the history, and it renews
+// the lock before each collection.
+// A failing collection does not stop the others from being pruned.
+func (b *backend) enforceHistoryRetention(ctx context.Context, now time.Time) error {
+	ctx, span := b.tracer.Start(ctx, tracePrefix+"EnforceHistoryRetention")
+	defer span.End()
+
+	// make sure the lock exists before trying to acquire it
+	if _, err := dbutil.Exec(ctx, b.db, sqlResourceLockInsert, &sqlResourceLockRequest{
+		SQLTemplate: sqltemplate.New(b.dialect),
+		Name:        historyRetentionLock,
+		Owner:       b.lockOwner,
+	}); err != nil {
+		return fmt.Errorf("create lock: %w", err)
+	}
+	acquired, err := b.acquireHistoryRetentionLock(ctx)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		b.log.Debug("history retention is enforced by another replica")
+		return nil
+	}
+
+	if b.retentionMetrics != nil {
+		timer := prometheus.NewTimer(b.retentionMetrics.runDuration)
+		defer timer.ObserveDuration()
+	}
+
+	stats, err := b.GetResourceStats(ctx, "", 0)
+	if err != nil {
+		return fmt.Errorf("list collections: %w", err)
+	}
+	var failed int
+	for i, s := range stats {
+		if i > 0 {
+			acquired, err := b.acquireHistoryRetentionLock(ctx)
+			if err != nil {
+				return err
+			}
+			if !acquired {
+				return fmt.Errorf("lost the lock after pruning the history of %d collections", i)
+			}
+		}
+		key := &resourcepb.ResourceKey{
+			Namespace: s.Namespace,
+			Group:     s.Group,
+			Resource:  s.Resource,
+		}
+		rows, err := b.pruneHistory(ctx, key, now)
+		if err != nil {
+			failed++
+			b.log.Error("failed to prune history",
+				"namespace", key.Namespace,
+				"group", key.Group,
+				"resource", key.Resource,
+				"error", err)
+			continue
+		}
+		b.log.Debug("pruned history successfully",
+			"namespace", key.Namespace,
+			"group", key.Group,
+			"resource", key.Resource,
+			"rows", rows)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to prune the history of %d collections", failed)
+	}
+	return nil
+}
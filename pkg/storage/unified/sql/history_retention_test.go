@@ -0,0 +1,219 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+func TestHistoryRetentionPolicy_pruneRequest(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMicro(1_700_000_000_000_000)
+	key := &resourcepb.ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards"}
+
+	tests := []struct {
+		name     string
+		policy   HistoryRetentionPolicy
+		expected *sqlPruneHistoryRequest
+	}{
+		{
+			name:   "keep everything",
+			policy: HistoryRetentionPolicy{KeepAtLeast: 10},
+		},
+		{
+			name:     "max versions",
+			policy:   HistoryRetentionPolicy{MaxVersions: 20},
+			expected: &sqlPruneHistoryRequest{HistoryLimit: 20, KeepAtLeast: 1},
+		},
+		{
+			name:     "max versions below keep at least",
+			policy:   HistoryRetentionPolicy{MaxVersions: 2, KeepAtLeast: 5},
+			expected: &sqlPruneHistoryRequest{HistoryLimit: 5, KeepAtLeast: 5},
+		},
+		{
+			name:   "max age",
+			policy: HistoryRetentionPolicy{MaxAge: time.Hour, KeepAtLeast: 3},
+			expected: &sqlPruneHistoryRequest{
+				MinResourceVersion: now.Add(-time.Hour).UnixMicro(),
+				KeepAtLeast:        3,
+			},
+		},
+		{
+			name:   "max age keeps the latest version",
+			policy: HistoryRetentionPolicy{MaxVersions: 100, MaxAge: time.Hour},
+			expected: &sqlPruneHistoryRequest{
+				HistoryLimit:       100,
+				MinResourceVersion: now.Add(-time.Hour).UnixMicro(),
+				KeepAtLeast:        1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := tt.policy.pruneRequest(sqltemplate.SQLite, key, now)
+			if tt.expected == nil {
+				require.Nil(t, req)
+				return
+			}
+			require.NotNil(t, req)
+			require.NoError(t, req.Validate())
+			require.Equal(t, key, req.Key)
+			require.Equal(t, tt.expected.HistoryLimit, req.HistoryLimit)
+			require.Equal(t, tt.expected.MinResourceVersion, req.MinResourceVersion)
+			require.Equal(t, tt.expected.KeepAtLeast, req.KeepAtLeast)
+		})
+	}
+}
+
+func TestNewHistoryRetentionOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := setting.NewCfg()
+	cfg.HistoryRetention = setting.HistoryRetentionSettings{MaxVersions: 20, KeepAtLeast: 1}
+	cfg.HistoryRetentionInterval = time.Hour
+	cfg.UnifiedStorage = map[string]setting.UnifiedStorageConfig{
+		"dashboards.dashboard.grafana.app": {
+			HistoryRetention: &setting.HistoryRetentionSettings{MaxAge: 365 * 24 * time.Hour, KeepAtLeast: 5},
+		},
+		"playlists.playlist.grafana.app": {},
+	}
+
+	opts := NewHistoryRetentionOptions(cfg)
+	require.Zero(t, opts.Interval, "the job is disabled by default")
+	require.Equal(t, HistoryRetentionPolicy{MaxAge: 365 * 24 * time.Hour, KeepAtLeast: 5},
+		opts.policy("dashboard.grafana.app", "dashboards"))
+	require.Equal(t, HistoryRetentionPolicy{MaxVersions: 20, KeepAtLeast: 1},
+		opts.policy("playlist.grafana.app", "playlists"))
+	require.Len(t, opts.Resources, 1)
+	require.Contains(t, opts.Resources, schema.GroupResource{Group: "dashboard.grafana.app", Resource: "dashboards"})
+
+	cfg.HistoryRetentionEnabled = true
+	require.Equal(t, time.Hour, NewHistoryRetentionOptions(cfg).Interval)
+
+	require.Equal(t, defaultHistoryRetention, (&HistoryRetentionOptions{}).policy("playlist.grafana.app", "playlists"))
+}
+
+func TestBackend_enforceHistoryRetention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	stats := Rows{
+		{"ns", "dashboard.grafana.app", "dashboards", 10, 100},
+		{"ns", "playlist.grafana.app", "playlists", 5, 200},
+		{"ns", "folder.grafana.app", "folders", 5, 300},
+	}
+
+	t.Run("happy path", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+		b.historyRetention = HistoryRetentionOptions{
+			Resources: map[schema.GroupResource]HistoryRetentionPolicy{
+				{Group: "dashboard.grafana.app", Resource: "dashboards"}: {MaxAge: time.Hour, KeepAtLeast: 5},
+				{Group: "folder.grafana.app", Resource: "folders"}:       {},
+			},
+		}
+		b.retentionMetrics = newHistoryRetentionMetrics(nil)
+
+		b.ExecWithResult("insert resource_lock", 0, 1)
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource", 5, stats)
+		b.SQLMock.ExpectCommit()
+		// dashboards, with their own policy, in two batches
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, historyPruneBatchSize)
+		b.SQLMock.ExpectCommit()
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 3)
+		b.SQLMock.ExpectCommit()
+		// playlists, with the default policy
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 2)
+		b.SQLMock.ExpectCommit()
+		// folders keep their history
+		b.ExecWithResult("update resource_lock", 0, 1)
+
+		err := b.enforceHistoryRetention(ctx, now)
+		require.NoError(t, err)
+	})
+
+	t.Run("a failing collection does not stop the others", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.ExecWithResult("insert resource_lock", 0, 1)
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource", 5, stats)
+		b.SQLMock.ExpectCommit()
+		b.SQLMock.ExpectBegin()
+		b.ExecWithErr("delete resource_history", errTest)
+		b.SQLMock.ExpectRollback()
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 2)
+		b.SQLMock.ExpectCommit()
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 0)
+		b.SQLMock.ExpectCommit()
+
+		err := b.enforceHistoryRetention(ctx, now)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to prune the history of 1 collections")
+	})
+
+	t.Run("error listing collections", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.ExecWithResult("insert resource_lock", 0, 1)
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.QueryWithErr("select resource", errTest)
+		b.SQLMock.ExpectRollback()
+
+		err := b.enforceHistoryRetention(ctx, now)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "list collections")
+	})
+	t.Run("the lock is held by another replica", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.ExecWithResult("insert resource_lock", 0, 0)
+		b.ExecWithResult("update resource_lock", 0, 0)
+
+		err := b.enforceHistoryRetention(ctx, now)
+		require.NoError(t, err)
+	})
+
+	t.Run("the lock is lost while pruning", func(t *testing.T) {
+		t.Parallel()
+		b, ctx := setupBackendTest(t)
+
+		b.ExecWithResult("insert resource_lock", 0, 1)
+		b.ExecWithResult("update resource_lock", 0, 1)
+		b.SQLMock.ExpectBegin()
+		b.QueryWithResult("select resource", 5, stats)
+		b.SQLMock.ExpectCommit()
+		b.SQLMock.ExpectBegin()
+		b.ExecWithResult("delete resource_history", 0, 3)
+		b.SQLMock.ExpectCommit()
+		b.ExecWithResult("update resource_lock", 0, 0)
+
+		err := b.enforceHistoryRetention(ctx, now)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "lost the lock")
+	})
+}
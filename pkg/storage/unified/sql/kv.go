@@ -0,0 +1,339 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/dbutil"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+const (
+	// values larger than this are split in multiple rows
+	defaultKVChunkSize = 1 << 20
+	// number of keys fetched per query when listing
+	defaultKVPageSize = 1000
+)
+
+var _ resource.KV = &sqlKV{}
+
+// sqlKV implements the resource.KV interface on top of the resource database.
+// Keys are stored as binary values so range scans follow byte ordering in every
+// supported database. Large values are written in chunks that share a guid, so
+// readers never mix the chunks of two different writes.
+type sqlKV struct {
+	db        db.DB
+	dialect   sqltemplate.Dialect
+	chunkSize int
+	pageSize  int64
+}
+
+func NewKV(dbConn db.DB) (resource.KV, error) {
+	return newKV(dbConn)
+}
+
+func newKV(dbConn db.DB) (*sqlKV, error) {
+	driverName := dbConn.DriverName()
+	dialect := sqltemplate.DialectForDriver(driverName)
+	if dialect == nil {
+		return nil, fmt.Errorf("no dialect for driver %q", driverName)
+	}
+	return &sqlKV{
+		db:        dbConn,
+		dialect:   dialect,
+		chunkSize: defaultKVChunkSize,
+		pageSize:  defaultKVPageSize,
+	}, nil
+}
+
+func (k *sqlKV) Get(ctx context.Context, section string, key string) (io.ReadCloser, error) {
+	req := sqlKVKeyRequest{
+		SQLTemplate: sqltemplate.New(k.dialect),
+		Section:     section,
+		Key:         []byte(key),
+	}
+	rows, err := dbutil.QueryRows(ctx, k.db, sqlResourceKVGet, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, resource.ErrNotFound
+	}
+
+	var (
+		guid   string
+		value  []byte
+		chunks int
+	)
+	if err := rows.Scan(&guid, &value, &chunks); err != nil {
+		return nil, fmt.Errorf("read kv value: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if chunks <= 1 {
+		return io.NopCloser(bytes.NewReader(value)), nil
+	}
+	return &sqlKVReader{
+		ctx:     ctx,
+		kv:      k,
+		section: section,
+		key:     []byte(key),
+		guid:    guid,
+		chunks:  chunks,
+		next:    1,
+		buf:     bytes.NewReader(value),
+	}, nil
+}
+
+// sqlKVReader reads the remaining chunks of a value when they are needed
+type sqlKVReader struct {
+	ctx     context.Context
+	kv      *sqlKV
+	section string
+	key     []byte
+	guid    string
+	chunks  int
+	next    int
+	buf     *bytes.Reader
+}
+
+// Read implements io.Reader
+func (r *sqlKVReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.next >= r.chunks {
+			return 0, io.EOF
+		}
+		value, err := r.kv.getChunk(r.ctx, r.section, r.key, r.next, r.guid)
+		if err != nil {
+			return 0, err
+		}
+		r.buf = bytes.NewReader(value)
+		r.next++
+	}
+	return r.buf.Read(p)
+}
+
+// Close implements io.Closer
+func (r *sqlKVReader) Close() error {
+	return nil
+}
+
+func (k *sqlKV) getChunk(ctx context.Context, section string, key []byte, chunk int, guid string) ([]byte, error) {
+	rows, err := dbutil.QueryRows(ctx, k.db, sqlResourceKVGetChunk, sqlKVGetChunkRequest{
+		SQLTemplate: sqltemplate.New(k.dialect),
+		Section:     section,
+		Key:         key,
+		Chunk:       chunk,
+		GUID:        guid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("value for key %q was modified while reading", key)
+	}
+	var value []byte
+	if err := rows.Scan(&value); err != nil {
+		return nil, fmt.Errorf("read kv chunk %d: %w", chunk, err)
+	}
+	return value, rows.Err()
+}
+
+func (k *sqlKV) Save(ctx context.Context, section string, key string) (io.WriteCloser, error) {
+	if section == "" {
+		return nil, fmt.Errorf("section is required")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	return &sqlKVWriter{
+		ctx:     ctx,
+		kv:      k,
+		section: section,
+		key:     []byte(key),
+		guid:    uuid.New().String(),
+	}, nil
+}
+
+// sqlKVWriter buffers the whole value and stores it in chunks when the writer is
+// closed, in a single short transaction, so that no transaction is kept open while
+// the caller writes. The chunks are upserted, starting with chunk 0, so that concurrent
+// writers of the same key wait for each other on the row of chunk 0 and the last writer wins.
+type sqlKVWriter struct {
+	ctx     context.Context
+	kv      *sqlKV
+	section string
+	key     []byte
+	guid    string
+	buf     bytes.Buffer
+	closed  bool
+}
+
+// Write implements io.Writer
+func (w *sqlKVWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed writer")
+	}
+	return w.buf.Write(p)
+}
+
+// Close implements io.Closer - writes the buffered value
+func (w *sqlKVWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.kv.db.WithTx(w.ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+		value := w.buf.Bytes()
+		chunk := 0
+		// chunk 0 is always written, even for empty values
+		for chunk == 0 || len(value) > 0 {
+			n := min(len(value), w.kv.chunkSize)
+			_, err := dbutil.Exec(ctx, tx, sqlResourceKVUpsert, sqlKVUpsertRequest{
+				SQLTemplate: sqltemplate.New(w.kv.dialect),
+				Section:     w.section,
+				Key:         w.key,
+				Chunk:       chunk,
+				GUID:        w.guid,
+				Value:       value[:n],
+			})
+			if err != nil {
+				return fmt.Errorf("write kv chunk %d: %w", chunk, err)
+			}
+			value = value[n:]
+			chunk++
+		}
+
+		// remove the chunks of a previous value that was longer
+		_, err := dbutil.Exec(ctx, tx, sqlResourceKVDeleteChunks, sqlKVDeleteChunksRequest{
+			SQLTemplate: sqltemplate.New(w.kv.dialect),
+			Section:     w.section,
+			Key:         w.key,
+			Chunk:       chunk,
+		})
+		if err != nil {
+			return fmt.Errorf("delete previous kv chunks: %w", err)
+		}
+		return nil
+	})
+}
+
+func (k *sqlKV) Delete(ctx context.Context, section string, key string) error {
+	res, err := dbutil.Exec(ctx, k.db, sqlResourceKVDelete, sqlKVKeyRequest{
+		SQLTemplate: sqltemplate.New(k.dialect),
+		Section:     section,
+		Key:         []byte(key),
+	})
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete kv value: %w", err)
+	}
+	if count == 0 {
+		return resource.ErrNotFound
+	}
+	return nil
+}
+
+func (k *sqlKV) Keys(ctx context.Context, section string, opt resource.ListOptions) iter.Seq2[string, error] {
+	if section == "" {
+		return func(yield func(string, error) bool) {
+			yield("", fmt.Errorf("section is required"))
+		}
+	}
+
+	return func(yield func(string, error) bool) {
+		req := sqlKVKeysRequest{
+			Section:  section,
+			StartKey: []byte(opt.StartKey),
+			EndKey:   []byte(opt.EndKey),
+			SortDesc: opt.Sort == resource.SortOrderDesc,
+		}
+		count := int64(0)
+		for {
+			req.SQLTemplate = sqltemplate.New(k.dialect)
+			req.Limit = k.pageSize
+			if opt.Limit > 0 && opt.Limit-count < req.Limit {
+				req.Limit = opt.Limit - count
+			}
+
+			keys, err := k.listKeys(ctx, req)
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, key := range keys {
+				if !yield(key, nil) {
+					return
+				}
+			}
+
+			count += int64(len(keys))
+			if int64(len(keys)) < req.Limit || (opt.Limit > 0 && count >= opt.Limit) {
+				return
+			}
+
+			// continue after the last key
+			last := []byte(keys[len(keys)-1])
+			if req.SortDesc {
+				req.EndKey = last
+			} else {
+				req.AfterKey = last
+			}
+		}
+	}
+}
+
+// listKeys reads a single page of keys, so that no rows are left open while
+// the caller consumes them
+func (k *sqlKV) listKeys(ctx context.Context, req sqlKVKeysRequest) ([]string, error) {
+	rows, err := dbutil.QueryRows(ctx, k.db, sqlResourceKVKeys, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	keys := make([]string, 0, req.Limit)
+	for rows.Next() {
+		var key []byte
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("read kv key: %w", err)
+		}
+		keys = append(keys, string(key))
+	}
+	return keys, rows.Err()
+}
+
+func (k *sqlKV) UnixTimestamp(ctx context.Context) (int64, error) {
+	res, err := dbutil.QueryRow(ctx, k.db, sqlResourceKVTimestamp, sqlKVTimestampRequest{
+		SQLTemplate: sqltemplate.New(k.dialect),
+		Response:    new(kvTimestampResponse),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("read database time: %w", err)
+	}
+	// the database epoch is in microseconds
+	return res.CurrentEpoch / 1_000_000, nil
+}
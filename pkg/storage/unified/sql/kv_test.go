@@ -0,0 +1,155 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db/dbimpl"
+)
+
+func TestIntegrationSQLKVChunks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+
+	grafanaDB := db.InitTestDB(t)
+	resourceDBProvider, err := dbimpl.ProvideResourceDB(grafanaDB, setting.NewCfg(), tracing.NewNoopTracerService())
+	require.NoError(t, err)
+	resourceDB, err := resourceDBProvider.Init(ctx)
+	require.NoError(t, err)
+
+	kv, err := newKV(resourceDB)
+	require.NoError(t, err)
+	kv.chunkSize = 10
+	kv.pageSize = 2
+
+	save := func(t *testing.T, key string, value []byte) {
+		w, err := kv.Save(ctx, "chunks", key)
+		require.NoError(t, err)
+		// write in pieces that do not line up with the chunks
+		for len(value) > 0 {
+			n := min(len(value), 7)
+			_, err = w.Write(value[:n])
+			require.NoError(t, err)
+			value = value[n:]
+		}
+		require.NoError(t, w.Close())
+	}
+	get := func(t *testing.T, key string) []byte {
+		r, err := kv.Get(ctx, "chunks", key)
+		require.NoError(t, err)
+		defer func() { _ = r.Close() }()
+		value, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("large values are split and read back", func(t *testing.T) {
+		value := []byte(strings.Repeat("0123456789abcdef", 10))
+		save(t, "large", value)
+		require.Equal(t, value, get(t, "large"))
+
+		// overwriting with a smaller value removes the extra chunks
+		save(t, "large", []byte("small"))
+		require.Equal(t, []byte("small"), get(t, "large"))
+	})
+
+	t.Run("values that fill exactly one chunk", func(t *testing.T) {
+		value := bytes.Repeat([]byte("x"), 10)
+		save(t, "exact", value)
+		require.Equal(t, value, get(t, "exact"))
+	})
+
+	t.Run("empty values", func(t *testing.T) {
+		save(t, "empty", nil)
+		require.Empty(t, get(t, "empty"))
+	})
+
+	t.Run("values are stored when the writer is closed", func(t *testing.T) {
+		w, err := kv.Save(ctx, "chunks", "pending")
+		require.NoError(t, err)
+		_, err = w.Write(bytes.Repeat([]byte("p"), 25))
+		require.NoError(t, err)
+
+		_, err = kv.Get(ctx, "chunks", "pending")
+		require.ErrorIs(t, err, resource.ErrNotFound)
+
+		require.NoError(t, w.Close())
+		require.Equal(t, bytes.Repeat([]byte("p"), 25), get(t, "pending"))
+	})
+
+	t.Run("reading fails when the value is replaced", func(t *testing.T) {
+		save(t, "replaced", bytes.Repeat([]byte("a"), 30))
+		r, err := kv.Get(ctx, "chunks", "replaced")
+		require.NoError(t, err)
+		save(t, "replaced", bytes.Repeat([]byte("b"), 30))
+
+		_, err = io.ReadAll(r)
+		require.ErrorContains(t, err, "modified while reading")
+	})
+
+	t.Run("concurrent writes of the same key keep one of the values", func(t *testing.T) {
+		values := make([][]byte, 5)
+		for i := range values {
+			// different lengths, so that mixed chunks of two writes would be noticed
+			values[i] = bytes.Repeat([]byte{byte('a' + i)}, 5+i*10)
+		}
+		start := make(chan struct{})
+		errs := make(chan error, len(values))
+		var wg sync.WaitGroup
+		for _, value := range values {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				w, err := kv.Save(ctx, "chunks", "concurrent")
+				if err == nil {
+					_, err = w.Write(value)
+				}
+				if err == nil {
+					err = w.Close()
+				}
+				errs <- err
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		require.Contains(t, values, get(t, "concurrent"))
+	})
+
+	t.Run("keys are listed across pages", func(t *testing.T) {
+		section := "pages"
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			w, err := kv.Save(ctx, section, key)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+		}
+		list := func(opt resource.ListOptions) []string {
+			var keys []string
+			for key, err := range kv.Keys(ctx, section, opt) {
+				require.NoError(t, err)
+				keys = append(keys, key)
+			}
+			return keys
+		}
+
+		require.Equal(t, []string{"a", "b", "c", "d", "e"}, list(resource.ListOptions{}))
+		require.Equal(t, []string{"b", "c", "d"}, list(resource.ListOptions{StartKey: "b", EndKey: "e"}))
+		require.Equal(t, []string{"e", "d", "c"}, list(resource.ListOptions{Sort: resource.SortOrderDesc, Limit: 3}))
+	})
+}
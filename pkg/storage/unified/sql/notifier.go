@@ -23,8 +23,7 @@ type eventNotifier interface {
 
 func newNotifier(b *backend) (eventNotifier, error) {
 	if b.isHA {
-		b.log.Info("Using polling notifier")
-		notifier, err := newPollingNotifier(&pollingNotifierConfig{
+		cfg := &pollingNotifierConfig{
 			pollingInterval: b.pollingInterval,
 			watchBufferSize: b.watchBufferSize,
 			log:             b.log,
@@ -49,7 +48,19 @@ func newNotifier(b *backend) (eventNotifier, error) {
 			},
 			done:    b.done,
 			dialect: b.dialect,
-		})
+		}
+
+		if b.listenNotify && b.dialect.DialectName() == sqltemplate.PostgreSQL.DialectName() {
+			notifier, err := newPostgresNotifier(b, cfg)
+			if err == nil {
+				b.log.Info("Using postgres listen/notify notifier")
+				return notifier, nil
+			}
+			b.log.Warn("Failed to create postgres listen/notify notifier, falling back to polling", "err", err)
+		}
+
+		b.log.Info("Using polling notifier")
+		notifier, err := newPollingNotifier(cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -60,6 +71,26 @@ func newNotifier(b *backend) (eventNotifier, error) {
 	return newChannelNotifier(b.watchBufferSize, b.log), nil
 }
 
+func newPostgresNotifier(b *backend, cfg *pollingNotifierConfig) (*listenNotifier, error) {
+	listener, err := newPostgresListener(b.dbProvider, b.log)
+	if err != nil {
+		return nil, err
+	}
+	// polling only covers missed notifications
+	fallback := *cfg
+	fallback.pollingInterval = max(cfg.pollingInterval, listenFallbackPollingInterval)
+	notifier, err := newListenNotifier(&listenNotifierConfig{
+		polling:          &fallback,
+		listener:         listener,
+		sendNotification: sendPostgresNotification(b),
+	})
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return notifier, nil
+}
+
 type channelNotifier struct {
 	log        logging.Logger
 	bufferSize int
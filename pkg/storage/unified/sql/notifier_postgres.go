@@ -0,0 +1,203 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/dbutil"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+const (
+	// postgresNotifyChannel is the channel used to signal new writes to resource_history.
+	postgresNotifyChannel = "unified_storage_resource_history"
+
+	// listenFallbackPollingInterval is how often watchers poll when no
+	// notification was received, to cover notifications missed while the
+	// listener was disconnected.
+	listenFallbackPollingInterval = 5 * time.Second
+
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = 30 * time.Second
+)
+
+var (
+	errListenerRequired         = fmt.Errorf("listener is required")
+	errSendNotificationRequired = fmt.Errorf("sendNotification is required")
+)
+
+// notificationListener receives notifications from the database, it is
+// implemented by *pq.Listener. A nil notification is received after the
+// connection was re-established, meaning that notifications may have been lost.
+type notificationListener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Close() error
+}
+
+// listenNotifier is a notifier for PostgreSQL using LISTEN/NOTIFY. Every write
+// sends a notification, which wakes up the watchers of all the replicas to poll
+// the new events right away. Watchers still poll on a longer interval to cover
+// missed notifications.
+type listenNotifier struct {
+	*pollingNotifier
+
+	log              logging.Logger
+	listener         notificationListener
+	sendNotification func(ctx context.Context, event *resource.WrittenEvent) error
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]bool
+}
+
+type listenNotifierConfig struct {
+	polling          *pollingNotifierConfig
+	listener         notificationListener
+	sendNotification func(ctx context.Context, event *resource.WrittenEvent) error
+}
+
+func (cfg *listenNotifierConfig) validate() error {
+	if cfg.listener == nil {
+		return errListenerRequired
+	}
+	if cfg.sendNotification == nil {
+		return errSendNotificationRequired
+	}
+	return nil
+}
+
+func newListenNotifier(cfg *listenNotifierConfig) (*listenNotifier, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid listen notifier config: %w", err)
+	}
+	poller, err := newPollingNotifier(cfg.polling)
+	if err != nil {
+		return nil, err
+	}
+	n := &listenNotifier{
+		pollingNotifier:  poller,
+		log:              cfg.polling.log,
+		listener:         cfg.listener,
+		sendNotification: cfg.sendNotification,
+		subscribers:      make(map[chan struct{}]bool),
+	}
+	go n.run()
+	return n, nil
+}
+
+// run starts listening and wakes up the watchers on every notification. Listen
+// blocks until the connection is established, so it must not block Init.
+func (n *listenNotifier) run() {
+	go func() {
+		if err := n.listener.Listen(postgresNotifyChannel); err != nil && !n.isDone() {
+			n.log.Error("listen for resource history notifications", "err", err)
+		}
+	}()
+	defer n.close()
+
+	notifications := n.listener.NotificationChannel()
+	for {
+		select {
+		case <-n.done:
+			return
+		case _, ok := <-notifications:
+			if !ok {
+				return
+			}
+			n.wakeAll()
+		}
+	}
+}
+
+func (n *listenNotifier) isDone() bool {
+	select {
+	case <-n.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *listenNotifier) wakeAll() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for wake := range n.subscribers {
+		// a pending wake up already covers this notification
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *listenNotifier) notify(ctx context.Context) (<-chan *resource.WrittenEvent, error) {
+	since, err := n.listLatestRVs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("watch, get latest resource version: %w", err)
+	}
+
+	wake := make(chan struct{}, 1)
+	n.mu.Lock()
+	n.subscribers[wake] = true
+	n.mu.Unlock()
+
+	stream := make(chan *resource.WrittenEvent, n.watchBufferSize)
+	go func() {
+		defer func() {
+			n.mu.Lock()
+			delete(n.subscribers, wake)
+			n.mu.Unlock()
+		}()
+		n.poller(ctx, since, stream, wake)
+	}()
+	return stream, nil
+}
+
+func (n *listenNotifier) send(ctx context.Context, event *resource.WrittenEvent) {
+	// Local watchers are woken up by our own notification, like the ones of the
+	// other replicas. If it is lost, the fallback polling will find the event.
+	if err := n.sendNotification(ctx, event); err != nil {
+		n.log.Warn("send resource history notification", "err", err)
+	}
+}
+
+func (n *listenNotifier) close() {
+	if err := n.listener.Close(); err != nil {
+		n.log.Warn("close resource history listener", "err", err)
+	}
+}
+
+// newPostgresListener opens a dedicated connection for LISTEN. It reconnects
+// on its own when the connection is lost.
+func newPostgresListener(provider db.DBProvider, log logging.Logger) (notificationListener, error) {
+	p, ok := provider.(db.ConnectionStringProvider)
+	if !ok {
+		return nil, fmt.Errorf("db provider does not expose its connection string")
+	}
+	return pq.NewListener(p.ConnectionString(), listenerMinReconnectInterval, listenerMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Warn("resource history listener", "event", event, "err", err)
+			}
+		}), nil
+}
+
+func sendPostgresNotification(b *backend) func(ctx context.Context, event *resource.WrittenEvent) error {
+	return func(ctx context.Context, event *resource.WrittenEvent) error {
+		_, err := dbutil.Exec(ctx, b.db, sqlResourceHistoryNotify, sqlResourceHistoryNotifyRequest{
+			SQLTemplate: sqltemplate.New(b.dialect),
+			Channel:     postgresNotifyChannel,
+			Payload:     event.Key.Group + "/" + event.Key.Resource,
+		})
+		return err
+	}
+}
@@ -0,0 +1,179 @@
+package sql
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+type fakeListener struct {
+	listened      chan string
+	notifications chan *pq.Notification
+	closed        atomic.Bool
+}
+
+func newFakeListener() *fakeListener {
+	return &fakeListener{
+		listened:      make(chan string, 1),
+		notifications: make(chan *pq.Notification, 10),
+	}
+}
+
+func (l *fakeListener) Listen(channel string) error {
+	l.listened <- channel
+	return nil
+}
+
+func (l *fakeListener) NotificationChannel() <-chan *pq.Notification {
+	return l.notifications
+}
+
+func (l *fakeListener) Close() error {
+	l.closed.Store(true)
+	return nil
+}
+
+func TestListenNotifierConfig(t *testing.T) {
+	t.Parallel()
+
+	polling := &pollingNotifierConfig{
+		historyPoll: func(ctx context.Context, grp string, res string, since int64) ([]*historyPollResponse, error) {
+			return nil, nil
+		},
+		listLatestRVs:   func(ctx context.Context) (groupResourceRV, error) { return nil, nil },
+		bulkLock:        &bulkLock{},
+		tracer:          noop.NewTracerProvider().Tracer("test"),
+		log:             &logging.NoOpLogger{},
+		watchBufferSize: 10,
+		pollingInterval: time.Second,
+		done:            make(chan struct{}),
+		dialect:         sqltemplate.PostgreSQL,
+	}
+	sendNotification := func(ctx context.Context, event *resource.WrittenEvent) error { return nil }
+
+	_, err := newListenNotifier(&listenNotifierConfig{polling: polling, sendNotification: sendNotification})
+	require.ErrorIs(t, err, errListenerRequired)
+
+	_, err = newListenNotifier(&listenNotifierConfig{polling: polling, listener: newFakeListener()})
+	require.ErrorIs(t, err, errSendNotificationRequired)
+
+	invalid := *polling
+	invalid.historyPoll = nil
+	_, err = newListenNotifier(&listenNotifierConfig{polling: &invalid, listener: newFakeListener(), sendNotification: sendNotification})
+	require.ErrorIs(t, err, errHistoryPollRequired)
+}
+
+func TestListenNotifier(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*listenNotifier, *fakeListener, *atomic.Int64, chan struct{}) {
+		done := make(chan struct{})
+		var latestRV atomic.Int64
+
+		listener := newFakeListener()
+		notifier, err := newListenNotifier(&listenNotifierConfig{
+			polling: &pollingNotifierConfig{
+				dialect: sqltemplate.PostgreSQL,
+				// long enough to make sure events are not found by polling
+				pollingInterval: time.Hour,
+				watchBufferSize: 10,
+				log:             &logging.NoOpLogger{},
+				tracer:          noop.NewTracerProvider().Tracer("test"),
+				bulkLock:        &bulkLock{},
+				listLatestRVs: func(ctx context.Context) (groupResourceRV, error) {
+					return groupResourceRV{"test-group": {"test-resource": latestRV.Load()}}, nil
+				},
+				historyPoll: func(ctx context.Context, grp string, res string, since int64) ([]*historyPollResponse, error) {
+					return []*historyPollResponse{{
+						Key: resourcepb.ResourceKey{
+							Namespace: "test-ns",
+							Group:     grp,
+							Resource:  res,
+							Name:      "test-name",
+						},
+						ResourceVersion: latestRV.Load(),
+						Action:          1,
+					}}, nil
+				},
+				done: done,
+			},
+			listener: listener,
+			sendNotification: func(ctx context.Context, event *resource.WrittenEvent) error {
+				listener.notifications <- &pq.Notification{Channel: postgresNotifyChannel}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		select {
+		case channel := <-listener.listened:
+			require.Equal(t, postgresNotifyChannel, channel)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for listen")
+		}
+		return notifier, listener, &latestRV, done
+	}
+
+	t.Run("notification wakes up the watchers", func(t *testing.T) {
+		t.Parallel()
+
+		notifier, _, latestRV, done := setup(t)
+		defer close(done)
+
+		events, err := notifier.notify(context.Background())
+		require.NoError(t, err)
+
+		latestRV.Store(2)
+		notifier.send(context.Background(), &resource.WrittenEvent{
+			Key: &resourcepb.ResourceKey{Group: "test-group", Resource: "test-resource"},
+		})
+
+		select {
+		case event := <-events:
+			require.Equal(t, "test-name", event.Key.Name)
+			require.Equal(t, int64(2), event.ResourceVersion)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	})
+
+	t.Run("reconnect wakes up the watchers", func(t *testing.T) {
+		t.Parallel()
+
+		notifier, listener, latestRV, done := setup(t)
+		defer close(done)
+
+		events, err := notifier.notify(context.Background())
+		require.NoError(t, err)
+
+		// events written while disconnected are found once reconnected
+		latestRV.Store(3)
+		listener.notifications <- nil
+
+		select {
+		case event := <-events:
+			require.Equal(t, int64(3), event.ResourceVersion)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	})
+
+	t.Run("closes the listener when done", func(t *testing.T) {
+		t.Parallel()
+
+		_, listener, _, done := setup(t)
+		close(done)
+
+		require.Eventually(t, listener.closed.Load, time.Second, 10*time.Millisecond)
+	})
+}
@@ -115,11 +115,13 @@ func (p *pollingNotifier) notify(ctx context.Context) (<-chan *resource.WrittenE
 		return nil, fmt.Errorf("watch, get latest resource version: %w", err)
 	}
 	stream := make(chan *resource.WrittenEvent, p.watchBufferSize)
-	go p.poller(ctx, since, stream)
+	go p.poller(ctx, since, stream, nil)
 	return stream, nil
 }
 
-func (p *pollingNotifier) poller(ctx context.Context, since groupResourceRV, stream chan<- *resource.WrittenEvent) {
+// poller polls for new events every pollingInterval, or as soon as a value is
+// received on wake. A nil wake channel only polls on the interval.
+func (p *pollingNotifier) poller(ctx context.Context, since groupResourceRV, stream chan<- *resource.WrittenEvent, wake <-chan struct{}) {
 	t := time.NewTicker(p.pollingInterval)
 	defer close(stream)
 	defer t.Stop()
@@ -131,44 +133,51 @@ func (p *pollingNotifier) poller(ctx context.Context, since groupResourceRV, str
 		case <-p.done:
 			return
 		case <-t.C:
-			ctx, span := p.tracer.Start(ctx, tracePrefix+"poller")
-			// List the latest RVs to see if any of those are not have been seen before.
-			grv, err := p.listLatestRVs(ctx)
+			p.pollLatest(ctx, since, stream)
+			t.Reset(p.pollingInterval)
+		case <-wake:
+			p.pollLatest(ctx, since, stream)
+			t.Reset(p.pollingInterval)
+		}
+	}
+}
+
+// pollLatest sends the events of every group/resource whose latest resource
+// version is newer than the one in since, and updates since accordingly.
+func (p *pollingNotifier) pollLatest(ctx context.Context, since groupResourceRV, stream chan<- *resource.WrittenEvent) {
+	ctx, span := p.tracer.Start(ctx, tracePrefix+"poller")
+	defer span.End()
+
+	// List the latest RVs to see if any of those are not have been seen before.
+	grv, err := p.listLatestRVs(ctx)
+	if err != nil {
+		p.log.Error("poller get latest resource version", "err", err)
+		return
+	}
+	for group, items := range grv {
+		for resource, latestRV := range items {
+			// If we haven't seen this resource before, we start from 0.
+			if _, ok := since[group]; !ok {
+				since[group] = make(map[string]int64)
+			}
+			if _, ok := since[group][resource]; !ok {
+				since[group][resource] = 0
+			}
+
+			// We don't need to poll if the RV hasn't changed.
+			if since[group][resource] >= latestRV {
+				continue
+			}
+
+			// Poll for new events since the last known RV.
+			next, err := p.poll(ctx, group, resource, since[group][resource], stream)
 			if err != nil {
-				p.log.Error("poller get latest resource version", "err", err)
-				t.Reset(p.pollingInterval)
+				p.log.Error("polling for resource", "err", err)
 				continue
 			}
-			for group, items := range grv {
-				for resource, latestRV := range items {
-					// If we haven't seen this resource before, we start from 0.
-					if _, ok := since[group]; !ok {
-						since[group] = make(map[string]int64)
-					}
-					if _, ok := since[group][resource]; !ok {
-						since[group][resource] = 0
-					}
-
-					// We don't need to poll if the RV hasn't changed.
-					if since[group][resource] >= latestRV {
-						continue
-					}
-
-					// Poll for new events since the last known RV.
-					next, err := p.poll(ctx, group, resource, since[group][resource], stream)
-					if err != nil {
-						p.log.Error("polling for resource", "err", err)
-						t.Reset(p.pollingInterval)
-						continue
-					}
-					if next > since[group][resource] {
-						since[group][resource] = next
-					}
-				}
+			if next > since[group][resource] {
+				since[group][resource] = next
 			}
-
-			t.Reset(p.pollingInterval)
-			span.End()
 		}
 	}
 }
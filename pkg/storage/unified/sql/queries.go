@@ -44,6 +44,7 @@ var (
 	sqlResourceHistoryUpdateRV          = mustTemplate("resource_history_update_rv.sql")
 	sqlResourceHistoryInsert            = mustTemplate("resource_history_insert.sql")
 	sqlResourceHistoryPoll              = mustTemplate("resource_history_poll.sql")
+	sqlResourceHistoryNotify            = mustTemplate("resource_history_notify.sql")
 	sqlResourceHistoryGet               = mustTemplate("resource_history_get.sql")
 	sqlResourceHistoryDelete            = mustTemplate("resource_history_delete.sql")
 	sqlResourceHistoryPrune             = mustTemplate("resource_history_prune.sql")
@@ -58,6 +59,17 @@ var (
 
 	sqlResourceBlobInsert = mustTemplate("resource_blob_insert.sql")
 	sqlResourceBlobQuery  = mustTemplate("resource_blob_query.sql")
+
+	sqlResourceKVGet          = mustTemplate("resource_kv_get.sql")
+	sqlResourceKVGetChunk     = mustTemplate("resource_kv_get_chunk.sql")
+	sqlResourceKVKeys         = mustTemplate("resource_kv_keys.sql")
+	sqlResourceKVUpsert       = mustTemplate("resource_kv_upsert.sql")
+	sqlResourceKVDelete       = mustTemplate("resource_kv_delete.sql")
+	sqlResourceKVDeleteChunks = mustTemplate("resource_kv_delete_chunks.sql")
+	sqlResourceKVTimestamp    = mustTemplate("resource_kv_timestamp.sql")
+
+	sqlResourceLockInsert  = mustTemplate("resource_lock_insert.sql")
+	sqlResourceLockAcquire = mustTemplate("resource_lock_acquire.sql")
 )
 
 // TxOptions.
@@ -165,6 +177,21 @@ func (r *sqlResourceHistoryPollRequest) Results() (*historyPollResponse, error)
 	}, nil
 }
 
+// sqlResourceHistoryNotifyRequest wakes up the watchers of other replicas,
+// only supported by PostgreSQL.
+type sqlResourceHistoryNotifyRequest struct {
+	sqltemplate.SQLTemplate
+	Channel string
+	Payload string
+}
+
+func (r sqlResourceHistoryNotifyRequest) Validate() error {
+	if r.Channel == "" {
+		return fmt.Errorf("missing channel")
+	}
+	return nil
+}
+
 // sqlResourceReadRequest can be used to retrieve a row fromthe "resource" tables.
 func NewReadResponse() *resource.BackendReadResponse {
 	return &resource.BackendReadResponse{
@@ -311,13 +338,22 @@ func (r sqlGetHistoryRequest) Validate() error {
 type sqlPruneHistoryRequest struct {
 	sqltemplate.SQLTemplate
 	Key                   *resourcepb.ResourceKey
-	PartitionByGeneration bool // include generation in the partition
-	HistoryLimit          int64
+	PartitionByGeneration bool  // include generation in the partition
+	HistoryLimit          int64 // prune the versions after this many, 0 for no limit
+	MinResourceVersion    int64 // prune the versions older than this, 0 for no limit
+	KeepAtLeast           int64 // versions kept whatever their resource version
+	BatchSize             int64 // maximum number of versions deleted, 0 for no limit
 }
 
 func (r *sqlPruneHistoryRequest) Validate() error {
-	if r.HistoryLimit <= 0 {
-		return fmt.Errorf("history limit must be greater than zero")
+	if r.HistoryLimit < 0 || r.MinResourceVersion < 0 || r.BatchSize < 0 {
+		return fmt.Errorf("history limits can not be negative")
+	}
+	if r.HistoryLimit == 0 && r.MinResourceVersion == 0 {
+		return fmt.Errorf("history limit or minimum resource version must be set")
+	}
+	if r.MinResourceVersion > 0 && r.KeepAtLeast < 1 {
+		return fmt.Errorf("at least one version must be kept")
 	}
 	if r.Key == nil {
 		return fmt.Errorf("missing key")
@@ -334,6 +370,25 @@ func (r *sqlPruneHistoryRequest) Validate() error {
 	return nil
 }
 
+// lease on a named lock shared by the replicas
+type sqlResourceLockRequest struct {
+	sqltemplate.SQLTemplate
+	Name    string
+	Owner   string
+	Now     int64 // unix microseconds
+	Expires int64 // unix microseconds
+}
+
+func (r *sqlResourceLockRequest) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if r.Owner == "" {
+		return fmt.Errorf("missing owner")
+	}
+	return nil
+}
+
 type sqlResourceBlobInsertRequest struct {
 	sqltemplate.SQLTemplate
 	Now         time.Time
@@ -450,3 +505,119 @@ func (r sqlResourceListModifiedSinceRequest) Validate() error {
 	}
 	return nil
 }
+
+// KV
+
+type sqlKVKeyRequest struct {
+	sqltemplate.SQLTemplate
+	Section string
+	Key     []byte
+}
+
+func (r sqlKVKeyRequest) Validate() error {
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	if len(r.Key) == 0 {
+		return fmt.Errorf("key is required")
+	}
+	return nil
+}
+
+type sqlKVGetChunkRequest struct {
+	sqltemplate.SQLTemplate
+	Section string
+	Key     []byte
+	Chunk   int
+	GUID    string
+}
+
+func (r sqlKVGetChunkRequest) Validate() error {
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	if len(r.Key) == 0 {
+		return fmt.Errorf("key is required")
+	}
+	if r.GUID == "" {
+		return fmt.Errorf("missing guid")
+	}
+	return nil
+}
+
+type sqlKVUpsertRequest struct {
+	sqltemplate.SQLTemplate
+	Section string
+	Key     []byte
+	Chunk   int
+	GUID    string
+	Value   []byte
+}
+
+func (r sqlKVUpsertRequest) Validate() error {
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	if len(r.Key) == 0 {
+		return fmt.Errorf("key is required")
+	}
+	if r.GUID == "" {
+		return fmt.Errorf("missing guid")
+	}
+	return nil
+}
+
+type sqlKVDeleteChunksRequest struct {
+	sqltemplate.SQLTemplate
+	Section string
+	Key     []byte
+	// Chunk is the first chunk that is deleted
+	Chunk int
+}
+
+func (r sqlKVDeleteChunksRequest) Validate() error {
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	if len(r.Key) == 0 {
+		return fmt.Errorf("key is required")
+	}
+	return nil
+}
+
+type sqlKVKeysRequest struct {
+	sqltemplate.SQLTemplate
+	Section  string
+	StartKey []byte
+	AfterKey []byte
+	EndKey   []byte
+	SortDesc bool
+	Limit    int64
+}
+
+func (r sqlKVKeysRequest) Validate() error {
+	if r.Section == "" {
+		return fmt.Errorf("section is required")
+	}
+	if r.Limit < 1 {
+		return fmt.Errorf("limit must be greater than zero")
+	}
+	return nil
+}
+
+type kvTimestampResponse struct {
+	CurrentEpoch int64
+}
+
+type sqlKVTimestampRequest struct {
+	sqltemplate.SQLTemplate
+	Response *kvTimestampResponse
+}
+
+func (r sqlKVTimestampRequest) Validate() error {
+	return nil
+}
+
+func (r sqlKVTimestampRequest) Results() (*kvTimestampResponse, error) {
+	return &kvTimestampResponse{CurrentEpoch: r.Response.CurrentEpoch}, nil
+}
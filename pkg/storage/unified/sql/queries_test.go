@@ -145,6 +145,17 @@ func TestUnifiedStorageQueries(t *testing.T) {
 				},
 			},
 
+			sqlResourceHistoryNotify: {
+				{
+					Name: "simple",
+					Data: &sqlResourceHistoryNotifyRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Channel:     "unified_storage_resource_history",
+						Payload:     "group/res",
+					},
+				},
+			},
+
 			sqlResourceUpdateRV: {
 				{
 					Name: "single path",
@@ -315,6 +326,47 @@ func TestUnifiedStorageQueries(t *testing.T) {
 						HistoryLimit:          1,
 					},
 				},
+				{
+					Name: "max-age",
+					Data: &sqlPruneHistoryRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key: &resourcepb.ResourceKey{
+							Namespace: "default",
+							Group:     "dashboard.grafana.app",
+							Resource:  "dashboards",
+						},
+						MinResourceVersion: 1700000000000000,
+						KeepAtLeast:        5,
+					},
+				},
+				{
+					Name: "max-age-and-versions",
+					Data: &sqlPruneHistoryRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key: &resourcepb.ResourceKey{
+							Namespace: "default",
+							Group:     "dashboard.grafana.app",
+							Resource:  "dashboards",
+						},
+						HistoryLimit:       100,
+						MinResourceVersion: 1700000000000000,
+						KeepAtLeast:        5,
+					},
+				},
+				{
+					Name: "batch",
+					Data: &sqlPruneHistoryRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key: &resourcepb.ResourceKey{
+							Namespace: "default",
+							Group:     "dashboard.grafana.app",
+							Resource:  "dashboards",
+						},
+						HistoryLimit: 20,
+						KeepAtLeast:  1,
+						BatchSize:    1000,
+					},
+				},
 			},
 
 			sqlResourceVersionGet: {
@@ -446,6 +498,123 @@ func TestUnifiedStorageQueries(t *testing.T) {
 					},
 				},
 			},
+			sqlResourceKVGet: {
+				{
+					Name: "basic",
+					Data: &sqlKVKeyRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						Key:         []byte("a/b/c"),
+					},
+				},
+			},
+			sqlResourceKVGetChunk: {
+				{
+					Name: "basic",
+					Data: &sqlKVGetChunkRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						Key:         []byte("a/b/c"),
+						Chunk:       1,
+						GUID:        "xxx",
+					},
+				},
+			},
+			sqlResourceKVKeys: {
+				{
+					Name: "all",
+					Data: &sqlKVKeysRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						Limit:       100,
+					},
+				},
+				{
+					Name: "range",
+					Data: &sqlKVKeysRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						StartKey:    []byte("a"),
+						AfterKey:    []byte("b"),
+						EndKey:      []byte("c"),
+						Limit:       100,
+					},
+				},
+				{
+					Name: "desc",
+					Data: &sqlKVKeysRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						EndKey:      []byte("c"),
+						SortDesc:    true,
+						Limit:       100,
+					},
+				},
+			},
+			sqlResourceKVUpsert: {
+				{
+					Name: "basic",
+					Data: &sqlKVUpsertRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						Key:         []byte("a/b/c"),
+						GUID:        "xxx",
+						Value:       []byte("value"),
+					},
+				},
+			},
+			sqlResourceKVDelete: {
+				{
+					Name: "basic",
+					Data: &sqlKVKeyRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						Key:         []byte("a/b/c"),
+					},
+				},
+			},
+			sqlResourceKVDeleteChunks: {
+				{
+					Name: "basic",
+					Data: &sqlKVDeleteChunksRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Section:     "unified/data",
+						Key:         []byte("a/b/c"),
+						Chunk:       2,
+					},
+				},
+			},
+			sqlResourceKVTimestamp: {
+				{
+					Name: "basic",
+					Data: &sqlKVTimestampRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Response:    new(kvTimestampResponse),
+					},
+				},
+			},
+			sqlResourceLockInsert: {
+				{
+					Name: "basic",
+					Data: &sqlResourceLockRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Name:        "history_retention",
+						Owner:       "replica-1",
+					},
+				},
+			},
+			sqlResourceLockAcquire: {
+				{
+					Name: "basic",
+					Data: &sqlResourceLockRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Name:        "history_retention",
+						Owner:       "replica-1",
+						Now:         1700000000000000,
+						Expires:     1700003600000000,
+					},
+				},
+			},
 			sqlResourceHistoryDelete: {
 				{
 					Name: "guid",
@@ -99,14 +99,19 @@ func NewResourceServer(
 	isHA := isHighAvailabilityEnabled(opts.Cfg.SectionWithEnvOverrides("database"),
 		opts.Cfg.SectionWithEnvOverrides("resource_api"))
 	withPruner := opts.Features.IsEnabledGlobally(featuremgmt.FlagUnifiedStorageHistoryPruner)
+	// Push new events to the watchers of all replicas with LISTEN/NOTIFY when using PostgreSQL.
+	// Opt-in while it is experimental, see the README.
+	listenNotify := unifiedStorageCfg.Key("postgres_listen_notify").MustBool(false)
 
 	store, err := NewBackend(BackendOptions{
-		DBProvider:     eDB,
-		Tracer:         opts.Tracer,
-		Reg:            opts.Reg,
-		IsHA:           isHA,
-		withPruner:     withPruner,
-		storageMetrics: opts.StorageMetrics,
+		DBProvider:       eDB,
+		Tracer:           opts.Tracer,
+		Reg:              opts.Reg,
+		IsHA:             isHA,
+		ListenNotify:     listenNotify,
+		withPruner:       withPruner,
+		HistoryRetention: NewHistoryRetentionOptions(opts.Cfg),
+		storageMetrics:   opts.StorageMetrics,
 	})
 	if err != nil {
 		return nil, err
@@ -30,6 +30,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/grpcserver"
 	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/parquet"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	"github.com/grafana/grafana/pkg/storage/unified/resource/grpc"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
@@ -246,6 +247,8 @@ func (s *service) starting(ctx context.Context) error {
 	resourcepb.RegisterManagedObjectIndexServer(srv, server)
 	resourcepb.RegisterBlobStoreServer(srv, server)
 	resourcepb.RegisterDiagnosticsServer(srv, server)
+	resourcepb.RegisterRestoreStoreServer(srv, server)
+	resourcepb.RegisterArchiveStoreServer(srv, parquet.NewArchiveServer(resource.NewLocalResourceClient(server), s.cfg.ArchiveMaxImportSize))
 	grpc_health_v1.RegisterHealthServer(srv, healthService)
 
 	// register reflection service
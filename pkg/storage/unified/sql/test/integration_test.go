@@ -2,6 +2,8 @@ package test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"github.com/grafana/dskit/kv"
 	"github.com/grafana/dskit/services"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -57,6 +60,87 @@ func TestIntegrationStorageServer(t *testing.T) {
 	})
 }
 
+func TestIntegrationRestorePrunedHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := types.WithAuthInfo(testutil.NewDefaultTestContext(t), &identity.StaticRequester{
+		Type:           types.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true,
+	})
+
+	dbstore := db.InitTestDB(t)
+	eDB, err := dbimpl.ProvideResourceDB(dbstore, setting.NewCfg(), nil)
+	require.NoError(t, err)
+	backend, err := sql.NewBackend(sql.BackendOptions{
+		DBProvider: eDB,
+		IsHA:       true,
+		HistoryRetention: sql.HistoryRetentionOptions{
+			Default:  &sql.HistoryRetentionPolicy{MaxVersions: 1},
+			Interval: 50 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, backend.Init(ctx))
+	server, err := resource.NewResourceServer(resource.ResourceServerOptions{
+		Backend:      backend,
+		AccessClient: types.FixedAccessClient(true),
+	})
+	require.NoError(t, err)
+
+	key := &resourcepb.ResourceKey{Namespace: "restore-pruned", Group: "playlist.grafana.app", Resource: "playlists", Name: "a"}
+	value := func(generation int, title string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"apiVersion": "playlist.grafana.app/v1",
+			"kind": "Playlist",
+			"metadata": {"name": "a", "namespace": "restore-pruned", "generation": %d},
+			"spec": {"title": "%s"}
+		}`, generation, title))
+	}
+	created, err := server.Create(ctx, &resourcepb.CreateRequest{Key: key, Value: value(1, "a1")})
+	require.NoError(t, err)
+	require.Nil(t, created.Error)
+	rv := created.ResourceVersion
+	for i := 2; i <= 3; i++ {
+		updated, err := server.Update(ctx, &resourcepb.UpdateRequest{Key: key, ResourceVersion: rv, Value: value(i, fmt.Sprintf("a%d", i))})
+		require.NoError(t, err)
+		require.Nil(t, updated.Error)
+		rv = updated.ResourceVersion
+	}
+
+	// only the latest version is kept
+	require.Eventually(t, func() bool {
+		history, err := server.List(ctx, &resourcepb.ListRequest{
+			Source:  resourcepb.ListRequest_HISTORY,
+			Options: &resourcepb.ListOptions{Key: key},
+		})
+		return err == nil && history.Error == nil && len(history.Items) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// the resource existed at the target, but it can not be told apart from a resource created since
+	rsp, err := server.Restore(ctx, &resourcepb.RestoreRequest{
+		Namespace:       key.Namespace,
+		Group:           key.Group,
+		Resource:        key.Resource,
+		ResourceVersion: created.ResourceVersion,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, rsp.Error)
+	require.Equal(t, int32(http.StatusBadRequest), rsp.Error.Code)
+	require.Contains(t, rsp.Error.Message, "playlists/a")
+	require.Contains(t, rsp.Error.Message, "pruned")
+	require.Empty(t, rsp.Changes)
+
+	read, err := server.Read(ctx, &resourcepb.ReadRequest{Key: key})
+	require.NoError(t, err)
+	require.Nil(t, read.Error)
+	require.Equal(t, rv, read.ResourceVersion)
+}
+
 // TestStorageBackend is a test for the StorageBackend interface.
 func TestIntegrationSQLStorageBackend(t *testing.T) {
 	if testing.Short() {
@@ -101,6 +185,41 @@ func TestIntegrationSQLStorageBackend(t *testing.T) {
 	})
 }
 
+func TestIntegrationSQLKV(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	newKV := func(ctx context.Context) resource.KV {
+		dbstore := db.InitTestDB(t)
+		eDB, err := dbimpl.ProvideResourceDB(dbstore, setting.NewCfg(), nil)
+		require.NoError(t, err)
+		dbConn, err := eDB.Init(ctx)
+		require.NoError(t, err)
+		kv, err := sql.NewKV(dbConn)
+		require.NoError(t, err)
+		return kv
+	}
+
+	t.Run("KV", func(t *testing.T) {
+		unitest.RunKVTest(t, newKV, &unitest.KVTestOptions{
+			NSPrefix: "sql-kv-test",
+		})
+	})
+
+	t.Run("KV storage backend", func(t *testing.T) {
+		unitest.RunStorageBackendTest(t, func(ctx context.Context) resource.StorageBackend {
+			return resource.NewKvStorageBackend(newKV(ctx))
+		}, &unitest.TestOptions{
+			NSPrefix: "sql-kvstorage-test",
+			SkipTests: map[string]bool{
+				// same as the badger backed KV storage
+				unitest.TestBlobSupport:       true,
+				unitest.TestListModifiedSince: true,
+			},
+		})
+	})
+}
+
 func TestIntegrationSearchAndStorage(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
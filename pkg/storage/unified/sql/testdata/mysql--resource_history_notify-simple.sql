@@ -0,0 +1,2 @@
+SELECT pg_notify('unified_storage_resource_history', 'group/res')
+;
@@ -4,6 +4,7 @@ WHERE `guid` IN (
   FROM (
   SELECT
     `guid`,
+    `resource_version`,
     ROW_NUMBER() OVER (
       PARTITION BY `namespace`
         , `group`
@@ -0,0 +1,10 @@
+SELECT
+    `key`
+    FROM `resource_kv`
+    WHERE 1 = 1
+        AND `section` = 'unified/data'
+        AND `chunk`   = 0
+        AND `key`     < '[99]'
+    ORDER BY `key` DESC
+    LIMIT 100
+;
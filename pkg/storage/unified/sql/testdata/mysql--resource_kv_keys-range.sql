@@ -0,0 +1,12 @@
+SELECT
+    `key`
+    FROM `resource_kv`
+    WHERE 1 = 1
+        AND `section` = 'unified/data'
+        AND `chunk`   = 0
+        AND `key`    >= '[97]'
+        AND `key`     > '[98]'
+        AND `key`     < '[99]'
+    ORDER BY `key` ASC
+    LIMIT 100
+;
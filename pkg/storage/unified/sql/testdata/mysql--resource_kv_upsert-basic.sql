@@ -0,0 +1,19 @@
+INSERT INTO `resource_kv`
+    (
+        `section`,
+        `key`,
+        `chunk`,
+        `guid`,
+        `value`
+    )
+    VALUES (
+        'unified/data',
+        '[97 47 98 47 99]',
+        0,
+        'xxx',
+        '[118 97 108 117 101]'
+    )
+    ON DUPLICATE KEY UPDATE
+        `guid`  = 'xxx',
+        `value` = '[118 97 108 117 101]'
+;
@@ -0,0 +1,10 @@
+UPDATE `resource_lock`
+    SET
+        `owner`   = 'replica-1',
+        `expires` = 1700003600000000
+    WHERE `name` = 'history_retention'
+        AND (
+            `owner` = 'replica-1'
+            OR `expires` < 1700000000000000
+        )
+;
@@ -0,0 +1,13 @@
+INSERT INTO `resource_lock`
+    (
+        `name`,
+        `owner`,
+        `expires`
+    )
+    VALUES (
+        'history_retention',
+        '',
+        0
+    )
+    ON DUPLICATE KEY UPDATE `name` = `name`
+;
@@ -0,0 +1,25 @@
+DELETE FROM "resource_history"
+WHERE "guid" IN (
+  SELECT "guid"
+  FROM (
+  SELECT "guid"
+  FROM (
+  SELECT
+    "guid",
+    "resource_version",
+    ROW_NUMBER() OVER (
+      PARTITION BY "namespace"
+        , "group"
+        , "resource"
+        , "name"
+      ORDER BY "resource_version" DESC
+    ) AS "rn"
+  FROM "resource_history"
+  WHERE "namespace" = 'default'
+    AND "group" = 'dashboard.grafana.app'
+    AND "resource" = 'dashboards'
+  ) AS "ranked"
+  WHERE "rn" > 20
+  LIMIT 1000
+  ) AS "batch"
+);
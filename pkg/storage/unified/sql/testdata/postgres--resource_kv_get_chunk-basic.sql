@@ -0,0 +1,9 @@
+SELECT
+    "value"
+    FROM "resource_kv"
+    WHERE 1 = 1
+        AND "section" = 'unified/data'
+        AND "key"     = '[97 47 98 47 99]'
+        AND "chunk"   = 1
+        AND "guid"    = 'xxx'
+;
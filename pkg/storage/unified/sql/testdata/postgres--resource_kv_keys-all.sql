@@ -0,0 +1,9 @@
+SELECT
+    "key"
+    FROM "resource_kv"
+    WHERE 1 = 1
+        AND "section" = 'unified/data'
+        AND "chunk"   = 0
+    ORDER BY "key" ASC
+    LIMIT 100
+;
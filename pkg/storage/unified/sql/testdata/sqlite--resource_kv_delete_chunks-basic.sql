@@ -0,0 +1,6 @@
+DELETE FROM "resource_kv"
+    WHERE 1 = 1
+        AND "section" = 'unified/data'
+        AND "key"     = '[97 47 98 47 99]'
+        AND "chunk"  >= 2
+;
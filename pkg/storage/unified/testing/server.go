@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/grafana/authlib/types"
 
@@ -21,6 +22,7 @@ import (
 func RunStorageServerTest(t *testing.T, newBackend NewBackendFunc) {
 	runTestResourcePermissionScenarios(t, newBackend(context.Background()), GenerateRandomNSPrefix())
 	runTestListTrashAccessControl(t, newBackend(context.Background()), GenerateRandomNSPrefix())
+	runTestRestore(t, newBackend(context.Background()), GenerateRandomNSPrefix())
 }
 
 // func runTestIntegrationBackendHappyPath(t *testing.T, backend resource.StorageBackend, nsPrefix string) {
@@ -490,6 +492,212 @@ func runTestListTrashAccessControl(t *testing.T, backend resource.StorageBackend
 	}
 }
 
+func runTestRestore(t *testing.T, backend resource.StorageBackend, nsPrefix string) {
+	testUser := &identity.StaticRequester{
+		Type:           types.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true,
+	}
+	ctx := types.WithAuthInfo(context.Background(), testUser)
+	namespace := nsPrefix + "-restore"
+
+	server, err := resource.NewResourceServer(resource.ResourceServerOptions{
+		Backend:      backend,
+		AccessClient: &mockAccessClient{allowed: true},
+	})
+	require.NoError(t, err)
+	var restorer resourcepb.RestoreStoreServer = server
+
+	folderKey := func(name string) *resourcepb.ResourceKey {
+		return &resourcepb.ResourceKey{Group: "folder.grafana.app", Resource: "folders", Namespace: namespace, Name: name}
+	}
+	playlistKey := func(name string) *resourcepb.ResourceKey {
+		return &resourcepb.ResourceKey{Group: "playlist.grafana.app", Resource: "playlists", Namespace: namespace, Name: name}
+	}
+	value := func(key *resourcepb.ResourceKey, folder, title string) []byte {
+		kind := "Playlist"
+		if key.Resource == "folders" {
+			kind = "Folder"
+		}
+		return []byte(fmt.Sprintf(`{
+			"apiVersion": "%s/v1",
+			"kind": "%s",
+			"metadata": {
+				"name": "%s",
+				"uid": "uid-%s",
+				"namespace": "%s",
+				"annotations": {"grafana.app/folder": "%s"}
+			},
+			"spec": {"title": "%s"}
+		}`, key.Group, kind, key.Name, key.Name, namespace, folder, title))
+	}
+	create := func(key *resourcepb.ResourceKey, folder, title string) int64 {
+		rsp, err := server.Create(ctx, &resourcepb.CreateRequest{Key: key, Value: value(key, folder, title)})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		return rsp.ResourceVersion
+	}
+	update := func(key *resourcepb.ResourceKey, rv int64, folder, title string) int64 {
+		rsp, err := server.Update(ctx, &resourcepb.UpdateRequest{Key: key, ResourceVersion: rv, Value: value(key, folder, title)})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		return rsp.ResourceVersion
+	}
+	read := func(key *resourcepb.ResourceKey) string {
+		rsp, err := server.Read(ctx, &resourcepb.ReadRequest{Key: key})
+		require.NoError(t, err)
+		if rsp.Error != nil {
+			require.Equal(t, int32(http.StatusNotFound), rsp.Error.Code)
+			return ""
+		}
+		var obj map[string]any
+		require.NoError(t, json.Unmarshal(rsp.Value, &obj))
+		return obj["spec"].(map[string]any)["title"].(string)
+	}
+	changes := func(rsp *resourcepb.RestoreResponse) []string {
+		var out []string
+		for _, c := range rsp.Changes {
+			out = append(out, fmt.Sprintf("%s %s/%s", c.Action, c.Key.Resource, c.Key.Name))
+		}
+		return out
+	}
+
+	// f1 > f2, and another folder
+	create(folderKey("f1"), "", "f1")
+	create(folderKey("f2"), "f1", "f2")
+	create(folderKey("other"), "", "other")
+	rvA := create(playlistKey("a"), "f1", "a1")
+	rvB := create(playlistKey("b"), "f2", "b1")
+	rvC := create(playlistKey("c"), "other", "c1")
+	target := rvC
+
+	// changes after the target version
+	update(playlistKey("a"), rvA, "f1", "a2")
+	deleted, err := server.Delete(ctx, &resourcepb.DeleteRequest{Key: playlistKey("b"), ResourceVersion: rvB})
+	require.NoError(t, err)
+	require.Nil(t, deleted.Error)
+	create(playlistKey("d"), "f2", "d1")
+	update(playlistKey("c"), rvC, "other", "c2")
+
+	t.Run("validation", func(t *testing.T) {
+		rsp, err := restorer.Restore(ctx, &resourcepb.RestoreRequest{Namespace: namespace})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.Equal(t, int32(http.StatusBadRequest), rsp.Error.Code)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		// can read, but not write
+		denied, err := resource.NewResourceServer(resource.ResourceServerOptions{
+			Backend: backend,
+			AccessClient: &mockAccessClient{
+				allowed: false,
+				compileFn: func(user types.AuthInfo, req types.ListRequest) types.ItemChecker {
+					return func(name, folder string) bool { return true }
+				},
+			},
+		})
+		require.NoError(t, err)
+		rsp, err := denied.Restore(ctx, &resourcepb.RestoreRequest{
+			Namespace:       namespace,
+			ResourceVersion: target,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.Equal(t, int32(http.StatusForbidden), rsp.Error.Code)
+		require.NotEmpty(t, rsp.Changes)
+
+		// nothing was written
+		require.Equal(t, "a2", read(playlistKey("a")))
+	})
+
+	t.Run("preview a folder subtree", func(t *testing.T) {
+		rsp, err := restorer.Restore(ctx, &resourcepb.RestoreRequest{
+			Namespace:       namespace,
+			Folder:          "f1",
+			ResourceVersion: target,
+			DryRun:          true,
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, []string{
+			"UPDATE playlists/a",
+			"CREATE playlists/b",
+			"DELETE playlists/d",
+		}, changes(rsp))
+		require.Equal(t, []string{"spec"}, rsp.Changes[0].Fields)
+
+		// nothing was written
+		require.Equal(t, "a2", read(playlistKey("a")))
+		require.Equal(t, "", read(playlistKey("b")))
+	})
+
+	var previous int64
+	t.Run("restore a folder subtree", func(t *testing.T) {
+		rsp, err := restorer.Restore(ctx, &resourcepb.RestoreRequest{
+			Namespace:       namespace,
+			Folder:          "f1",
+			ResourceVersion: target,
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Len(t, rsp.Changes, 3)
+		for _, c := range rsp.Changes {
+			require.Nil(t, c.Error)
+			require.Greater(t, c.ResourceVersion, rsp.PreviousResourceVersion)
+		}
+		previous = rsp.PreviousResourceVersion
+
+		require.Equal(t, "a1", read(playlistKey("a")))
+		require.Equal(t, "b1", read(playlistKey("b")))
+		require.Equal(t, "", read(playlistKey("d")))
+		// outside of the folder
+		require.Equal(t, "c2", read(playlistKey("c")))
+
+		// the restore is a new version
+		history, err := server.List(ctx, &resourcepb.ListRequest{
+			Source:  resourcepb.ListRequest_HISTORY,
+			Options: &resourcepb.ListOptions{Key: playlistKey("a")},
+		})
+		require.NoError(t, err)
+		require.Len(t, history.Items, 3)
+	})
+
+	t.Run("revert the restore", func(t *testing.T) {
+		rsp, err := restorer.Restore(ctx, &resourcepb.RestoreRequest{
+			Namespace:       namespace,
+			Group:           "playlist.grafana.app",
+			Resource:        "playlists",
+			ResourceVersion: previous,
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Equal(t, []string{
+			"UPDATE playlists/a",
+			"CREATE playlists/d",
+			"DELETE playlists/b",
+		}, changes(rsp))
+
+		require.Equal(t, "a2", read(playlistKey("a")))
+		require.Equal(t, "", read(playlistKey("b")))
+		require.Equal(t, "d1", read(playlistKey("d")))
+	})
+
+	t.Run("nothing to restore", func(t *testing.T) {
+		rsp, err := restorer.Restore(ctx, &resourcepb.RestoreRequest{
+			Namespace: namespace,
+			Timestamp: time.Now().Add(time.Hour).UnixMilli(),
+			DryRun:    true,
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Empty(t, rsp.Changes)
+	})
+}
+
 // Mock access client for testing
 type mockAccessClient struct {
 	allowed    bool
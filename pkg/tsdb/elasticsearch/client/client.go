@@ -53,6 +53,9 @@ type Client interface {
 	GetConfiguredFields() ConfiguredFields
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	ExecuteESQL(r *ESQLRequest) (*ESQLResponse, error)
+	OpenPointInTime(timeRange backend.TimeRange, keepAlive string) (string, error)
+	ClosePointInTime(id string) error
 }
 
 // NewClient creates a new elasticsearch client
@@ -99,7 +102,7 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -131,7 +134,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*http.Response, error) {
 	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
@@ -140,17 +143,16 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 	u.Path = path.Join(u.Path, uriPath)
 	u.RawQuery = uriQuery
 
-	var req *http.Request
-	if method == http.MethodPost {
-		req, err = http.NewRequestWithContext(c.ctx, http.MethodPost, u.String(), bytes.NewBuffer(body))
-	} else {
-		req, err = http.NewRequestWithContext(c.ctx, http.MethodGet, u.String(), nil)
+	var reqBody io.Reader
+	if method != http.MethodGet && body != nil {
+		reqBody = bytes.NewBuffer(body)
 	}
+	req, err := http.NewRequestWithContext(c.ctx, method, u.String(), reqBody)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	//nolint:bodyclose
 	resp, err := c.ds.HTTPClient.Do(req)
@@ -294,6 +296,11 @@ func StreamMultiSearchResponse(body io.Reader, msr *MultiSearchResponse) error {
 						if err != nil {
 							return err
 						}
+					case "pit_id":
+						err := dec.Decode(&sr.PitID)
+						if err != nil {
+							return err
+						}
 					default:
 						// skip over unknown fields
 						err := skipUnknownField(dec)
@@ -456,6 +463,18 @@ func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchReque
 	multiRequests := []*multiRequest{}
 
 	for _, searchReq := range searchRequests {
+		// Searches of a point in time must not define indices
+		if searchReq.PointInTime != "" {
+			multiRequests = append(multiRequests, &multiRequest{
+				header: map[string]any{
+					"search_type": "query_then_fetch",
+				},
+				body:     searchReq,
+				interval: searchReq.Interval,
+			})
+			continue
+		}
+
 		indices, err := c.indexPattern.GetIndices(searchReq.TimeRange)
 		if err != nil {
 			err := fmt.Errorf("failed to get indices from index pattern. %s", err)
@@ -223,6 +223,40 @@ func TestClient_ExecuteMultisearch(t *testing.T) {
 		require.Error(t, err)
 		require.True(t, backend.IsDownstreamError(err))
 	})
+
+	t.Run("Should not send indices for searches of a point in time", func(t *testing.T) {
+		var requestBody []byte
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var err error
+			requestBody, err = io.ReadAll(r.Body)
+			require.NoError(t, err)
+			rw.Header().Set("Content-Type", "application/x-ndjson")
+			_, err = rw.Write([]byte(`{"responses": [{"hits": {"hits": []}, "pit_id": "pit-2"}]}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		c, err := NewClient(context.Background(), &DatasourceInfo{
+			URL:        ts.URL,
+			Database:   "[metrics-]YYYY.MM.DD",
+			Interval:   "Daily",
+			HTTPClient: ts.Client(),
+		}, log.NewNullLogger())
+		require.NoError(t, err)
+
+		ms := c.MultiSearch()
+		ms.Search(15*time.Second, backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()}).PointInTime("pit-1", "5m")
+		req, err := ms.Build()
+		require.NoError(t, err)
+
+		res, err := c.ExecuteMultisearch(req)
+		require.NoError(t, err)
+		require.Len(t, res.Responses, 1)
+		assert.Equal(t, "pit-2", res.Responses[0].PitID)
+
+		headerLine := bytes.SplitN(requestBody, []byte("\n"), 2)[0]
+		assert.JSONEq(t, `{"search_type": "query_then_fetch"}`, string(headerLine))
+	})
 }
 
 func TestClient_Index(t *testing.T) {
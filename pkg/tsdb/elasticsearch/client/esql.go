@@ -0,0 +1,113 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ESQLRequest represents a request to the ES|QL query API
+type ESQLRequest struct {
+	Query  string         `json:"query"`
+	Filter map[string]any `json:"filter,omitempty"`
+}
+
+// ESQLColumn represents a column of an ES|QL response
+type ESQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ESQLResponse represents a response of the ES|QL query API
+type ESQLResponse struct {
+	Columns []ESQLColumn `json:"columns"`
+	Values  [][]any      `json:"values"`
+}
+
+type esqlErrorResponse struct {
+	Error struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// NewESQLTimeRangeFilter returns a range filter on the time field that restricts an ES|QL query to the time range.
+func NewESQLTimeRangeFilter(timeField string, timeRange backend.TimeRange) map[string]any {
+	return map[string]any{
+		"range": map[string]any{
+			timeField: map[string]any{
+				"gte":    timeRange.From.UnixMilli(),
+				"lte":    timeRange.To.UnixMilli(),
+				"format": DateFormatEpochMS,
+			},
+		},
+	}
+}
+
+func (c *baseClientImpl) ExecuteESQL(r *ESQLRequest) (*ESQLResponse, error) {
+	var err error
+	_, span := tracing.DefaultTracer().Start(c.ctx, "datasource.elasticsearch.queryData.executeESQL", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, "_query", "", "application/json", body)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		c.logger.Error("Error received from Elasticsearch", "error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	if res.StatusCode >= 400 {
+		var errRes esqlErrorResponse
+		reason := fmt.Sprintf("unexpected status code: %d", res.StatusCode)
+		if decodeErr := json.NewDecoder(res.Body).Decode(&errRes); decodeErr == nil && errRes.Error.Reason != "" {
+			reason = errRes.Error.Reason
+		}
+		err = fmt.Errorf("ES|QL query failed: %s", reason)
+		if backend.ErrorSourceFromHTTPStatus(res.StatusCode) == backend.ErrorSourceDownstream {
+			err = backend.DownstreamError(err)
+		}
+		return nil, err
+	}
+
+	var esqlRes ESQLResponse
+	if err = json.NewDecoder(res.Body).Decode(&esqlRes); err != nil {
+		// Invalid JSON response from Elasticsearch
+		err = backend.DownstreamError(err)
+		c.logger.Error("Failed to decode ES|QL response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	return &esqlRes, nil
+}
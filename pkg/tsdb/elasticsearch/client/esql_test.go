@@ -0,0 +1,81 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ExecuteESQL(t *testing.T) {
+	newClient := func(t *testing.T, status int, response string) (Client, *http.Request, *ESQLRequest) {
+		t.Helper()
+		var request http.Request
+		var body ESQLRequest
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			request = *r
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(status)
+			_, err := rw.Write([]byte(response))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		c, err := NewClient(context.Background(), &DatasourceInfo{
+			URL:        ts.URL,
+			HTTPClient: ts.Client(),
+			Database:   "metrics",
+		}, log.New())
+		require.NoError(t, err)
+		return c, &request, &body
+	}
+
+	timeRange := backend.TimeRange{
+		From: time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC),
+		To:   time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC),
+	}
+
+	t.Run("should send the query and decode the response", func(t *testing.T) {
+		c, request, body := newClient(t, http.StatusOK, `{
+			"columns": [{"name": "@timestamp", "type": "date"}, {"name": "count", "type": "long"}],
+			"values": [["2018-05-15T17:50:00.000Z", 4]]
+		}`)
+
+		res, err := c.ExecuteESQL(&ESQLRequest{
+			Query:  "FROM metrics | STATS count = COUNT(*) BY @timestamp",
+			Filter: NewESQLTimeRangeFilter("@timestamp", timeRange),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/_query", request.URL.Path)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		assert.Equal(t, "FROM metrics | STATS count = COUNT(*) BY @timestamp", body.Query)
+		assert.Contains(t, body.Filter, "range")
+
+		require.Len(t, res.Columns, 2)
+		assert.Equal(t, ESQLColumn{Name: "count", Type: "long"}, res.Columns[1])
+		assert.Equal(t, [][]any{{"2018-05-15T17:50:00.000Z", float64(4)}}, res.Values)
+	})
+
+	t.Run("should return the reason of a failed query as a downstream error", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusBadRequest, `{
+			"error": {"type": "verification_exception", "reason": "Unknown column [foo]"},
+			"status": 400
+		}`)
+
+		_, err := c.ExecuteESQL(&ESQLRequest{Query: "FROM metrics | KEEP foo"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Unknown column [foo]")
+		assert.True(t, backend.IsDownstreamError(err))
+	})
+}
@@ -20,10 +20,18 @@ type SearchRequest struct {
 	Aggs        AggArray
 	CustomProps map[string]interface{}
 	TimeRange   backend.TimeRange
+	// RawBody is sent instead of the other properties of the request if it is set
+	RawBody json.RawMessage
+	// PointInTime is the ID of the point in time that is searched instead of the indices of the data source
+	PointInTime string
 }
 
 // MarshalJSON returns the JSON encoding of the request.
 func (r *SearchRequest) MarshalJSON() ([]byte, error) {
+	if len(r.RawBody) > 0 {
+		return r.RawBody, nil
+	}
+
 	root := make(map[string]interface{})
 
 	root["size"] = r.Size
@@ -60,6 +68,7 @@ type SearchResponse struct {
 	Error        map[string]interface{} `json:"error"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 	Hits         *SearchResponseHits    `json:"hits"`
+	PitID        string                 `json:"pit_id"`
 }
 
 // MultiSearchRequest represents a multi search request
@@ -251,6 +260,13 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation
+type CompositeAggregation struct {
+	Size    int              `json:"size"`
+	Sources []map[string]any `json:"sources"`
+	After   map[string]any   `json:"after,omitempty"`
+}
+
 // NestedAggregation represents a nested aggregation
 type NestedAggregation struct {
 	Path string `json:"path"`
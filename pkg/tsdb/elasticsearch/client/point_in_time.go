@@ -0,0 +1,80 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+type pointInTimeResponse struct {
+	ID string `json:"id"`
+}
+
+// OpenPointInTime opens a point in time on the indices of the time range and returns its ID.
+// The point in time is kept alive for the given duration after each search that uses it.
+func (c *baseClientImpl) OpenPointInTime(timeRange backend.TimeRange, keepAlive string) (string, error) {
+	indices, err := c.indexPattern.GetIndices(timeRange)
+	if err != nil {
+		return "", backend.DownstreamError(fmt.Errorf("failed to get indices from index pattern. %s", err))
+	}
+
+	params := url.Values{}
+	params.Set("keep_alive", keepAlive)
+	params.Set("ignore_unavailable", "true")
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, strings.Join(indices, ",")+"/_pit", params.Encode(), "application/json", nil)
+	if err != nil {
+		c.logger.Error("Failed to open point in time", "error", err, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return "", err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode >= 400 {
+		err := fmt.Errorf("failed to open point in time: unexpected status code: %d", res.StatusCode)
+		if backend.ErrorSourceFromHTTPStatus(res.StatusCode) == backend.ErrorSourceDownstream {
+			err = backend.DownstreamError(err)
+		}
+		return "", err
+	}
+
+	var pit pointInTimeResponse
+	if err := json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		return "", backend.DownstreamError(fmt.Errorf("failed to decode point in time response: %w", err))
+	}
+	c.logger.Debug("Opened point in time", "duration", time.Since(start))
+	return pit.ID, nil
+}
+
+// ClosePointInTime closes a point in time so that Elasticsearch can release its resources before it expires.
+func (c *baseClientImpl) ClosePointInTime(id string) error {
+	body, err := json.Marshal(pointInTimeResponse{ID: id})
+	if err != nil {
+		return err
+	}
+
+	res, err := c.executeRequest(http.MethodDelete, "_pit", "", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	// A point in time that already expired can not be found
+	if res.StatusCode >= 400 && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to close point in time: unexpected status code: %d", res.StatusCode)
+	}
+	return nil
+}
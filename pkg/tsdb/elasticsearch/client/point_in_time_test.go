@@ -0,0 +1,87 @@
+package es
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PointInTime(t *testing.T) {
+	newClient := func(t *testing.T, status int, response string) (Client, *http.Request, *string) {
+		t.Helper()
+		var request http.Request
+		var body string
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			request = *r
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			body = string(b)
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(status)
+			_, err = rw.Write([]byte(response))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		c, err := NewClient(context.Background(), &DatasourceInfo{
+			URL:        ts.URL,
+			HTTPClient: ts.Client(),
+			Database:   "[metrics-]YYYY.MM.DD",
+			Interval:   "Daily",
+		}, log.New())
+		require.NoError(t, err)
+		return c, &request, &body
+	}
+
+	timeRange := backend.TimeRange{
+		From: time.Date(2018, 5, 14, 17, 50, 0, 0, time.UTC),
+		To:   time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC),
+	}
+
+	t.Run("should open a point in time on the indices of the time range", func(t *testing.T) {
+		c, request, _ := newClient(t, http.StatusOK, `{"id": "pit-1"}`)
+
+		id, err := c.OpenPointInTime(timeRange, "5m")
+
+		require.NoError(t, err)
+		assert.Equal(t, "pit-1", id)
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/metrics-2018.05.14,metrics-2018.05.15/_pit", request.URL.Path)
+		assert.Equal(t, "5m", request.URL.Query().Get("keep_alive"))
+		assert.Equal(t, "true", request.URL.Query().Get("ignore_unavailable"))
+	})
+
+	t.Run("should return client errors as downstream errors", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusForbidden, `{}`)
+
+		_, err := c.OpenPointInTime(timeRange, "5m")
+
+		require.Error(t, err)
+		assert.True(t, backend.IsDownstreamError(err))
+	})
+
+	t.Run("should close a point in time", func(t *testing.T) {
+		c, request, body := newClient(t, http.StatusOK, `{"succeeded": true}`)
+
+		err := c.ClosePointInTime("pit-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, request.Method)
+		assert.Equal(t, "/_pit", request.URL.Path)
+		assert.JSONEq(t, `{"id": "pit-1"}`, *body)
+	})
+
+	t.Run("should ignore points in time that already expired", func(t *testing.T) {
+		c, _, _ := newClient(t, http.StatusNotFound, `{"succeeded": false}`)
+
+		require.NoError(t, c.ClosePointInTime("pit-1"))
+	})
+}
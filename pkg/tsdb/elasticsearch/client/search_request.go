@@ -1,6 +1,7 @@
 package es
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -34,6 +35,8 @@ type SearchRequestBuilder struct {
 	aggBuilders  []AggBuilder
 	customProps  map[string]any
 	timeRange    backend.TimeRange
+	rawBody      json.RawMessage
+	pointInTime  string
 }
 
 // NewSearchRequestBuilder create a new search request builder
@@ -57,6 +60,8 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Size:        b.size,
 		Sort:        b.sort,
 		CustomProps: b.customProps,
+		RawBody:     b.rawBody,
+		PointInTime: b.pointInTime,
 	}
 
 	if b.queryBuilder != nil {
@@ -145,6 +150,22 @@ func (b *SearchRequestBuilder) AddSearchAfter(value any) *SearchRequestBuilder {
 	return b
 }
 
+// RawBody sets a body that is sent as is instead of the body built from the other properties of the request
+func (b *SearchRequestBuilder) RawBody(body json.RawMessage) *SearchRequestBuilder {
+	b.rawBody = body
+	return b
+}
+
+// PointInTime makes the request search the point in time with the given ID and extends its keep alive
+func (b *SearchRequestBuilder) PointInTime(id string, keepAlive string) *SearchRequestBuilder {
+	b.pointInTime = id
+	b.customProps["pit"] = map[string]any{
+		"id":         id,
+		"keep_alive": keepAlive,
+	}
+	return b
+}
+
 // Query creates and return a query builder
 func (b *SearchRequestBuilder) Query() *QueryBuilder {
 	if b.queryBuilder == nil {
@@ -305,6 +326,7 @@ type AggBuilder interface {
 	Histogram(key, field string, fn func(a *HistogramAgg, b AggBuilder)) AggBuilder
 	DateHistogram(key, field string, fn func(a *DateHistogramAgg, b AggBuilder)) AggBuilder
 	Terms(key, field string, fn func(a *TermsAggregation, b AggBuilder)) AggBuilder
+	Composite(key string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder
 	Nested(key, path string, fn func(a *NestedAggregation, b AggBuilder)) AggBuilder
 	Filters(key string, fn func(a *FiltersAggregation, b AggBuilder)) AggBuilder
 	GeoHashGrid(key, field string, fn func(a *GeoHashGridAggregation, b AggBuilder)) AggBuilder
@@ -416,6 +438,26 @@ func (b *aggBuilderImpl) Terms(key, field string, fn func(a *TermsAggregation, b
 	return b
 }
 
+func (b *aggBuilderImpl) Composite(key string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &CompositeAggregation{
+		Sources: make([]map[string]any, 0),
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "composite",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder()
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) Nested(key, field string, fn func(a *NestedAggregation, b AggBuilder)) AggBuilder {
 	innerAgg := &NestedAggregation{
 		Path: field,
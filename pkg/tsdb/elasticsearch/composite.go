@@ -0,0 +1,161 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+// defaultCompositeMaxBuckets is the maximum number of buckets fetched for a composite aggregation that does not define one
+const defaultCompositeMaxBuckets = 10000
+
+// compositePaging holds the state of paging through the buckets of the composite aggregation of a query
+type compositePaging struct {
+	query      *Query
+	aggDef     *BucketAgg
+	response   int
+	agg        map[string]any
+	buckets    []any
+	afterKey   map[string]any
+	pageSize   int
+	maxBuckets int
+}
+
+// hasNextPage returns true if the last page was full and more buckets can be collected.
+func (p *compositePaging) hasNextPage(lastPageLen int) bool {
+	return len(p.afterKey) > 0 && lastPageLen >= p.pageSize && len(p.buckets) < p.maxBuckets
+}
+
+func isCompositeQuery(query *Query) bool {
+	return len(query.BucketAggs) > 0 && query.BucketAggs[0].Type == compositeType
+}
+
+// pageCompositeAggregations requests the following pages of the composite aggregations of the responses
+// until all buckets are fetched or the bucket cap of the aggregation is reached. The buckets of all pages
+// are merged into the first response, with the composite keys replaced by the value of their source so that
+// the buckets can be processed like terms buckets.
+func (e *elasticsearchDataQuery) pageCompositeAggregations(queries []*Query, responses []*es.SearchResponse) {
+	var paging []*compositePaging
+	for i, q := range queries {
+		if !isCompositeQuery(q) || i >= len(responses) || responses[i].Error != nil {
+			continue
+		}
+		aggDef := q.BucketAggs[0]
+		agg, ok := responses[i].Aggregations[aggDef.ID].(map[string]any)
+		if !ok {
+			continue
+		}
+		p := &compositePaging{
+			query:      q,
+			aggDef:     aggDef,
+			response:   i,
+			agg:        agg,
+			pageSize:   compositeSetting(aggDef, "size", defaultSize),
+			maxBuckets: compositeSetting(aggDef, "maxBuckets", defaultCompositeMaxBuckets),
+		}
+		p.mergePage(agg)
+		paging = append(paging, p)
+	}
+
+	pending := make([]*compositePaging, 0, len(paging))
+	for _, p := range paging {
+		if p.hasNextPage(len(p.buckets)) {
+			pending = append(pending, p)
+		}
+	}
+
+	for len(pending) > 0 {
+		pending = e.requestNextCompositePages(pending, responses)
+	}
+
+	for _, p := range paging {
+		if responses[p.response].Error != nil {
+			continue
+		}
+		if len(p.buckets) > p.maxBuckets {
+			p.buckets = p.buckets[:p.maxBuckets]
+		}
+		for _, b := range p.buckets {
+			if bucket, ok := b.(map[string]any); ok {
+				if key, ok := bucket["key"].(map[string]any); ok {
+					bucket["key"] = key[p.aggDef.Field]
+				}
+			}
+		}
+		p.agg["buckets"] = p.buckets
+		delete(p.agg, "after_key")
+		p.aggDef.Settings.Del("after")
+	}
+}
+
+// requestNextCompositePages requests the next page of every pending composite aggregation in a single
+// multi search request and returns the aggregations that have more pages.
+func (e *elasticsearchDataQuery) requestNextCompositePages(pending []*compositePaging, responses []*es.SearchResponse) []*compositePaging {
+	start := time.Now()
+	fail := func(err error) []*compositePaging {
+		e.logger.Error("Failed to request next page of composite aggregations", "error", err, "queriesLength", len(pending), "duration", time.Since(start), "stage", es.StageDatabaseRequest)
+		for _, p := range pending {
+			responses[p.response] = &es.SearchResponse{Error: map[string]any{"reason": err.Error()}}
+		}
+		return nil
+	}
+
+	ms := e.client.MultiSearch()
+	for _, p := range pending {
+		p.aggDef.Settings.Set("after", p.afterKey)
+		from := p.query.TimeRange.From.UnixNano() / int64(time.Millisecond)
+		to := p.query.TimeRange.To.UnixNano() / int64(time.Millisecond)
+		if err := e.processQuery(p.query, ms, from, to); err != nil {
+			return fail(err)
+		}
+	}
+
+	req, err := ms.Build()
+	if err != nil {
+		return fail(err)
+	}
+	res, err := e.client.ExecuteMultisearch(req)
+	if err != nil {
+		return fail(err)
+	}
+	if res.Status >= 400 {
+		return fail(fmt.Errorf("unexpected status code: %d", res.Status))
+	}
+	if len(res.Responses) != len(pending) {
+		return fail(fmt.Errorf("unexpected number of responses: %d", len(res.Responses)))
+	}
+
+	next := make([]*compositePaging, 0, len(pending))
+	for i, p := range pending {
+		if res.Responses[i].Error != nil {
+			responses[p.response] = res.Responses[i]
+			continue
+		}
+		agg, ok := res.Responses[i].Aggregations[p.aggDef.ID].(map[string]any)
+		if !ok {
+			continue
+		}
+		if p.hasNextPage(p.mergePage(agg)) {
+			next = append(next, p)
+		}
+	}
+	e.logger.Debug("Requested next page of composite aggregations", "queriesLength", len(pending), "duration", time.Since(start))
+	return next
+}
+
+// mergePage adds the buckets of a page to the collected buckets and returns the number of buckets of the page.
+func (p *compositePaging) mergePage(agg map[string]any) int {
+	buckets, _ := agg["buckets"].([]any)
+	p.buckets = append(p.buckets, buckets...)
+	p.afterKey, _ = agg["after_key"].(map[string]any)
+	return len(buckets)
+}
+
+// compositeSetting returns a positive numeric setting of a composite aggregation, which can be stored as a number or a string.
+func compositeSetting(aggDef *BucketAgg, key string, defaultValue int) int {
+	if value, err := aggDef.Settings.Get(key).Int(); err == nil && value > 0 {
+		return value
+	}
+	return stringToIntWithDefaultValue(aggDef.Settings.Get(key).MustString(), defaultValue)
+}
@@ -0,0 +1,121 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestCompositeAggregation(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	page := func(t *testing.T, body string) *es.MultiSearchResponse {
+		t.Helper()
+		var res es.SearchResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &res))
+		return &es.MultiSearchResponse{Status: 200, Responses: []*es.SearchResponse{&res}}
+	}
+
+	query := func(settings string) string {
+		return `{
+			"bucketAggs": [{"type": "composite", "id": "2", "field": "host", "settings": ` + settings + `}],
+			"metrics": [{"type": "count", "id": "1"}]
+		}`
+	}
+
+	t.Run("should build a composite aggregation with a terms source", func(t *testing.T) {
+		c := newFakeClient()
+		_, err := executeElasticsearchDataQuery(c, query(`{"size": "2"}`), from, to)
+		require.NoError(t, err)
+
+		sr := c.multisearchRequests[0].Requests[0]
+		agg := sr.Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+		assert.Equal(t, "composite", sr.Aggs[0].Aggregation.Type)
+		assert.Equal(t, 2, agg.Size)
+		assert.Equal(t, []map[string]any{{"host": map[string]any{"terms": map[string]any{"field": "host"}}}}, agg.Sources)
+		assert.Empty(t, agg.After)
+	})
+
+	t.Run("should page through the buckets until the last page", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchPages = []*es.MultiSearchResponse{
+			page(t, `{"aggregations": {"2": {"after_key": {"host": "b"}, "buckets": [
+				{"key": {"host": "a"}, "doc_count": 1},
+				{"key": {"host": "b"}, "doc_count": 2}
+			]}}}`),
+			page(t, `{"aggregations": {"2": {"after_key": {"host": "c"}, "buckets": [
+				{"key": {"host": "c"}, "doc_count": 3}
+			]}}}`),
+		}
+
+		res, err := executeElasticsearchDataQuery(c, query(`{"size": 2}`), from, to)
+		require.NoError(t, err)
+
+		require.Len(t, c.multisearchRequests, 2)
+		after := c.multisearchRequests[1].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation).After
+		assert.Equal(t, map[string]any{"host": "b"}, after)
+
+		require.NoError(t, res.Responses["A"].Error)
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 3, frames[0].Rows())
+		assert.Equal(t, "host", frames[0].Fields[0].Name)
+		assert.Equal(t, "c", *frames[0].Fields[0].At(2).(*string))
+		assert.Equal(t, float64(3), *frames[0].Fields[1].At(2).(*float64))
+	})
+
+	t.Run("should stop paging at the bucket cap", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchPages = []*es.MultiSearchResponse{
+			page(t, `{"aggregations": {"2": {"after_key": {"host": "b"}, "buckets": [
+				{"key": {"host": "a"}, "doc_count": 1},
+				{"key": {"host": "b"}, "doc_count": 2}
+			]}}}`),
+			page(t, `{"aggregations": {"2": {"after_key": {"host": "d"}, "buckets": [
+				{"key": {"host": "c"}, "doc_count": 3},
+				{"key": {"host": "d"}, "doc_count": 4}
+			]}}}`),
+		}
+
+		res, err := executeElasticsearchDataQuery(c, query(`{"size": 2, "maxBuckets": 3}`), from, to)
+		require.NoError(t, err)
+
+		require.Len(t, c.multisearchRequests, 2)
+		require.Equal(t, 3, res.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("should return an error if a page fails", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchPages = []*es.MultiSearchResponse{
+			page(t, `{"aggregations": {"2": {"after_key": {"host": "a"}, "buckets": [{"key": {"host": "a"}, "doc_count": 1}]}}}`),
+			page(t, `{"error": {"reason": "too many buckets"}}`),
+		}
+
+		res, err := executeElasticsearchDataQuery(c, query(`{"size": 1}`), from, to)
+		require.NoError(t, err)
+
+		require.Error(t, res.Responses["A"].Error)
+		assert.Contains(t, res.Responses["A"].Error.Error(), "too many buckets")
+	})
+
+	t.Run("should reject a composite aggregation that is not the first bucket aggregation", func(t *testing.T) {
+		c := newFakeClient()
+		res, err := executeElasticsearchDataQuery(c, `{
+			"bucketAggs": [
+				{"type": "terms", "id": "2", "field": "env"},
+				{"type": "composite", "id": "3", "field": "host"}
+			],
+			"metrics": [{"type": "count", "id": "1"}]
+		}`, from, to)
+		require.NoError(t, err)
+
+		require.Error(t, res.Responses["A"].Error)
+		assert.Empty(t, c.multisearchRequests)
+	})
+}
@@ -29,6 +29,8 @@ type elasticsearchDataQuery struct {
 	logger               log.Logger
 	ctx                  context.Context
 	keepLabelsInResponse bool
+	// signs the pagination cursors
+	cursorKey []byte
 }
 
 var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, req *backend.QueryDataRequest, logger log.Logger) *elasticsearchDataQuery {
@@ -58,6 +60,21 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return response, nil
 	}
 
+	// ES|QL queries are not part of the multi search request and are sent separately
+	searchQueries := make([]*Query, 0, len(queries))
+	for _, q := range queries {
+		if isESQLQuery(q) {
+			response.Responses[q.RefID] = e.executeESQLQuery(q)
+			continue
+		}
+		searchQueries = append(searchQueries, q)
+	}
+	if len(searchQueries) == 0 {
+		return response, nil
+	}
+	queries = searchQueries
+	defer e.closePointInTimes(queries)
+
 	ms := e.client.MultiSearch()
 
 	for _, q := range queries {
@@ -75,7 +92,7 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 	if err != nil {
 		mqs, _ := json.Marshal(e.dataQueries)
 		e.logger.Error("Failed to build multisearch request", "error", err, "queriesLength", len(queries), "queries", string(mqs), "duration", time.Since(start), "stage", es.StagePrepareRequest)
-		response.Responses[e.dataQueries[0].RefID] = backend.ErrorResponseWithErrorSource(err)
+		response.Responses[queries[0].RefID] = backend.ErrorResponseWithErrorSource(err)
 		return response, nil
 	}
 
@@ -92,32 +109,53 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 				err = backend.DownstreamError(err)
 			}
 		}
-		response.Responses[e.dataQueries[0].RefID] = backend.ErrorResponseWithErrorSource(err)
+		response.Responses[queries[0].RefID] = backend.ErrorResponseWithErrorSource(err)
 		return response, nil
 	}
 
 	if res.Status >= 400 {
 		statusErr := fmt.Errorf("unexpected status code: %d", res.Status)
 		if backend.ErrorSourceFromHTTPStatus(res.Status) == backend.ErrorSourceDownstream {
-			response.Responses[e.dataQueries[0].RefID] = backend.ErrorResponseWithErrorSource(backend.DownstreamError(statusErr))
+			response.Responses[queries[0].RefID] = backend.ErrorResponseWithErrorSource(backend.DownstreamError(statusErr))
 		} else {
-			response.Responses[e.dataQueries[0].RefID] = backend.ErrorResponseWithErrorSource(backend.PluginError(statusErr))
+			response.Responses[queries[0].RefID] = backend.ErrorResponseWithErrorSource(backend.PluginError(statusErr))
 		}
 		return response, nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger)
+	e.pageCompositeAggregations(queries, res.Responses)
+
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger)
+	if err != nil {
+		return result, err
+	}
+	e.setPaginationCursors(queries, res.Responses, result)
+	for refID, res := range response.Responses {
+		result.Responses[refID] = res
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
+	if isRawDSLQuery(q) {
+		return processRawDSLQuery(q, ms, from, to)
+	}
+
 	err := isQueryWithError(q)
 	if err != nil {
 		return backend.DownstreamError(fmt.Errorf("received invalid query. %w", err))
 	}
 
+	if err := e.preparePagination(q); err != nil {
+		return err
+	}
+
 	defaultTimeField := e.client.GetConfiguredFields().TimeField
 	b := ms.Search(q.Interval, q.TimeRange)
 	b.Size(0)
+	if q.pagination != nil {
+		b.PointInTime(q.pagination.PitID, pointInTimeKeepAlive)
+	}
 	filters := b.Query().Bool().Filter()
 	filters.AddDateRangeFilter(defaultTimeField, to, from, es.DateFormatEpochMS)
 	filters.AddQueryStringFilter(q.RawQuery, true)
@@ -294,6 +332,25 @@ func addTermsAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, metrics []*Metr
 	return aggBuilder
 }
 
+func addCompositeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.Composite(bucketAgg.ID, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = compositeSetting(bucketAgg, "size", defaultSize)
+		// The source is named after the field, so that the keys of the buckets can be mapped back to it
+		a.Sources = append(a.Sources, map[string]any{
+			bucketAgg.Field: map[string]any{
+				"terms": map[string]any{"field": bucketAgg.Field},
+			},
+		})
+		if after := bucketAgg.Settings.Get("after").MustMap(); len(after) > 0 {
+			a.After = after
+		}
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
 func addNestedAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	aggBuilder.Nested(bucketAgg.ID, bucketAgg.Field, func(a *es.NestedAggregation, b es.AggBuilder) {
 		aggBuilder = b
@@ -347,6 +404,12 @@ func getPipelineAggField(m *MetricAgg) string {
 }
 
 func isQueryWithError(query *Query) error {
+	for i, bucketAgg := range query.BucketAggs {
+		// Elasticsearch does not allow a composite aggregation to have a parent aggregation
+		if bucketAgg.Type == compositeType && i > 0 {
+			return fmt.Errorf("invalid query, composite aggregation must be the first bucket aggregation")
+		}
+	}
 	if len(query.BucketAggs) == 0 {
 		// If no aggregations, only document and logs queries are valid
 		if len(query.Metrics) == 0 || (!isLogsQuery(query) && !isDocumentQuery(query)) {
@@ -380,7 +443,7 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 		sort = es.SortOrderAsc
 	}
 	b.Sort(sort, defaultTimeField, "boolean")
-	b.Sort(sort, "_doc", "")
+	b.Sort(sort, tiebreakerSortField(q), "")
 	b.AddDocValueField(defaultTimeField)
 	// We need to add timeField as field with standardized time format to not receive
 	// invalid formats that elasticsearch can parse, but our frontend can't (e.g. yyyy_MM_dd_HH_mm_ss)
@@ -391,6 +454,10 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 	// This is currently used only for log context query to get
 	// log lines before and after the selected log line
 	searchAfter := metric.Settings.Get("searchAfter").MustArray()
+	if q.pagination != nil {
+		b.Size(q.pagination.pageSize)
+		searchAfter = q.pagination.SearchAfter
+	}
 	for _, value := range searchAfter {
 		b.AddSearchAfter(value)
 	}
@@ -415,7 +482,7 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defaultTimeField string) {
 	metric := q.Metrics[0]
 	b.Sort(es.SortOrderDesc, defaultTimeField, "boolean")
-	b.Sort(es.SortOrderDesc, "_doc", "")
+	b.Sort(es.SortOrderDesc, tiebreakerSortField(q), "")
 	b.AddDocValueField(defaultTimeField)
 	if isRawDataQuery(q) {
 		// For raw_data queries we need to add timeField as field with standardized time format to not receive
@@ -423,6 +490,21 @@ func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, from, to int64,
 		b.AddTimeFieldWithStandardizedFormat(defaultTimeField)
 	}
 	b.Size(stringToIntWithDefaultValue(metric.Settings.Get("size").MustString(), defaultSize))
+	if q.pagination != nil {
+		b.Size(q.pagination.pageSize)
+		for _, value := range q.pagination.SearchAfter {
+			b.AddSearchAfter(value)
+		}
+	}
+}
+
+// tiebreakerSortField returns the field that orders documents with the same timestamp.
+// Searches of a point in time are ordered by _shard_doc, which is unique across shards.
+func tiebreakerSortField(q *Query) string {
+	if q.pagination != nil {
+		return "_shard_doc"
+	}
+	return "_doc"
 }
 
 func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defaultTimeField string) {
@@ -442,6 +524,8 @@ func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64
 			aggBuilder = addFiltersAgg(aggBuilder, bucketAgg)
 		case termsType:
 			aggBuilder = addTermsAgg(aggBuilder, bucketAgg, q.Metrics)
+		case compositeType:
+			aggBuilder = addCompositeAgg(aggBuilder, bucketAgg)
 		case geohashGridType:
 			aggBuilder = addGeoHashGridAgg(aggBuilder, bucketAgg)
 		case nestedType:
@@ -1842,9 +1842,18 @@ func TestSettingsCasting(t *testing.T) {
 type fakeClient struct {
 	configuredFields    es.ConfiguredFields
 	multiSearchResponse *es.MultiSearchResponse
+	// multiSearchPages are returned by consecutive multi search requests before multiSearchResponse
+	multiSearchPages    []*es.MultiSearchResponse
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	esqlResponse        *es.ESQLResponse
+	esqlError           error
+	esqlRequests        []*es.ESQLRequest
+	pointInTimeID       string
+	pointInTimeError    error
+	openedPointInTimes  int
+	closedPointInTimes  []string
 }
 
 func newFakeClient() *fakeClient {
@@ -1867,9 +1876,29 @@ func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchPages) > 0 {
+		page := c.multiSearchPages[0]
+		c.multiSearchPages = c.multiSearchPages[1:]
+		return page, c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteESQL(r *es.ESQLRequest) (*es.ESQLResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.esqlResponse, c.esqlError
+}
+
+func (c *fakeClient) OpenPointInTime(timeRange backend.TimeRange, keepAlive string) (string, error) {
+	c.openedPointInTimes++
+	return c.pointInTimeID, c.pointInTimeError
+}
+
+func (c *fakeClient) ClosePointInTime(id string) error {
+	c.closedPointInTimes = append(c.closedPointInTimes, id)
+	return nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
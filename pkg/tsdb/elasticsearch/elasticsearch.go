@@ -3,6 +3,7 @@ package elasticsearch
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 
+	"github.com/grafana/grafana/pkg/setting"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 )
 
@@ -35,12 +37,21 @@ const (
 type Service struct {
 	im     instancemgmt.InstanceManager
 	logger log.Logger
+	// cursorKey signs the pagination cursors, it is the same on every Grafana server
+	cursorKey []byte
 }
 
-func ProvideService(httpClientProvider *httpclient.Provider) *Service {
+func ProvideService(cfg *setting.Cfg, httpClientProvider *httpclient.Provider) *Service {
+	cursorKey := []byte(cfg.SecretKey)
+	if len(cursorKey) == 0 {
+		// without a secret key the cursors are only valid on this server
+		cursorKey = make([]byte, 32)
+		_, _ = rand.Read(cursorKey)
+	}
 	return &Service{
-		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
-		logger: backend.NewLoggerWith("logger", "tsdb.elasticsearch"),
+		im:        datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		logger:    backend.NewLoggerWith("logger", "tsdb.elasticsearch"),
+		cursorKey: cursorKey,
 	}
 }
 
@@ -54,11 +65,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
-	return queryData(ctx, req, dsInfo, logger)
+	return queryData(ctx, req, dsInfo, s.cursorKey, logger)
 }
 
 // separate function to allow testing the whole transformation and query flow
-func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *es.DatasourceInfo, logger log.Logger) (*backend.QueryDataResponse, error) {
+func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *es.DatasourceInfo, cursorKey []byte, logger log.Logger) (*backend.QueryDataResponse, error) {
 	if len(req.Queries) == 0 {
 		return &backend.QueryDataResponse{}, fmt.Errorf("query contains no queries")
 	}
@@ -68,6 +79,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *es.Da
 		return &backend.QueryDataResponse{}, err
 	}
 	query := newElasticsearchDataQuery(ctx, client, req, logger)
+	query.cursorKey = cursorKey
 	return query.execute()
 }
 
@@ -0,0 +1,276 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+// esqlQueryType is the query type of queries that are written in ES|QL
+const esqlQueryType = "esql"
+
+func isESQLQuery(query *Query) bool {
+	return query.QueryType == esqlQueryType
+}
+
+// executeESQLQuery sends an ES|QL query restricted to the time range of the query and converts the result to data frames.
+func (e *elasticsearchDataQuery) executeESQLQuery(q *Query) backend.DataResponse {
+	start := time.Now()
+	if strings.TrimSpace(q.RawQuery) == "" {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(errors.New("ES|QL query is empty")))
+	}
+
+	timeField := e.client.GetConfiguredFields().TimeField
+	res, err := e.client.ExecuteESQL(&es.ESQLRequest{
+		Query:  q.RawQuery,
+		Filter: es.NewESQLTimeRangeFilter(timeField, q.TimeRange),
+	})
+	if err != nil {
+		if backend.IsDownstreamHTTPError(err) {
+			err = backend.DownstreamError(err)
+		}
+		e.logger.Error("Failed to execute ES|QL query", "error", err, "refId", q.RefID, "duration", time.Since(start), "stage", es.StageDatabaseRequest)
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frames, err := esqlResponseToFrames(res, q)
+	if err != nil {
+		e.logger.Error("Failed to process ES|QL response", "error", err, "refId", q.RefID, "stage", es.StageParseResponse)
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+	}
+	return backend.DataResponse{Frames: frames}
+}
+
+// esqlResponseToFrames converts the columnar ES|QL response to data frames.
+// If the response has a time column, at least one numeric column and grouping columns, it is returned as
+// a multi-frame time series with one frame per group. Otherwise, it is returned as a single table frame.
+func esqlResponseToFrames(res *es.ESQLResponse, q *Query) (data.Frames, error) {
+	timeIdx := -1
+	var valueIdxs, groupIdxs []int
+	for i, col := range res.Columns {
+		switch esqlFieldType(col.Type) {
+		case data.FieldTypeNullableTime:
+			if timeIdx == -1 {
+				timeIdx = i
+			}
+		case data.FieldTypeNullableFloat64:
+			valueIdxs = append(valueIdxs, i)
+		case data.FieldTypeNullableString:
+			groupIdxs = append(groupIdxs, i)
+		}
+	}
+
+	if timeIdx != -1 && len(valueIdxs) > 0 && len(groupIdxs) > 0 {
+		return esqlResponseToTimeSeries(res, q, timeIdx, valueIdxs, groupIdxs)
+	}
+	frame, err := esqlResponseToTable(res, q)
+	if err != nil {
+		return nil, err
+	}
+	return data.Frames{frame}, nil
+}
+
+func esqlResponseToTable(res *es.ESQLResponse, q *Query) (*data.Frame, error) {
+	frame := data.NewFrame("")
+	frame.RefID = q.RefID
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: q.RawQuery}
+	multiValued := map[int]bool{}
+	for i, col := range res.Columns {
+		field := data.NewFieldFromFieldType(esqlFieldType(col.Type), len(res.Values))
+		field.Name = col.Name
+		for r, row := range res.Values {
+			if i >= len(row) {
+				continue
+			}
+			if isESQLMultiValue(field.Type(), row[i]) {
+				multiValued[i] = true
+			}
+			v, err := esqlValue(field.Type(), row[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read column %q: %w", col.Name, err)
+			}
+			field.Set(r, v)
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	frame.AppendNotices(esqlMultiValueNotices(res, multiValued)...)
+	return frame, nil
+}
+
+func esqlResponseToTimeSeries(res *es.ESQLResponse, q *Query, timeIdx int, valueIdxs, groupIdxs []int) (data.Frames, error) {
+	type row struct {
+		time   time.Time
+		values []any
+	}
+	rows := make([]row, 0, len(res.Values))
+	multiValued := map[int]bool{}
+	for _, values := range res.Values {
+		if len(values) < len(res.Columns) {
+			continue
+		}
+		if isESQLMultiValue(data.FieldTypeNullableTime, values[timeIdx]) {
+			multiValued[timeIdx] = true
+		}
+		t, err := esqlValue(data.FieldTypeNullableTime, values[timeIdx])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read column %q: %w", res.Columns[timeIdx].Name, err)
+		}
+		// Rows without a timestamp can not be part of a time series
+		if t.(*time.Time) == nil {
+			continue
+		}
+		rows = append(rows, row{time: *t.(*time.Time), values: values})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].time.Before(rows[j].time)
+	})
+
+	frames := data.Frames{}
+	framesByGroup := map[string]*data.Frame{}
+	for _, r := range rows {
+		labels := data.Labels{}
+		for _, idx := range groupIdxs {
+			v, err := esqlValue(data.FieldTypeNullableString, r.values[idx])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read column %q: %w", res.Columns[idx].Name, err)
+			}
+			if s := v.(*string); s != nil {
+				labels[res.Columns[idx].Name] = *s
+			}
+		}
+
+		key := labels.String()
+		frame, ok := framesByGroup[key]
+		if !ok {
+			frame = data.NewFrame("", data.NewField(data.TimeSeriesTimeFieldName, nil, []time.Time{}))
+			for _, idx := range valueIdxs {
+				frame.Fields = append(frame.Fields, data.NewField(res.Columns[idx].Name, labels, []*float64{}))
+			}
+			frame.RefID = q.RefID
+			frame.Meta = &data.FrameMeta{
+				Type:                data.FrameTypeTimeSeriesMulti,
+				TypeVersion:         data.FrameTypeVersion{0, 1},
+				ExecutedQueryString: q.RawQuery,
+			}
+			framesByGroup[key] = frame
+			frames = append(frames, frame)
+		}
+
+		frame.Fields[0].Append(r.time)
+		for i, idx := range valueIdxs {
+			if isESQLMultiValue(data.FieldTypeNullableFloat64, r.values[idx]) {
+				multiValued[idx] = true
+			}
+			v, err := esqlValue(data.FieldTypeNullableFloat64, r.values[idx])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read column %q: %w", res.Columns[idx].Name, err)
+			}
+			frame.Fields[i+1].Append(v)
+		}
+	}
+	notices := esqlMultiValueNotices(res, multiValued)
+	for _, frame := range frames {
+		frame.AppendNotices(notices...)
+	}
+	return frames, nil
+}
+
+// isESQLMultiValue returns true if a value of a multi-valued field is reduced to its first value by esqlValue.
+func isESQLMultiValue(fieldType data.FieldType, v any) bool {
+	values, ok := v.([]any)
+	return ok && len(values) > 1 && fieldType != data.FieldTypeNullableString
+}
+
+// esqlMultiValueNotices returns a warning per column of which only the first values of multi-valued fields are shown.
+func esqlMultiValueNotices(res *es.ESQLResponse, multiValued map[int]bool) []data.Notice {
+	var notices []data.Notice
+	for i, col := range res.Columns {
+		if multiValued[i] {
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Column %q has multiple values in some rows, only their first value is shown", col.Name),
+			})
+		}
+	}
+	return notices
+}
+
+// esqlFieldType returns the data frame field type of an ES|QL column type.
+func esqlFieldType(columnType string) data.FieldType {
+	switch columnType {
+	case "date", "date_nanos":
+		return data.FieldTypeNullableTime
+	case "double", "float", "half_float", "scaled_float", "long", "integer", "short", "byte", "unsigned_long",
+		"counter_double", "counter_long", "counter_integer":
+		return data.FieldTypeNullableFloat64
+	case "boolean":
+		return data.FieldTypeNullableBool
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+// esqlValue converts a value of an ES|QL response to a value of the given field type.
+// Multi-valued fields are returned as arrays, they are serialized for string fields and
+// reduced to their first value for the other field types.
+func esqlValue(fieldType data.FieldType, v any) (any, error) {
+	if values, ok := v.([]any); ok && fieldType != data.FieldTypeNullableString {
+		v = nil
+		if len(values) > 0 {
+			v = values[0]
+		}
+	}
+	switch fieldType {
+	case data.FieldTypeNullableTime:
+		switch value := v.(type) {
+		case nil:
+			return (*time.Time)(nil), nil
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, err
+			}
+			return &t, nil
+		case float64:
+			t := time.UnixMilli(int64(value)).UTC()
+			return &t, nil
+		}
+	case data.FieldTypeNullableFloat64:
+		switch value := v.(type) {
+		case nil:
+			return (*float64)(nil), nil
+		case float64:
+			return &value, nil
+		}
+	case data.FieldTypeNullableBool:
+		switch value := v.(type) {
+		case nil:
+			return (*bool)(nil), nil
+		case bool:
+			return &value, nil
+		}
+	case data.FieldTypeNullableString:
+		switch value := v.(type) {
+		case nil:
+			return (*string)(nil), nil
+		case string:
+			return &value, nil
+		default:
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			s := string(b)
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected value %v of type %T for field type %s", v, v, fieldType)
+}
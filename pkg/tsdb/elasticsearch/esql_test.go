@@ -0,0 +1,259 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestESQLQuery(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	executeESQL := func(t *testing.T, c *fakeClient, queries ...backend.DataQuery) *backend.QueryDataResponse {
+		t.Helper()
+		for i := range queries {
+			queries[i].TimeRange = backend.TimeRange{From: from, To: to}
+		}
+		query := newElasticsearchDataQuery(context.Background(), c, &backend.QueryDataRequest{Queries: queries}, log.New())
+		res, err := query.execute()
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("should send the query with a time range filter", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{
+			Columns: []es.ESQLColumn{{Name: "host", Type: "keyword"}, {Name: "count", Type: "long"}},
+			Values:  [][]any{{"a", float64(1)}, {nil, float64(2)}},
+		}
+
+		res := executeESQL(t, c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{"query": "FROM logs | STATS count = COUNT(*) BY host"}`),
+		})
+
+		require.Len(t, c.esqlRequests, 1)
+		assert.Empty(t, c.multisearchRequests)
+		assert.Equal(t, "FROM logs | STATS count = COUNT(*) BY host", c.esqlRequests[0].Query)
+		assert.Equal(t, map[string]any{
+			"range": map[string]any{
+				"@timestamp": map[string]any{
+					"gte":    from.UnixMilli(),
+					"lte":    to.UnixMilli(),
+					"format": es.DateFormatEpochMS,
+				},
+			},
+		}, c.esqlRequests[0].Filter)
+
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		frame := res.Responses["A"].Frames[0]
+		require.Len(t, frame.Fields, 2)
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+		assert.Nil(t, frame.Fields[0].At(1))
+		assert.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+	})
+
+	t.Run("should run search queries alongside ES|QL queries", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{}
+
+		res := executeESQL(t, c,
+			backend.DataQuery{
+				RefID:     "A",
+				QueryType: esqlQueryType,
+				JSON:      json.RawMessage(`{"query": "FROM logs"}`),
+			},
+			backend.DataQuery{
+				RefID: "B",
+				JSON:  json.RawMessage(`{"metrics": [{"type": "count", "id": "1"}], "bucketAggs": [{"type": "date_histogram", "id": "2"}]}`),
+			},
+		)
+
+		require.Len(t, c.esqlRequests, 1)
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		assert.Contains(t, res.Responses, "A")
+	})
+
+	t.Run("should return an error for an empty query", func(t *testing.T) {
+		c := newFakeClient()
+
+		res := executeESQL(t, c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{"query": " "}`),
+		})
+
+		assert.Empty(t, c.esqlRequests)
+		require.Error(t, res.Responses["A"].Error)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+	})
+
+	t.Run("should return client errors per query", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlError = backend.DownstreamError(assert.AnError)
+
+		res := executeESQL(t, c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{"query": "FROM logs"}`),
+		})
+
+		require.ErrorIs(t, res.Responses["A"].Error, assert.AnError)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+	})
+}
+
+func TestESQLResponseToFrames(t *testing.T) {
+	query := &Query{RefID: "A", RawQuery: "FROM metrics"}
+
+	t.Run("should return a time series per group", func(t *testing.T) {
+		res := &es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "bucket", Type: "date"},
+				{Name: "host", Type: "keyword"},
+				{Name: "avg", Type: "double"},
+			},
+			Values: [][]any{
+				{"2024-01-01T00:01:00.000Z", "a", float64(2)},
+				{"2024-01-01T00:00:00.000Z", "a", float64(1)},
+				{"2024-01-01T00:00:00.000Z", "b", nil},
+				{nil, "b", float64(5)},
+			},
+		}
+
+		frames, err := esqlResponseToFrames(res, query)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		assert.Equal(t, data.FrameTypeTimeSeriesMulti, frames[0].Meta.Type)
+		assert.Equal(t, "A", frames[0].RefID)
+
+		a := frames[0]
+		require.Equal(t, 2, a.Rows())
+		assert.Equal(t, data.Labels{"host": "a"}, a.Fields[1].Labels)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), a.Fields[0].At(0))
+		assert.Equal(t, float64(1), *a.Fields[1].At(0).(*float64))
+		assert.Equal(t, float64(2), *a.Fields[1].At(1).(*float64))
+
+		b := frames[1]
+		require.Equal(t, 1, b.Rows())
+		assert.Equal(t, data.Labels{"host": "b"}, b.Fields[1].Labels)
+		assert.Nil(t, b.Fields[1].At(0))
+	})
+
+	t.Run("should return a table without a time column", func(t *testing.T) {
+		res := &es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "bytes", Type: "long"},
+				{Name: "ok", Type: "boolean"},
+				{Name: "tags", Type: "keyword"},
+			},
+			Values: [][]any{
+				{float64(10), true, []any{"x", "y"}},
+			},
+		}
+
+		frames, err := esqlResponseToFrames(res, query)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		assert.Equal(t, "FROM metrics", frame.Meta.ExecutedQueryString)
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, float64(10), *frame.Fields[0].At(0).(*float64))
+		assert.Equal(t, true, *frame.Fields[1].At(0).(*bool))
+		assert.Equal(t, `["x","y"]`, *frame.Fields[2].At(0).(*string))
+	})
+
+	t.Run("should return a table without grouping columns", func(t *testing.T) {
+		res := &es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "@timestamp", Type: "date"},
+				{Name: "bytes", Type: "long"},
+			},
+			Values: [][]any{
+				{"2024-01-01T00:00:00.000Z", float64(10)},
+			},
+		}
+
+		frames, err := esqlResponseToFrames(res, query)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 2)
+		assert.Equal(t, data.FieldTypeNullableTime, frames[0].Fields[0].Type())
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *frames[0].Fields[0].At(0).(*time.Time))
+	})
+
+	t.Run("should show the first value of multi-valued fields in a table", func(t *testing.T) {
+		res := &es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "bytes", Type: "long"},
+				{Name: "ok", Type: "boolean"},
+			},
+			Values: [][]any{
+				{[]any{float64(10), float64(20)}, []any{true}},
+				{[]any{}, false},
+			},
+		}
+
+		frames, err := esqlResponseToFrames(res, query)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		assert.Equal(t, float64(10), *frame.Fields[0].At(0).(*float64))
+		assert.Nil(t, frame.Fields[0].At(1))
+		assert.Equal(t, true, *frame.Fields[1].At(0).(*bool))
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		assert.Contains(t, frame.Meta.Notices[0].Text, `"bytes"`)
+	})
+
+	t.Run("should show the first value of multi-valued fields in a time series", func(t *testing.T) {
+		res := &es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "@timestamp", Type: "date"},
+				{Name: "host", Type: "keyword"},
+				{Name: "bytes", Type: "long"},
+			},
+			Values: [][]any{
+				{"2024-01-01T00:00:00.000Z", []any{"a", "b"}, []any{float64(1), float64(2)}},
+			},
+		}
+
+		frames, err := esqlResponseToFrames(res, query)
+
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, data.Labels{"host": `["a","b"]`}, frames[0].Fields[1].Labels)
+		assert.Equal(t, float64(1), *frames[0].Fields[1].At(0).(*float64))
+		require.Len(t, frames[0].Meta.Notices, 1)
+		assert.Contains(t, frames[0].Meta.Notices[0].Text, `"bytes"`)
+	})
+
+	t.Run("should fail on values that do not match the column type", func(t *testing.T) {
+		res := &es.ESQLResponse{
+			Columns: []es.ESQLColumn{{Name: "bytes", Type: "long"}},
+			Values:  [][]any{{"10"}},
+		}
+
+		_, err := esqlResponseToFrames(res, query)
+
+		require.Error(t, err)
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 // Query represents the time series query model of the datasource
 type Query struct {
+	QueryType     string       `json:"queryType"`
 	RawQuery      string       `json:"query"`
 	BucketAggs    []*BucketAgg `json:"bucketAggs"`
 	Metrics       []*MetricAgg `json:"metrics"`
@@ -18,6 +19,9 @@ type Query struct {
 	RefID         string
 	MaxDataPoints int64
 	TimeRange     backend.TimeRange
+
+	// pagination is set for logs and raw document queries that are paginated with search_after
+	pagination *pagination
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
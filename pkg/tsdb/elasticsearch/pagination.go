@@ -0,0 +1,184 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// pointInTimeKeepAlive is how long Elasticsearch keeps a point in time alive between the pages of a query
+	pointInTimeKeepAlive = "5m"
+	// maxPaginatedDocuments is the maximum number of documents fetched over all pages of a paginated query
+	maxPaginatedDocuments = 100000
+)
+
+// pagination holds the state of a logs or raw document query that is paginated with search_after
+// on a point in time. It is returned to the client as an opaque cursor in the frame metadata.
+// The cursor is signed, so the client can not change the number of fetched documents.
+type pagination struct {
+	PitID       string `json:"pitId"`
+	SearchAfter []any  `json:"searchAfter,omitempty"`
+	Fetched     int    `json:"fetched"`
+
+	pageSize   int
+	nextCursor string
+}
+
+// encodeCursor returns the state as base64 JSON followed by its HMAC signature
+func encodeCursor(p *pagination, key []byte) (string, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b) + "." + base64.RawURLEncoding.EncodeToString(signCursor(b, key)), nil
+}
+
+func decodeCursor(cursor string, key []byte) (*pagination, error) {
+	payload, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, errors.New("missing signature")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, signCursor(b, key)) {
+		return nil, errors.New("invalid signature")
+	}
+	// Sort values are decoded as numbers to not lose the precision of large integers
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	p := &pagination{}
+	if err := dec.Decode(p); err != nil {
+		return nil, err
+	}
+	if p.PitID == "" {
+		return nil, errors.New("missing point in time ID")
+	}
+	return p, nil
+}
+
+func signCursor(b []byte, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+// preparePagination opens a point in time for a query that requests pagination, or restores the state of
+// the pagination from the cursor of the query. The page size of the query is limited so that the total number
+// of fetched documents does not exceed maxPaginatedDocuments.
+func (e *elasticsearchDataQuery) preparePagination(q *Query) error {
+	if !isLogsQuery(q) && !isDocumentQuery(q) {
+		return nil
+	}
+	metric := q.Metrics[0]
+
+	var p *pagination
+	if cursor := metric.Settings.Get("cursor").MustString(); cursor != "" {
+		var err error
+		if p, err = decodeCursor(cursor, e.cursorKey); err != nil {
+			return backend.DownstreamError(errors.New("invalid pagination cursor"))
+		}
+	} else if metric.Settings.Get("paginate").MustBool(false) {
+		id, err := e.client.OpenPointInTime(q.TimeRange, pointInTimeKeepAlive)
+		if err != nil {
+			return err
+		}
+		p = &pagination{PitID: id}
+	} else {
+		return nil
+	}
+
+	sizeSetting := "size"
+	if isLogsQuery(q) {
+		sizeSetting = "limit"
+	}
+	p.pageSize = min(stringToIntWithDefaultValue(metric.Settings.Get(sizeSetting).MustString(), defaultSize), maxPaginatedDocuments-p.Fetched)
+	if p.pageSize <= 0 {
+		e.closePointInTime(p)
+		return backend.DownstreamError(errors.New("pagination cursor exceeds the maximum number of documents"))
+	}
+	q.pagination = p
+	return nil
+}
+
+// setPaginationCursors adds the cursor of the next page to the first frame of every paginated query
+// that returned a full page and has not reached maxPaginatedDocuments.
+func (e *elasticsearchDataQuery) setPaginationCursors(queries []*Query, responses []*es.SearchResponse, result *backend.QueryDataResponse) {
+	for i, q := range queries {
+		p := q.pagination
+		if p == nil || i >= len(responses) {
+			continue
+		}
+		res := responses[i]
+		// Elasticsearch can return a new point in time ID for each search
+		if res.PitID != "" {
+			p.PitID = res.PitID
+		}
+		if res.Error != nil || res.Hits == nil || len(res.Hits.Hits) < p.pageSize {
+			continue
+		}
+		sortValues, ok := res.Hits.Hits[len(res.Hits.Hits)-1]["sort"].([]any)
+		if !ok {
+			continue
+		}
+		fetched := p.Fetched + len(res.Hits.Hits)
+		if fetched >= maxPaginatedDocuments {
+			continue
+		}
+
+		cursor, err := encodeCursor(&pagination{PitID: p.PitID, SearchAfter: sortValues, Fetched: fetched}, e.cursorKey)
+		if err != nil {
+			e.logger.Warn("Failed to encode pagination cursor", "error", err, "refId", q.RefID)
+			continue
+		}
+
+		queryRes := result.Responses[q.RefID]
+		if len(queryRes.Frames) == 0 {
+			continue
+		}
+		setFrameCustomMeta(queryRes.Frames[0], "cursor", cursor)
+		p.nextCursor = cursor
+	}
+}
+
+// closePointInTimes closes the points in time of paginated queries that have no next page.
+func (e *elasticsearchDataQuery) closePointInTimes(queries []*Query) {
+	for _, q := range queries {
+		if q.pagination != nil && q.pagination.nextCursor == "" {
+			e.closePointInTime(q.pagination)
+		}
+	}
+}
+
+func (e *elasticsearchDataQuery) closePointInTime(p *pagination) {
+	if err := e.client.ClosePointInTime(p.PitID); err != nil {
+		e.logger.Warn("Failed to close point in time", "error", err)
+	}
+}
+
+func setFrameCustomMeta(frame *data.Frame, key string, value any) {
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	custom, ok := frame.Meta.Custom.(map[string]any)
+	if !ok {
+		custom = map[string]any{}
+	}
+	custom[key] = value
+	frame.Meta.Custom = custom
+}
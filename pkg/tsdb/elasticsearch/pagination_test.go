@@ -0,0 +1,173 @@
+package elasticsearch
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestPaginatedQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	hits := func(n int) *es.SearchResponseHits {
+		res := &es.SearchResponseHits{}
+		for i := 0; i < n; i++ {
+			res.Hits = append(res.Hits, map[string]any{
+				"_id":     fmt.Sprint(i),
+				"_index":  "logs",
+				"_source": map[string]any{"@timestamp": "2018-05-15T17:50:00.000Z", "line": "hello"},
+				"sort":    []any{float64(1526406600000), float64(i)},
+			})
+		}
+		return res
+	}
+
+	cursorOf := func(t *testing.T, res *backend.QueryDataResponse) string {
+		t.Helper()
+		require.NoError(t, res.Responses["A"].Error)
+		require.NotEmpty(t, res.Responses["A"].Frames)
+		meta := res.Responses["A"].Frames[0].Meta
+		if meta == nil {
+			return ""
+		}
+		custom, _ := meta.Custom.(map[string]any)
+		cursor, _ := custom["cursor"].(string)
+		return cursor
+	}
+
+	t.Run("should open a point in time and return a cursor for a full page", func(t *testing.T) {
+		c := newFakeClient()
+		c.pointInTimeID = "pit-1"
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Hits: hits(2), PitID: "pit-2"}},
+		}
+
+		res, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{"type": "raw_data", "id": "1", "settings": {"size": "2", "paginate": true}}]
+		}`, from, to)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, c.openedPointInTimes)
+		assert.Empty(t, c.closedPointInTimes)
+		require.Len(t, c.multisearchRequests, 1)
+		sr := c.multisearchRequests[0].Requests[0]
+		assert.Equal(t, "pit-1", sr.PointInTime)
+		assert.Equal(t, 2, sr.Size)
+		assert.Equal(t, map[string]any{"id": "pit-1", "keep_alive": pointInTimeKeepAlive}, sr.CustomProps["pit"])
+		assert.Contains(t, sr.Sort, "_shard_doc")
+		assert.NotContains(t, sr.Sort, "_doc")
+
+		p, err := decodeCursor(cursorOf(t, res), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "pit-2", p.PitID)
+		assert.Equal(t, 2, p.Fetched)
+		assert.Equal(t, []any{json.Number("1526406600000"), json.Number("1")}, p.SearchAfter)
+	})
+
+	t.Run("should continue from the cursor and close the point in time after the last page", func(t *testing.T) {
+		cursor, err := encodeCursor(&pagination{PitID: "pit-2", SearchAfter: []any{1526406600000, 1}, Fetched: 2}, nil)
+		require.NoError(t, err)
+		c := newFakeClient()
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Hits: hits(1)}},
+		}
+
+		res, err := executeElasticsearchDataQuery(c, fmt.Sprintf(`{
+			"metrics": [{"type": "logs", "id": "1", "settings": {"limit": "2", "cursor": %q}}]
+		}`, cursor), from, to)
+		require.NoError(t, err)
+
+		assert.Zero(t, c.openedPointInTimes)
+		require.Len(t, c.multisearchRequests, 1)
+		sr := c.multisearchRequests[0].Requests[0]
+		assert.Equal(t, "pit-2", sr.PointInTime)
+		assert.Equal(t, []any{json.Number("1526406600000"), json.Number("1")}, sr.CustomProps["search_after"])
+
+		assert.Empty(t, cursorOf(t, res))
+		assert.Equal(t, []string{"pit-2"}, c.closedPointInTimes)
+	})
+
+	t.Run("should not fetch more than the maximum number of documents", func(t *testing.T) {
+		cursor, err := encodeCursor(&pagination{PitID: "pit-1", Fetched: maxPaginatedDocuments - 1}, nil)
+		require.NoError(t, err)
+		c := newFakeClient()
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Hits: hits(1)}},
+		}
+
+		res, err := executeElasticsearchDataQuery(c, fmt.Sprintf(`{
+			"metrics": [{"type": "raw_data", "id": "1", "settings": {"size": "500", "cursor": %q}}]
+		}`, cursor), from, to)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, c.multisearchRequests[0].Requests[0].Size)
+		assert.Empty(t, cursorOf(t, res))
+		assert.Equal(t, []string{"pit-1"}, c.closedPointInTimes)
+	})
+
+	t.Run("should return an error for an invalid cursor", func(t *testing.T) {
+		c := newFakeClient()
+
+		res, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{"type": "raw_data", "id": "1", "settings": {"cursor": "not a cursor"}}]
+		}`, from, to)
+		require.NoError(t, err)
+
+		require.Error(t, res.Responses["A"].Error)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+		assert.Empty(t, c.multisearchRequests)
+	})
+
+	t.Run("should reject a cursor that was changed by the client", func(t *testing.T) {
+		cursor, err := encodeCursor(&pagination{PitID: "pit-1", Fetched: maxPaginatedDocuments - 1}, nil)
+		require.NoError(t, err)
+		payload, signature, _ := strings.Cut(cursor, ".")
+		b, err := base64.RawURLEncoding.DecodeString(payload)
+		require.NoError(t, err)
+		reset := strings.Replace(string(b), fmt.Sprintf(`"fetched":%d`, maxPaginatedDocuments-1), `"fetched":0`, 1)
+		require.NotEqual(t, string(b), reset)
+
+		for _, tampered := range []string{
+			base64.RawURLEncoding.EncodeToString([]byte(reset)) + "." + signature,
+			base64.RawURLEncoding.EncodeToString([]byte(reset)),
+		} {
+			c := newFakeClient()
+			res, err := executeElasticsearchDataQuery(c, fmt.Sprintf(`{
+				"metrics": [{"type": "raw_data", "id": "1", "settings": {"size": "500", "cursor": %q}}]
+			}`, tampered), from, to)
+			require.NoError(t, err)
+
+			require.Error(t, res.Responses["A"].Error)
+			assert.Empty(t, c.multisearchRequests)
+		}
+
+		_, err = decodeCursor(cursor, []byte("another key"))
+		require.Error(t, err)
+	})
+
+	t.Run("should not paginate queries that do not request it", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Hits: hits(2)}},
+		}
+
+		res, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{"type": "raw_data", "id": "1", "settings": {"size": "2"}}]
+		}`, from, to)
+		require.NoError(t, err)
+
+		assert.Zero(t, c.openedPointInTimes)
+		assert.Empty(t, c.multisearchRequests[0].Requests[0].PointInTime)
+		assert.Empty(t, cursorOf(t, res))
+	})
+}
@@ -31,11 +31,16 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 			logger.Error("Failed to parse metrics in query", "error", err, "model", string(q.JSON))
 			return nil, err
 		}
+		queryType := q.QueryType
+		if queryType == "" {
+			queryType = model.Get("queryType").MustString()
+		}
 		alias := model.Get("alias").MustString("")
 		intervalMs := model.Get("intervalMs").MustInt64(0)
 		interval := q.Interval
 
 		queries = append(queries, &Query{
+			QueryType:     queryType,
 			RawQuery:      rawQuery,
 			BucketAggs:    bucketAggs,
 			Metrics:       metrics,
@@ -141,7 +141,7 @@ func queryDataTestWithResponseCode(queriesBytes []byte, responseStatusCode int,
 		return nil
 	})
 
-	result, err := queryData(context.Background(), &req, dsInfo, log.New())
+	result, err := queryData(context.Background(), &req, dsInfo, nil, log.New())
 	if err != nil {
 		return queryDataTestResult{}, err
 	}
@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+// rawDSLQueryType is the query type of queries that are written in the Elasticsearch Query DSL
+const rawDSLQueryType = "raw_dsl"
+
+func isRawDSLQuery(query *Query) bool {
+	return query.QueryType == rawDSLQueryType
+}
+
+// processRawDSLQuery adds the search request body of a raw DSL query to the multi search request.
+// The $__timeFrom and $__timeTo macros are replaced with the time range of the query in epoch milliseconds,
+// the $__interval and $__interval_ms macros are replaced when the request is encoded.
+func processRawDSLQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
+	body := strings.TrimSpace(q.RawQuery)
+	if body == "" {
+		return backend.DownstreamError(errors.New("raw DSL query is empty"))
+	}
+	body = strings.ReplaceAll(body, "$__timeFrom", strconv.FormatInt(from, 10))
+	body = strings.ReplaceAll(body, "$__timeTo", strconv.FormatInt(to, 10))
+
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return backend.DownstreamError(errors.New("raw DSL query must be a JSON object"))
+	}
+
+	ms.Search(q.Interval, q.TimeRange).RawBody(json.RawMessage(body))
+	return nil
+}
+
+// processRawDSLResponse converts the hits of a raw DSL query response to a table of documents and
+// each top level aggregation to a table of its buckets.
+func processRawDSLResponse(res *es.SearchResponse, target *Query, configuredFields es.ConfiguredFields, queryRes *backend.DataResponse, logger log.Logger) error {
+	frames := data.Frames{}
+	if res.Hits != nil && len(res.Hits.Hits) > 0 {
+		if err := processRawDataResponse(res, target, configuredFields, queryRes, logger); err != nil {
+			return err
+		}
+		frames = append(frames, queryRes.Frames...)
+	}
+
+	aggNames := make([]string, 0, len(res.Aggregations))
+	for name := range res.Aggregations {
+		aggNames = append(aggNames, name)
+	}
+	sort.Strings(aggNames)
+
+	for _, name := range aggNames {
+		agg, ok := res.Aggregations[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if frame := rawDSLAggregationToFrame(name, agg); frame != nil {
+			frames = append(frames, frame)
+		}
+	}
+
+	for _, frame := range frames {
+		frame.RefID = target.RefID
+	}
+	queryRes.Frames = frames
+	logger.Debug("Processed raw DSL query response", "framesLength", len(frames))
+	return nil
+}
+
+// rawDSLAggregationToFrame returns a table with a row per bucket of a bucket aggregation or
+// a single row with the values of a metric aggregation.
+func rawDSLAggregationToFrame(name string, agg map[string]any) *data.Frame {
+	var docs []map[string]any
+	isDateHistogram := false
+	switch buckets := agg["buckets"].(type) {
+	case []any:
+		for _, b := range buckets {
+			bucket, ok := b.(map[string]any)
+			if !ok {
+				continue
+			}
+			doc := flatten(bucket, 10)
+			// Date histogram buckets have numeric keys in epoch milliseconds and a formatted key
+			if key, ok := bucket["key"].(float64); ok {
+				if _, ok := bucket["key_as_string"]; ok {
+					isDateHistogram = true
+					doc["key"] = time.UnixMilli(int64(key)).UTC().Format(time.RFC3339Nano)
+					delete(doc, "key_as_string")
+				}
+			}
+			docs = append(docs, doc)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(buckets))
+		for k := range buckets {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			bucket, ok := buckets[k].(map[string]any)
+			if !ok {
+				continue
+			}
+			doc := flatten(bucket, 10)
+			doc["key"] = k
+			docs = append(docs, doc)
+		}
+	default:
+		docs = append(docs, flatten(agg, 10))
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	propNames := make(map[string]bool)
+	for _, doc := range docs {
+		for k := range doc {
+			propNames[k] = true
+		}
+	}
+	// The key of date histogram buckets is parsed as time
+	fieldsConfig := es.ConfiguredFields{}
+	if isDateHistogram {
+		fieldsConfig.TimeField = "key"
+	}
+	fields := processDocsToDataFrameFields(docs, sortPropNames(propNames, fieldsConfig, false), fieldsConfig)
+	return data.NewFrame(name, fields...)
+}
@@ -0,0 +1,101 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestRawDSLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	t.Run("should send the body with the time range macros replaced", func(t *testing.T) {
+		c := newFakeClient()
+		_, err := executeElasticsearchDataQuery(c, `{
+			"queryType": "raw_dsl",
+			"query": "{\"size\": 0, \"query\": {\"range\": {\"@timestamp\": {\"gte\": $__timeFrom, \"lte\": $__timeTo}}}}"
+		}`, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		body, err := json.Marshal(c.multisearchRequests[0].Requests[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"size": 0, "query": {"range": {"@timestamp": {"gte": 1526406600000, "lte": 1526406900000}}}}`, string(body))
+	})
+
+	t.Run("should return an error if the body is not a JSON object", func(t *testing.T) {
+		c := newFakeClient()
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "raw_dsl", "query": "[1, 2]"}`, from, to)
+		require.NoError(t, err)
+
+		require.Error(t, res.Responses["A"].Error)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+		assert.Empty(t, c.multisearchRequests)
+	})
+}
+
+func TestProcessRawDSLResponse(t *testing.T) {
+	var res es.SearchResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"hits": {
+			"hits": [
+				{"_id": "1", "_index": "logs", "_source": {"@timestamp": "2018-05-15T17:50:00.000Z", "host": "a"}}
+			]
+		},
+		"aggregations": {
+			"per_minute": {
+				"buckets": [
+					{"key": 1526406600000, "key_as_string": "2018-05-15T17:50:00.000Z", "doc_count": 2, "bytes": {"value": 10}},
+					{"key": 1526406660000, "key_as_string": "2018-05-15T17:51:00.000Z", "doc_count": 3, "bytes": {"value": 20}}
+				]
+			},
+			"hosts": {
+				"buckets": [
+					{"key": "a", "doc_count": 4}
+				]
+			},
+			"total_bytes": {"value": 30}
+		}
+	}`), &res))
+
+	queryRes := backend.DataResponse{}
+	err := processRawDSLResponse(&res, &Query{RefID: "A"}, es.ConfiguredFields{TimeField: "@timestamp"}, &queryRes, log.New())
+	require.NoError(t, err)
+
+	require.Len(t, queryRes.Frames, 4)
+	for _, frame := range queryRes.Frames {
+		assert.Equal(t, "A", frame.RefID)
+	}
+
+	hits := queryRes.Frames[0]
+	assert.Equal(t, 1, hits.Rows())
+	assert.Equal(t, "@timestamp", hits.Fields[0].Name)
+
+	hosts := queryRes.Frames[1]
+	assert.Equal(t, "hosts", hosts.Name)
+	assert.Equal(t, "a", *hosts.Fields[1].At(0).(*string))
+
+	perMinute := queryRes.Frames[2]
+	assert.Equal(t, "per_minute", perMinute.Name)
+	require.Equal(t, 2, perMinute.Rows())
+	assert.Equal(t, "key", perMinute.Fields[0].Name)
+	assert.Equal(t, data.FieldTypeNullableTime, perMinute.Fields[0].Type())
+	assert.Equal(t, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC), *perMinute.Fields[0].At(0).(*time.Time))
+	field, idx := perMinute.FieldByName("bytes.value")
+	require.NotEqual(t, -1, idx)
+	assert.Equal(t, float64(20), *field.At(1).(*float64))
+
+	total := queryRes.Frames[3]
+	assert.Equal(t, "total_bytes", total.Name)
+	assert.Equal(t, float64(30), *total.Fields[0].At(0).(*float64))
+}
@@ -37,6 +37,7 @@ const (
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+	compositeType   = "composite"
 	//  Document types
 	rawDocumentType = "raw_document"
 	rawDataType     = "raw_data"
@@ -60,8 +61,12 @@ func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets
 	defer span.End()
 
 	for i, res := range responses {
+		queryMetricType := targets[i].QueryType
+		if len(targets[i].Metrics) > 0 {
+			queryMetricType = targets[i].Metrics[0].Type
+		}
 		_, resSpan := tracing.DefaultTracer().Start(ctx, "datasource.elastic.parseResponse.response", trace.WithAttributes(
-			attribute.String("queryMetricType", targets[i].Metrics[0].Type),
+			attribute.String("queryMetricType", queryMetricType),
 		))
 		start := time.Now()
 		target := targets[i]
@@ -80,7 +85,13 @@ func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets
 
 		queryRes := backend.DataResponse{}
 
-		if isRawDataQuery(target) {
+		if isRawDSLQuery(target) {
+			err := processRawDSLResponse(res, target, configuredFields, &queryRes, logger)
+			if err != nil {
+				return &backend.QueryDataResponse{}, err
+			}
+			result.Responses[target.RefID] = queryRes
+		} else if isRawDataQuery(target) {
 			err := processRawDataResponse(res, target, configuredFields, &queryRes, logger)
 			if err != nil {
 				// TODO: This error never happens so we should remove it
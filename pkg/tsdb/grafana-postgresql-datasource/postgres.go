@@ -66,7 +66,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsInfo.QueryData(ctx, req)
 }
 
-func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit int64, dsInfo sqleng.DataSourceInfo, cnnstr string, logger log.Logger, settings backend.DataSourceInstanceSettings) (*sql.DB, *sqleng.DataSourceHandler, error) {
+func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit int64, byteLimit int64, dsInfo sqleng.DataSourceInfo, cnnstr string, logger log.Logger, settings backend.DataSourceInstanceSettings) (*sql.DB, *sqleng.DataSourceHandler, error) {
 	connector, err := pq.NewConnector(cnnstr)
 	if err != nil {
 		logger.Error("postgres connector creation failed", "error", err)
@@ -94,6 +94,7 @@ func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit in
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 		RowLimit:          rowLimit,
+		ByteLimit:         byteLimit,
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{}
@@ -115,7 +116,7 @@ func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit in
 	return db, handler, nil
 }
 
-func newPostgresPGX(ctx context.Context, userFacingDefaultError string, rowLimit int64, dsInfo sqleng.DataSourceInfo, cnnstr string, logger log.Logger, settings backend.DataSourceInstanceSettings) (*pgxpool.Pool, *sqleng.DataSourceHandler, error) {
+func newPostgresPGX(ctx context.Context, userFacingDefaultError string, rowLimit int64, byteLimit int64, dsInfo sqleng.DataSourceInfo, cnnstr string, logger log.Logger, settings backend.DataSourceInstanceSettings) (*pgxpool.Pool, *sqleng.DataSourceHandler, error) {
 	pgxConf, err := pgxpool.ParseConfig(cnnstr)
 	if err != nil {
 		logger.Error("postgres config creation failed", "error", err)
@@ -148,6 +149,7 @@ func newPostgresPGX(ctx context.Context, userFacingDefaultError string, rowLimit
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"unknown", "text", "varchar", "char", "bpchar"},
 		RowLimit:          rowLimit,
+		ByteLimit:         byteLimit,
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{}
@@ -230,7 +232,7 @@ func (s *Service) newInstanceSettings() datasource.InstanceFactoryFunc {
 			if err != nil {
 				return "", err
 			}
-			_, handler, err = newPostgresPGX(ctx, userFacingDefaultError, sqlCfg.RowLimit, dsInfo, cnnstr, logger, settings)
+			_, handler, err = newPostgresPGX(ctx, userFacingDefaultError, sqlCfg.RowLimit, sqleng.ByteLimitFromConfig(cfg), dsInfo, cnnstr, logger, settings)
 			if err != nil {
 				logger.Error("Failed connecting to Postgres", "err", err)
 				return nil, err
@@ -244,7 +246,7 @@ func (s *Service) newInstanceSettings() datasource.InstanceFactoryFunc {
 			if err != nil {
 				return nil, err
 			}
-			_, handler, err = newPostgres(ctx, userFacingDefaultError, sqlCfg.RowLimit, dsInfo, cnnstr, logger, settings)
+			_, handler, err = newPostgres(ctx, userFacingDefaultError, sqlCfg.RowLimit, sqleng.ByteLimitFromConfig(cfg), dsInfo, cnnstr, logger, settings)
 			if err != nil {
 				logger.Error("Failed connecting to Postgres", "err", err)
 				return nil, err
@@ -391,6 +393,15 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 	return dsHandler.CheckHealth(ctx, req, s.features)
 }
 
+// CallResource serves the schema of the connected SQL database
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
 func (t *postgresQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	return []sqlutil.StringConverter{
 		{
@@ -212,7 +212,7 @@ func TestIntegrationPostgresPGX(t *testing.T) {
 
 	cnnstr := postgresTestDBConnString()
 
-	p, exe, err := newPostgresPGX(t.Context(), "error", 10000, dsInfo, cnnstr, logger, backend.DataSourceInstanceSettings{})
+	p, exe, err := newPostgresPGX(t.Context(), "error", 10000, 0, dsInfo, cnnstr, logger, backend.DataSourceInstanceSettings{})
 
 	require.NoError(t, err)
 
@@ -1299,7 +1299,7 @@ func TestIntegrationPostgresPGX(t *testing.T) {
 				JsonData:                jsonData,
 				DecryptedSecureJSONData: map[string]string{},
 			}
-			_, handler, err := newPostgresPGX(t.Context(), "error", 1, dsInfo, cnnstr, logger, backend.DataSourceInstanceSettings{})
+			_, handler, err := newPostgresPGX(t.Context(), "error", 1, 0, dsInfo, cnnstr, logger, backend.DataSourceInstanceSettings{})
 
 			require.NoError(t, err)
 
@@ -0,0 +1,150 @@
+package sqleng
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// ByteLimitConfigKey is the key of the Grafana config that holds the maximum number of bytes of the result of a query
+const ByteLimitConfigKey = "GF_SQL_BYTE_LIMIT"
+
+// The reasons of the truncation of a query result
+const (
+	TruncatedByRowLimit  = "rowLimit"
+	TruncatedByByteLimit = "byteLimit"
+)
+
+// fixedValueSize is the size that is counted for values that are not strings or bytes
+const fixedValueSize = 8
+
+// ResultTruncation is set as custom metadata of a frame whose rows were truncated because the query result exceeded a limit
+type ResultTruncation struct {
+	Truncated bool   `json:"truncated"`
+	Reason    string `json:"reason"`
+	Limit     int64  `json:"limit"`
+	Rows      int64  `json:"rows"`
+}
+
+// ByteLimitFromConfig returns the byte limit of query results of the Grafana config, or 0 if there is no limit.
+func ByteLimitFromConfig(cfg *backend.GrafanaCfg) int64 {
+	limit, err := strconv.ParseInt(cfg.Get(ByteLimitConfigKey), 10, 64)
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// frameFromRows converts rows to a frame one row at a time. The conversion stops once the row limit is reached or
+// once the scanned values of the rows would exceed the byte limit, so that the memory of a query result is bounded.
+// A truncated frame has a warning notice and a ResultTruncation as custom metadata.
+// There is no limit if a limit is less than or equal to 0.
+func frameFromRows(rows *sql.Rows, rowLimit int64, byteLimit int64, converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+
+	var count, size int64
+	var truncation *ResultTruncation
+	for truncation == nil {
+		// first iterate over rows may be nop if not switched result set to next
+		for rows.Next() {
+			if rowLimit > 0 && count == rowLimit {
+				truncation = &ResultTruncation{Truncated: true, Reason: TruncatedByRowLimit, Limit: rowLimit, Rows: count}
+				break
+			}
+
+			r := scanRow.NewScannableRow()
+			if err := rows.Scan(r...); err != nil {
+				return nil, err
+			}
+
+			rowSize := scannedRowSize(r)
+			if byteLimit > 0 && size+rowSize > byteLimit {
+				truncation = &ResultTruncation{Truncated: true, Reason: TruncatedByByteLimit, Limit: byteLimit, Rows: count}
+				break
+			}
+
+			if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+				return nil, err
+			}
+			count++
+			size += rowSize
+		}
+		if truncation != nil || !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return frame, backend.DownstreamError(err)
+	}
+
+	if truncation != nil {
+		setTruncation(frame, truncation)
+	}
+	return frame, nil
+}
+
+func setTruncation(frame *data.Frame, truncation *ResultTruncation) {
+	text := fmt.Sprintf("Results have been limited to %d rows because the SQL row limit was reached", truncation.Rows)
+	if truncation.Reason == TruncatedByByteLimit {
+		text = fmt.Sprintf("Results have been limited to %d rows because the SQL byte limit of %d bytes was reached", truncation.Rows, truncation.Limit)
+	}
+	frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: text})
+	frame.Meta.Custom = truncation
+}
+
+// scannedRowSize returns the approximate size in bytes of the values of a scanned row.
+func scannedRowSize(row []any) int64 {
+	var size int64
+	for _, v := range row {
+		size += valueSize(reflect.ValueOf(v))
+	}
+	return size
+}
+
+func valueSize(v reflect.Value) int64 {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return int64(v.Len())
+		}
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += valueSize(v.Index(i))
+		}
+		return size
+	case reflect.Struct:
+		// Nullable types of database/sql hold the value in their first field
+		if v.NumField() > 0 && v.Type().PkgPath() == "database/sql" {
+			return valueSize(v.Field(0))
+		}
+	}
+	return fixedValueSize
+}
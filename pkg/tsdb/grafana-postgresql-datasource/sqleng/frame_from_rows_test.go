@@ -0,0 +1,66 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameFromRows(t *testing.T) {
+	convert := func(t *testing.T, rowLimit int64, byteLimit int64) *data.Frame {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"name"}).
+			AddRow("aaaa").
+			AddRow("bbbb").
+			AddRow("cccc"))
+
+		rows, err := db.Query("SELECT name FROM metrics")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = rows.Close() })
+
+		frame, err := frameFromRows(rows, rowLimit, byteLimit)
+		require.NoError(t, err)
+		return frame
+	}
+
+	t.Run("should convert all rows when no limit is reached", func(t *testing.T) {
+		frame := convert(t, 0, 0)
+
+		require.Equal(t, 3, frame.Rows())
+		assert.Nil(t, frame.Meta)
+	})
+
+	t.Run("should truncate the rows at the row limit", func(t *testing.T) {
+		frame := convert(t, 2, 0)
+
+		require.Equal(t, 2, frame.Rows())
+		require.NotNil(t, frame.Meta)
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		assert.Equal(t, &ResultTruncation{Truncated: true, Reason: TruncatedByRowLimit, Limit: 2, Rows: 2}, frame.Meta.Custom)
+	})
+
+	t.Run("should truncate the rows at the byte limit", func(t *testing.T) {
+		frame := convert(t, 0, 10)
+
+		require.Equal(t, 2, frame.Rows())
+		require.NotNil(t, frame.Meta)
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Contains(t, frame.Meta.Notices[0].Text, "byte limit")
+		assert.Equal(t, &ResultTruncation{Truncated: true, Reason: TruncatedByByteLimit, Limit: 10, Rows: 2}, frame.Meta.Custom)
+	})
+
+	t.Run("should not truncate the rows when the result is within the limits", func(t *testing.T) {
+		frame := convert(t, 3, 12)
+
+		require.Equal(t, 3, frame.Rows())
+		assert.Nil(t, frame.Meta)
+	})
+}
@@ -0,0 +1,154 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// cancelQueryTimeout is the maximum time of cancelling a query on the server
+const cancelQueryTimeout = 5 * time.Second
+
+// queryCanceledSQLState is the SQLSTATE of a statement that was cancelled, for example because it exceeded statement_timeout
+const queryCanceledSQLState = "57014"
+
+var (
+	// ErrQueryTimeout is returned when a query exceeds the query timeout of the data source
+	ErrQueryTimeout = errors.New("query timed out")
+	// ErrQueryCancelled is returned when a query is cancelled because the request was cancelled
+	ErrQueryCancelled = errors.New("query cancelled")
+)
+
+var cancelledQueriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana_plugin",
+	Name:      "postgres_cancelled_queries_total",
+	Help:      "Number of PostgreSQL queries that were cancelled because they timed out or the request was cancelled",
+}, []string{"reason"})
+
+// sqlStateError is implemented by the errors of both the lib/pq and the pgx driver
+type sqlStateError interface {
+	SQLState() string
+}
+
+func (e *DataSourceHandler) queryTimeout() time.Duration {
+	return time.Duration(e.dsInfo.JsonData.QueryTimeout) * time.Second
+}
+
+// resetStatementTimeout restores the statement_timeout of a session, before its connection is returned to the pool
+const resetStatementTimeout = "RESET statement_timeout"
+
+// setStatementTimeout returns the statement that sets the query timeout of the data source as statement_timeout
+// of the session, or an empty string if the data source has no query timeout.
+// The setting must be reset with resetStatementTimeout before the connection is released.
+func (e *DataSourceHandler) setStatementTimeout() string {
+	timeout := e.queryTimeout()
+	if timeout <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("SET statement_timeout = %d", timeout.Milliseconds())
+}
+
+// queryWithCancellation runs a query on a dedicated connection with the statement timeout of the data source.
+// The lib/pq driver sends a cancel request to the server when the context is done.
+// The returned release function must be called after the rows are closed, it resets the statement timeout.
+func (e *DataSourceHandler) queryWithCancellation(ctx context.Context, query string, logger log.Logger) (*sql.Rows, func(), error) {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() {
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to close connection", "err", err)
+		}
+	}
+
+	if stmt := e.setStatementTimeout(); stmt != "" {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			release()
+			return nil, nil, err
+		}
+		release = func() {
+			resetSession(conn, logger)
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// resetSession resets the statement timeout of a connection and closes it. The connection is discarded instead of
+// being returned to the pool when the reset fails, so the timeout does not apply to the next queries.
+func resetSession(conn *sql.Conn, logger log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+	defer cancel()
+	if _, err := conn.ExecContext(ctx, resetStatementTimeout); err != nil {
+		logger.Debug("Failed to reset the statement timeout, discarding the connection", "err", err)
+		// returning ErrBadConn closes the connection instead of returning it to the pool
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return
+	}
+	if err := conn.Close(); err != nil {
+		logger.Warn("Failed to close connection", "err", err)
+	}
+}
+
+// cancelBackendOnDone cancels the query of the backend process with the given PID on the server when the context
+// is done, because pgx only closes the connection. The returned stop function must be called before the
+// connection is released.
+func (e *DataSourceHandler) cancelBackendOnDone(ctx context.Context, pid uint32, logger log.Logger) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			cancelCtx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+			defer cancel()
+			if _, err := e.pool.Exec(cancelCtx, "SELECT pg_cancel_backend($1)", pid); err != nil {
+				logger.Warn("Failed to cancel query", "pid", pid, "err", err)
+				return
+			}
+			logger.Debug("Cancelled query", "pid", pid)
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// queryCancellationError returns a downstream error if a query failed because it exceeded the query timeout
+// or because the request was cancelled, and nil otherwise.
+func (e *DataSourceHandler) queryCancellationError(ctx context.Context, err error) error {
+	var stateErr sqlStateError
+	isStatementTimeout := errors.As(err, &stateErr) && stateErr.SQLState() == queryCanceledSQLState &&
+		strings.Contains(err.Error(), "statement timeout")
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), isStatementTimeout:
+		cancelledQueriesTotal.WithLabelValues("timeout").Inc()
+		if timeout := e.queryTimeout(); timeout > 0 {
+			return backend.DownstreamError(fmt.Errorf("%w: the query exceeded the timeout of %s", ErrQueryTimeout, timeout))
+		}
+		return backend.DownstreamError(ErrQueryTimeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		cancelledQueriesTotal.WithLabelValues("cancelled").Inc()
+		return backend.DownstreamError(ErrQueryCancelled)
+	}
+	return nil
+}
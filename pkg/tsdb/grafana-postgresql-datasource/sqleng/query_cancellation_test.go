@@ -0,0 +1,111 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCancellation(t *testing.T) {
+	newHandler := func(t *testing.T, queryTimeout int) (*DataSourceHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		config := DataPluginConfiguration{
+			DSInfo:   DataSourceInfo{JsonData: JsonData{QueryTimeout: queryTimeout}},
+			RowLimit: 1000000,
+		}
+		handler, err := NewQueryDataHandler("error", db, config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New())
+		require.NoError(t, err)
+		return handler, mock
+	}
+
+	query := func(t *testing.T, ctx context.Context, handler *DataSourceHandler) backend.DataResponse {
+		t.Helper()
+		res, err := handler.QueryData(ctx, &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID: "A",
+				JSON:  json.RawMessage(`{"rawSql": "SELECT 1 AS value", "format": "table"}`),
+			}},
+		})
+		require.NoError(t, err)
+		return res.Responses["A"]
+	}
+
+	t.Run("should set the statement timeout of the session", func(t *testing.T) {
+		handler, mock := newHandler(t, 30)
+		mock.ExpectExec(regexp.QuoteMeta("SET statement_timeout = 30000")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("RESET statement_timeout")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		res := query(t, context.Background(), handler)
+
+		require.NoError(t, res.Error)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should discard the connection when the statement timeout cannot be reset", func(t *testing.T) {
+		handler, mock := newHandler(t, 30)
+		mock.ExpectExec(regexp.QuoteMeta("SET statement_timeout = 30000")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("RESET statement_timeout")).WillReturnError(assert.AnError)
+		mock.ExpectClose()
+
+		res := query(t, context.Background(), handler)
+		require.NoError(t, res.Error)
+
+		// the discarded connection is closed, the pool is empty
+		require.Equal(t, 0, handler.db.Stats().OpenConnections)
+	})
+
+	t.Run("should return a cancelled query as a downstream error", func(t *testing.T) {
+		handler, mock := newHandler(t, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillDelayFor(time.Minute).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		res := query(t, ctx, handler)
+
+		require.ErrorIs(t, res.Error, ErrQueryCancelled)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+
+	t.Run("should return statement timeouts as timeout errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+
+		err := handler.queryCancellationError(context.Background(), &pgconn.PgError{
+			Code:    queryCanceledSQLState,
+			Message: "canceling statement due to statement timeout",
+		})
+
+		require.ErrorIs(t, err, ErrQueryTimeout)
+		assert.True(t, backend.IsDownstreamError(err))
+		assert.Contains(t, err.Error(), "30s")
+	})
+
+	t.Run("should not change other errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+
+		assert.Nil(t, handler.queryCancellationError(context.Background(), &pgconn.PgError{
+			Code:    queryCanceledSQLState,
+			Message: "canceling statement due to user request",
+		}))
+		assert.Nil(t, handler.queryCancellationError(context.Background(), assert.AnError))
+	})
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
@@ -43,6 +43,7 @@ type JsonData struct {
 	MaxIdleConns            int    `json:"maxIdleConns"`
 	ConnMaxLifetime         int    `json:"connMaxLifetime"`
 	ConnectionTimeout       int    `json:"connectionTimeout"`
+	QueryTimeout            int    `json:"queryTimeout"`
 	Timescaledb             bool   `json:"timescaledb"`
 	Mode                    string `json:"sslmode"`
 	ConfigurationMethod     string `json:"tlsConfigurationMethod"`
@@ -77,6 +78,7 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	ByteLimit         int64
 }
 
 type DataSourceHandler struct {
@@ -88,7 +90,9 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
 	userError              string
+	schemaCache            *schemaCache
 	pool                   *pgxpool.Pool
 }
 
@@ -124,7 +128,9 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.ByteLimit,
 		userError:              userFacingDefaultError,
+		schemaCache:            newSchemaCache(),
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -256,11 +262,23 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	if timeout := e.queryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, timeout)
+		defer cancel()
+	}
+
+	rows, release, err := e.queryWithCancellation(queryContext, interpolatedQuery, logger)
 	if err != nil {
-		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
+		if cancelErr := e.queryCancellationError(queryContext, err); cancelErr != nil {
+			err = cancelErr
+		} else {
+			err = e.TransformQueryError(logger, err)
+		}
+		errAppendDebug("db query error", err, interpolatedQuery, backend.ErrorSourceDownstream)
 		return
 	}
+	defer release()
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Warn("Failed to close rows", "err", err)
@@ -275,8 +293,11 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := frameFromRows(rows, e.rowLimit, e.byteLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
+		if cancelErr := e.queryCancellationError(queryContext, err); cancelErr != nil {
+			err = cancelErr
+		}
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery, backend.ErrorSourcePlugin)
 		return
 	}
@@ -1,11 +1,13 @@
 package sqleng
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -28,7 +30,9 @@ func NewQueryDataHandlerPGX(userFacingDefaultError string, p *pgxpool.Pool, conf
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.ByteLimit,
 		userError:              userFacingDefaultError,
+		schemaCache:            newSchemaCache(),
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -127,20 +131,77 @@ func (e *DataSourceHandler) handlePanic(logger log.Logger, queryResult *DBDataRe
 	}
 }
 
-func (e *DataSourceHandler) execQuery(ctx context.Context, query string, logger log.Logger) ([]*pgconn.Result, error) {
+func (e *DataSourceHandler) execQuery(ctx context.Context, query string, logger log.Logger) ([]*pgconn.Result, *ResultTruncation, error) {
 	c, err := e.pool.Acquire(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+		return nil, nil, fmt.Errorf("failed to acquire connection: %w", err)
 	}
 	defer c.Release()
 
+	if stmt := e.setStatementTimeout(); stmt != "" {
+		if _, err := c.Exec(ctx, stmt); err != nil {
+			return nil, nil, err
+		}
+		defer func() {
+			resetCtx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+			defer cancel()
+			if _, err := c.Exec(resetCtx, resetStatementTimeout); err != nil {
+				// the pool destroys closed connections instead of reusing them
+				logger.Debug("Failed to reset the statement timeout, closing the connection", "err", err)
+				_ = c.Conn().Close(resetCtx)
+			}
+		}()
+	}
+	stop := e.cancelBackendOnDone(ctx, c.Conn().PgConn().PID(), logger)
+	defer stop()
+
 	mrr := c.Conn().PgConn().Exec(ctx, query)
-	defer func() {
-		if err := mrr.Close(); err != nil {
-			logger.Warn("Failed to close multi-result reader", "error", err)
+	return readResults(mrr, e.rowLimit, e.byteLimit)
+}
+
+// readResults reads the results of a query one row at a time, like MultiResultReader.ReadAll.
+// The rows of all results end up in one frame, so like in frameFromRows, the limits apply to the rows of all results
+// together. Once the row or byte limit is reached, the remaining rows are received and dropped without being kept in
+// memory. There is no limit if a limit is less than or equal to 0.
+func readResults(mrr *pgconn.MultiResultReader, rowLimit int64, byteLimit int64) ([]*pgconn.Result, *ResultTruncation, error) {
+	var results []*pgconn.Result
+	var truncation *ResultTruncation
+	var count, size int64
+	for mrr.NextResult() {
+		rr := mrr.ResultReader()
+		result := &pgconn.Result{}
+		for truncation == nil && rr.NextRow() {
+			if rowLimit > 0 && count == rowLimit {
+				truncation = &ResultTruncation{Truncated: true, Reason: TruncatedByRowLimit, Limit: rowLimit, Rows: count}
+				break
+			}
+			values := rr.Values()
+			var rowSize int64
+			for _, v := range values {
+				rowSize += int64(len(v))
+			}
+			if byteLimit > 0 && size+rowSize > byteLimit {
+				truncation = &ResultTruncation{Truncated: true, Reason: TruncatedByByteLimit, Limit: byteLimit, Rows: count}
+				break
+			}
+			size += rowSize
+			count++
+
+			if result.FieldDescriptions == nil {
+				result.FieldDescriptions = slices.Clone(rr.FieldDescriptions())
+			}
+			row := make([][]byte, len(values))
+			for i, v := range values {
+				if v != nil {
+					row[i] = bytes.Clone(v)
+				}
+			}
+			result.Rows = append(result.Rows, row)
 		}
-	}()
-	return mrr.ReadAll()
+		result.CommandTag, result.Err = rr.Close()
+		results = append(results, result)
+	}
+	return results, truncation, mrr.Close()
 }
 
 func (e *DataSourceHandler) executeQueryPGX(queryContext context.Context, query backend.DataQuery, wg *sync.WaitGroup,
@@ -168,9 +229,20 @@ func (e *DataSourceHandler) executeQueryPGX(queryContext context.Context, query
 		return
 	}
 
-	results, err := e.execQuery(queryContext, interpolatedQuery, logger)
+	if timeout := e.queryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, timeout)
+		defer cancel()
+	}
+
+	results, truncation, err := e.execQuery(queryContext, interpolatedQuery, logger)
 	if err != nil {
-		e.handleQueryError("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourcePlugin, ch, queryResult)
+		if cancelErr := e.queryCancellationError(queryContext, err); cancelErr != nil {
+			err = cancelErr
+		} else {
+			err = e.TransformQueryError(logger, err)
+		}
+		e.handleQueryError("db query error", err, interpolatedQuery, backend.ErrorSourcePlugin, ch, queryResult)
 		return
 	}
 
@@ -180,7 +252,7 @@ func (e *DataSourceHandler) executeQueryPGX(queryContext context.Context, query
 		return
 	}
 
-	frame, err := convertResultsToFrame(results, e.rowLimit)
+	frame, err := convertResultsToFrame(results, truncation)
 	if err != nil {
 		e.handleQueryError("convert frame from rows error", err, interpolatedQuery, backend.ErrorSourcePlugin, ch, queryResult)
 		return
@@ -387,7 +459,7 @@ func (e *DataSourceHandler) newProcessCfgPGX(queryContext context.Context, query
 	return qm, nil
 }
 
-func convertResultsToFrame(results []*pgconn.Result, rowLimit int64) (*data.Frame, error) {
+func convertResultsToFrame(results []*pgconn.Result, truncation *ResultTruncation) (*data.Frame, error) {
 	frame := data.Frame{}
 	m := pgtype.NewMap()
 
@@ -411,7 +483,7 @@ func convertResultsToFrame(results []*pgconn.Result, rowLimit int64) (*data.Fram
 		frame = *data.NewFrame("", fields...)
 	}
 
-	// Add rows to the frame
+	// Add rows to the frame, the results are already truncated to the limits
 	for _, result := range results {
 		// Skip non-select statements
 		if !result.CommandTag.Select() {
@@ -419,13 +491,6 @@ func convertResultsToFrame(results []*pgconn.Result, rowLimit int64) (*data.Fram
 		}
 		fieldDescriptions := result.FieldDescriptions
 		for rowIdx := range result.Rows {
-			if rowIdx == int(rowLimit) {
-				frame.AppendNotices(data.Notice{
-					Severity: data.NoticeSeverityWarning,
-					Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
-				})
-				break
-			}
 			row := make([]interface{}, len(fieldDescriptions))
 			for colIdx, fd := range fieldDescriptions {
 				rawValue := result.Rows[rowIdx][colIdx]
@@ -516,6 +581,9 @@ func convertResultsToFrame(results []*pgconn.Result, rowLimit int64) (*data.Fram
 		}
 	}
 
+	if truncation != nil {
+		setTruncation(&frame, truncation)
+	}
 	return &frame, nil
 }
 
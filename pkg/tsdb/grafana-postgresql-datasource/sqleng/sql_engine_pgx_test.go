@@ -0,0 +1,127 @@
+package sqleng
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePostgres returns a connection to a server that answers every query with the given results, one per
+// statement. Each result is a list of rows with a single text column.
+func fakePostgres(t *testing.T, results ...[]string) *pgconn.PgConn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		b := pgproto3.NewBackend(conn, conn)
+		if _, err := b.ReceiveStartupMessage(); err != nil {
+			return
+		}
+		b.Send(&pgproto3.AuthenticationOk{})
+		b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		if err := b.Flush(); err != nil {
+			return
+		}
+		for {
+			msg, err := b.Receive()
+			if err != nil {
+				return
+			}
+			if _, ok := msg.(*pgproto3.Query); !ok {
+				return
+			}
+			for _, rows := range results {
+				b.Send(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+					{Name: []byte("value"), DataTypeOID: pgtype.TextOID, DataTypeSize: -1, TypeModifier: -1},
+				}})
+				for _, v := range rows {
+					b.Send(&pgproto3.DataRow{Values: [][]byte{[]byte(v)}})
+				}
+				b.Send(&pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("SELECT %d", len(rows)))})
+			}
+			b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := b.Flush(); err != nil {
+				return
+			}
+		}
+	}()
+
+	conn, err := pgconn.Connect(context.Background(), fmt.Sprintf("postgres://grafana@%s/grafana?sslmode=disable", ln.Addr()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close(context.Background()) })
+	return conn
+}
+
+func TestReadResults(t *testing.T) {
+	conn := fakePostgres(t, []string{"a", "b"}, []string{"c", "d", "e"})
+
+	rowsOf := func(results []*pgconn.Result) []string {
+		var rows []string
+		for _, r := range results {
+			require.NoError(t, r.Err)
+			for _, row := range r.Rows {
+				rows = append(rows, string(row[0]))
+			}
+		}
+		return rows
+	}
+
+	tests := []struct {
+		name       string
+		rowLimit   int64
+		byteLimit  int64
+		rows       []string
+		truncation *ResultTruncation
+	}{
+		{
+			name: "no limits",
+			rows: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "negative row limit is unlimited",
+			rowLimit: -1,
+			rows:     []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:       "row limit applies to all statements together",
+			rowLimit:   3,
+			rows:       []string{"a", "b", "c"},
+			truncation: &ResultTruncation{Truncated: true, Reason: TruncatedByRowLimit, Limit: 3, Rows: 3},
+		},
+		{
+			name:       "byte limit applies to all statements together",
+			byteLimit:  3,
+			rows:       []string{"a", "b", "c"},
+			truncation: &ResultTruncation{Truncated: true, Reason: TruncatedByByteLimit, Limit: 3, Rows: 3},
+		},
+		{
+			name:       "row limit reached in the first statement",
+			rowLimit:   1,
+			byteLimit:  10,
+			rows:       []string{"a"},
+			truncation: &ResultTruncation{Truncated: true, Reason: TruncatedByRowLimit, Limit: 1, Rows: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, truncation, err := readResults(conn.Exec(context.Background(), "SELECT 1; SELECT 2"), tt.rowLimit, tt.byteLimit)
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+			require.Equal(t, tt.rows, rowsOf(results))
+			require.Equal(t, tt.truncation, truncation)
+		})
+	}
+}
@@ -12,14 +12,17 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
@@ -40,6 +43,9 @@ const (
 	TargetModelField     = "target"
 )
 
+// maxConcurrentQueries is the maximum number of queries of a request that are sent to Graphite at the same time.
+const maxConcurrentQueries = 10
+
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
 	return &Service{
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
@@ -48,9 +54,20 @@ func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Trace
 }
 
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
-	Id         int64
+	HTTPClient   *http.Client
+	URL          string
+	Id           int64
+	SupportsTags bool
+}
+
+type graphiteQuery struct {
+	refID    string
+	req      *http.Request
+	formData url.Values
+}
+
+type datasourceJSONData struct {
+	GraphiteVersion string `json:"graphiteVersion"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -65,10 +82,18 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := datasourceJSONData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("failed to parse data source settings: %w", err)
+			}
+		}
+
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:   client,
+			URL:          settings.URL,
+			Id:           settings.ID,
+			SupportsTags: supportsTags(jsonData.GraphiteVersion),
 		}
 
 		return model, nil
@@ -97,15 +122,21 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	ctx, span := s.tracer.Start(ctx, "datasource.graphite.queryData", trace.WithAttributes(
+		attribute.Int("queries", len(req.Queries)),
+		attribute.Int64("datasource_id", dsInfo.Id),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	))
+	defer span.End()
+
+	result := backend.NewQueryDataResponse()
 	emptyQueries := []string{}
-	graphiteQueries := map[string]struct {
-		req      *http.Request
-		formData url.Values
-	}{}
+	graphiteQueries := make([]graphiteQuery, 0, len(req.Queries))
 	for _, query := range req.Queries {
 		graphiteReq, formData, emptyQuery, err := s.createGraphiteRequest(ctx, query, logger, dsInfo)
 		if err != nil {
-			return nil, err
+			result.Responses[query.RefID] = backend.ErrorResponseWithErrorSource(err)
+			continue
 		}
 
 		if emptyQuery != nil {
@@ -113,89 +144,77 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			continue
 		}
 
-		graphiteQueries[query.RefID] = struct {
-			req      *http.Request
-			formData url.Values
-		}{
+		graphiteQueries = append(graphiteQueries, graphiteQuery{
+			refID:    query.RefID,
 			req:      graphiteReq,
 			formData: formData,
-		}
+		})
 	}
 
-	var result = backend.QueryDataResponse{}
 	if len(emptyQueries) != 0 {
 		logger.Warn("Found query models without targets", "models without targets", strings.Join(emptyQueries, "\n"))
 		// If no queries had a valid target, return an error; otherwise, attempt with the targets we have
 		if len(emptyQueries) == len(req.Queries) {
-			if result.Responses == nil {
-				result.Responses = make(map[string]backend.DataResponse)
-			}
 			// marking this downstream error as it is a user error, but arguably this is a plugin error
 			// since the plugin should have frontend validation that prevents us from getting into this state
 			missingQueryResponse := backend.ErrDataResponseWithSource(400, backend.ErrorSourceDownstream, "no query target found for the alert rule")
 			result.Responses["A"] = missingQueryResponse
-			return &result, nil
+			return result, nil
 		}
 	}
 
-	frames := data.Frames{}
-
-	for refId, graphiteReq := range graphiteQueries {
-		ctx, span := s.tracer.Start(ctx, "graphite query")
-		defer span.End()
-		targetStr := strings.Join(graphiteReq.formData["target"], ",")
-		span.SetAttributes(
-			attribute.String("refId", refId),
-			attribute.String("target", targetStr),
-			attribute.String("from", graphiteReq.formData["from"][0]),
-			attribute.String("until", graphiteReq.formData["until"][0]),
-			attribute.Int64("datasource_id", dsInfo.Id),
-			attribute.Int64("org_id", req.PluginContext.OrgID),
-		)
-		s.tracer.Inject(ctx, graphiteReq.req.Header, span)
-		res, err := dsInfo.HTTPClient.Do(graphiteReq.req)
-		if res != nil {
-			span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
-		}
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-			return &result, err
-		}
+	resultLock := sync.Mutex{}
+	err = concurrency.ForEachJob(ctx, len(graphiteQueries), maxConcurrentQueries, func(ctx context.Context, idx int) error {
+		query := graphiteQueries[idx]
+		queryRes := s.executeQuery(ctx, logger, dsInfo, query, req.PluginContext.OrgID)
 
-		defer func() {
-			err := res.Body.Close()
-			if err != nil {
-				logger.Warn("Failed to close response body", "error", err)
-			}
-		}()
-
-		queryFrames, err := s.toDataFrames(logger, res, refId)
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-			return &result, err
-		}
+		resultLock.Lock()
+		defer resultLock.Unlock()
+		result.Responses[query.refID] = queryRes
+		return nil // errors are saved per-query, always return nil
+	})
+	return result, err
+}
 
-		frames = append(frames, queryFrames...)
+// executeQuery sends a single query to Graphite and converts the result to a data response.
+func (s *Service) executeQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query graphiteQuery, orgID int64) backend.DataResponse {
+	ctx, span := s.tracer.Start(ctx, "graphite query")
+	defer span.End()
+	targetStr := strings.Join(query.formData["target"], ",")
+	span.SetAttributes(
+		attribute.String("refId", query.refID),
+		attribute.String("target", targetStr),
+		attribute.String("from", query.formData["from"][0]),
+		attribute.String("until", query.formData["until"][0]),
+		attribute.Int64("datasource_id", dsInfo.Id),
+		attribute.Int64("org_id", orgID),
+	)
+	req := query.req.WithContext(ctx)
+	s.tracer.Inject(ctx, req.Header, span)
+	res, err := dsInfo.HTTPClient.Do(req)
+	if res != nil {
+		span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
 	}
-
-	result = backend.QueryDataResponse{
-		Responses: make(backend.Responses),
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Graphite request failed", "refId", query.refID, "error", err)
+		if backend.IsDownstreamHTTPError(err) {
+			err = backend.DownstreamError(err)
+		}
+		return backend.ErrorResponseWithErrorSource(err)
 	}
 
-	for _, f := range frames {
-		if resp, ok := result.Responses[f.Name]; ok {
-			resp.Frames = append(resp.Frames, f)
-			result.Responses[f.Name] = resp
-		} else {
-			result.Responses[f.Name] = backend.DataResponse{
-				Frames: data.Frames{f},
-			}
-		}
+	frames, err := s.toDataFrames(logger, res, query.refID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errRes := backend.ErrorResponseWithErrorSource(err)
+		errRes.Status = backend.Status(res.StatusCode)
+		return errRes
 	}
 
-	return &result, nil
+	return backend.DataResponse{Frames: frames}
 }
 
 // processQuery converts a Graphite data source query to a Graphite query target. It returns the target,
@@ -203,7 +222,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 func (s *Service) processQuery(logger log.Logger, query backend.DataQuery) (string, *simplejson.Json, error) {
 	model, err := simplejson.NewJson(query.JSON)
 	if err != nil {
-		return "", nil, err
+		return "", nil, backend.DownstreamError(fmt.Errorf("failed to parse query model: %w", err))
 	}
 	logger.Debug("Graphite", "query", model)
 	currTarget := ""
@@ -289,14 +308,14 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]Target
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		return nil, backend.DownstreamErrorf("request failed, status: %s", res.Status)
 	}
 
 	var data []TargetResponseDTO
 	err = json.Unmarshal(body, &data)
 	if err != nil {
 		logger.Info("Failed to unmarshal graphite response", "error", err, "status", res.Status, "body", string(body))
-		return nil, err
+		return nil, backend.DownstreamError(err)
 	}
 
 	return data, nil
@@ -316,7 +335,7 @@ func (s *Service) toDataFrames(logger log.Logger, response *http.Response, refId
 		for _, dataPoint := range series.DataPoints {
 			var timestamp, value, err = parseDataTimePoint(dataPoint)
 			if err != nil {
-				return nil, err
+				return nil, backend.DownstreamError(err)
 			}
 			timeVector = append(timeVector, timestamp)
 			values = append(values, value)
@@ -347,6 +366,21 @@ func (s *Service) toDataFrames(logger log.Logger, response *http.Response, refId
 	return frames, nil
 }
 
+// supportsTags returns true if the Graphite version supports tags, which were introduced in 1.1.
+// Data sources without a version are assumed to use the latest version.
+func supportsTags(version string) bool {
+	if version == "" {
+		return true
+	}
+	major, minor, _ := strings.Cut(version, ".")
+	majorVersion, err := strconv.Atoi(major)
+	if err != nil {
+		return true
+	}
+	minorVersion, _ := strconv.Atoi(minor)
+	return majorVersion > 1 || (majorVersion == 1 && minorVersion >= 1)
+}
+
 func fixIntervalFormat(target string) string {
 	rMinute := regexp.MustCompile(`'(\d+)m'`)
 	target = rMinute.ReplaceAllStringFunc(target, func(m string) string {
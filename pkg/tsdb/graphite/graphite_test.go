@@ -3,6 +3,7 @@ package graphite
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -239,3 +240,54 @@ func TestConvertResponses(t *testing.T) {
 		}
 	})
 }
+
+func TestQueryDataConcurrently(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		target := r.Form.Get("target")
+		if target == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"target": "` + target + `", "datapoints": [[50, 1]]}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := ProvideService(httpclient.NewProvider(), tracing.NewNoopTracerService())
+	queries := make([]backend.DataQuery, 0, 2*maxConcurrentQueries)
+	for i := 0; i < 2*maxConcurrentQueries; i++ {
+		target := fmt.Sprintf("series.%d", i)
+		if i%5 == 0 {
+			target = "fail"
+		}
+		queries = append(queries, backend.DataQuery{
+			RefID: fmt.Sprintf("Q%d", i),
+			JSON:  []byte(`{"target": "` + target + `"}`),
+		})
+	}
+	queries = append(queries, backend.DataQuery{RefID: "invalid", JSON: []byte(`{`)})
+
+	rsp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{URL: server.URL},
+		},
+		Queries: queries,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, rsp.Responses, len(queries))
+	for i := 0; i < 2*maxConcurrentQueries; i++ {
+		res := rsp.Responses[fmt.Sprintf("Q%d", i)]
+		if i%5 == 0 {
+			require.Error(t, res.Error)
+			assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+			assert.Equal(t, backend.StatusInternal, res.Status)
+			continue
+		}
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, fmt.Sprintf("series.%d", i), res.Frames[0].Fields[1].Config.DisplayNameFromDS)
+	}
+	assert.Error(t, rsp.Responses["invalid"].Error)
+	assert.Equal(t, backend.ErrorSourceDownstream, rsp.Responses["invalid"].ErrorSource)
+}
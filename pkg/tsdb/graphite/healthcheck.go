@@ -0,0 +1,105 @@
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// healthCheckTarget is the target rendered by the health check. It does not depend on any stored series.
+const healthCheckTarget = "constantLine(100)"
+
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Health check failed: Failed to get data source info",
+		}, nil
+	}
+
+	if err := s.checkRender(ctx, logger, dsInfo); err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Health check failed: %s", err),
+		}, nil
+	}
+
+	if dsInfo.SupportsTags {
+		if err := s.checkTags(ctx, logger, dsInfo); err != nil {
+			return &backend.CheckHealthResult{
+				Status:  backend.HealthStatusError,
+				Message: fmt.Sprintf("Health check failed: %s. If your Graphite version does not support tags, select an older version in the data source settings", err),
+			}, nil
+		}
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source is working",
+	}, nil
+}
+
+// checkRender verifies that the render endpoint returns data in the expected format.
+func (s *Service) checkRender(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo) error {
+	req, err := s.createRequest(ctx, logger, dsInfo, url.Values{
+		"target": []string{healthCheckTarget},
+		"from":   []string{"-1h"},
+		"until":  []string{"now"},
+		"format": []string{"json"},
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		logger.Error("Failed to connect to Graphite", "error", err)
+		return fmt.Errorf("failed to connect to Graphite")
+	}
+	if _, err := s.parseResponse(logger, res); err != nil {
+		return fmt.Errorf("render endpoint %w", err)
+	}
+	return nil
+}
+
+// checkTags verifies that the tags endpoint is available.
+func (s *Service) checkTags(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo) error {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse data source URL")
+	}
+	u.Path = path.Join(u.Path, "tags/autoComplete/tags")
+	u.RawQuery = url.Values{"limit": []string{"1"}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		logger.Error("Failed to connect to Graphite", "error", err)
+		return fmt.Errorf("failed to connect to Graphite")
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if res.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		logger.Info("Tags request failed", "status", res.Status, "body", string(body))
+		return fmt.Errorf("tags endpoint request failed, status: %s", res.Status)
+	}
+	return nil
+}
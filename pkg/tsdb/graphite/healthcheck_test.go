@@ -0,0 +1,98 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestCheckHealth(t *testing.T) {
+	setup := func(t *testing.T, renderStatus, tagsStatus int) (*Service, *httptest.Server) {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/render":
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, healthCheckTarget, r.Form.Get("target"))
+				w.WriteHeader(renderStatus)
+				_, _ = w.Write([]byte(`[{"target": "constantLine(100)", "datapoints": [[100, 1]]}]`))
+			case "/tags/autoComplete/tags":
+				w.WriteHeader(tagsStatus)
+				_, _ = w.Write([]byte(`["name"]`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return ProvideService(httpclient.NewProvider(), tracing.NewNoopTracerService()), server
+	}
+
+	request := func(url string, jsonData string) *backend.CheckHealthRequest {
+		return &backend.CheckHealthRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					URL:      url,
+					JSONData: []byte(jsonData),
+				},
+			},
+		}
+	}
+
+	t.Run("should succeed if render and tags endpoints work", func(t *testing.T) {
+		service, server := setup(t, http.StatusOK, http.StatusOK)
+
+		res, err := service.CheckHealth(context.Background(), request(server.URL, `{"graphiteVersion": "1.1"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("should fail if render endpoint fails", func(t *testing.T) {
+		service, server := setup(t, http.StatusInternalServerError, http.StatusOK)
+
+		res, err := service.CheckHealth(context.Background(), request(server.URL, `{}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, "render endpoint request failed")
+	})
+
+	t.Run("should fail if tags endpoint fails", func(t *testing.T) {
+		service, server := setup(t, http.StatusOK, http.StatusNotFound)
+
+		res, err := service.CheckHealth(context.Background(), request(server.URL, `{}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, "tags endpoint request failed")
+	})
+
+	t.Run("should not check tags if the version does not support them", func(t *testing.T) {
+		service, server := setup(t, http.StatusOK, http.StatusNotFound)
+
+		res, err := service.CheckHealth(context.Background(), request(server.URL, `{"graphiteVersion": "1.0"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+}
+
+func TestSupportsTags(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"":    true,
+		"0.9": false,
+		"1.0": false,
+		"1.1": true,
+		"2.0": true,
+	} {
+		assert.Equal(t, expected, supportsTags(version), version)
+	}
+}
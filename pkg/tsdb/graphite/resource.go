@@ -0,0 +1,127 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// resourcePaths are the Graphite API endpoints that can be called through CallResource.
+var resourcePaths = map[string]struct{}{
+	"metrics/find":             {},
+	"metrics/expand":           {},
+	"tags":                     {},
+	"tags/autoComplete/tags":   {},
+	"tags/autoComplete/values": {},
+}
+
+// forwardedResourceHeaders are the headers of a resource request that are sent to Graphite.
+// Authentication headers are added by the HTTP client of the data source.
+var forwardedResourceHeaders = []string{"Accept", "Content-Type"}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	if _, ok := resourcePaths[req.Path]; !ok {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		logger.Error("Invalid resource method", "path", req.Path, "method", req.Method)
+		return fmt.Errorf("invalid resource method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	graphiteURL, err := createResourceURL(req, dsInfo)
+	if err != nil {
+		logger.Error("Failed to create request url", "error", err, "url", dsInfo.URL, "path", req.Path)
+		return err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("path", req.Path),
+		attribute.Int64("datasource_id", dsInfo.Id),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	)
+
+	request, err := http.NewRequestWithContext(ctx, req.Method, graphiteURL, bytes.NewReader(req.Body))
+	if err != nil {
+		logger.Error("Failed to create request", "error", err, "url", graphiteURL)
+		return err
+	}
+	for _, h := range forwardedResourceHeaders {
+		if v, ok := req.Headers[h]; ok && len(v) > 0 {
+			request.Header.Set(h, v[0])
+		}
+	}
+	s.tracer.Inject(ctx, request.Header, span)
+
+	logger.Debug("Sending request to Graphite", "resourcePath", req.Path)
+	start := time.Now()
+	response, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Error received from Graphite", "error", err, "duration", time.Since(start), "resourcePath", req.Path)
+		return err
+	}
+	span.SetAttributes(attribute.Int("graphite.response.code", response.StatusCode))
+	logger.Debug("Response received from Graphite", "statusCode", response.StatusCode, "duration", time.Since(start), "resourcePath", req.Path)
+
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		logger.Error("Error reading response body bytes", "error", err)
+		return err
+	}
+
+	responseHeaders := map[string][]string{
+		"content-type": {"application/json"},
+	}
+	if ct := response.Header.Get("Content-Type"); ct != "" {
+		responseHeaders["content-type"] = []string{ct}
+	}
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  response.StatusCode,
+		Headers: responseHeaders,
+		Body:    body,
+	})
+}
+
+// createResourceURL returns the Graphite URL of the resource request, keeping its query parameters.
+func createResourceURL(req *backend.CallResourceRequest, dsInfo *datasourceInfo) (string, error) {
+	graphiteURL, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse data source URL: %s, error: %w", dsInfo.URL, err)
+	}
+	graphiteURL.Path = path.Join(graphiteURL.Path, req.Path)
+
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse resource URL: %s, error: %w", req.URL, err)
+	}
+	graphiteURL.RawQuery = reqURL.RawQuery
+	return graphiteURL.String(), nil
+}
@@ -0,0 +1,108 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestCallResource(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		lastRequest = r
+		lastBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"text": "a", "id": "a", "leaf": 0, "expandable": 1}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := ProvideService(httpclient.NewProvider(), tracing.NewNoopTracerService())
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			URL:              server.URL + "/graphite",
+			BasicAuthEnabled: true,
+			BasicAuthUser:    "user",
+			DecryptedSecureJSONData: map[string]string{
+				"basicAuthPassword": "password",
+			},
+		},
+	}
+
+	call := func(t *testing.T, req *backend.CallResourceRequest) (*backend.CallResourceResponse, error) {
+		t.Helper()
+		req.PluginContext = pluginCtx
+		var res *backend.CallResourceResponse
+		err := service.CallResource(context.Background(), req, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+			res = r
+			return nil
+		}))
+		return res, err
+	}
+
+	t.Run("should forward GET requests with query parameters", func(t *testing.T) {
+		res, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "metrics/find",
+			URL:    "metrics/find?query=a.*&from=-1h",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[{"text": "a", "id": "a", "leaf": 0, "expandable": 1}]`, string(res.Body))
+		assert.Equal(t, "/graphite/metrics/find", lastRequest.URL.Path)
+		assert.Equal(t, "a.*", lastRequest.URL.Query().Get("query"))
+		user, password, ok := lastRequest.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "password", password)
+	})
+
+	t.Run("should forward POST requests with their body", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "tags/autoComplete/values",
+			URL:     "tags/autoComplete/values",
+			Body:    []byte("tag=name&expr=env%3Dprod"),
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}, "Cookie": {"session=secret"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, lastRequest.Method)
+		assert.Equal(t, "/graphite/tags/autoComplete/values", lastRequest.URL.Path)
+		assert.Equal(t, "tag=name&expr=env%3Dprod", lastBody)
+		assert.Equal(t, "application/x-www-form-urlencoded", lastRequest.Header.Get("Content-Type"))
+		assert.Empty(t, lastRequest.Header.Get("Cookie"))
+	})
+
+	t.Run("should reject unknown paths", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "render",
+			URL:    "render?target=a",
+		})
+
+		require.Error(t, err)
+	})
+
+	t.Run("should reject unsupported methods", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodDelete,
+			Path:   "tags",
+			URL:    "tags",
+		})
+
+		require.Error(t, err)
+	})
+}
@@ -65,6 +65,7 @@ var (
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	splitting  querySplitting
 
 	// open streams
 	streams   map[string]data.FrameJSONCache
@@ -104,9 +105,15 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, backend.DownstreamError(fmt.Errorf("error creating http client: %w", err))
 		}
 
+		splitting, err := parseQuerySplitting(settings.JSONData)
+		if err != nil {
+			return nil, backend.DownstreamError(fmt.Errorf("error reading settings: %w", err))
+		}
+
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
+			splitting:  splitting,
 			streams:    make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
@@ -217,7 +224,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, dsInfo.splitting, responseOpts, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -226,7 +233,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, dsInfo.splitting, responseOpts, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -234,7 +241,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer trace.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, splitting querySplitting, responseOpts ResponseOpts, tracer trace.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -247,7 +254,7 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	queryRes, err := runQuery(ctx, api, query, responseOpts, plog)
+	queryRes, err := runSplitQuery(ctx, api, query, splitting, responseOpts, plog)
 	if queryRes == nil {
 		// we always want to return a backend.DataResponse object, even if we received just an error
 		queryRes = &backend.DataResponse{}
@@ -0,0 +1,472 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+const defaultQuerySplitConcurrency = 4
+
+// these stats are added up when the frames of split queries are merged, the same way
+// the frontend does it when it splits queries. other stats are taken from the first frame.
+var summedStats = map[string]bool{
+	"Summary: total bytes processed": true,
+	"Summary: exec time":             true,
+}
+
+// querySplitting configures how range queries over long time ranges are split
+// into sub-queries. Sub-queries of metric queries are sent to Loki concurrently, those of
+// logs queries one after another until the max lines are reached.
+type querySplitting struct {
+	// Duration is the maximum time range of a sub-query. Queries are not split when it is 0.
+	Duration time.Duration
+	// Concurrency is the maximum number of sub-queries of a metric query that run at the same time
+	Concurrency int
+}
+
+type querySplittingJSONData struct {
+	QuerySplitDuration    string `json:"querySplitDuration"`
+	QuerySplitConcurrency int    `json:"querySplitConcurrency"`
+}
+
+func parseQuerySplitting(jsonData json.RawMessage) (querySplitting, error) {
+	splitting := querySplitting{Concurrency: defaultQuerySplitConcurrency}
+	if len(jsonData) == 0 {
+		return splitting, nil
+	}
+
+	model := querySplittingJSONData{}
+	if err := json.Unmarshal(jsonData, &model); err != nil {
+		return splitting, fmt.Errorf("failed to parse query splitting settings: %w", err)
+	}
+
+	if model.QuerySplitDuration != "" {
+		duration, err := gtime.ParseDuration(model.QuerySplitDuration)
+		if err != nil {
+			return splitting, fmt.Errorf("invalid querySplitDuration: %w", err)
+		}
+		if duration < 0 {
+			return splitting, fmt.Errorf("invalid querySplitDuration: %s", model.QuerySplitDuration)
+		}
+		splitting.Duration = duration
+	}
+
+	if model.QuerySplitConcurrency > 0 {
+		splitting.Concurrency = model.QuerySplitConcurrency
+	}
+
+	return splitting, nil
+}
+
+// splitQuery splits a range query into sub-queries that cover at most the split duration.
+// the query is returned as it is when it cannot be split.
+func splitQuery(query *lokiQuery, splitDuration time.Duration) []*lokiQuery {
+	if splitDuration <= 0 || query.QueryType != QueryTypeRange || query.End.Sub(query.Start) <= splitDuration {
+		return []*lokiQuery{query}
+	}
+
+	// samples only need a few lines, so they are not worth splitting
+	if query.SupportingQueryType == SupportingQueryLogsSample || query.SupportingQueryType == SupportingQueryDataSample {
+		return []*lokiQuery{query}
+	}
+
+	isLogs, err := isLogsQuery(query.Expr)
+	if err != nil {
+		return []*lokiQuery{query}
+	}
+
+	var ranges [][2]time.Time
+	if isLogs {
+		ranges = splitLogsTimeRange(query.Start, query.End, splitDuration)
+	} else {
+		ranges = splitMetricTimeRange(query.Start, query.End, query.Step, splitDuration)
+	}
+
+	queries := make([]*lokiQuery, 0, len(ranges))
+	for _, r := range ranges {
+		subQuery := *query
+		subQuery.Start = r[0]
+		subQuery.End = r[1]
+		queries = append(queries, &subQuery)
+	}
+	return queries
+}
+
+func isLogsQuery(expr string) (bool, error) {
+	parsed, err := syntax.ParseExpr(expr)
+	if err != nil {
+		return false, err
+	}
+	_, isSample := parsed.(syntax.SampleExpr)
+	return !isSample, nil
+}
+
+// splitLogsTimeRange splits the time range of a logs query. Loki includes the start and excludes
+// the end of a logs query, so the sub-ranges can share their boundaries without returning a line twice.
+// the first sub-range is the shorter one if the time range is not a multiple of the split duration.
+func splitLogsTimeRange(start time.Time, end time.Time, splitDuration time.Duration) [][2]time.Time {
+	var ranges [][2]time.Time
+	for chunkEnd := end; chunkEnd.After(start); chunkEnd = chunkEnd.Add(-splitDuration) {
+		chunkStart := chunkEnd.Add(-splitDuration)
+		if chunkStart.Before(start) {
+			chunkStart = start
+		}
+		ranges = append(ranges, [2]time.Time{chunkStart, chunkEnd})
+	}
+
+	// we walked backwards, so the ranges have to be reversed
+	for i, j := 0, len(ranges)-1; i < j; i, j = i+1, j-1 {
+		ranges[i], ranges[j] = ranges[j], ranges[i]
+	}
+	return ranges
+}
+
+// splitMetricTimeRange splits the time range of a metric query at multiples of the step.
+// Loki includes both the start and the end of a metric query, so every sub-range ends one step
+// before the start of the next one, and the last sub-range can consist of the end only.
+// this is compatible with https://github.com/grafana/loki/blob/089ec1b05f5ec15a8851d0e8230153e0eeb4dcec/pkg/querier/queryrange/split_by_interval.go#L327-L336
+func splitMetricTimeRange(start time.Time, end time.Time, step time.Duration, splitDuration time.Duration) [][2]time.Time {
+	stepMs := step.Milliseconds()
+	if stepMs <= 0 || splitDuration.Milliseconds() < stepMs {
+		// we cannot create sub-ranges smaller than the step
+		return [][2]time.Time{{start, end}}
+	}
+
+	// the duration is a multiple of the step, lowered if necessary
+	alignedDurationMs := splitDuration.Milliseconds() / stepMs * stepMs
+	startMs := start.UnixMilli()
+	endMs := end.UnixMilli()
+	alignedStartMs := startMs - startMs%stepMs
+
+	var ranges [][2]time.Time
+	for chunkStartMs := alignedStartMs; chunkStartMs <= endMs; chunkStartMs += alignedDurationMs {
+		chunkEndMs := min(chunkStartMs+alignedDurationMs-stepMs, endMs)
+		ranges = append(ranges, [2]time.Time{time.UnixMilli(chunkStartMs).UTC(), time.UnixMilli(chunkEndMs).UTC()})
+	}
+	return ranges
+}
+
+// runSplitQuery runs the sub-queries of a query and merges their frames. The sub-queries of metric
+// queries run concurrently, those of logs queries run one after another, see runSplitLogsQuery.
+// the query is run as it is when it does not need to be split.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, splitting querySplitting, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	queries := splitQuery(query, splitting.Duration)
+	if len(queries) == 1 {
+		return runQuery(ctx, api, queries[0], responseOpts, plog)
+	}
+
+	plog.Debug("Splitting query", "refId", query.RefID, "subQueriesLength", len(queries), "splitDuration", splitting.Duration)
+
+	// the query was split, so its expression parses
+	if isLogs, _ := isLogsQuery(query.Expr); isLogs {
+		return runSplitLogsQuery(ctx, api, query, queries, responseOpts, plog)
+	}
+
+	responses := make([]*backend.DataResponse, len(queries))
+	err := concurrency.ForEachJob(ctx, len(queries), splitting.Concurrency, func(ctx context.Context, idx int) error {
+		res, err := runQuery(ctx, api, queries[idx], responseOpts, plog)
+		if err != nil {
+			return err
+		}
+		if res != nil && res.Error != nil {
+			// we stop the other sub-queries, the response of the query is this error response
+			responses[idx] = res
+			return res.Error
+		}
+		responses[idx] = res
+		return nil
+	})
+
+	for _, res := range responses {
+		if res != nil && res.Error != nil {
+			return res, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]data.Frames, 0, len(responses))
+	for _, res := range responses {
+		if res != nil {
+			frames = append(frames, res.Frames)
+		}
+	}
+
+	merged, err := mergeSplitFrames(frames, query)
+	if err != nil {
+		return nil, err
+	}
+	return &backend.DataResponse{Frames: merged}, nil
+}
+
+// runSplitLogsQuery runs the sub-queries of a logs query one after another in the direction of the query,
+// so the newest lines are fetched first for backward queries. Every sub-query asks for the lines that are
+// still missing, and no more sub-queries are run once the max lines of the query are reached, as the frontend does.
+func runSplitLogsQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, queries []*lokiQuery, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	frames := make([]data.Frames, 0, len(queries))
+	lines := 0
+	for i := range queries {
+		subQuery := queries[i]
+		if query.Direction == DirectionBackward {
+			subQuery = queries[len(queries)-1-i]
+		}
+		if query.MaxLines > 0 {
+			subQuery.MaxLines = query.MaxLines - lines
+		}
+
+		res, err := runQuery(ctx, api, subQuery, responseOpts, plog)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			continue
+		}
+		if res.Error != nil {
+			return res, nil
+		}
+		frames = append(frames, res.Frames)
+
+		for _, frame := range res.Frames {
+			if isLogsFrame(frame) {
+				lines += frame.Rows()
+			}
+		}
+		if query.MaxLines > 0 && lines >= query.MaxLines {
+			break
+		}
+	}
+
+	merged, err := mergeSplitFrames(frames, query)
+	if err != nil {
+		return nil, err
+	}
+	return &backend.DataResponse{Frames: merged}, nil
+}
+
+// mergeSplitFrames merges the frames of the sub-queries of a query. Series of metric queries
+// are joined by their labels, and log lines are joined into one frame. Rows are deduplicated
+// and ordered by time, and log lines are limited to the max lines of the query.
+func mergeSplitFrames(responses []data.Frames, query *lokiQuery) (data.Frames, error) {
+	var merged data.Frames
+	groups := map[string][]*data.Frame{}
+	for _, frames := range responses {
+		for _, frame := range frames {
+			key, err := splitFrameKey(frame)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := groups[key]; !ok {
+				// the frame is a placeholder, it keeps the order in which the frames are first seen
+				merged = append(merged, frame)
+			}
+			groups[key] = append(groups[key], frame)
+		}
+	}
+
+	for i, frame := range merged {
+		key, err := splitFrameKey(frame)
+		if err != nil {
+			return nil, err
+		}
+		group := groups[key]
+		if len(group) == 1 {
+			continue
+		}
+
+		if isLogsFrame(frame) {
+			merged[i], err = mergeLogsFrames(group, query)
+		} else {
+			merged[i], err = mergeMetricFrames(group)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+func isLogsFrame(frame *data.Frame) bool {
+	// metric-fields have "timefield, valuefield"
+	// logs-fields have "labelsfield, timefield, ..."
+	return len(frame.Fields) > 2 || (len(frame.Fields) == 2 && frame.Fields[1].Type() != data.FieldTypeFloat64)
+}
+
+// splitFrameKey returns the key by which the frames of split queries are joined
+func splitFrameKey(frame *data.Frame) (string, error) {
+	if len(frame.Fields) < 2 {
+		// frames without fields have no rows to merge
+		return "empty:" + frame.Name, nil
+	}
+	if isLogsFrame(frame) {
+		names := ""
+		for _, field := range frame.Fields {
+			names += field.Name + ","
+		}
+		return "logs:" + frame.Name + ":" + names, nil
+	}
+	return "metric:" + frame.Name + ":" + frame.Fields[1].Labels.String(), nil
+}
+
+func mergeMetricFrames(frames []*data.Frame) (*data.Frame, error) {
+	merged, err := concatFrames(frames)
+	if err != nil {
+		return nil, err
+	}
+
+	// sub-ranges do not overlap, but we still make sure every timestamp is returned once
+	seen := map[time.Time]bool{}
+	merged, err = filterAndSortRows(merged, 0, false, func(row []any) bool {
+		t, ok := row[0].(time.Time)
+		if !ok {
+			return true
+		}
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func mergeLogsFrames(frames []*data.Frame, query *lokiQuery) (*data.Frame, error) {
+	merged, err := concatFrames(frames)
+	if err != nil {
+		return nil, err
+	}
+
+	idIdx := -1
+	for i, field := range merged.Fields {
+		if field.Name == "id" && field.Type() == data.FieldTypeString {
+			idIdx = i
+		}
+	}
+
+	seen := map[string]bool{}
+	merged, err = filterAndSortRows(merged, 1, query.Direction == DirectionBackward, func(row []any) bool {
+		if idIdx < 0 {
+			return true
+		}
+		id, ok := row[idIdx].(string)
+		if !ok {
+			return true
+		}
+		if seen[id] {
+			return false
+		}
+		seen[id] = true
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if query.MaxLines > 0 && merged.Rows() > query.MaxLines {
+		merged = limitRows(merged, query.MaxLines)
+	}
+	return merged, nil
+}
+
+// concatFrames appends the rows of all frames to a copy of the first frame
+func concatFrames(frames []*data.Frame) (*data.Frame, error) {
+	first := frames[0]
+	merged := first.EmptyCopy()
+	merged.Meta = mergeFrameMeta(frames)
+
+	for _, frame := range frames {
+		if len(frame.Fields) != len(merged.Fields) {
+			return nil, fmt.Errorf("cannot merge frames of split queries: expected %d fields, got %d", len(merged.Fields), len(frame.Fields))
+		}
+		rows, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < rows; i++ {
+			merged.AppendRow(frame.RowCopy(i)...)
+		}
+	}
+	return merged, nil
+}
+
+func mergeFrameMeta(frames []*data.Frame) *data.FrameMeta {
+	if frames[0].Meta == nil {
+		return nil
+	}
+	meta := *frames[0].Meta
+	meta.Stats = make([]data.QueryStat, len(frames[0].Meta.Stats))
+	copy(meta.Stats, frames[0].Meta.Stats)
+
+	for i, stat := range meta.Stats {
+		if !summedStats[stat.DisplayName] {
+			continue
+		}
+		for _, frame := range frames[1:] {
+			if frame.Meta == nil {
+				continue
+			}
+			for _, other := range frame.Meta.Stats {
+				if other.DisplayName == stat.DisplayName {
+					meta.Stats[i].Value += other.Value
+				}
+			}
+		}
+	}
+	return &meta
+}
+
+// filterAndSortRows returns a copy of the frame with the rows that are kept by the filter,
+// ordered by the time field at the given index.
+func filterAndSortRows(frame *data.Frame, timeIdx int, descending bool, keep func(row []any) bool) (*data.Frame, error) {
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, 0, rowLen)
+	for i := 0; i < rowLen; i++ {
+		row := frame.RowCopy(i)
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	rowTime := func(row []any) time.Time {
+		t, _ := row[timeIdx].(time.Time)
+		return t
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if descending {
+			return rowTime(rows[i]).After(rowTime(rows[j]))
+		}
+		return rowTime(rows[i]).Before(rowTime(rows[j]))
+	})
+
+	sorted := frame.EmptyCopy()
+	for _, row := range rows {
+		sorted.AppendRow(row...)
+	}
+	return sorted, nil
+}
+
+func limitRows(frame *data.Frame, limit int) *data.Frame {
+	limited := frame.EmptyCopy()
+	for i := 0; i < limit; i++ {
+		limited.AppendRow(frame.RowCopy(i)...)
+	}
+	return limited
+}
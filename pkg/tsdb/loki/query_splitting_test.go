@@ -0,0 +1,214 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type splitQueryRoundTripper struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	respond  func(start time.Time, end time.Time) string
+}
+
+func (rt *splitQueryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, req)
+	rt.mu.Unlock()
+
+	start, err := strconv.ParseInt(req.URL.Query().Get("start"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	end, err := strconv.ParseInt(req.URL.Query().Get("end"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Add("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader([]byte(rt.respond(time.Unix(0, start).UTC(), time.Unix(0, end).UTC())))),
+	}, nil
+}
+
+func makeSplitQueryAPI(respond func(start time.Time, end time.Time) string) (*LokiAPI, *splitQueryRoundTripper) {
+	rt := &splitQueryRoundTripper{respond: respond}
+	client := http.Client{Transport: rt}
+	return newLokiAPI(&client, "http://localhost:9999", backend.NewLoggerWith("logger", "test"), tracing.DefaultTracer()), rt
+}
+
+func TestParseQuerySplitting(t *testing.T) {
+	t.Run("should not split queries by default", func(t *testing.T) {
+		splitting, err := parseQuerySplitting([]byte(`{}`))
+		require.NoError(t, err)
+		assert.Equal(t, querySplitting{Concurrency: defaultQuerySplitConcurrency}, splitting)
+	})
+
+	t.Run("should parse the split duration and concurrency", func(t *testing.T) {
+		splitting, err := parseQuerySplitting([]byte(`{"querySplitDuration": "1d", "querySplitConcurrency": 2}`))
+		require.NoError(t, err)
+		assert.Equal(t, querySplitting{Duration: 24 * time.Hour, Concurrency: 2}, splitting)
+	})
+
+	t.Run("should fail on an invalid split duration", func(t *testing.T) {
+		_, err := parseQuerySplitting([]byte(`{"querySplitDuration": "one day"}`))
+		require.Error(t, err)
+	})
+}
+
+func TestSplitQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should not split a query that is shorter than the split duration", func(t *testing.T) {
+		query := &lokiQuery{Expr: `{job="app"}`, QueryType: QueryTypeRange, Start: start, End: start.Add(12 * time.Hour)}
+		assert.Equal(t, []*lokiQuery{query}, splitQuery(query, 24*time.Hour))
+	})
+
+	t.Run("should not split an instant query", func(t *testing.T) {
+		query := &lokiQuery{Expr: `count_over_time({job="app"}[3d])`, QueryType: QueryTypeInstant, Start: start, End: start.Add(72 * time.Hour)}
+		assert.Equal(t, []*lokiQuery{query}, splitQuery(query, 24*time.Hour))
+	})
+
+	t.Run("should split a logs query into ranges that share their boundaries", func(t *testing.T) {
+		query := &lokiQuery{Expr: `{job="app"}`, QueryType: QueryTypeRange, Start: start, End: start.Add(60 * time.Hour)}
+
+		queries := splitQuery(query, 24*time.Hour)
+
+		require.Len(t, queries, 3)
+		assert.Equal(t, start, queries[0].Start)
+		assert.Equal(t, start.Add(12*time.Hour), queries[0].End)
+		assert.Equal(t, start.Add(12*time.Hour), queries[1].Start)
+		assert.Equal(t, start.Add(36*time.Hour), queries[1].End)
+		assert.Equal(t, start.Add(36*time.Hour), queries[2].Start)
+		assert.Equal(t, start.Add(60*time.Hour), queries[2].End)
+	})
+
+	t.Run("should split a metric query at multiples of the step", func(t *testing.T) {
+		query := &lokiQuery{Expr: `count_over_time({job="app"}[1m])`, QueryType: QueryTypeRange, Step: time.Hour, Start: start.Add(30 * time.Minute), End: start.Add(48 * time.Hour)}
+
+		queries := splitQuery(query, 24*time.Hour)
+
+		require.Len(t, queries, 3)
+		assert.Equal(t, start, queries[0].Start)
+		assert.Equal(t, start.Add(23*time.Hour), queries[0].End)
+		assert.Equal(t, start.Add(24*time.Hour), queries[1].Start)
+		assert.Equal(t, start.Add(47*time.Hour), queries[1].End)
+		assert.Equal(t, start.Add(48*time.Hour), queries[2].Start)
+		assert.Equal(t, start.Add(48*time.Hour), queries[2].End)
+	})
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	splitting := querySplitting{Duration: 24 * time.Hour, Concurrency: 2}
+
+	t.Run("should merge the series of a metric query", func(t *testing.T) {
+		api, rt := makeSplitQueryAPI(func(start time.Time, end time.Time) string {
+			return fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"job":"app"},"values":[[%d,"1"],[%d,"2"]]}
+			]}}`, start.Unix(), end.Unix())
+		})
+		query := &lokiQuery{Expr: `count_over_time({job="app"}[1m])`, QueryType: QueryTypeRange, Step: time.Hour, Start: start, End: start.Add(48 * time.Hour), RefID: "A"}
+
+		res, err := runSplitQuery(context.Background(), api, query, splitting, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		assert.Len(t, rt.requests, 3)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		// the last range only contains its start, which is also its end
+		require.Equal(t, 5, frame.Rows())
+		for i := 1; i < frame.Rows(); i++ {
+			assert.True(t, frame.Fields[0].At(i-1).(time.Time).Before(frame.Fields[0].At(i).(time.Time)))
+		}
+		assert.Equal(t, data.Labels{"job": "app"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("should merge, order and limit the lines of a logs query", func(t *testing.T) {
+		api, rt := makeSplitQueryAPI(func(start time.Time, end time.Time) string {
+			return fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"job":"app"},"values":[["%d","last line of %s"],["%d","first line of %s"]]}
+			]}}`, end.Add(-time.Second).UnixNano(), start, start.UnixNano(), start)
+		})
+		query := &lokiQuery{Expr: `{job="app"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, MaxLines: 5, Start: start, End: start.Add(72 * time.Hour), RefID: "A"}
+
+		res, err := runSplitQuery(context.Background(), api, query, splitting, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		assert.Len(t, rt.requests, 3)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 5, frame.Rows())
+		for i := 1; i < frame.Rows(); i++ {
+			assert.True(t, frame.Fields[1].At(i-1).(time.Time).After(frame.Fields[1].At(i).(time.Time)))
+		}
+		assert.Equal(t, fmt.Sprintf("last line of %s", start.Add(48*time.Hour)), frame.Fields[2].At(0))
+	})
+
+	t.Run("should run the sub-queries of a logs query in its direction until the max lines are reached", func(t *testing.T) {
+		respond := func(start time.Time, end time.Time) string {
+			return fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"job":"app"},"values":[["%d","last line of %s"],["%d","first line of %s"]]}
+			]}}`, end.Add(-time.Second).UnixNano(), start, start.UnixNano(), start)
+		}
+		for _, tc := range []struct {
+			direction dataquery.LokiQueryDirection
+			starts    []time.Time
+		}{
+			{direction: DirectionBackward, starts: []time.Time{start.Add(48 * time.Hour), start.Add(24 * time.Hour)}},
+			{direction: DirectionForward, starts: []time.Time{start, start.Add(24 * time.Hour)}},
+		} {
+			t.Run(string(tc.direction), func(t *testing.T) {
+				api, rt := makeSplitQueryAPI(respond)
+				query := &lokiQuery{Expr: `{job="app"}`, QueryType: QueryTypeRange, Direction: tc.direction, MaxLines: 3, Start: start, End: start.Add(72 * time.Hour), RefID: "A"}
+
+				res, err := runSplitQuery(context.Background(), api, query, splitting, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+
+				require.NoError(t, err)
+				require.NoError(t, res.Error)
+				require.Len(t, rt.requests, 2)
+				for i, req := range rt.requests {
+					assert.Equal(t, strconv.FormatInt(tc.starts[i].UnixNano(), 10), req.URL.Query().Get("start"))
+				}
+				assert.Equal(t, "3", rt.requests[0].URL.Query().Get("limit"))
+				assert.Equal(t, "1", rt.requests[1].URL.Query().Get("limit"))
+				require.Len(t, res.Frames, 1)
+				assert.Equal(t, 3, res.Frames[0].Rows())
+			})
+		}
+	})
+
+	t.Run("should deduplicate the lines of a logs query", func(t *testing.T) {
+		api, _ := makeSplitQueryAPI(func(time.Time, time.Time) string {
+			return fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[
+				{"stream":{"job":"app"},"values":[["%d","line"]]}
+			]}}`, start.UnixNano())
+		})
+		query := &lokiQuery{Expr: `{job="app"}`, QueryType: QueryTypeRange, Direction: DirectionForward, Start: start, End: start.Add(48 * time.Hour), RefID: "A"}
+
+		res, err := runSplitQuery(context.Background(), api, query, splitting, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+
+		require.NoError(t, err)
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, 1, res.Frames[0].Rows())
+	})
+}
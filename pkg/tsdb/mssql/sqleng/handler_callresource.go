@@ -0,0 +1,102 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+)
+
+// CallResource serves the schema of the database to query editors. All lookups accept a limit parameter
+// for the maximum number of returned rows, and the table lookups accept a schema parameter that defaults
+// to the default schema of the user.
+func (e *DataSourceHandler) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return httpadapter.New(e.registerResourceRoutes()).CallResource(ctx, req, sender)
+}
+
+func (e *DataSourceHandler) registerResourceRoutes() *http.ServeMux {
+	router := http.NewServeMux()
+	router.HandleFunc("GET /schemas", e.schemaHandler(false, func(ctx context.Context, schema, table string, limit int) (any, error) {
+		return e.Schemas(ctx, limit)
+	}))
+	router.HandleFunc("GET /tables", e.schemaHandler(false, func(ctx context.Context, schema, table string, limit int) (any, error) {
+		return e.Tables(ctx, schema, limit)
+	}))
+	router.HandleFunc("GET /columns", e.schemaHandler(true, func(ctx context.Context, schema, table string, limit int) (any, error) {
+		return e.Columns(ctx, schema, table, limit)
+	}))
+	router.HandleFunc("GET /indexes", e.schemaHandler(true, func(ctx context.Context, schema, table string, limit int) (any, error) {
+		return e.Indexes(ctx, schema, table, limit)
+	}))
+	return router
+}
+
+type schemaLookup func(ctx context.Context, schema, table string, limit int) (any, error)
+
+func (e *DataSourceHandler) schemaHandler(requiresTable bool, lookup schemaLookup) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		schema := strings.TrimSpace(r.URL.Query().Get("schema"))
+		table := strings.TrimSpace(r.URL.Query().Get("table"))
+		if requiresTable && table == "" {
+			writeResourceError(rw, http.StatusBadRequest, "missing table parameter")
+			return
+		}
+		limit, err := schemaRowLimit(r.URL.Query().Get("limit"), e.rowLimit)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadRequest, "invalid limit parameter")
+			return
+		}
+
+		key := schemaCacheKey(r.URL.Path, map[string]string{"schema": schema, "table": table, "limit": strconv.Itoa(limit)})
+		if body, ok := e.schemaCache.get(key); ok {
+			writeResourceBody(rw, body)
+			return
+		}
+
+		res, err := lookup(r.Context(), schema, table, limit)
+		if err != nil {
+			e.log.Error("Schema lookup failed", "path", r.URL.Path, "err", err)
+			writeResourceError(rw, http.StatusInternalServerError, e.TransformQueryError(e.log, err).Error())
+			return
+		}
+		body, err := json.Marshal(res)
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, "failed to encode response")
+			return
+		}
+		e.schemaCache.set(key, body)
+		writeResourceBody(rw, body)
+	}
+}
+
+// schemaRowLimit returns the row limit of a schema lookup, which is capped by maxSchemaRowLimit and the row limit of the data source.
+func schemaRowLimit(param string, rowLimit int64) (int, error) {
+	limit := defaultSchemaRowLimit
+	if param != "" {
+		var err error
+		if limit, err = strconv.Atoi(param); err != nil || limit <= 0 {
+			return 0, strconv.ErrSyntax
+		}
+	}
+	limit = min(limit, maxSchemaRowLimit)
+	if rowLimit > 0 && int64(limit) > rowLimit {
+		limit = int(rowLimit)
+	}
+	return limit, nil
+}
+
+func writeResourceBody(rw http.ResponseWriter, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(body)
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
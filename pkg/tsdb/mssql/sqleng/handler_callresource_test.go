@@ -0,0 +1,136 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	newHandler := func(t *testing.T) (*DataSourceHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		handler, err := NewQueryDataHandler("error", db, DataPluginConfiguration{RowLimit: 1000000}, &testQueryResultTransformer{}, nil, log.New())
+		require.NoError(t, err)
+		return handler, mock
+	}
+
+	callResource := func(t *testing.T, handler *DataSourceHandler, resourceURL string) *backend.CallResourceResponse {
+		t.Helper()
+		sender := &fakeSender{}
+		err := handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   strings.Split(resourceURL, "?")[0],
+			URL:    resourceURL,
+		}, sender)
+		require.NoError(t, err)
+		require.NotNil(t, sender.res)
+		return sender.res
+	}
+
+	t.Run("should list the tables of the default schema", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta(tablesQuery)).WithArgs("", defaultSchemaRowLimit).
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "table_type"}).
+				AddRow("metrics", "BASE TABLE").
+				AddRow("metrics_view", "VIEW"))
+
+		res := callResource(t, handler, "tables")
+
+		require.Equal(t, http.StatusOK, res.Status)
+		var tables []SchemaTable
+		require.NoError(t, json.Unmarshal(res.Body, &tables))
+		assert.Equal(t, []SchemaTable{{Name: "metrics", Type: "BASE TABLE"}, {Name: "metrics_view", Type: "VIEW"}}, tables)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return the columns of a table and cache them", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta(columnsQuery)).WithArgs("dbo", "metrics", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "is_nullable"}).
+				AddRow("time", "datetime2", "NO").
+				AddRow("value", "float", "YES"))
+
+		for i := 0; i < 2; i++ {
+			res := callResource(t, handler, "columns?schema=dbo&table=metrics&limit=10")
+
+			require.Equal(t, http.StatusOK, res.Status)
+			var columns []SchemaColumn
+			require.NoError(t, json.Unmarshal(res.Body, &columns))
+			assert.Equal(t, []SchemaColumn{{Name: "time", Type: "datetime2"}, {Name: "value", Type: "float", Nullable: true}}, columns)
+		}
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should group the columns of indexes", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta(indexesQuery)).WithArgs("", "metrics", defaultSchemaRowLimit).
+			WillReturnRows(sqlmock.NewRows([]string{"index_name", "column_name", "is_unique"}).
+				AddRow("PK_metrics", "id", true).
+				AddRow("time_host", "time", false).
+				AddRow("time_host", "host", false))
+
+		res := callResource(t, handler, "indexes?table=metrics")
+
+		require.Equal(t, http.StatusOK, res.Status)
+		var indexes []SchemaIndex
+		require.NoError(t, json.Unmarshal(res.Body, &indexes))
+		assert.Equal(t, []SchemaIndex{
+			{Name: "PK_metrics", Columns: []string{"id"}, Unique: true},
+			{Name: "time_host", Columns: []string{"time", "host"}},
+		}, indexes)
+	})
+
+	t.Run("should cap the row limit", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta(schemasQuery)).WithArgs(maxSchemaRowLimit).
+			WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("dbo"))
+
+		res := callResource(t, handler, "schemas?limit=1000000")
+
+		require.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["dbo"]`, string(res.Body))
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		handler, _ := newHandler(t)
+
+		assert.Equal(t, http.StatusBadRequest, callResource(t, handler, "columns").Status)
+		assert.Equal(t, http.StatusBadRequest, callResource(t, handler, "tables?limit=-1").Status)
+	})
+
+	t.Run("should not cache errors", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectQuery(regexp.QuoteMeta(schemasQuery)).WillReturnError(errors.New("access denied"))
+		mock.ExpectQuery(regexp.QuoteMeta(schemasQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("dbo"))
+
+		res := callResource(t, handler, "schemas")
+		assert.Equal(t, http.StatusInternalServerError, res.Status)
+		assert.JSONEq(t, `{"error": "access denied"}`, string(res.Body))
+
+		res = callResource(t, handler, "schemas")
+		assert.Equal(t, http.StatusOK, res.Status)
+	})
+}
@@ -82,6 +82,7 @@ func logCheckHealthError(ctx context.Context, dsInfo DataSourceInfo, err error)
 		"config_max_idle_conns":             dsInfo.JsonData.MaxIdleConns,
 		"config_conn_max_life_time":         dsInfo.JsonData.ConnMaxLifetime,
 		"config_conn_timeout":               dsInfo.JsonData.ConnectionTimeout,
+		"config_query_timeout":              dsInfo.JsonData.QueryTimeout,
 		"config_ssl_mode":                   dsInfo.JsonData.Mode,
 		"config_tls_configuration_method":   dsInfo.JsonData.ConfigurationMethod,
 		"config_tls_skip_verify":            dsInfo.JsonData.TlsSkipVerify,
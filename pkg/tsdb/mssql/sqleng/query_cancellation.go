@@ -0,0 +1,48 @@
+package sqleng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ErrQueryTimeout is returned when a query exceeds the query timeout of the data source
+	ErrQueryTimeout = errors.New("query timed out")
+	// ErrQueryCancelled is returned when a query is cancelled because the request was cancelled
+	ErrQueryCancelled = errors.New("query cancelled")
+)
+
+var cancelledQueriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana_plugin",
+	Name:      "mssql_cancelled_queries_total",
+	Help:      "Number of MSSQL queries that were cancelled because they timed out or the request was cancelled",
+}, []string{"reason"})
+
+// queryTimeout returns the query timeout of the data source. SQL Server has no statement timeout setting,
+// the driver sends an attention request that cancels the query on the server when the query context is done.
+func (e *DataSourceHandler) queryTimeout() time.Duration {
+	return time.Duration(e.dsInfo.JsonData.QueryTimeout) * time.Second
+}
+
+// queryCancellationError returns a downstream error if a query failed because it exceeded the query timeout
+// or because the request was cancelled, and nil otherwise.
+func (e *DataSourceHandler) queryCancellationError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		cancelledQueriesTotal.WithLabelValues("timeout").Inc()
+		if timeout := e.queryTimeout(); timeout > 0 {
+			return backend.DownstreamError(fmt.Errorf("%w: the query exceeded the timeout of %s", ErrQueryTimeout, timeout))
+		}
+		return backend.DownstreamError(ErrQueryTimeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		cancelledQueriesTotal.WithLabelValues("cancelled").Inc()
+		return backend.DownstreamError(ErrQueryCancelled)
+	}
+	return nil
+}
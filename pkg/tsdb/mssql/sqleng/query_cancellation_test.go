@@ -0,0 +1,73 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCancellation(t *testing.T) {
+	newHandler := func(t *testing.T, queryTimeout int) (*DataSourceHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		config := DataPluginConfiguration{
+			DSInfo:   DataSourceInfo{JsonData: JsonData{QueryTimeout: queryTimeout}},
+			RowLimit: 1000000,
+		}
+		handler, err := NewQueryDataHandler("error", db, config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New())
+		require.NoError(t, err)
+		return handler, mock
+	}
+
+	t.Run("should return a cancelled query as a downstream error", func(t *testing.T) {
+		handler, mock := newHandler(t, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillDelayFor(time.Minute).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		res, err := handler.QueryData(ctx, &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID: "A",
+				JSON:  json.RawMessage(`{"rawSql": "SELECT 1 AS value", "format": "table"}`),
+			}},
+		})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, res.Responses["A"].Error, ErrQueryCancelled)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+	})
+
+	t.Run("should return timeouts as timeout errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		err := handler.queryCancellationError(ctx, context.DeadlineExceeded)
+
+		require.ErrorIs(t, err, ErrQueryTimeout)
+		assert.True(t, backend.IsDownstreamError(err))
+		assert.Contains(t, err.Error(), "30s")
+	})
+
+	t.Run("should not change other errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+
+		assert.Nil(t, handler.queryCancellationError(context.Background(), assert.AnError))
+	})
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
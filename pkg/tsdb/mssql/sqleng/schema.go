@@ -0,0 +1,203 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// schemaCacheTTL is how long the results of schema lookups are cached, so that query editors
+	// do not query the information schema for every change
+	schemaCacheTTL = time.Minute
+	// defaultSchemaRowLimit is the number of rows returned by a schema lookup that does not set a limit
+	defaultSchemaRowLimit = 1000
+	// maxSchemaRowLimit is the maximum number of rows returned by a schema lookup
+	maxSchemaRowLimit = 10000
+)
+
+const (
+	schemasQuery = `SELECT TOP (@p1) name FROM sys.schemas
+WHERE name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest') AND name NOT LIKE 'db[_]%'
+ORDER BY name`
+	tablesQuery = `SELECT TOP (@p2) TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())
+ORDER BY TABLE_NAME`
+	columnsQuery = `SELECT TOP (@p3) COLUMN_NAME, DATA_TYPE, IS_NULLABLE FROM INFORMATION_SCHEMA.COLUMNS
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(@p1, ''), SCHEMA_NAME()) AND TABLE_NAME = @p2
+ORDER BY ORDINAL_POSITION`
+	indexesQuery = `SELECT TOP (@p3) i.name, c.name, i.is_unique FROM sys.indexes i
+JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+WHERE i.object_id = OBJECT_ID(QUOTENAME(COALESCE(NULLIF(@p1, ''), SCHEMA_NAME())) + '.' + QUOTENAME(@p2))
+AND i.name IS NOT NULL AND ic.is_included_column = 0
+ORDER BY i.name, ic.key_ordinal`
+)
+
+// SchemaTable is a table or view of a database schema
+type SchemaTable struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SchemaColumn is a column of a table
+type SchemaColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// SchemaIndex is an index of a table with its columns in index order
+type SchemaIndex struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+// Schemas returns the names of the schemas of the database, without the system schemas.
+func (e *DataSourceHandler) Schemas(ctx context.Context, limit int) ([]string, error) {
+	schemas := []string{}
+	err := e.querySchema(ctx, schemasQuery, func(rows *sql.Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		schemas = append(schemas, name)
+		return nil
+	}, limit)
+	return schemas, err
+}
+
+// Tables returns the tables and views of a schema. The default schema of the user is used if the schema is empty.
+func (e *DataSourceHandler) Tables(ctx context.Context, schema string, limit int) ([]SchemaTable, error) {
+	tables := []SchemaTable{}
+	err := e.querySchema(ctx, tablesQuery, func(rows *sql.Rows) error {
+		var table SchemaTable
+		if err := rows.Scan(&table.Name, &table.Type); err != nil {
+			return err
+		}
+		tables = append(tables, table)
+		return nil
+	}, schema, limit)
+	return tables, err
+}
+
+// Columns returns the columns of a table with their types in table order.
+func (e *DataSourceHandler) Columns(ctx context.Context, schema, table string, limit int) ([]SchemaColumn, error) {
+	columns := []SchemaColumn{}
+	err := e.querySchema(ctx, columnsQuery, func(rows *sql.Rows) error {
+		var column SchemaColumn
+		var nullable string
+		if err := rows.Scan(&column.Name, &column.Type, &nullable); err != nil {
+			return err
+		}
+		column.Nullable = strings.EqualFold(nullable, "YES")
+		columns = append(columns, column)
+		return nil
+	}, schema, table, limit)
+	return columns, err
+}
+
+// Indexes returns the indexes of a table. The limit applies to the number of indexed columns.
+func (e *DataSourceHandler) Indexes(ctx context.Context, schema, table string, limit int) ([]SchemaIndex, error) {
+	indexes := []SchemaIndex{}
+	byName := map[string]int{}
+	err := e.querySchema(ctx, indexesQuery, func(rows *sql.Rows) error {
+		var name, column string
+		var unique bool
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return err
+		}
+		i, ok := byName[name]
+		if !ok {
+			i = len(indexes)
+			byName[name] = i
+			indexes = append(indexes, SchemaIndex{Name: name, Unique: unique})
+		}
+		indexes[i].Columns = append(indexes[i].Columns, column)
+		return nil
+	}, schema, table, limit)
+	return indexes, err
+}
+
+func (e *DataSourceHandler) querySchema(ctx context.Context, query string, scan func(rows *sql.Rows) error, args ...any) error {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// schemaCache caches the responses of schema lookups by resource path and parameters
+type schemaCache struct {
+	mu      sync.Mutex
+	entries map[string]schemaCacheEntry
+}
+
+type schemaCacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{entries: map[string]schemaCacheEntry{}}
+}
+
+func schemaCacheKey(path string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(path)
+	for _, k := range keys {
+		b.WriteString("\x00" + k + "=" + params[k])
+	}
+	return b.String()
+}
+
+func (c *schemaCache) get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *schemaCache) set(key string, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = schemaCacheEntry{body: body, expires: now.Add(schemaCacheTTL)}
+}
@@ -42,6 +42,7 @@ type JsonData struct {
 	MaxIdleConns            int    `json:"maxIdleConns"`
 	ConnMaxLifetime         int    `json:"connMaxLifetime"`
 	ConnectionTimeout       int    `json:"connectionTimeout"`
+	QueryTimeout            int    `json:"queryTimeout"`
 	Timescaledb             bool   `json:"timescaledb"`
 	Mode                    string `json:"sslmode"`
 	ConfigurationMethod     string `json:"tlsConfigurationMethod"`
@@ -76,6 +77,7 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	ByteLimit         int64
 }
 
 type DataSourceHandler struct {
@@ -87,7 +89,9 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
 	userError              string
+	schemaCache            *schemaCache
 }
 
 type QueryJson struct {
@@ -122,7 +126,9 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		byteLimit:              config.ByteLimit,
 		userError:              userFacingDefaultError,
+		schemaCache:            newSchemaCache(),
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -250,9 +256,20 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	if timeout := e.queryTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, timeout)
+		defer cancel()
+	}
+
 	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
 	if err != nil {
-		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery, backend.ErrorSourceDownstream)
+		if cancelErr := e.queryCancellationError(queryContext, err); cancelErr != nil {
+			err = cancelErr
+		} else {
+			err = e.TransformQueryError(logger, err)
+		}
+		errAppendDebug("db query error", err, interpolatedQuery, backend.ErrorSourceDownstream)
 		return
 	}
 	defer func() {
@@ -269,8 +286,11 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := frameFromRows(rows, e.rowLimit, e.byteLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
+		if cancelErr := e.queryCancellationError(queryContext, err); cancelErr != nil {
+			err = cancelErr
+		}
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery, backend.ErrorSourcePlugin)
 		return
 	}
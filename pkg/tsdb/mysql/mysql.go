@@ -136,6 +136,7 @@ func NewInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          sqlCfg.RowLimit,
+			ByteLimit:         sqleng.ByteLimitFromConfig(cfg),
 		}
 
 		userFacingDefaultError, err := cfg.UserFacingDefaultError()
@@ -51,3 +51,12 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 	return dsHandler.QueryData(ctx, req)
 }
+
+// NOTE: do not put any business logic into this method. it's whole job is to forward the call "inside"
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
@@ -0,0 +1,216 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// killQueryTimeout is the maximum time of killing a query on the server
+const killQueryTimeout = 5 * time.Second
+
+// maxCachedConnectionIDs is the maximum number of connection ids that are cached. The pool closes connections
+// without notice, so the cache is cleared when it is full instead of growing with every new connection.
+const maxCachedConnectionIDs = 100
+
+// erQueryTimeout is the MySQL error number of a statement that exceeded max_execution_time
+const erQueryTimeout = 3024
+
+var (
+	// ErrQueryTimeout is returned when a query exceeds the query timeout of the data source
+	ErrQueryTimeout = errors.New("query timed out")
+	// ErrQueryCancelled is returned when a query is cancelled because the request was cancelled
+	ErrQueryCancelled = errors.New("query cancelled")
+)
+
+var cancelledQueriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana_plugin",
+	Name:      "mysql_cancelled_queries_total",
+	Help:      "Number of MySQL queries that were cancelled because they timed out or the request was cancelled",
+}, []string{"reason"})
+
+func (e *DataSourceHandler) queryTimeout() time.Duration {
+	return time.Duration(e.dsInfo.JsonData.QueryTimeout) * time.Second
+}
+
+// queryWithCancellation runs a query on a dedicated connection. The driver only closes the connection when the
+// context is done, so the query is killed on the server to not leave it running, for example when a dashboard is
+// closed. If the data source has a query timeout, it is set as max_execution_time of the session, which MySQL
+// enforces for SELECT statements, and reset before the connection is released.
+// The returned release function must be called after the rows are closed.
+func (e *DataSourceHandler) queryWithCancellation(ctx context.Context, query string, logger log.Logger) (*sql.Rows, func(), error) {
+	timeout := e.queryTimeout()
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeConn := func() {
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to close connection", "err", err)
+		}
+	}
+
+	connectionID, err := e.connectionID(ctx, conn)
+	if err != nil {
+		closeConn()
+		return nil, nil, err
+	}
+	if timeout > 0 {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())); err != nil {
+			closeConn()
+			return nil, nil, err
+		}
+		closeConn = func() {
+			resetSession(conn, logger)
+		}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			// the driver closes the connection of a cancelled query
+			e.connectionIDs.delete(connectionID.conn)
+			e.killQuery(connectionID.id, logger)
+		case <-done:
+		}
+	}()
+	release := func() {
+		close(done)
+		// The connection must not be returned to the pool before a kill of its query finished
+		wg.Wait()
+		closeConn()
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// connectionID returns the id of a connection on the server. It is looked up once per connection, the query
+// cannot run anymore once the connection has to be killed.
+func (e *DataSourceHandler) connectionID(ctx context.Context, conn *sql.Conn) (cachedConnectionID, error) {
+	var driverConn any
+	if err := conn.Raw(func(dc any) error {
+		driverConn = dc
+		return nil
+	}); err != nil {
+		return cachedConnectionID{}, err
+	}
+	if id, ok := e.connectionIDs.get(driverConn); ok {
+		return cachedConnectionID{conn: driverConn, id: id}, nil
+	}
+
+	var id int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id); err != nil {
+		return cachedConnectionID{}, err
+	}
+	e.connectionIDs.set(driverConn, id)
+	return cachedConnectionID{conn: driverConn, id: id}, nil
+}
+
+type cachedConnectionID struct {
+	conn any
+	id   int64
+}
+
+// connectionIDCache caches the ids of the connections of the pool by their driver connection
+type connectionIDCache struct {
+	mu  sync.Mutex
+	ids map[any]int64
+}
+
+func newConnectionIDCache() *connectionIDCache {
+	return &connectionIDCache{ids: map[any]int64{}}
+}
+
+func (c *connectionIDCache) get(conn any) (int64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.ids[conn]
+	return id, ok
+}
+
+func (c *connectionIDCache) set(conn any, id int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.ids) >= maxCachedConnectionIDs {
+		clear(c.ids)
+	}
+	c.ids[conn] = id
+}
+
+func (c *connectionIDCache) delete(conn any) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.ids, conn)
+}
+
+// resetSession resets the max_execution_time of a connection and closes it. The connection is discarded instead of
+// being returned to the pool when the reset fails, so the timeout does not apply to the next queries.
+func resetSession(conn *sql.Conn, logger log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+	defer cancel()
+	if _, err := conn.ExecContext(ctx, "SET SESSION max_execution_time = DEFAULT"); err != nil {
+		logger.Debug("Failed to reset max_execution_time, discarding the connection", "err", err)
+		// returning ErrBadConn closes the connection instead of returning it to the pool
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return
+	}
+	if err := conn.Close(); err != nil {
+		logger.Warn("Failed to close connection", "err", err)
+	}
+}
+
+func (e *DataSourceHandler) killQuery(connectionID int64, logger log.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+	defer cancel()
+	if _, err := e.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connectionID)); err != nil {
+		logger.Warn("Failed to kill query", "connectionId", connectionID, "err", err)
+		return
+	}
+	logger.Debug("Killed query", "connectionId", connectionID)
+}
+
+// queryCancellationError returns a downstream error if a query failed because it exceeded the query timeout
+// or because the request was cancelled, and nil otherwise.
+func (e *DataSourceHandler) queryCancellationError(ctx context.Context, err error) error {
+	var driverErr *mysql.MySQLError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.As(err, &driverErr) && driverErr.Number == erQueryTimeout:
+		cancelledQueriesTotal.WithLabelValues("timeout").Inc()
+		if timeout := e.queryTimeout(); timeout > 0 {
+			return backend.DownstreamError(fmt.Errorf("%w: the query exceeded the timeout of %s", ErrQueryTimeout, timeout))
+		}
+		return backend.DownstreamError(ErrQueryTimeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		cancelledQueriesTotal.WithLabelValues("cancelled").Inc()
+		return backend.DownstreamError(ErrQueryCancelled)
+	}
+	return nil
+}
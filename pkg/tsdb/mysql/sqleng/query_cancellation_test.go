@@ -0,0 +1,157 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCancellation(t *testing.T) {
+	newHandler := func(t *testing.T, queryTimeout int) (*DataSourceHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		mock.MatchExpectationsInOrder(false)
+		config := DataPluginConfiguration{
+			DSInfo:   DataSourceInfo{JsonData: JsonData{QueryTimeout: queryTimeout}},
+			RowLimit: 1000000,
+		}
+		handler, err := NewQueryDataHandler("error", db, config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New())
+		require.NoError(t, err)
+		return handler, mock
+	}
+
+	query := func(t *testing.T, ctx context.Context, handler *DataSourceHandler) backend.DataResponse {
+		t.Helper()
+		res, err := handler.QueryData(ctx, &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID: "A",
+				JSON:  json.RawMessage(`{"rawSql": "SELECT 1 AS value", "format": "table"}`),
+			}},
+		})
+		require.NoError(t, err)
+		return res.Responses["A"]
+	}
+
+	t.Run("should set the query timeout of the session", func(t *testing.T) {
+		handler, mock := newHandler(t, 30)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT CONNECTION_ID()")).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectExec(regexp.QuoteMeta("SET SESSION max_execution_time = 30000")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("SET SESSION max_execution_time = DEFAULT")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		res := query(t, context.Background(), handler)
+
+		require.NoError(t, res.Error)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should discard the connection when the query timeout cannot be reset", func(t *testing.T) {
+		handler, mock := newHandler(t, 30)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT CONNECTION_ID()")).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectExec(regexp.QuoteMeta("SET SESSION max_execution_time = 30000")).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("SET SESSION max_execution_time = DEFAULT")).WillReturnError(assert.AnError)
+
+		res := query(t, context.Background(), handler)
+		require.NoError(t, res.Error)
+
+		// the discarded connection is closed, the pool is empty
+		require.Equal(t, 0, handler.db.Stats().OpenConnections)
+	})
+
+	t.Run("should not set a session timeout without a query timeout", func(t *testing.T) {
+		handler, mock := newHandler(t, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT CONNECTION_ID()")).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+
+		res := query(t, context.Background(), handler)
+
+		require.NoError(t, res.Error)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should look up the id of a connection once", func(t *testing.T) {
+		handler, mock := newHandler(t, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT CONNECTION_ID()")).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+
+		require.NoError(t, query(t, context.Background(), handler).Error)
+		require.NoError(t, query(t, context.Background(), handler).Error)
+
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should kill the query on the server when a request without a deadline is cancelled", func(t *testing.T) {
+		handler, mock := newHandler(t, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT CONNECTION_ID()")).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillDelayFor(time.Minute).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+		mock.ExpectExec(regexp.QuoteMeta("KILL QUERY 42")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		res := query(t, ctx, handler)
+
+		require.ErrorIs(t, res.Error, ErrQueryCancelled)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should kill the query on the server when the request is cancelled", func(t *testing.T) {
+		handler, mock := newHandler(t, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT CONNECTION_ID()")).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 AS value")).WillDelayFor(time.Minute).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+		mock.ExpectExec(regexp.QuoteMeta("KILL QUERY 42")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		time.AfterFunc(50*time.Millisecond, cancel)
+		res := query(t, ctx, handler)
+
+		require.ErrorIs(t, res.Error, ErrQueryCancelled)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return server-side timeouts as timeout errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+
+		err := handler.queryCancellationError(context.Background(), &mysql.MySQLError{Number: erQueryTimeout, Message: "Query execution was interrupted"})
+
+		require.ErrorIs(t, err, ErrQueryTimeout)
+		assert.True(t, backend.IsDownstreamError(err))
+		assert.Contains(t, err.Error(), "30s")
+	})
+
+	t.Run("should return client-side timeouts as timeout errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		err := handler.queryCancellationError(ctx, context.DeadlineExceeded)
+
+		require.ErrorIs(t, err, ErrQueryTimeout)
+		assert.True(t, backend.IsDownstreamError(err))
+	})
+
+	t.Run("should not change other errors", func(t *testing.T) {
+		handler, _ := newHandler(t, 30)
+
+		assert.Nil(t, handler.queryCancellationError(context.Background(), assert.AnError))
+	})
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
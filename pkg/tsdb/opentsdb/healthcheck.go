@@ -0,0 +1,75 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Health check failed: Failed to get data source info",
+		}, nil
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Health check failed: Failed to parse data source URL",
+		}, nil
+	}
+	u.Path = path.Join(u.Path, "api/suggest")
+	u.RawQuery = url.Values{
+		"type": []string{"metrics"},
+		"q":    []string{"cpu"},
+		"max":  []string{"1"},
+	}.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Health check failed: Failed to create request: %s", err),
+		}, nil
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		logger.Error("Failed to connect to OpenTSDB", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Health check failed: Failed to connect to OpenTSDB",
+		}, nil
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		logger.Info("Health check request failed", "status", res.Status, "body", string(body))
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Health check failed: suggest endpoint request failed, status: %s", res.Status),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source is working",
+	}, nil
+}
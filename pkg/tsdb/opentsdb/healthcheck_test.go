@@ -0,0 +1,55 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestCheckHealth(t *testing.T) {
+	setup := func(t *testing.T, status int) (*Service, *backend.CheckHealthRequest) {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/suggest", r.URL.Path)
+			assert.Equal(t, "metrics", r.URL.Query().Get("type"))
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`["cpu.average.percent"]`))
+		}))
+		t.Cleanup(server.Close)
+		return ProvideService(httpclient.NewProvider(), tracing.NewNoopTracerService()), &backend.CheckHealthRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					URL:      server.URL,
+					JSONData: []byte(`{}`),
+				},
+			},
+		}
+	}
+
+	t.Run("should succeed if the suggest endpoint works", func(t *testing.T) {
+		service, req := setup(t, http.StatusOK)
+
+		res, err := service.CheckHealth(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("should fail if the suggest endpoint fails", func(t *testing.T) {
+		service, req := setup(t, http.StatusInternalServerError)
+
+		res, err := service.CheckHealth(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, "suggest endpoint request failed")
+	})
+}
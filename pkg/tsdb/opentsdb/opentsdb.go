@@ -11,28 +11,39 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 var logger = log.New("tsdb.opentsdb")
 
+// maxConcurrentQueries is the maximum number of requests to OpenTSDB that are sent at the same time for a query.
+const maxConcurrentQueries = 10
+
 type Service struct {
-	im instancemgmt.InstanceManager
+	im     instancemgmt.InstanceManager
+	tracer tracing.Tracer
 }
 
-func ProvideService(httpClientProvider httpclient.Provider) *Service {
+func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
 	return &Service{
-		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		tracer: tracer,
 	}
 }
 
@@ -83,20 +94,90 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	refID := q.RefID
+	ctx, span := s.tracer.Start(ctx, "datasource.opentsdb.queryData", trace.WithAttributes(
+		attribute.Int("queries", len(req.Queries)),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	))
+	defer span.End()
+
+	batches := batchQueries(req.Queries, dsInfo.TSDBVersion)
+	result := backend.NewQueryDataResponse()
+	resultLock := sync.Mutex{}
+	err = concurrency.ForEachJob(ctx, len(batches), maxConcurrentQueries, func(ctx context.Context, idx int) error {
+		responses := s.executeQueries(ctx, logger, dsInfo, batches[idx])
+
+		resultLock.Lock()
+		defer resultLock.Unlock()
+		for refID, res := range responses {
+			result.Responses[refID] = res
+		}
+		return nil // errors are saved per-query, always return nil
+	})
+	return result, err
+}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+// batchQueries groups the queries that can be sent to OpenTSDB in a single request, which are the queries with the
+// same time range. The series of a request are only matched to their sub query from OpenTSDB 2.3, so each query is
+// sent in its own request with older versions.
+func batchQueries(queries []backend.DataQuery, tsdbVersion float32) [][]backend.DataQuery {
+	if tsdbVersion < 3 {
+		batches := make([][]backend.DataQuery, 0, len(queries))
+		for _, query := range queries {
+			batches = append(batches, []backend.DataQuery{query})
+		}
+		return batches
+	}
+
+	var batches [][]backend.DataQuery
+	byTimeRange := make(map[backend.TimeRange]int)
+	for _, query := range queries {
+		idx, ok := byTimeRange[query.TimeRange]
+		if !ok {
+			idx = len(batches)
+			byTimeRange[query.TimeRange] = idx
+			batches = append(batches, nil)
+		}
+		batches[idx] = append(batches[idx], query)
+	}
+	return batches
+}
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+// executeQueries sends queries with the same time range to OpenTSDB in a single request and converts the result to
+// a data response per query.
+func (s *Service) executeQueries(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, queries []backend.DataQuery) backend.Responses {
+	timeRange := queries[0].TimeRange
+	tsdbQuery := OpenTsdbQuery{
+		Start:     timeRange.From.UnixNano() / int64(time.Millisecond),
+		End:       timeRange.To.UnixNano() / int64(time.Millisecond),
+		Queries:   make([]map[string]any, 0, len(queries)),
+		ShowQuery: len(queries) > 1,
+	}
+	refIDs := make([]string, 0, len(queries))
+	for _, query := range queries {
+		tsdbQuery.Queries = append(tsdbQuery.Queries, s.buildMetric(query, dsInfo.TSDBVersion))
+		refIDs = append(refIDs, query.RefID)
+	}
+
+	ctx, span := s.tracer.Start(ctx, "opentsdb query", trace.WithAttributes(
+		attribute.StringSlice("refIds", refIDs),
+		attribute.Int64("from", tsdbQuery.Start),
+		attribute.Int64("until", tsdbQuery.End),
+	))
+	defer span.End()
+
+	errorResponses := func(res backend.DataResponse) backend.Responses {
+		responses := make(backend.Responses, len(refIDs))
+		for _, refID := range refIDs {
+			responses[refID] = res
+		}
+		return responses
 	}
 
 	// TODO: Don't use global variable
@@ -104,34 +185,38 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return errorResponses(backend.ErrorResponseWithErrorSource(err))
 	}
+	s.tracer.Inject(ctx, request.Header, span)
 
 	res, err := dsInfo.HTTPClient.Do(request)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+	if res != nil {
+		span.SetAttributes(attribute.Int("opentsdb.response.code", res.StatusCode))
 	}
-
-	defer func() {
-		err := res.Body.Close()
-		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("OpenTSDB request failed", "refIds", refIDs, "error", err)
+		if backend.IsDownstreamHTTPError(err) {
+			err = backend.DownstreamError(err)
 		}
-	}()
+		return errorResponses(backend.ErrorResponseWithErrorSource(err))
+	}
 
-	result, err := s.parseResponse(logger, res, refID, dsInfo.TSDBVersion)
+	result, err := s.parseResponse(logger, res, refIDs, dsInfo.TSDBVersion)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		errRes := backend.ErrorResponseWithErrorSource(err)
+		errRes.Status = backend.Status(res.StatusCode)
+		return errorResponses(errRes)
 	}
 
-	return result, nil
+	return result.Responses
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -222,7 +307,19 @@ func parseResponseLT24(responseData []OpenTsdbResponse, refID string, frames dat
 	return frames, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, refID string, tsdbVersion float32) (*backend.QueryDataResponse, error) {
+// refIDOf returns the refID of the query of a series. All the series of a request with a single query belong to it,
+// otherwise OpenTSDB returns the index of their sub query when showQuery is set.
+func refIDOf(val OpenTsdbCommon, refIDs []string) (string, error) {
+	if len(refIDs) == 1 {
+		return refIDs[0], nil
+	}
+	if val.Query == nil || val.Query.Index < 0 || val.Query.Index >= len(refIDs) {
+		return "", fmt.Errorf("the response does not identify the query of the series %s", val.Metric)
+	}
+	return refIDs[val.Query.Index], nil
+}
+
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, refIDs []string, tsdbVersion float32) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -237,10 +334,13 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, refID str
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		return nil, backend.DownstreamErrorf("request failed, status: %s", res.Status)
 	}
 
-	frames := data.Frames{}
+	frames := make(map[string]data.Frames, len(refIDs))
+	for _, refID := range refIDs {
+		frames[refID] = data.Frames{}
+	}
 
 	var responseData []OpenTsdbResponse
 	var responseData24 []OpenTsdbResponse24
@@ -248,30 +348,44 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, refID str
 		err = json.Unmarshal(body, &responseData24)
 		if err != nil {
 			logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
-			return nil, err
+			return nil, backend.DownstreamError(err)
 		}
 
-		frames = parseResponse24(responseData24, refID, frames)
+		for _, val := range responseData24 {
+			refID, err := refIDOf(val.OpenTsdbCommon, refIDs)
+			if err != nil {
+				return nil, backend.DownstreamError(err)
+			}
+			frames[refID] = parseResponse24([]OpenTsdbResponse24{val}, refID, frames[refID])
+		}
 	} else {
 		err = json.Unmarshal(body, &responseData)
 		if err != nil {
 			logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
-			return nil, err
+			return nil, backend.DownstreamError(err)
 		}
 
-		frames, err = parseResponseLT24(responseData, refID, frames)
-		if err != nil {
-			return nil, err
+		for _, val := range responseData {
+			refID, err := refIDOf(val.OpenTsdbCommon, refIDs)
+			if err != nil {
+				return nil, backend.DownstreamError(err)
+			}
+			frames[refID], err = parseResponseLT24([]OpenTsdbResponse{val}, refID, frames[refID])
+			if err != nil {
+				return nil, backend.DownstreamError(err)
+			}
 		}
 	}
 
-	result := resp.Responses[refID]
-	result.Frames = frames
-	resp.Responses[refID] = result
+	for refID, f := range frames {
+		result := resp.Responses[refID]
+		result.Frames = f
+		resp.Responses[refID] = result
+	}
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
+func (s *Service) buildMetric(query backend.DataQuery, tsdbVersion float32) map[string]any {
 	metric := make(map[string]any)
 
 	model, err := simplejson.NewJson(query.JSON)
@@ -281,18 +395,18 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 
 	// Setting metric and aggregator
 	metric["metric"] = model.Get("metric").MustString()
-	metric["aggregator"] = model.Get("aggregator").MustString()
+	metric["aggregator"] = model.Get("aggregator").MustString("avg")
 
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
 	if !disableDownsampling {
 		downsampleInterval := model.Get("downsampleInterval").MustString()
 		if downsampleInterval == "" {
-			downsampleInterval = "1m" // default value for blank
+			downsampleInterval = formatDownsampleInterval(query.Interval)
 		}
-		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
+		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString("avg")
+		if fillPolicy := model.Get("downsampleFillPolicy").MustString(); fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -304,38 +418,93 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 		rateOptions := make(map[string]any)
 		rateOptions["counter"] = model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
+		counterMax, counterMaxCheck := numberOption(model, "counterMax")
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := numberOption(model, "counterResetValue")
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		// dropResets is supported from OpenTSDB 2.2
+		if tsdbVersion >= 2 && !counterMaxCheck && (!resetValueCheck || resetValue == 0) {
 			rateOptions["dropResets"] = true
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
+	// Setting filters, which are supported from OpenTSDB 2.2 and replace the tags of the query
+	filters := buildFilters(model)
+	if tsdbVersion >= 2 && len(filters) > 0 {
+		metric["filters"] = filters
+	} else {
+		tags, tagsCheck := model.CheckGet("tags")
+		if tagsCheck && len(tags.MustMap()) > 0 {
+			metric["tags"] = tags.MustMap()
+		}
 	}
 
-	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
 }
 
+// buildFilters returns the complete filters of the query model.
+func buildFilters(model *simplejson.Json) []OpenTsdbFilter {
+	var filters []OpenTsdbFilter
+	for i := range model.Get("filters").MustArray() {
+		f := model.Get("filters").GetIndex(i)
+		filter := OpenTsdbFilter{
+			Type:    f.Get("type").MustString(),
+			Tagk:    f.Get("tagk").MustString(),
+			Filter:  f.Get("filter").MustString(),
+			GroupBy: f.Get("groupBy").MustBool(),
+		}
+		if filter.Type == "" || filter.Tagk == "" {
+			continue
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
+
+// numberOption returns the value of a numeric option of the query model, which can be stored as a number or a string.
+func numberOption(model *simplejson.Json, key string) (float64, bool) {
+	value, ok := model.CheckGet(key)
+	if !ok {
+		return 0, false
+	}
+	if f, err := value.Float64(); err == nil {
+		return f, true
+	}
+	str := strings.TrimSpace(value.MustString())
+	if str == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// formatDownsampleInterval formats the interval of the query as an OpenTSDB duration, defaulting to one minute.
+func formatDownsampleInterval(interval time.Duration) string {
+	switch {
+	case interval <= 0:
+		return "1m"
+	case interval%time.Second != 0:
+		return strconv.FormatInt(interval.Milliseconds(), 10) + "ms"
+	default:
+		return strconv.FormatInt(int64(interval/time.Second), 10) + "s"
+	}
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
@@ -2,9 +2,14 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +18,9 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestOpenTsdbExecutor(t *testing.T) {
@@ -34,7 +42,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		response := `{ invalid }`
 
 		tsdbVersion := float32(4)
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A", tsdbVersion)
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, []string{"A"}, tsdbVersion)
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -70,7 +78,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A", tsdbVersion)
+		result, err := service.parseResponse(logger, &resp, []string{"A"}, tsdbVersion)
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -111,7 +119,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A", tsdbVersion)
+		result, err := service.parseResponse(logger, &resp, []string{"A"}, tsdbVersion)
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -183,7 +191,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A", tsdbVersion)
+		result, err := service.parseResponse(logger, &resp, []string{"A"}, tsdbVersion)
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -227,7 +235,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, myRefid, tsdbVersion)
+		result, err := service.parseResponse(logger, &resp, []string{myRefid}, tsdbVersion)
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(testFrame, result.Responses[myRefid].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
@@ -249,7 +257,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 4)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -271,7 +279,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 4)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -292,7 +300,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 4)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -318,7 +326,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 4)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -349,7 +357,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 4)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -384,7 +392,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, 4)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -403,4 +411,191 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric with counter options as strings", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true,
+						"counterMax": "45",
+						"counterResetValue": ""
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, 4)
+
+		metricRateOptions := metric["rateOptions"].(map[string]any)
+		require.Len(t, metricRateOptions, 2)
+		require.True(t, metricRateOptions["counter"].(bool))
+		require.Equal(t, float64(45), metricRateOptions["counterMax"])
+	})
+
+	t.Run("Build metric with rate drops resets only from version 2.2", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true
+					}`,
+			),
+		}
+
+		require.Equal(t, true, service.buildMetric(query, 2)["rateOptions"].(map[string]any)["dropResets"])
+		require.NotContains(t, service.buildMetric(query, 1)["rateOptions"], "dropResets")
+	})
+
+	t.Run("Build metric with downsampling using the query interval and a fill policy", func(t *testing.T) {
+		query := backend.DataQuery{
+			Interval: 1500 * time.Millisecond,
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"downsampleAggregator": "max",
+						"downsampleFillPolicy": "zero"
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, 4)
+
+		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, "1500ms-max-zero", metric["downsample"])
+	})
+
+	t.Run("Build metric with filters, group by and explicit tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "sum",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"filters": [
+							{"type": "literal_or", "tagk": "env", "filter": "prod|dev", "groupBy": true},
+							{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": false},
+							{"type": "", "tagk": "", "filter": ""}
+						],
+						"tags": {
+							"app": "grafana"
+						}
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, 3)
+
+		require.Len(t, metric, 4)
+		require.Nil(t, metric["tags"])
+		require.True(t, metric["explicitTags"].(bool))
+		require.Equal(t, []OpenTsdbFilter{
+			{Type: "literal_or", Tagk: "env", Filter: "prod|dev", GroupBy: true},
+			{Type: "wildcard", Tagk: "host", Filter: "web*", GroupBy: false},
+		}, metric["filters"])
+
+		// Filters are not supported before OpenTSDB 2.2, so the tags are used.
+		metric = service.buildMetric(query, 1)
+		require.Nil(t, metric["filters"])
+		require.Equal(t, map[string]any{"app": "grafana"}, metric["tags"])
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	// the queries of this time range fail
+	failingRange := backend.TimeRange{From: time.Unix(3000, 0), To: time.Unix(4000, 0)}
+	var mtx sync.Mutex
+	var requests []OpenTsdbQuery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query OpenTsdbQuery
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		mtx.Lock()
+		requests = append(requests, query)
+		mtx.Unlock()
+		if query.Start == failingRange.From.UnixMilli() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		series := make([]string, 0, len(query.Queries))
+		for i, q := range query.Queries {
+			dps := `{"1405544146": 50.0}`
+			if r.URL.Query().Get("arrays") == "true" {
+				dps = `[[1405544146, 50.0]]`
+			}
+			sub := ""
+			if query.ShowQuery {
+				sub = fmt.Sprintf(`, "query": {"index": %d}`, i)
+			}
+			series = append(series, fmt.Sprintf(`{"metric": %q, "dps": %s, "tags": {}%s}`, q["metric"], dps, sub))
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(series, ",") + "]"))
+	}))
+	t.Cleanup(server.Close)
+
+	timeRange := backend.TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)}
+	queries := []backend.DataQuery{
+		{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "metric.a", "disableDownsampling": true}`)},
+		{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "metric.b", "disableDownsampling": true}`)},
+		{RefID: "C", TimeRange: failingRange, JSON: []byte(`{"metric": "metric.c", "disableDownsampling": true}`)},
+		{RefID: "D", TimeRange: timeRange, JSON: []byte(`{"metric": "metric.d", "disableDownsampling": true}`)},
+	}
+
+	testCases := []struct {
+		name        string
+		tsdbVersion int
+		subQueries  []int
+	}{
+		{name: "queries with the same time range are sent in one request", tsdbVersion: 4, subQueries: []int{1, 3}},
+		{name: "each query is sent in its own request before OpenTSDB 2.3", tsdbVersion: 2, subQueries: []int{1, 1, 1, 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = nil
+			service := ProvideService(httpclient.NewProvider(), tracing.InitializeTracerForTest())
+			rsp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+				PluginContext: backend.PluginContext{
+					DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+						URL:      server.URL,
+						JSONData: []byte(fmt.Sprintf(`{"tsdbVersion": %d}`, tc.tsdbVersion)),
+					},
+				},
+				Queries: queries,
+			})
+			require.NoError(t, err)
+
+			subQueries := make([]int, 0, len(requests))
+			for _, r := range requests {
+				subQueries = append(subQueries, len(r.Queries))
+			}
+			sort.Ints(subQueries)
+			require.Equal(t, tc.subQueries, subQueries)
+
+			require.Len(t, rsp.Responses, len(queries))
+			for _, refID := range []string{"A", "B", "D"} {
+				res := rsp.Responses[refID]
+				require.NoError(t, res.Error)
+				require.Len(t, res.Frames, 1)
+				assert.Equal(t, refID, res.Frames[0].RefID)
+				assert.Equal(t, "metric."+strings.ToLower(refID), res.Frames[0].Name)
+			}
+			res := rsp.Responses["C"]
+			require.Error(t, res.Error)
+			assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+			assert.Equal(t, backend.StatusBadGateway, res.Status)
+		})
+	}
+
+	t.Run("should fail if a series does not identify its query", func(t *testing.T) {
+		resp := http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(strings.NewReader(`[{"metric": "metric.a", "dps": [[1405544146, 50.0]], "tags": {}}]`)),
+		}
+		_, err := ProvideService(httpclient.NewProvider(), tracing.InitializeTracerForTest()).parseResponse(logger, &resp, []string{"A", "B"}, 4)
+		require.Error(t, err)
+	})
 }
@@ -0,0 +1,128 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// resourcePaths are the OpenTSDB API endpoints that can be called through CallResource.
+var resourcePaths = map[string]struct{}{
+	"api/suggest":        {},
+	"api/search/lookup":  {},
+	"api/aggregators":    {},
+	"api/config/filters": {},
+}
+
+// resourceLimitParams are the query parameters that limit the number of results of a resource request.
+// They default to the lookup limit of the data source if the request does not set them.
+var resourceLimitParams = map[string]string{
+	"api/suggest":       "max",
+	"api/search/lookup": "limit",
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	if _, ok := resourcePaths[req.Path]; !ok {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if req.Method != http.MethodGet {
+		logger.Error("Invalid resource method", "path", req.Path, "method", req.Method)
+		return fmt.Errorf("invalid resource method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	tsdbURL, err := createResourceURL(req, dsInfo)
+	if err != nil {
+		logger.Error("Failed to create request url", "error", err, "url", dsInfo.URL, "path", req.Path)
+		return err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "opentsdb resource")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("path", req.Path),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, tsdbURL, nil)
+	if err != nil {
+		logger.Error("Failed to create request", "error", err, "url", tsdbURL)
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	s.tracer.Inject(ctx, request.Header, span)
+
+	logger.Debug("Sending request to OpenTSDB", "resourcePath", req.Path)
+	start := time.Now()
+	response, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Error received from OpenTSDB", "error", err, "duration", time.Since(start), "resourcePath", req.Path)
+		return err
+	}
+	span.SetAttributes(attribute.Int("opentsdb.response.code", response.StatusCode))
+	logger.Debug("Response received from OpenTSDB", "statusCode", response.StatusCode, "duration", time.Since(start), "resourcePath", req.Path)
+
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		logger.Error("Error reading response body bytes", "error", err)
+		return err
+	}
+
+	responseHeaders := map[string][]string{
+		"content-type": {"application/json"},
+	}
+	if ct := response.Header.Get("Content-Type"); ct != "" {
+		responseHeaders["content-type"] = []string{ct}
+	}
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  response.StatusCode,
+		Headers: responseHeaders,
+		Body:    body,
+	})
+}
+
+// createResourceURL returns the OpenTSDB URL of the resource request, keeping its query parameters.
+func createResourceURL(req *backend.CallResourceRequest, dsInfo *datasourceInfo) (string, error) {
+	tsdbURL, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse data source URL: %s, error: %w", dsInfo.URL, err)
+	}
+	tsdbURL.Path = path.Join(tsdbURL.Path, req.Path)
+
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse resource URL: %s, error: %w", req.URL, err)
+	}
+	params := reqURL.Query()
+	if param, ok := resourceLimitParams[req.Path]; ok && params.Get(param) == "" && dsInfo.LookupLimit > 0 {
+		params.Set(param, strconv.Itoa(int(dsInfo.LookupLimit)))
+	}
+	tsdbURL.RawQuery = params.Encode()
+	return tsdbURL.String(), nil
+}
@@ -0,0 +1,103 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestCallResource(t *testing.T) {
+	var lastRequest *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["avg", "sum"]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := ProvideService(httpclient.NewProvider(), tracing.NewNoopTracerService())
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			URL:      server.URL + "/tsdb",
+			JSONData: []byte(`{"lookupLimit": 50}`),
+		},
+	}
+
+	call := func(t *testing.T, req *backend.CallResourceRequest) (*backend.CallResourceResponse, error) {
+		t.Helper()
+		req.PluginContext = pluginCtx
+		var res *backend.CallResourceResponse
+		err := service.CallResource(context.Background(), req, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+			res = r
+			return nil
+		}))
+		return res, err
+	}
+
+	t.Run("should forward requests to the aggregators endpoint", func(t *testing.T) {
+		res, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/aggregators",
+			URL:    "api/aggregators",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["avg", "sum"]`, string(res.Body))
+		assert.Equal(t, "/tsdb/api/aggregators", lastRequest.URL.Path)
+	})
+
+	t.Run("should apply the lookup limit if the request has no limit", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/suggest",
+			URL:    "api/suggest?type=tagk&q=ho",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "/tsdb/api/suggest", lastRequest.URL.Path)
+		assert.Equal(t, "tagk", lastRequest.URL.Query().Get("type"))
+		assert.Equal(t, "ho", lastRequest.URL.Query().Get("q"))
+		assert.Equal(t, "50", lastRequest.URL.Query().Get("max"))
+	})
+
+	t.Run("should keep the limit of the request", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/search/lookup",
+			URL:    "api/search/lookup?m=cpu%7Bhost%3D%2A%7D&limit=1000",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "cpu{host=*}", lastRequest.URL.Query().Get("m"))
+		assert.Equal(t, "1000", lastRequest.URL.Query().Get("limit"))
+	})
+
+	t.Run("should reject unknown paths", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/query",
+			URL:    "api/query",
+		})
+
+		require.Error(t, err)
+	})
+
+	t.Run("should reject unsupported methods", func(t *testing.T) {
+		_, err := call(t, &backend.CallResourceRequest{
+			Method: http.MethodPost,
+			Path:   "api/suggest",
+			URL:    "api/suggest",
+		})
+
+		require.Error(t, err)
+	})
+}
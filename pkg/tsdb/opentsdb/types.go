@@ -1,14 +1,21 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start     int64            `json:"start"`
+	End       int64            `json:"end"`
+	Queries   []map[string]any `json:"queries"`
+	ShowQuery bool             `json:"showQuery,omitempty"`
 }
 
 type OpenTsdbCommon struct {
 	Metric string            `json:"metric"`
 	Tags   map[string]string `json:"tags"`
+	// Query is the sub query of the series, only returned with showQuery
+	Query *OpenTsdbSubQuery `json:"query,omitempty"`
+}
+
+type OpenTsdbSubQuery struct {
+	Index int `json:"index"`
 }
 
 type OpenTsdbResponse struct {
@@ -20,3 +27,10 @@ type OpenTsdbResponse24 struct {
 	OpenTsdbCommon
 	DataPoints [][]float64 `json:"dps"`
 }
+
+type OpenTsdbFilter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
@@ -1,9 +1,8 @@
 import { Suspense, lazy } from 'react';
 
-import { config } from '@grafana/runtime';
 import { RulerGrafanaRuleDTO } from 'app/types/unified-alerting-dto';
 
-import { StateHistoryImplementation } from '../../../hooks/useStateHistoryModal';
+import { StateHistoryImplementation, getStateHistoryImplementation } from '../../../hooks/useStateHistoryModal';
 
 const AnnotationsStateHistory = lazy(() => import('../../../components/rules/state-history/StateHistory'));
 const LokiStateHistory = lazy(() => import('../../../components/rules/state-history/LokiStateHistory'));
@@ -13,18 +12,7 @@ interface HistoryProps {
 }
 
 const History = ({ rule }: HistoryProps) => {
-  // can be "loki", "multiple" or "annotations"
-  const stateHistoryBackend = config.unifiedAlerting.alertStateHistoryBackend;
-  // can be "loki" or "annotations"
-  const stateHistoryPrimary = config.unifiedAlerting.alertStateHistoryPrimary;
-
-  // if "loki" is either the backend or the primary, show the new state history implementation
-  const usingNewAlertStateHistory = [stateHistoryBackend, stateHistoryPrimary].some(
-    (implementation) => implementation === StateHistoryImplementation.Loki
-  );
-  const implementation = usingNewAlertStateHistory
-    ? StateHistoryImplementation.Loki
-    : StateHistoryImplementation.Annotations;
+  const implementation = getStateHistoryImplementation();
 
   const ruleUID = rule.grafana_alert.uid;
 
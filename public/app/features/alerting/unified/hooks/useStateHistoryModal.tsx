@@ -12,27 +12,33 @@ const LokiStateHistory = lazy(() => import('../components/rules/state-history/Lo
 
 export enum StateHistoryImplementation {
   Loki = 'loki',
+  SQL = 'sql',
   Annotations = 'annotations',
 }
 
-function useStateHistoryModal() {
-  const [showModal, setShowModal] = useState<boolean>(false);
-  const [rule, setRule] = useState<RulerGrafanaRuleDTO | undefined>();
+// the SQL backend returns the state history in the same format as Loki
+const lokiFormatImplementations: string[] = [StateHistoryImplementation.Loki, StateHistoryImplementation.SQL];
 
-  const styles = useStyles2(getStyles);
-
-  // can be "loki", "multiple" or "annotations"
+export function getStateHistoryImplementation() {
+  // can be "loki", "sql", "multiple" or "annotations"
   const stateHistoryBackend = config.unifiedAlerting.alertStateHistoryBackend;
-  // can be "loki" or "annotations"
+  // can be "loki", "sql" or "annotations"
   const stateHistoryPrimary = config.unifiedAlerting.alertStateHistoryPrimary;
 
-  // if "loki" is either the backend or the primary, show the new state history implementation
+  // if "loki" or "sql" is either the backend or the primary, show the new state history implementation
   const usingNewAlertStateHistory = [stateHistoryBackend, stateHistoryPrimary].some(
-    (implementation) => implementation === StateHistoryImplementation.Loki
+    (implementation) => implementation !== undefined && lokiFormatImplementations.includes(implementation)
   );
-  const implementation = usingNewAlertStateHistory
-    ? StateHistoryImplementation.Loki
-    : StateHistoryImplementation.Annotations;
+  return usingNewAlertStateHistory ? StateHistoryImplementation.Loki : StateHistoryImplementation.Annotations;
+}
+
+function useStateHistoryModal() {
+  const [showModal, setShowModal] = useState<boolean>(false);
+  const [rule, setRule] = useState<RulerGrafanaRuleDTO | undefined>();
+
+  const styles = useStyles2(getStyles);
+
+  const implementation = getStateHistoryImplementation();
 
   const dismissModal = useCallback(() => {
     setRule(undefined);
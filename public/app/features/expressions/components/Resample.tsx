@@ -1,8 +1,8 @@
-import { ChangeEvent } from 'react';
+import { ChangeEvent, FormEvent } from 'react';
 
 import { SelectableValue } from '@grafana/data';
 import { t } from '@grafana/i18n';
-import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
 
 import { downsamplingTypes, ExpressionQuery, upsamplingTypes } from '../types';
 
@@ -33,6 +33,15 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
     onChange({ ...query, upsampler: value.value });
   };
 
+  const onFillValueChange = (event: FormEvent<HTMLInputElement>) => {
+    const value = event.currentTarget.valueAsNumber;
+    onChange({ ...query, fillValue: isNaN(value) ? 0 : value });
+  };
+
+  const onAlignChange = (event: FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, align: event.currentTarget.checked });
+  };
+
   return (
     <>
       <InlineFieldRow>
@@ -54,6 +63,20 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
         <InlineField label={t('expressions.resample.label-upsample', 'Upsample')}>
           <Select options={upsamplingTypes} value={upsampler} onChange={onSelectUpsampler} width={25} />
         </InlineField>
+        {query.upsampler === 'fillvalue' && (
+          <InlineField label={t('expressions.resample.label-fill-value', 'Fill value')}>
+            <Input type="number" width={10} onChange={onFillValueChange} value={query.fillValue ?? 0} />
+          </InlineField>
+        )}
+        <InlineField
+          label={t('expressions.resample.label-align', 'Align')}
+          tooltip={t(
+            'expressions.resample.tooltip-align',
+            'Align the resampled points to multiples of the window, for example to the start of each minute or hour'
+          )}
+        >
+          <InlineSwitch value={query.align ?? false} onChange={onAlignChange} />
+        </InlineField>
       </InlineFieldRow>
     </>
   );
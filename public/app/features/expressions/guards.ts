@@ -34,5 +34,11 @@ export function isReducerType(value: string): value is ReducerType {
     'percent_diff',
     'percent_diff_abs',
     'count_non_null',
+    'first',
+    'range',
+    'stddev',
+    'p90',
+    'p95',
+    'p99',
   ].includes(value);
 }
@@ -83,6 +83,14 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first values' },
+  { value: ReducerID.range, label: 'Range', description: 'Get the difference between the maximum and minimum values' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of all values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
+  { value: ReducerID.p90, label: '90th percentile', description: 'Get the 90th percentile value' },
+  { value: ReducerID.p95, label: '95th percentile', description: 'Get the 95th percentile value' },
+  { value: ReducerID.p99, label: '99th percentile', description: 'Get the 99th percentile value' },
 ];
 
 export enum ReducerMode {
@@ -115,12 +123,32 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: ReducerID.median, label: 'Median', description: 'Fill with the median value' },
+  { value: ReducerID.count, label: 'Count', description: 'Fill with the number of values' },
+  { value: ReducerID.first, label: 'First', description: 'Fill with the first value' },
+  {
+    value: ReducerID.diff,
+    label: 'Difference',
+    description: 'Fill with the difference between the last and first values',
+  },
+  {
+    value: ReducerID.range,
+    label: 'Range',
+    description: 'Fill with the difference between the maximum and minimum values',
+  },
+  { value: 'stddev', label: 'Standard deviation', description: 'Fill with the standard deviation of all values' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Fill with the number of non-null values' },
+  { value: ReducerID.p90, label: '90th percentile', description: 'Fill with the 90th percentile value' },
+  { value: ReducerID.p95, label: '95th percentile', description: 'Fill with the 95th percentile value' },
+  { value: ReducerID.p99, label: '99th percentile', description: 'Fill with the 99th percentile value' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'Interpolate between the last and the next known value' },
+  { value: 'fillvalue', label: 'fillvalue', description: 'Fill with a constant value' },
 ];
 
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
@@ -147,6 +175,8 @@ export interface ExpressionQuery extends DataQuery {
   window?: string;
   downsampler?: string;
   upsampler?: string;
+  fillValue?: number;
+  align?: boolean;
   conditions?: ClassicCondition[];
   settings?: ExpressionQuerySettings;
 }
@@ -198,4 +228,10 @@ export type ReducerType =
   | 'diff_abs'
   | 'percent_diff'
   | 'percent_diff_abs'
-  | 'count_non_null';
+  | 'count_non_null'
+  | 'first'
+  | 'range'
+  | 'stddev'
+  | 'p90'
+  | 'p95'
+  | 'p99';